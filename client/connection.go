@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/simplestreams"
@@ -120,6 +121,7 @@ func ConnectPublicLXD(url string, args *ConnectionArgs) (ImageServer, error) {
 // ConnectSimpleStreams lets you connect to a remote SimpleStreams image server over HTTPs.
 //
 // Unless the remote server is trusted by the system CA, the remote certificate must be provided (TLSServerCert).
+// Local mirrors can be used through file:// URLs.
 func ConnectSimpleStreams(url string, args *ConnectionArgs) (ImageServer, error) {
 	logger.Debugf("Connecting to a remote simplestreams server")
 
@@ -140,6 +142,11 @@ func ConnectSimpleStreams(url string, args *ConnectionArgs) (ImageServer, error)
 	if err != nil {
 		return nil, err
 	}
+	// Allow for local mirrors
+	if strings.HasPrefix(url, "file://") {
+		httpClient.Transport.(*http.Transport).RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	}
+
 	server.http = httpClient
 
 	// Get simplestreams client
@@ -3,6 +3,7 @@ package lxd
 import (
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 
@@ -44,21 +45,26 @@ type ContainerServer interface {
 	// Server functions
 	GetServer() (server *api.Server, ETag string, err error)
 	GetServerResources() (resources *api.Resources, err error)
+	GetMetadataConfiguration() (metadata *api.MetadataConfiguration, err error)
 	UpdateServer(server api.ServerPut, ETag string) (err error)
 	HasExtension(extension string) (exists bool)
 	RequireAuthenticated(authenticated bool)
+	UseProject(name string) (client ContainerServer)
 
 	// Certificate functions
 	GetCertificateFingerprints() (fingerprints []string, err error)
 	GetCertificates() (certificates []api.Certificate, err error)
 	GetCertificate(fingerprint string) (certificate *api.Certificate, ETag string, err error)
 	CreateCertificate(certificate api.CertificatesPost) (err error)
+	CreateCertificateToken(certificate api.CertificatesPost) (op *Operation, err error)
 	UpdateCertificate(fingerprint string, certificate api.CertificatePut, ETag string) (err error)
 	DeleteCertificate(fingerprint string) (err error)
 
 	// Container functions
 	GetContainerNames() (names []string, err error)
 	GetContainers() (containers []api.Container, err error)
+	GetContainersWithFilter(filters []string) (containers []api.Container, err error)
+	GetContainersFull(filters []string, fields []string) (containers []api.ContainerFull, err error)
 	GetContainer(name string) (container *api.Container, ETag string, err error)
 	CreateContainer(container api.ContainersPost) (op *Operation, err error)
 	CreateContainerFromImage(source ImageServer, image api.Image, imgcontainer api.ContainersPost) (op *RemoteOperation, err error)
@@ -67,6 +73,8 @@ type ContainerServer interface {
 	RenameContainer(name string, container api.ContainerPost) (op *Operation, err error)
 	MigrateContainer(name string, container api.ContainerPost) (op *Operation, err error)
 	DeleteContainer(name string) (op *Operation, err error)
+	RebuildContainer(name string, container api.ContainerRebuildPost) (op *Operation, err error)
+	RebuildContainerFromImage(source ImageServer, image api.Image, name string, req api.ContainerRebuildPost) (op *RemoteOperation, err error)
 
 	ExecContainer(containerName string, exec api.ContainerExecPost, args *ContainerExecArgs) (op *Operation, err error)
 	ConsoleContainer(containerName string, console api.ContainerConsolePost, args *ContainerConsoleArgs) (op *Operation, err error)
@@ -86,6 +94,15 @@ type ContainerServer interface {
 	MigrateContainerSnapshot(containerName string, name string, container api.ContainerSnapshotPost) (op *Operation, err error)
 	DeleteContainerSnapshot(containerName string, name string) (op *Operation, err error)
 
+	GetContainerBackupNames(containerName string) (names []string, err error)
+	GetContainerBackups(containerName string) (backups []api.ContainerBackup, err error)
+	GetContainerBackup(containerName string, name string) (backup *api.ContainerBackup, ETag string, err error)
+	CreateContainerBackup(containerName string, req api.ContainerBackupsPost) (op *Operation, err error)
+	RenameContainerBackup(containerName string, name string, backup api.ContainerBackupPost) (op *Operation, err error)
+	DeleteContainerBackup(containerName string, name string) (op *Operation, err error)
+	GetContainerBackupFile(containerName string, name string, req *BackupFileRequest) (resp *BackupFileResponse, err error)
+	CreateContainerFromBackup(args ContainerBackupArgs) (op *Operation, err error)
+
 	GetContainerState(name string) (state *api.ContainerState, ETag string, err error)
 	UpdateContainerState(name string, state api.ContainerStatePut, ETag string) (op *Operation, err error)
 
@@ -106,6 +123,7 @@ type ContainerServer interface {
 	GetEvents() (listener *EventListener, err error)
 
 	// Image functions
+	GetImagesWithFilter(filters []string) (images []api.Image, err error)
 	CreateImage(image api.ImagesPost, args *ImageCreateArgs) (op *Operation, err error)
 	CopyImage(source ImageServer, image api.Image, args *ImageCopyArgs) (op *RemoteOperation, err error)
 	UpdateImage(fingerprint string, image api.ImagePut, ETag string) (err error)
@@ -120,7 +138,10 @@ type ContainerServer interface {
 	// Network functions ("network" API extension)
 	GetNetworkNames() (names []string, err error)
 	GetNetworks() (networks []api.Network, err error)
+	GetNetworksWithFilter(filters []string) (networks []api.Network, err error)
 	GetNetwork(name string) (network *api.Network, ETag string, err error)
+	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
+	GetNetworkState(name string) (state *api.NetworkState, err error)
 	CreateNetwork(network api.NetworksPost) (err error)
 	UpdateNetwork(name string, network api.NetworkPut, ETag string) (err error)
 	RenameNetwork(name string, network api.NetworkPost) (err error)
@@ -129,6 +150,7 @@ type ContainerServer interface {
 	// Operation functions
 	GetOperationUUIDs() (uuids []string, err error)
 	GetOperations() (operations []api.Operation, err error)
+	GetOperationsHistory(since time.Time, until time.Time) (operations []api.Operation, err error)
 	GetOperation(uuid string) (op *api.Operation, ETag string, err error)
 	DeleteOperation(uuid string) (err error)
 	GetOperationWebsocket(uuid string, secret string) (conn *websocket.Conn, err error)
@@ -136,12 +158,22 @@ type ContainerServer interface {
 	// Profile functions
 	GetProfileNames() (names []string, err error)
 	GetProfiles() (profiles []api.Profile, err error)
+	GetProfilesWithFilter(filters []string) (profiles []api.Profile, err error)
 	GetProfile(name string) (profile *api.Profile, ETag string, err error)
 	CreateProfile(profile api.ProfilesPost) (err error)
 	UpdateProfile(name string, profile api.ProfilePut, ETag string) (err error)
 	RenameProfile(name string, profile api.ProfilePost) (err error)
 	DeleteProfile(name string) (err error)
 
+	// Project functions ("projects" API extension)
+	GetProjectNames() (names []string, err error)
+	GetProjects() (projects []api.Project, err error)
+	GetProject(name string) (project *api.Project, ETag string, err error)
+	CreateProject(project api.ProjectsPost) (err error)
+	UpdateProject(name string, project api.ProjectPut, ETag string) (err error)
+	RenameProject(name string, project api.ProjectPost) (err error)
+	DeleteProject(name string) (err error)
+
 	// Storage pool functions ("storage" API extension)
 	GetStoragePoolNames() (names []string, err error)
 	GetStoragePools() (pools []api.StoragePool, err error)
@@ -154,11 +186,22 @@ type ContainerServer interface {
 	// Storage volume functions ("storage" API extension)
 	GetStoragePoolVolumeNames(pool string) (names []string, err error)
 	GetStoragePoolVolumes(pool string) (volumes []api.StorageVolume, err error)
+	GetStoragePoolVolumesWithFilter(pool string, filters []string) (volumes []api.StorageVolume, err error)
 	GetStoragePoolVolume(pool string, volType string, name string) (volume *api.StorageVolume, ETag string, err error)
 	CreateStoragePoolVolume(pool string, volume api.StorageVolumesPost) (err error)
 	UpdateStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePut, ETag string) (err error)
 	DeleteStoragePoolVolume(pool string, volType string, name string) (err error)
 	RenameStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePost) (err error)
+	CopyStoragePoolVolume(pool string, source ContainerServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeCopyArgs) (op *RemoteOperation, err error)
+	MigrateStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePost) (op *Operation, err error)
+
+	// Storage volume snapshot functions ("storage_api_volume_snapshots" API extension)
+	GetStoragePoolVolumeSnapshotNames(pool string, volType string, volName string) (names []string, err error)
+	GetStoragePoolVolumeSnapshots(pool string, volType string, volName string) (snapshots []api.StorageVolumeSnapshot, err error)
+	GetStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshotName string) (snapshot *api.StorageVolumeSnapshot, err error)
+	CreateStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshot api.StorageVolumeSnapshotsPost) (op *Operation, err error)
+	RenameStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshotName string, snapshot api.StorageVolumeSnapshotPost) (op *Operation, err error)
+	DeleteStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshotName string) (op *Operation, err error)
 
 	// Internal functions (for internal use)
 	RawQuery(method string, path string, data interface{}, queryETag string) (resp *api.Response, ETag string, err error)
@@ -254,6 +297,21 @@ type ImageCopyArgs struct {
 	Public bool
 }
 
+// The StoragePoolVolumeCopyArgs struct is used to pass additional options
+// during storage volume copy
+type StoragePoolVolumeCopyArgs struct {
+	// New name for the target
+	Name string
+
+	// The transfer mode, can be "pull" (default), "push" or "relay"
+	// API extension: storage_api_remote_volume_handling
+	Mode string
+
+	// API extension: storage_api_remote_volume_handling
+	// If set, only the volume will be copied, its snapshots won't
+	VolumeOnly bool
+}
+
 // The ContainerCopyArgs struct is used to pass additional options during container copy
 type ContainerCopyArgs struct {
 	// If set, the container will be renamed on copy
@@ -282,6 +340,30 @@ type ContainerSnapshotCopyArgs struct {
 	Live bool
 }
 
+// The ContainerBackupArgs struct is used when creating a container from a backup
+type ContainerBackupArgs struct {
+	// The backup file
+	BackupFile io.Reader
+}
+
+// The BackupFileRequest struct is used for a backup download request
+type BackupFileRequest struct {
+	// Writer for the backup file
+	BackupFile io.WriteSeeker
+
+	// Progress handler (called whenever some progress is made)
+	ProgressHandler func(progress ProgressData)
+
+	// A canceler that can be used to interrupt the backup download request
+	Canceler *cancel.Canceler
+}
+
+// The BackupFileResponse struct is used as the response for backup downloads
+type BackupFileResponse struct {
+	// Size of backup file
+	Size int64
+}
+
 // The ContainerConsoleArgs struct is used to pass additional options during a
 // container console session
 type ContainerConsoleArgs struct {
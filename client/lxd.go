@@ -35,6 +35,8 @@ type ProtocolLXD struct {
 	bakeryClient         *httpbakery.Client
 	bakeryInteractor     httpbakery.Interactor
 	requireAuthenticated bool
+
+	project string
 }
 
 // GetConnectionInfo returns the basic connection information used to interact with the server
@@ -93,6 +95,22 @@ func (r *ProtocolLXD) RequireAuthenticated(authenticated bool) {
 	r.requireAuthenticated = authenticated
 }
 
+// UseProject returns a client that will use a specific project
+func (r *ProtocolLXD) UseProject(name string) ContainerServer {
+	return &ProtocolLXD{
+		server:               r.server,
+		http:                 r.http,
+		httpCertificate:      r.httpCertificate,
+		httpHost:             r.httpHost,
+		httpProtocol:         r.httpProtocol,
+		httpUserAgent:        r.httpUserAgent,
+		bakeryClient:         r.bakeryClient,
+		bakeryInteractor:     r.bakeryInteractor,
+		requireAuthenticated: r.requireAuthenticated,
+		project:              name,
+	}
+}
+
 // RawQuery allows directly querying the LXD API
 //
 // This should only be used by internal LXD tools.
@@ -206,9 +224,36 @@ func (r *ProtocolLXD) query(method string, path string, data interface{}, ETag s
 	// Generate the URL
 	url := fmt.Sprintf("%s/1.0%s", r.httpHost, path)
 
+	// Add project
+	url, err := r.setQueryAttributes(url)
+	if err != nil {
+		return nil, "", err
+	}
+
 	return r.rawQuery(method, url, data, ETag)
 }
 
+// setQueryAttributes adds the project the client is using to the given URL,
+// unless it's the default one or the URL already specifies a project.
+func (r *ProtocolLXD) setQueryAttributes(uri string) (string, error) {
+	if r.project == "" || r.project == "default" {
+		return uri, nil
+	}
+
+	fields, err := neturl.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+
+	values := fields.Query()
+	if values.Get("project") == "" {
+		values.Set("project", r.project)
+	}
+	fields.RawQuery = values.Encode()
+
+	return fields.String(), nil
+}
+
 func (r *ProtocolLXD) queryStruct(method string, path string, data interface{}, ETag string, target interface{}) (string, error) {
 	resp, etag, err := r.query(method, path, data, ETag)
 	if err != nil {
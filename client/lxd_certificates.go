@@ -57,6 +57,14 @@ func (r *ProtocolLXD) GetCertificate(fingerprint string) (*api.Certificate, stri
 
 // CreateCertificate adds a new certificate to the LXD trust store
 func (r *ProtocolLXD) CreateCertificate(certificate api.CertificatesPost) error {
+	if certificate.Restricted && !r.HasExtension("certificate_restrictions") {
+		return fmt.Errorf("The server is missing the required \"certificate_restrictions\" API extension")
+	}
+
+	if certificate.TrustToken != "" && !r.HasExtension("certificate_token") {
+		return fmt.Errorf("The server is missing the required \"certificate_token\" API extension")
+	}
+
 	// Send the request
 	_, _, err := r.query("POST", "/certificates", certificate, "")
 	if err != nil {
@@ -66,12 +74,35 @@ func (r *ProtocolLXD) CreateCertificate(certificate api.CertificatesPost) error
 	return nil
 }
 
+// CreateCertificateToken requests a single-use join token, letting a client add itself to the trust store
+func (r *ProtocolLXD) CreateCertificateToken(certificate api.CertificatesPost) (*Operation, error) {
+	if !r.HasExtension("certificate_token") {
+		return nil, fmt.Errorf("The server is missing the required \"certificate_token\" API extension")
+	}
+
+	if certificate.Restricted && !r.HasExtension("certificate_restrictions") {
+		return nil, fmt.Errorf("The server is missing the required \"certificate_restrictions\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", "/certificates?token=1", certificate, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // UpdateCertificate updates the certificate definition
 func (r *ProtocolLXD) UpdateCertificate(fingerprint string, certificate api.CertificatePut, ETag string) error {
 	if !r.HasExtension("certificate_update") {
 		return fmt.Errorf("The server is missing the required \"certificate_update\" API extension")
 	}
 
+	if certificate.Restricted && !r.HasExtension("certificate_restrictions") {
+		return fmt.Errorf("The server is missing the required \"certificate_restrictions\" API extension")
+	}
+
 	// Send the request
 	_, _, err := r.query("PUT", fmt.Sprintf("/certificates/%s", fingerprint), certificate, ETag)
 	if err != nil {
@@ -32,6 +32,23 @@ func (r *ProtocolLXD) GetImages() ([]api.Image, error) {
 	return images, nil
 }
 
+// GetImagesWithFilter returns a list of images matching the given filter terms
+func (r *ProtocolLXD) GetImagesWithFilter(filters []string) ([]api.Image, error) {
+	if !r.HasExtension("api_filtering") {
+		return nil, fmt.Errorf("The server is missing the required \"api_filtering\" API extension")
+	}
+
+	images := []api.Image{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/images?%s", filterQuery(filters, nil)), nil, "", &images)
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
 // GetImageFingerprints returns a list of available image fingerprints
 func (r *ProtocolLXD) GetImageFingerprints() ([]string, error) {
 	urls := []string{}
@@ -45,7 +62,7 @@ func (r *ProtocolLXD) GetImageFingerprints() ([]string, error) {
 	// Parse it
 	fingerprints := []string{}
 	for _, url := range urls {
-		fields := strings.Split(url, "/images/")
+		fields := strings.Split(strings.SplitN(url, "?", 2)[0], "/images/")
 		fingerprints = append(fingerprints, fields[len(fields)-1])
 	}
 
@@ -107,6 +124,12 @@ func (r *ProtocolLXD) GetPrivateImageFile(fingerprint string, secret string, req
 		url = fmt.Sprintf("%s?secret=%s", url, secret)
 	}
 
+	// Add project
+	url, err := r.setQueryAttributes(url)
+	if err != nil {
+		return nil, err
+	}
+
 	// Prepare the download request
 	request, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -259,7 +282,7 @@ func (r *ProtocolLXD) GetImageAliasNames() ([]string, error) {
 	// Parse it
 	names := []string{}
 	for _, url := range urls {
-		fields := strings.Split(url, "/images/aliases/")
+		fields := strings.Split(strings.SplitN(url, "?", 2)[0], "/images/aliases/")
 		names = append(names, fields[len(fields)-1])
 	}
 
@@ -373,6 +396,13 @@ func (r *ProtocolLXD) CreateImage(image api.ImagesPost, args *ImageCreateArgs) (
 
 	// Prepare the HTTP request
 	reqURL := fmt.Sprintf("%s/1.0/images", r.httpHost)
+
+	// Add project
+	reqURL, err := r.setQueryAttributes(reqURL)
+	if err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequest("POST", reqURL, body)
 	if err != nil {
 		return nil, err
@@ -2,7 +2,9 @@ package lxd
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 
@@ -50,6 +52,43 @@ func (r *ProtocolLXD) GetOperations() ([]api.Operation, error) {
 	return operations, nil
 }
 
+// GetOperationsHistory returns the operations created in the given time
+// range, including the finished ones kept in the history. A zero time leaves
+// the range open on that end.
+func (r *ProtocolLXD) GetOperationsHistory(since time.Time, until time.Time) ([]api.Operation, error) {
+	if !r.HasExtension("operation_history") {
+		return nil, fmt.Errorf("The server is missing the required \"operation_history\" API extension")
+	}
+
+	apiOperations := map[string][]api.Operation{}
+
+	values := url.Values{}
+	values.Set("recursion", "1")
+	values.Set("all", "1")
+
+	if !since.IsZero() {
+		values.Set("since", since.UTC().Format(time.RFC3339))
+	}
+
+	if !until.IsZero() {
+		values.Set("until", until.UTC().Format(time.RFC3339))
+	}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/operations?%s", values.Encode()), nil, "", &apiOperations)
+	if err != nil {
+		return nil, err
+	}
+
+	// Turn it into just a list of operations
+	operations := []api.Operation{}
+	for _, v := range apiOperations {
+		operations = append(operations, v...)
+	}
+
+	return operations, nil
+}
+
 // GetOperation returns an Operation entry for the provided uuid
 func (r *ProtocolLXD) GetOperation(uuid string) (*api.Operation, string, error) {
 	op := api.Operation{}
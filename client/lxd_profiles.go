@@ -22,7 +22,7 @@ func (r *ProtocolLXD) GetProfileNames() ([]string, error) {
 	// Parse it
 	names := []string{}
 	for _, url := range urls {
-		fields := strings.Split(url, "/profiles/")
+		fields := strings.Split(strings.SplitN(url, "?", 2)[0], "/profiles/")
 		names = append(names, fields[len(fields)-1])
 	}
 
@@ -42,6 +42,23 @@ func (r *ProtocolLXD) GetProfiles() ([]api.Profile, error) {
 	return profiles, nil
 }
 
+// GetProfilesWithFilter returns a list of profiles matching the given filter terms
+func (r *ProtocolLXD) GetProfilesWithFilter(filters []string) ([]api.Profile, error) {
+	if !r.HasExtension("api_filtering") {
+		return nil, fmt.Errorf("The server is missing the required \"api_filtering\" API extension")
+	}
+
+	profiles := []api.Profile{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/profiles?%s", filterQuery(filters, nil)), nil, "", &profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
 // GetProfile returns a Profile entry for the provided name
 func (r *ProtocolLXD) GetProfile(name string) (*api.Profile, string, error) {
 	profile := api.Profile{}
@@ -77,3 +77,20 @@ func (r *ProtocolLXD) GetServerResources() (*api.Resources, error) {
 
 	return &resources, nil
 }
+
+// GetMetadataConfiguration returns the description of every configuration key supported by the server
+func (r *ProtocolLXD) GetMetadataConfiguration() (*api.MetadataConfiguration, error) {
+	if !r.HasExtension("metadata_configuration") {
+		return nil, fmt.Errorf("The server is missing the required \"metadata_configuration\" API extension")
+	}
+
+	metadata := api.MetadataConfiguration{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", "/metadata/configuration", nil, "", &metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
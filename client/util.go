@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/cancel"
@@ -142,6 +143,25 @@ func downloadFileSha256(httpClient *http.Client, useragent string, progress func
 	return size, nil
 }
 
+// filterQuery returns the query string selecting the given filter terms and
+// optional fields of a listing.
+func filterQuery(filters []string, fields []string) string {
+	values := url.Values{}
+	values.Set("recursion", "1")
+
+	for _, filter := range filters {
+		if filter != "" {
+			values.Add("filter", filter)
+		}
+	}
+
+	if len(fields) > 0 {
+		values.Set("fields", strings.Join(fields, ","))
+	}
+
+	return values.Encode()
+}
+
 type nullReadWriteCloser int
 
 func (nullReadWriteCloser) Close() error                { return nil }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
@@ -25,7 +26,10 @@ import (
 )
 
 type configCmd struct {
-	expanded bool
+	expanded   bool
+	restricted bool
+	projects   string
+	name       string
 }
 
 func (c *configCmd) showByDefault() bool {
@@ -34,6 +38,9 @@ func (c *configCmd) showByDefault() bool {
 
 func (c *configCmd) flags() {
 	gnuflag.BoolVar(&c.expanded, "expanded", false, i18n.G("Show the expanded configuration"))
+	gnuflag.BoolVar(&c.restricted, "restricted", false, i18n.G("Restrict the certificate to the projects given with --projects"))
+	gnuflag.StringVar(&c.projects, "projects", "", i18n.G("Comma separated list of projects a restricted certificate has access to"))
+	gnuflag.StringVar(&c.name, "name", "", i18n.G("Name of the client a join token is issued for"))
 }
 
 func (c *configCmd) configEditHelp() string {
@@ -104,6 +111,10 @@ lxc config show [<remote>:][container] [--expanded]
 lxc config edit [<remote>:][container]
     Edit configuration, either by launching external editor or reading STDIN.
 
+lxc config keys [<remote>:] <scope>
+    List the configuration keys supported by the server.
+    Scope is one of container, profile, pool, volume, network or server.
+
 *Container metadata*
 
 lxc config metadata show [<remote>:][container]
@@ -158,12 +169,20 @@ lxc config device remove [<remote>:]<container> <name>...
 lxc config trust list [<remote>:]
     List all trusted certs.
 
-lxc config trust add [<remote>:] <certfile.crt>
-    Add certfile.crt to trusted hosts.
+lxc config trust add [<remote>:] [<certfile.crt>] [--name=NAME] [--restricted] [--projects=<project>[,<project>...]]
+    Add certfile.crt to trusted hosts, optionally restricting it to some projects.
+    Without a certificate, print a single-use join token for the client to
+    pass to "lxc remote add".
 
 lxc config trust remove [<remote>:] [hostname|fingerprint]
     Remove the cert from trusted hosts.
 
+lxc config trust list-tokens [<remote>:]
+    List the pending join tokens.
+
+lxc config trust revoke-token [<remote>:] <name>
+    Revoke the join token issued for the given client.
+
 *Examples*
 
 cat config.yaml | lxc config edit <container>
@@ -182,6 +201,118 @@ lxc config set core.trust_password blah
     Will set the server's trust password to blah.`)
 }
 
+func (c *configCmd) doKeysList(d lxd.ContainerServer, scope string) error {
+	metadata, err := d.GetMetadataConfiguration()
+	if err != nil {
+		return err
+	}
+
+	keys, ok := metadata.Configs[scope]
+	if !ok {
+		return fmt.Errorf(i18n.G("Unknown configuration scope: %s"), scope)
+	}
+
+	data := [][]string{}
+	for name, key := range keys {
+		data = append(data, []string{name, key.Type, key.Default, key.Description})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{
+		i18n.G("KEY"),
+		i18n.G("TYPE"),
+		i18n.G("DEFAULT"),
+		i18n.G("DESCRIPTION")})
+	sort.Sort(StringList(data))
+	table.AppendBulk(data)
+	table.Render()
+
+	return nil
+}
+
+func (c *configCmd) doTrustAddToken(d lxd.ContainerServer) error {
+	name := c.name
+	if name == "" {
+		fmt.Printf(i18n.G("Please provide client name: "))
+		line, err := shared.ReadStdin()
+		if err != nil {
+			return err
+		}
+
+		name = string(line)
+	}
+
+	req := api.CertificatesPost{}
+	req.Name = name
+	req.Type = "client"
+	req.Restricted = c.restricted
+	if c.projects != "" {
+		req.Projects = strings.Split(c.projects, ",")
+	}
+
+	op, err := d.CreateCertificateToken(req)
+	if err != nil {
+		return err
+	}
+
+	token, err := trustTokenFromOperation(op.Operation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Client %s certificate add token:")+"\n", name)
+	fmt.Println(token.String())
+
+	return nil
+}
+
+// trustTokens returns the pending join tokens of the server, indexed by the
+// ID of their operation.
+func (c *configCmd) trustTokens(d lxd.ContainerServer) (map[string]*api.CertificateAddToken, error) {
+	operations, err := d.GetOperations()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := map[string]*api.CertificateAddToken{}
+	for _, op := range operations {
+		if op.Class != "token" || op.StatusCode != api.Running {
+			continue
+		}
+
+		_, ok := op.Metadata["client_name"]
+		if !ok {
+			continue
+		}
+
+		token, err := trustTokenFromOperation(op)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens[op.ID] = token
+	}
+
+	return tokens, nil
+}
+
+func trustTokenFromOperation(op api.Operation) (*api.CertificateAddToken, error) {
+	data, err := json.Marshal(op.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	token := api.CertificateAddToken{}
+	err = json.Unmarshal(data, &token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
 func (c *configCmd) doSet(conf *config.Config, args []string, unset bool) error {
 	if len(args) != 4 {
 		return errArgs
@@ -214,6 +345,13 @@ func (c *configCmd) doSet(conf *config.Config, args []string, unset bool) error
 		return err
 	}
 
+	if !unset {
+		err := configKeyValidate(d, "container", key, value)
+		if err != nil {
+			return err
+		}
+	}
+
 	if unset {
 		_, ok := container.Config[key]
 		if !ok {
@@ -313,6 +451,11 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 				return err
 			}
 
+			err = configKeyValidate(c, "server", args[1], args[2])
+			if err != nil {
+				return err
+			}
+
 			server.Config[args[1]] = args[2]
 
 			return c.UpdateServer(server.Writable(), etag)
@@ -335,6 +478,11 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 				return err
 			}
 
+			err = configKeyValidate(c, "server", args[2], args[3])
+			if err != nil {
+				return err
+			}
+
 			server.Config[args[2]] = args[3]
 
 			return c.UpdateServer(server.Writable(), etag)
@@ -343,6 +491,28 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 		// Deal with container
 		return c.doSet(conf, args, false)
 
+	case "keys":
+		if len(args) < 2 || len(args) > 3 {
+			return errArgs
+		}
+
+		remote := conf.DefaultRemote
+		scope := args[len(args)-1]
+		if len(args) == 3 {
+			var err error
+			remote, _, err = conf.ParseRemote(args[1])
+			if err != nil {
+				return err
+			}
+		}
+
+		d, err := conf.GetContainerServer(remote)
+		if err != nil {
+			return err
+		}
+
+		return c.doKeysList(d, scope)
+
 	case "trust":
 		if len(args) < 2 {
 			return errArgs
@@ -407,9 +577,7 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 			return nil
 		case "add":
 			var remote string
-			if len(args) < 3 {
-				return fmt.Errorf(i18n.G("No certificate provided to add"))
-			} else if len(args) == 4 {
+			if len(args) == 4 || (len(args) == 3 && strings.HasSuffix(args[2], ":")) {
 				var err error
 				remote, _, err = conf.ParseRemote(args[2])
 				if err != nil {
@@ -424,6 +592,11 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 				return err
 			}
 
+			// Issue a join token when no certificate is provided
+			if len(args) == 2 || (len(args) == 3 && strings.HasSuffix(args[2], ":")) {
+				return c.doTrustAddToken(d)
+			}
+
 			fname := args[len(args)-1]
 			x509Cert, err := shared.ReadCert(fname)
 			if err != nil {
@@ -435,6 +608,10 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 			cert.Certificate = base64.StdEncoding.EncodeToString(x509Cert.Raw)
 			cert.Name = name
 			cert.Type = "client"
+			cert.Restricted = c.restricted
+			if c.projects != "" {
+				cert.Projects = strings.Split(c.projects, ",")
+			}
 
 			return d.CreateCertificate(cert)
 		case "remove":
@@ -457,6 +634,83 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 			}
 
 			return d.DeleteCertificate(args[len(args)-1])
+		case "list-tokens":
+			var remote string
+			if len(args) == 3 {
+				var err error
+				remote, _, err = conf.ParseRemote(args[2])
+				if err != nil {
+					return err
+				}
+			} else {
+				remote = conf.DefaultRemote
+			}
+
+			d, err := conf.GetContainerServer(remote)
+			if err != nil {
+				return err
+			}
+
+			tokens, err := c.trustTokens(d)
+			if err != nil {
+				return err
+			}
+
+			data := [][]string{}
+			for _, token := range tokens {
+				expiry := ""
+				if !token.ExpiresAt.IsZero() {
+					expiry = token.ExpiresAt.Local().Format("Jan 2, 2006 at 3:04pm (MST)")
+				}
+
+				data = append(data, []string{token.ClientName, token.String(), expiry})
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetAutoWrapText(false)
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			table.SetRowLine(true)
+			table.SetHeader([]string{
+				i18n.G("NAME"),
+				i18n.G("TOKEN"),
+				i18n.G("EXPIRY DATE")})
+			sort.Sort(StringList(data))
+			table.AppendBulk(data)
+			table.Render()
+
+			return nil
+		case "revoke-token":
+			var remote string
+			if len(args) < 3 {
+				return fmt.Errorf(i18n.G("No client name specified."))
+			} else if len(args) == 4 {
+				var err error
+				remote, _, err = conf.ParseRemote(args[2])
+				if err != nil {
+					return err
+				}
+			} else {
+				remote = conf.DefaultRemote
+			}
+
+			d, err := conf.GetContainerServer(remote)
+			if err != nil {
+				return err
+			}
+
+			tokens, err := c.trustTokens(d)
+			if err != nil {
+				return err
+			}
+
+			name := args[len(args)-1]
+			for id, token := range tokens {
+				if token.ClientName == name {
+					return d.DeleteOperation(id)
+				}
+			}
+
+			return fmt.Errorf(i18n.G("No join token for client %s"), name)
 		default:
 			return errArgs
 		}
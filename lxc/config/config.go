@@ -27,6 +27,9 @@ type Config struct {
 	// The UserAgent to pass for all queries
 	UserAgent string `yaml:"-"`
 
+	// The project to use for all queries, if not the default one
+	ProjectOverride string `yaml:"-"`
+
 	authInteractor httpbakery.Interactor
 
 	cookiejar *cookiejar.Jar
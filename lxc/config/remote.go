@@ -64,6 +64,10 @@ func (c *Config) GetContainerServer(name string) (lxd.ContainerServer, error) {
 			return nil, err
 		}
 
+		if c.ProjectOverride != "" {
+			return d.UseProject(c.ProjectOverride), nil
+		}
+
 		return d, nil
 	}
 
@@ -77,6 +81,10 @@ func (c *Config) GetContainerServer(name string) (lxd.ContainerServer, error) {
 		return nil, err
 	}
 
+	if c.ProjectOverride != "" {
+		return d.UseProject(c.ProjectOverride), nil
+	}
+
 	return d, nil
 }
 
@@ -101,6 +109,10 @@ func (c *Config) GetImageServer(name string) (lxd.ImageServer, error) {
 			return nil, err
 		}
 
+		if c.ProjectOverride != "" {
+			return d.UseProject(c.ProjectOverride), nil
+		}
+
 		return d, nil
 	}
 
@@ -130,6 +142,10 @@ func (c *Config) GetImageServer(name string) (lxd.ImageServer, error) {
 		return nil, err
 	}
 
+	if c.ProjectOverride != "" {
+		return d.UseProject(c.ProjectOverride), nil
+	}
+
 	return d, nil
 }
 
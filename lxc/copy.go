@@ -225,7 +225,7 @@ func (c *copyCmd) copyContainer(conf *config.Config, sourceResource string,
 		}
 
 		// Extract the name of the container
-		fields := strings.Split(containers[0], "/")
+		fields := strings.Split(strings.SplitN(containers[0], "?", 2)[0], "/")
 		fmt.Printf(i18n.G("Container name is: %s")+"\n", fields[len(fields)-1])
 	}
 
@@ -43,6 +43,9 @@ type execCmd struct {
 	forceInteractive    bool
 	forceNonInteractive bool
 	disableStdin        bool
+	user                string
+	group               string
+	cwd                 string
 }
 
 func (c *execCmd) showByDefault() bool {
@@ -51,11 +54,14 @@ func (c *execCmd) showByDefault() bool {
 
 func (c *execCmd) usage() string {
 	return i18n.G(
-		`Usage: lxc exec [<remote>:]<container> [-t] [-T] [-n] [--mode=auto|interactive|non-interactive] [--env KEY=VALUE...] [--] <command line>
+		`Usage: lxc exec [<remote>:]<container> [-t] [-T] [-n] [--mode=auto|interactive|non-interactive] [--env KEY=VALUE...] [--user=USER] [--group=GROUP] [--cwd=PATH] [--] <command line>
 
 Execute commands in containers.
 
-Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).`)
+Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).
+
+Commands run as root in /root unless --user, --group or --cwd are passed.
+The user and group can be names or numeric IDs.`)
 }
 
 func (c *execCmd) flags() {
@@ -64,6 +70,9 @@ func (c *execCmd) flags() {
 	gnuflag.BoolVar(&c.forceInteractive, "t", false, i18n.G("Force pseudo-terminal allocation"))
 	gnuflag.BoolVar(&c.forceNonInteractive, "T", false, i18n.G("Disable pseudo-terminal allocation"))
 	gnuflag.BoolVar(&c.disableStdin, "n", false, i18n.G("Disable stdin (reads from /dev/null)"))
+	gnuflag.StringVar(&c.user, "user", "", i18n.G("User to run the command as"))
+	gnuflag.StringVar(&c.group, "group", "", i18n.G("Group to run the command as"))
+	gnuflag.StringVar(&c.cwd, "cwd", "", i18n.G("Directory to run the command in"))
 }
 
 func (c *execCmd) sendTermSize(control *websocket.Conn) error {
@@ -207,6 +216,9 @@ func (c *execCmd) run(conf *config.Config, args []string) error {
 		Environment: env,
 		Width:       width,
 		Height:      height,
+		User:        c.user,
+		Group:       c.group,
+		Cwd:         c.cwd,
 	}
 
 	execArgs := lxd.ContainerExecArgs{
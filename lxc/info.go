@@ -131,6 +131,12 @@ func (c *infoCmd) containerInfo(d lxd.ContainerServer, remote config.Remote, nam
 		fmt.Printf(i18n.G("Type: persistent") + "\n")
 	}
 	fmt.Printf(i18n.G("Profiles: %s")+"\n", strings.Join(ct.Profiles, ", "))
+	if cs.Health != nil {
+		fmt.Printf(i18n.G("Health: %s")+"\n", cs.Health.Status)
+	}
+	if cs.Restarts > 0 {
+		fmt.Printf(i18n.G("Restarts: %d")+"\n", cs.Restarts)
+	}
 	if cs.Pid != 0 {
 		fmt.Printf(i18n.G("Pid: %d")+"\n", cs.Pid)
 
@@ -225,6 +231,17 @@ func (c *infoCmd) containerInfo(d lxd.ContainerServer, remote config.Remote, nam
 		}
 	}
 
+	// Snapshot schedule
+	schedule := ct.ExpandedConfig["snapshots.schedule"]
+	if schedule != "" {
+		fmt.Printf(i18n.G("Snapshot schedule: %s")+"\n", schedule)
+
+		expiry := ct.ExpandedConfig["snapshots.expiry"]
+		if expiry != "" {
+			fmt.Printf(i18n.G("Snapshot expiry: %s")+"\n", expiry)
+		}
+	}
+
 	// List snapshots
 	first_snapshot := true
 	snaps, err := d.GetContainerSnapshots(name)
@@ -244,6 +261,10 @@ func (c *infoCmd) containerInfo(d lxd.ContainerServer, remote config.Remote, nam
 			fmt.Printf(" ("+i18n.G("taken at %s")+")", snap.CreationDate.UTC().Format(layout))
 		}
 
+		if shared.TimeIsSet(snap.ExpiresAt) {
+			fmt.Printf(" ("+i18n.G("expires at %s")+")", snap.ExpiresAt.UTC().Format(layout))
+		}
+
 		if snap.Stateful {
 			fmt.Printf(" (" + i18n.G("stateful") + ")")
 		} else {
@@ -312,7 +312,7 @@ func (c *initCmd) create(conf *config.Config, args []string) (lxd.ContainerServe
 	}
 
 	if len(containers) == 1 && name == "" {
-		fields := strings.Split(containers[0], "/")
+		fields := strings.Split(strings.SplitN(containers[0], "?", 2)[0], "/")
 		name = fields[len(fields)-1]
 		fmt.Printf(i18n.G("Container name is: %s")+"\n", name)
 	}
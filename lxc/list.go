@@ -65,6 +65,10 @@ A key/value pair referring to a configuration item. For those, the namespace can
 
 A regular expression matching a configuration item or its value. (e.g. volatile.eth0.hwaddr=00:16:3e:.*).
 
+When the server supports it, the filtering is done by the server and the
+key can also refer to another property of the container or of its state,
+using the same names as the API. (e.g. status=Running or state.pid=1234).
+
 *Columns*
 The -c option takes a comma separated list of arguments that control
 which container attributes to output when displaying in table or csv
@@ -214,7 +218,7 @@ func (c *listCmd) shouldShow(filters []string, state *api.Container) bool {
 	return true
 }
 
-func (c *listCmd) listContainers(conf *config.Config, remote string, cinfos []api.Container, filters []string, columns []column) error {
+func (c *listCmd) listContainers(conf *config.Config, remote string, cinfos []api.Container, cStates map[string]*api.ContainerState, cSnapshots map[string][]api.ContainerSnapshot, filters []string, columns []column) error {
 	headers := []string{}
 	for _, column := range columns {
 		headers = append(headers, column.Name)
@@ -225,12 +229,10 @@ func (c *listCmd) listContainers(conf *config.Config, remote string, cinfos []ap
 		threads = len(cinfos)
 	}
 
-	cStates := map[string]*api.ContainerState{}
 	cStatesLock := sync.Mutex{}
 	cStatesQueue := make(chan string, threads)
 	cStatesWg := sync.WaitGroup{}
 
-	cSnapshots := map[string][]api.ContainerSnapshot{}
 	cSnapshotsLock := sync.Mutex{}
 	cSnapshotsQueue := make(chan string, threads)
 	cSnapshotsWg := sync.WaitGroup{}
@@ -432,6 +434,49 @@ func (c *listCmd) run(conf *config.Config, args []string) error {
 		return err
 	}
 
+	columns, err := c.parseColumns()
+	if err != nil {
+		return err
+	}
+
+	cStates := map[string]*api.ContainerState{}
+	cSnapshots := map[string][]api.ContainerSnapshot{}
+
+	// Let the server do the filtering and return the state and snapshots
+	// of the containers along with them when it can.
+	if d.HasExtension("api_filtering") {
+		fields := []string{}
+		for _, column := range columns {
+			if column.NeedsState && !shared.StringInSlice("state", fields) {
+				fields = append(fields, "state")
+			}
+
+			if column.NeedsSnapshots && !shared.StringInSlice("snapshots", fields) {
+				fields = append(fields, "snapshots")
+			}
+		}
+
+		ctslist, err := d.GetContainersFull(filters, fields)
+		if err != nil {
+			return err
+		}
+
+		cts := []api.Container{}
+		for _, cinfo := range ctslist {
+			cts = append(cts, cinfo.Container)
+
+			if shared.StringInSlice("state", fields) {
+				cStates[cinfo.Name] = cinfo.State
+			}
+
+			if shared.StringInSlice("snapshots", fields) {
+				cSnapshots[cinfo.Name] = cinfo.Snapshots
+			}
+		}
+
+		return c.listContainers(conf, remote, cts, cStates, cSnapshots, nil, columns)
+	}
+
 	var cts []api.Container
 	ctslist, err := d.GetContainers()
 	if err != nil {
@@ -446,12 +491,7 @@ func (c *listCmd) run(conf *config.Config, args []string) error {
 		cts = append(cts, cinfo)
 	}
 
-	columns, err := c.parseColumns()
-	if err != nil {
-		return err
-	}
-
-	return c.listContainers(conf, remote, cts, filters, columns)
+	return c.listContainers(conf, remote, cts, cStates, cSnapshots, filters, columns)
 }
 
 func (c *listCmd) parseColumns() ([]column, error) {
@@ -52,6 +52,7 @@ func run() error {
 	debug := gnuflag.Bool("debug", false, i18n.G("Enable debug mode"))
 	forceLocal := gnuflag.Bool("force-local", false, i18n.G("Force using the local unix socket"))
 	noAlias := gnuflag.Bool("no-alias", false, i18n.G("Ignore aliases when determining what command to run"))
+	project := gnuflag.String("project", "", i18n.G("Project to use instead of the default one"))
 
 	var configDir string
 	if os.Getenv("LXD_CONF") != "" {
@@ -151,6 +152,9 @@ func run() error {
 		return err
 	}
 
+	// Set the project to use
+	conf.ProjectOverride = *project
+
 	// If the user is running a command that may attempt to connect to the local daemon
 	// and this is the first time the client has been run by the user, then check to see
 	// if LXD has been properly configured.  Don't display the message if the var path
@@ -218,11 +222,13 @@ var commands = map[string]command{
 	"copy":      &copyCmd{},
 	"delete":    &deleteCmd{},
 	"exec":      &execCmd{},
+	"export":    &exportCmd{},
 	"file":      &fileCmd{},
 	"finger":    &fingerCmd{},
 	"query":     &queryCmd{},
 	"help":      &helpCmd{},
 	"image":     &imageCmd{},
+	"import":    &importCmd{},
 	"info":      &infoCmd{},
 	"init":      &initCmd{},
 	"launch":    &launchCmd{},
@@ -239,7 +245,9 @@ var commands = map[string]command{
 		name:        "pause",
 	},
 	"profile": &profileCmd{},
+	"project": &projectCmd{},
 	"publish": &publishCmd{},
+	"rebuild": &rebuildCmd{},
 	"remote":  &remoteCmd{},
 	"restart": &actionCmd{
 		action:      shared.Restart,
@@ -45,11 +45,15 @@ Monitor a local or remote LXD server.
 
 By default the monitor will listen to all message types.
 
-Message types to listen for can be specified with --type.
+Message types to listen for can be specified with --type, the supported
+types being "logging", "operation" and "lifecycle".
 
 *Examples*
 lxc monitor --type=logging
-    Only show log message.`)
+    Only show log message.
+
+lxc monitor --type=lifecycle
+    Only show container, image, profile, network and storage pool actions.`)
 }
 
 func (c *monitorCmd) flags() {
@@ -5,16 +5,20 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/yaml.v2"
 
 	"github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/lxc/config"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/gnuflag"
 	"github.com/lxc/lxd/shared/i18n"
 )
 
 type operationCmd struct {
+	all bool
 }
 
 func (c *operationCmd) showByDefault() bool {
@@ -27,8 +31,8 @@ func (c *operationCmd) usage() string {
 
 List, show and delete background operations.
 
-lxc operation list [<remote>:]
-    List background operations.
+lxc operation list [--all] [<remote>:]
+    List background operations, including the finished ones with --all.
 
 lxc operation show [<remote>:]<operation>
     Show details on a background operation.
@@ -41,7 +45,9 @@ lxc operation show 344a79e4-d88a-45bf-9c39-c72c26f6ab8a
     Show details on that operation UUID`)
 }
 
-func (c *operationCmd) flags() {}
+func (c *operationCmd) flags() {
+	gnuflag.BoolVar(&c.all, "all", false, i18n.G("Include the finished operations kept in the history"))
+}
 
 func (c *operationCmd) run(conf *config.Config, args []string) error {
 	if len(args) < 1 {
@@ -129,7 +135,12 @@ func (c *operationCmd) doOperationList(conf *config.Config, args []string) error
 		return err
 	}
 
-	operations, err := client.GetOperations()
+	var operations []api.Operation
+	if c.all {
+		operations, err = client.GetOperationsHistory(time.Time{}, time.Time{})
+	} else {
+		operations, err = client.GetOperations()
+	}
 	if err != nil {
 		return err
 	}
@@ -141,7 +152,15 @@ func (c *operationCmd) doOperationList(conf *config.Config, args []string) error
 			cancelable = i18n.G("YES")
 		}
 
-		data = append(data, []string{op.ID, strings.ToUpper(op.Class), strings.ToUpper(op.Status), cancelable, op.CreatedAt.UTC().Format("2006/01/02 15:04 UTC")})
+		requestor := ""
+		if op.Requestor != nil {
+			requestor = op.Requestor.Protocol
+			if op.Requestor.Username != "" {
+				requestor = fmt.Sprintf("%s (%s)", op.Requestor.Username, op.Requestor.Protocol)
+			}
+		}
+
+		data = append(data, []string{op.ID, strings.ToUpper(op.Class), strings.ToUpper(op.Status), cancelable, op.CreatedAt.UTC().Format("2006/01/02 15:04 UTC"), requestor})
 	}
 
 	table := tablewriter.NewWriter(os.Stdout)
@@ -153,7 +172,8 @@ func (c *operationCmd) doOperationList(conf *config.Config, args []string) error
 		i18n.G("TYPE"),
 		i18n.G("STATUS"),
 		i18n.G("CANCELABLE"),
-		i18n.G("CREATED")})
+		i18n.G("CREATED"),
+		i18n.G("REQUESTOR")})
 	sort.Sort(byName(data))
 	table.AppendBulk(data)
 	table.Render()
@@ -513,6 +513,11 @@ func (c *profileCmd) doProfileSet(client lxd.ContainerServer, p string, args []s
 		value = string(buf[:])
 	}
 
+	err := configKeyValidate(client, "profile", key, value)
+	if err != nil {
+		return err
+	}
+
 	profile, etag, err := client.GetProfile(p)
 	if err != nil {
 		return err
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxc/config"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/i18n"
+	"github.com/lxc/lxd/shared/termios"
+)
+
+type projectCmd struct {
+}
+
+func (c *projectCmd) showByDefault() bool {
+	return true
+}
+
+func (c *projectCmd) projectEditHelp() string {
+	return i18n.G(
+		`### This is a yaml representation of the project.
+### Any line starting with a '# will be ignored.
+###
+### A project consists of a set of features and a description.
+###
+### An example would look like:
+### name: my-project
+### config:
+###   features.images: "true"
+###   features.profiles: "true"
+### description: My own project
+###
+### Note that the name is shown but cannot be changed`)
+}
+
+func (c *projectCmd) usage() string {
+	return i18n.G(
+		`Usage: lxc project <subcommand> [options]
+
+Manage projects.
+
+lxc project list [<remote>:]
+    List available projects.
+
+lxc project show [<remote>:]<project>
+    Show details of a project.
+
+lxc project create [<remote>:]<project> [key=value...]
+    Create a project.
+
+lxc project get [<remote>:]<project> <key>
+    Get project configuration.
+
+lxc project set [<remote>:]<project> <key> <value>
+    Set project configuration.
+
+lxc project unset [<remote>:]<project> <key>
+    Unset project configuration.
+
+lxc project delete [<remote>:]<project>
+    Delete a project.
+
+lxc project edit [<remote>:]<project>
+    Edit project, either by launching external editor or reading STDIN.
+
+lxc project rename [<remote>:]<project> <new-name>
+    Rename a project.
+
+*Examples*
+lxc project create foo features.images=true
+    Create a project "foo" with its own set of images.
+
+cat project.yaml | lxc project edit <project>
+    Update a project using the content of project.yaml
+
+lxc launch ubuntu:16.04 c1 --project foo
+    Create and start a container in project "foo".`)
+}
+
+func (c *projectCmd) flags() {}
+
+func (c *projectCmd) run(conf *config.Config, args []string) error {
+	if len(args) < 1 {
+		return errUsage
+	}
+
+	if args[0] == "list" {
+		return c.doProjectList(conf, args)
+	}
+
+	if len(args) < 2 {
+		return errArgs
+	}
+
+	remote, project, err := conf.ParseRemote(args[1])
+	if err != nil {
+		return err
+	}
+
+	client, err := conf.GetContainerServer(remote)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "create":
+		return c.doProjectCreate(client, project, args[2:])
+	case "delete":
+		return c.doProjectDelete(client, project)
+	case "edit":
+		return c.doProjectEdit(client, project)
+	case "rename":
+		if len(args) != 3 {
+			return errArgs
+		}
+		return c.doProjectRename(client, project, args[2])
+	case "get":
+		return c.doProjectGet(client, project, args[2:])
+	case "set":
+		return c.doProjectSet(client, project, args[2:])
+	case "unset":
+		return c.doProjectUnset(client, project, args[2:])
+	case "show":
+		return c.doProjectShow(client, project)
+	default:
+		return errArgs
+	}
+}
+
+func (c *projectCmd) doProjectCreate(client lxd.ContainerServer, name string, args []string) error {
+	project := api.ProjectsPost{}
+	project.Name = name
+	project.Config = map[string]string{}
+
+	for i := 0; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return errArgs
+		}
+
+		project.Config[entry[0]] = entry[1]
+	}
+
+	err := client.CreateProject(project)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Project %s created")+"\n", name)
+	return nil
+}
+
+func (c *projectCmd) doProjectEdit(client lxd.ContainerServer, name string) error {
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(int(syscall.Stdin)) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		newdata := api.ProjectPut{}
+		err = yaml.Unmarshal(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return client.UpdateProject(name, newdata, "")
+	}
+
+	// Extract the current value
+	project, etag, err := client.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&project)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor
+	content, err := shared.TextEditor("", []byte(c.projectEditHelp()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor
+		newdata := api.ProjectPut{}
+		err = yaml.Unmarshal(content, &newdata)
+		if err == nil {
+			err = client.UpdateProject(name, newdata, etag)
+		}
+
+		// Respawn the editor
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		break
+	}
+	return nil
+}
+
+func (c *projectCmd) doProjectRename(client lxd.ContainerServer, name string, newName string) error {
+	err := client.RenameProject(name, api.ProjectPost{Name: newName})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Project %s renamed to %s")+"\n", name, newName)
+	return nil
+}
+
+func (c *projectCmd) doProjectDelete(client lxd.ContainerServer, name string) error {
+	err := client.DeleteProject(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Project %s deleted")+"\n", name)
+	return nil
+}
+
+func (c *projectCmd) doProjectShow(client lxd.ContainerServer, name string) error {
+	project, _, err := client.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&project)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+func (c *projectCmd) doProjectGet(client lxd.ContainerServer, name string, args []string) error {
+	// we shifted @args so so it should read "<key>"
+	if len(args) != 1 {
+		return errArgs
+	}
+
+	project, _, err := client.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", project.Config[args[0]])
+	return nil
+}
+
+func (c *projectCmd) doProjectSet(client lxd.ContainerServer, name string, args []string) error {
+	// we shifted @args so so it should read "<key> [<value>]"
+	if len(args) < 1 {
+		return errArgs
+	}
+
+	key := args[0]
+	var value string
+	if len(args) < 2 {
+		value = ""
+	} else {
+		value = args[1]
+	}
+
+	if !termios.IsTerminal(int(syscall.Stdin)) && value == "-" {
+		buf, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("Can't read from stdin: %s", err)
+		}
+		value = string(buf[:])
+	}
+
+	project, etag, err := client.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	project.Config[key] = value
+
+	return client.UpdateProject(name, project.Writable(), etag)
+}
+
+func (c *projectCmd) doProjectUnset(client lxd.ContainerServer, name string, args []string) error {
+	// we shifted @args so so it should read "<key>"
+	if len(args) != 1 {
+		return errArgs
+	}
+
+	return c.doProjectSet(client, name, args)
+}
+
+func (c *projectCmd) doProjectList(conf *config.Config, args []string) error {
+	var remote string
+	if len(args) > 1 {
+		var name string
+		var err error
+		remote, name, err = conf.ParseRemote(args[1])
+		if err != nil {
+			return err
+		}
+
+		if name != "" {
+			return fmt.Errorf(i18n.G("Cannot provide container name to list"))
+		}
+	} else {
+		remote = conf.DefaultRemote
+	}
+
+	client, err := conf.GetContainerServer(remote)
+	if err != nil {
+		return err
+	}
+
+	projects, err := client.GetProjects()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, project := range projects {
+		images := i18n.G("NO")
+		if shared.IsTrue(project.Config["features.images"]) {
+			images = i18n.G("YES")
+		}
+
+		profiles := i18n.G("NO")
+		if shared.IsTrue(project.Config["features.profiles"]) {
+			profiles = i18n.G("YES")
+		}
+
+		strUsedBy := fmt.Sprintf("%d", len(project.UsedBy))
+		data = append(data, []string{project.Name, images, profiles, strUsedBy})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.SetHeader([]string{
+		i18n.G("NAME"),
+		i18n.G("IMAGES"),
+		i18n.G("PROFILES"),
+		i18n.G("USED BY")})
+	sort.Sort(byName(data))
+	table.AppendBulk(data)
+	table.Render()
+
+	return nil
+}
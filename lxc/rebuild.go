@@ -0,0 +1,133 @@
+package main
+
+import (
+	"github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxc/config"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/gnuflag"
+	"github.com/lxc/lxd/shared/i18n"
+)
+
+type rebuildCmd struct {
+	empty bool
+}
+
+func (c *rebuildCmd) showByDefault() bool {
+	return false
+}
+
+func (c *rebuildCmd) usage() string {
+	return i18n.G(
+		`Usage: lxc rebuild [<remote>:]<image> [<remote>:]<container>
+       lxc rebuild --empty [<remote>:]<container>
+
+Rebuild containers from a new image.
+
+The root filesystem of the stopped container is replaced by the image,
+while its configuration, devices, snapshots and volatile keys are kept.
+
+*Examples*
+lxc rebuild ubuntu:18.04 c1
+    Replace the root filesystem of c1 by a fresh Ubuntu 18.04 one.
+
+lxc rebuild --empty c1
+    Replace the root filesystem of c1 by an empty one.`)
+}
+
+func (c *rebuildCmd) flags() {
+	gnuflag.BoolVar(&c.empty, "empty", false, i18n.G("Rebuild the container with an empty root filesystem"))
+}
+
+func (c *rebuildCmd) run(conf *config.Config, args []string) error {
+	if c.empty {
+		if len(args) != 1 {
+			return errArgs
+		}
+	} else if len(args) != 2 {
+		return errArgs
+	}
+
+	remote, name, err := conf.ParseRemote(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetContainerServer(remote)
+	if err != nil {
+		return err
+	}
+
+	if c.empty {
+		req := api.ContainerRebuildPost{}
+		req.Source.Type = "none"
+
+		op, err := d.RebuildContainer(name, req)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	}
+
+	iremote, image, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Connect to the image server
+	var imgRemote lxd.ImageServer
+	if iremote == remote {
+		imgRemote = d
+	} else {
+		imgRemote, err = conf.GetImageServer(iremote)
+		if err != nil {
+			return err
+		}
+	}
+
+	req := api.ContainerRebuildPost{}
+	var imgInfo *api.Image
+
+	// Optimisation for simplestreams
+	if conf.Remotes[iremote].Protocol == "simplestreams" {
+		imgInfo = &api.Image{}
+		imgInfo.Fingerprint = image
+		imgInfo.Public = true
+		req.Source.Alias = image
+	} else {
+		// Attempt to resolve an image alias
+		alias, _, err := imgRemote.GetImageAlias(image)
+		if err == nil {
+			req.Source.Alias = image
+			image = alias.Target
+		}
+
+		// Get the image info
+		imgInfo, _, err = imgRemote.GetImage(image)
+		if err != nil {
+			return err
+		}
+	}
+
+	op, err := d.RebuildContainerFromImage(imgRemote, *imgInfo, name, req)
+	if err != nil {
+		return err
+	}
+
+	// Watch the background operation
+	progress := ProgressRenderer{Format: i18n.G("Retrieving image: %s")}
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+	progress.Done("")
+
+	return nil
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"net"
@@ -45,6 +47,9 @@ Manage the list of remote LXD servers.
 lxc remote add [<remote>] <IP|FQDN|URL> [--accept-certificate] [--password=PASSWORD] [--public] [--protocol=PROTOCOL] [--auth-type=AUTH_TYPE]
     Add the remote <remote> at <url>.
 
+lxc remote add [<remote>] <token>
+    Add the remote <remote> using a join token from "lxc config trust add".
+
 lxc remote remove <remote>
     Remove the remote <remote>.
 
@@ -212,21 +217,11 @@ func (c *remoteCmd) addServer(conf *config.Config, server string, addr string, a
 			}
 		}
 
-		dnam := conf.ConfigPath("servercerts")
-		err := os.MkdirAll(dnam, 0750)
-		if err != nil {
-			return fmt.Errorf(i18n.G("Could not create server cert dir"))
-		}
-
-		certf := fmt.Sprintf("%s/%s.crt", dnam, server)
-		certOut, err := os.Create(certf)
+		err := c.saveServerCertificate(conf, server, certificate)
 		if err != nil {
 			return err
 		}
 
-		pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
-		certOut.Close()
-
 		// Setup a new connection, this time with the remote certificate
 		if public {
 			d, err = conf.GetImageServer(server)
@@ -317,6 +312,110 @@ func (c *remoteCmd) addServer(conf *config.Config, server string, addr string, a
 	return nil
 }
 
+// addServerToken adds the server which issued the given join token, using
+// the token to add the client certificate to its trust store.
+func (c *remoteCmd) addServerToken(conf *config.Config, server string, token *api.CertificateAddToken) error {
+	if conf.Remotes == nil {
+		conf.Remotes = make(map[string]config.Remote)
+	}
+
+	if !conf.HasClientCertificate() {
+		fmt.Fprintf(os.Stderr, i18n.G("Generating a client certificate. This may take a minute...")+"\n")
+		err := conf.GenerateClientCertificate()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Use the first address of the server we can reach
+	for _, address := range token.Addresses {
+		addr := fmt.Sprintf("https://%s", address)
+
+		certificate, err := shared.GetRemoteCertificate(addr)
+		if err != nil {
+			logger.Debugf("Failed to reach %s: %v", addr, err)
+			continue
+		}
+
+		if shared.CertFingerprint(certificate) != token.Fingerprint {
+			return fmt.Errorf(i18n.G("Certificate fingerprint mismatch between join token and server %s"), address)
+		}
+
+		err = c.saveServerCertificate(conf, server, certificate)
+		if err != nil {
+			return err
+		}
+
+		conf.Remotes[server] = config.Remote{Addr: addr, Protocol: "lxd", AuthType: "tls"}
+
+		d, err := conf.GetContainerServer(server)
+		if err != nil {
+			return err
+		}
+
+		req := api.CertificatesPost{
+			TrustToken: token.Secret,
+		}
+		req.Type = "client"
+
+		err = d.CreateCertificate(req)
+		if err != nil {
+			return err
+		}
+
+		srv, _, err := d.GetServer()
+		if err != nil {
+			return err
+		}
+
+		if srv.Auth != "trusted" {
+			return fmt.Errorf(i18n.G("Server doesn't trust us after authentication"))
+		}
+
+		fmt.Println(i18n.G("Client certificate stored at server: "), server)
+		return nil
+	}
+
+	return fmt.Errorf(i18n.G("Unable to connect to any of the addresses in the join token"))
+}
+
+func (c *remoteCmd) saveServerCertificate(conf *config.Config, server string, certificate *x509.Certificate) error {
+	dnam := conf.ConfigPath("servercerts")
+	err := os.MkdirAll(dnam, 0750)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Could not create server cert dir"))
+	}
+
+	certf := fmt.Sprintf("%s/%s.crt", dnam, server)
+	certOut, err := os.Create(certf)
+	if err != nil {
+		return err
+	}
+	defer certOut.Close()
+
+	return pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
+}
+
+// remoteTokenDecode parses the given argument as a join token.
+func remoteTokenDecode(value string) (*api.CertificateAddToken, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	token := api.CertificateAddToken{}
+	err = json.Unmarshal(data, &token)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Secret == "" || token.Fingerprint == "" || len(token.Addresses) == 0 {
+		return nil, fmt.Errorf("Incomplete join token")
+	}
+
+	return &token, nil
+}
+
 func (c *remoteCmd) removeCertificate(conf *config.Config, remote string) {
 	certf := conf.ServerCertPath(remote)
 	logger.Debugf("Trying to remove %s", certf)
@@ -341,11 +440,28 @@ func (c *remoteCmd) run(conf *config.Config, args []string) error {
 			fqdn = args[2]
 		}
 
+		// Without a name, the remote is named after the server which
+		// issued the join token
+		token, tokenErr := remoteTokenDecode(fqdn)
+		if tokenErr == nil && len(args) < 3 {
+			host, _, err := net.SplitHostPort(token.Addresses[0])
+			if err != nil {
+				host = token.Addresses[0]
+			}
+
+			remote = strings.Trim(host, "[]")
+		}
+
 		if rc, ok := conf.Remotes[remote]; ok {
 			return fmt.Errorf(i18n.G("remote %s exists as <%s>"), remote, rc.Addr)
 		}
 
-		err := c.addServer(conf, remote, fqdn, c.acceptCert, c.password, c.public, c.protocol, c.authType)
+		var err error
+		if tokenErr == nil {
+			err = c.addServerToken(conf, remote, token)
+		} else {
+			err = c.addServer(conf, remote, fqdn, c.acceptCert, c.password, c.public, c.protocol, c.authType)
+		}
 		if err != nil {
 			delete(conf.Remotes, remote)
 			c.removeCertificate(conf, remote)
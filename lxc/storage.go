@@ -22,7 +22,9 @@ import (
 )
 
 type storageCmd struct {
-	resources bool
+	resources  bool
+	mode       string
+	volumeOnly bool
 }
 
 func (c *storageCmd) showByDefault() bool {
@@ -104,6 +106,18 @@ lxc storage volume create [<remote>:]<pool> <volume> [key=value]...
 lxc storage volume rename [<remote>:]<pool> <old name> <new name>
     Rename a storage volume on a storage pool.
 
+lxc storage volume copy [<remote>:]<pool>/<volume> [<remote>:]<pool>/<volume> [--mode] [--volume-only]
+    Copy a storage volume, possibly to a different storage pool or remote.
+
+lxc storage volume move [<remote>:]<pool>/<volume> [<remote>:]<pool>/<volume> [--mode] [--volume-only]
+    Move a storage volume, possibly to a different storage pool or remote.
+
+lxc storage volume snapshot [<remote>:]<pool> <volume> [<snapshot name>]
+    Create a snapshot of a storage volume.
+
+lxc storage volume restore [<remote>:]<pool> <volume> <snapshot name>
+    Restore a storage volume from one of its snapshots.
+
 lxc storage volume get [<remote>:]<pool> <volume> <key>
     Get storage volume configuration on a storage pool.
 
@@ -133,6 +147,9 @@ lxc storage volume detach-profile [<remote:>]<pool> <volume> <profile> [device n
 
 Unless specified through a prefix, all volume operations affect "custom" (user created) volumes.
 
+Snapshots of custom volumes are referred to as <volume>/<snapshot name> and
+can be shown, renamed and deleted like any other volume.
+
 *Examples*
 cat pool.yaml | lxc storage edit [<remote>:]<pool>
     Update a storage pool using the content of pool.yaml.
@@ -149,6 +166,8 @@ lxc storage volume show default container/data
 
 func (c *storageCmd) flags() {
 	gnuflag.BoolVar(&c.resources, "resources", false, i18n.G("Show the resources available to the storage pool"))
+	gnuflag.StringVar(&c.mode, "mode", "pull", i18n.G("Transfer mode. One of pull (default), push or relay."))
+	gnuflag.BoolVar(&c.volumeOnly, "volume-only", false, i18n.G("Copy or move the storage volume without its snapshots"))
 }
 
 func (c *storageCmd) run(conf *config.Config, args []string) error {
@@ -194,6 +213,11 @@ func (c *storageCmd) run(conf *config.Config, args []string) error {
 			pool := sub
 			volume := args[3]
 			return c.doStoragePoolVolumeAttachProfile(client, pool, volume, args[4:])
+		case "copy":
+			if len(args) != 4 {
+				return errArgs
+			}
+			return c.doStoragePoolVolumeCopy(conf, client, remote, sub, args[3], false)
 		case "create":
 			if len(args) < 4 {
 				return errArgs
@@ -249,6 +273,13 @@ func (c *storageCmd) run(conf *config.Config, args []string) error {
 			pool := sub
 			volume := args[3]
 			return c.doStoragePoolVolumeRename(client, pool, volume, args)
+		case "restore":
+			if len(args) != 5 {
+				return errArgs
+			}
+			pool := sub
+			volume := args[3]
+			return c.doStoragePoolVolumeRestore(client, pool, volume, args[4])
 		case "set":
 			if len(args) < 4 {
 				return errArgs
@@ -256,6 +287,22 @@ func (c *storageCmd) run(conf *config.Config, args []string) error {
 			pool := sub
 			volume := args[3]
 			return c.doStoragePoolVolumeSet(client, pool, volume, args[3:])
+		case "move":
+			if len(args) != 4 {
+				return errArgs
+			}
+			return c.doStoragePoolVolumeCopy(conf, client, remote, sub, args[3], true)
+		case "snapshot":
+			if len(args) < 4 || len(args) > 5 {
+				return errArgs
+			}
+			pool := sub
+			volume := args[3]
+			snapshot := ""
+			if len(args) == 5 {
+				snapshot = args[4]
+			}
+			return c.doStoragePoolVolumeSnapshot(client, pool, volume, snapshot)
 		case "unset":
 			if len(args) < 4 {
 				return errArgs
@@ -330,9 +377,30 @@ func (c *storageCmd) parseVolume(name string) (string, string) {
 		return fields[0], defaultType
 	}
 
+	// Anything else is a snapshot of a custom volume
+	if !shared.StringInSlice(fields[0], []string{"custom", "image", "container"}) {
+		return name, defaultType
+	}
+
 	return fields[1], fields[0]
 }
 
+// parseVolumeSnapshot splits the name of a custom volume snapshot into the
+// name of its volume and the name of the snapshot. The snapshot name is empty
+// if the name doesn't refer to a snapshot.
+func (c *storageCmd) parseVolumeSnapshot(volName string, volType string) (string, string) {
+	if volType != "custom" {
+		return volName, ""
+	}
+
+	fields := strings.SplitN(volName, shared.SnapshotDelimiter, 2)
+	if len(fields) == 1 {
+		return fields[0], ""
+	}
+
+	return fields[0], fields[1]
+}
+
 func (c *storageCmd) doStoragePoolVolumeAttach(client lxd.ContainerServer, pool string, volume string, args []string) error {
 	if len(args) < 2 || len(args) > 3 {
 		return errArgs
@@ -717,6 +785,11 @@ func (c *storageCmd) doStoragePoolSet(client lxd.ContainerServer, name string, a
 		value = string(buf[:])
 	}
 
+	err = configKeyValidate(client, "pool", args[0], value)
+	if err != nil {
+		return err
+	}
+
 	// Update the pool
 	pool.Config[args[0]] = value
 
@@ -832,6 +905,23 @@ func (c *storageCmd) doStoragePoolVolumeDelete(client lxd.ContainerServer, pool
 	// Parse the input
 	volName, volType := c.parseVolume(volume)
 
+	// Delete a snapshot
+	parentName, snapName := c.parseVolumeSnapshot(volName, volType)
+	if snapName != "" {
+		op, err := client.DeleteStoragePoolVolumeSnapshot(pool, volType, parentName, snapName)
+		if err != nil {
+			return err
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(i18n.G("Storage volume snapshot %s deleted")+"\n", volume)
+		return nil
+	}
+
 	// Delete the volume
 	err := client.DeleteStoragePoolVolume(pool, volType, volName)
 	if err != nil {
@@ -897,6 +987,11 @@ func (c *storageCmd) doStoragePoolVolumeSet(client lxd.ContainerServer, pool str
 		value = string(buf[:])
 	}
 
+	err = configKeyValidate(client, "volume", key, value)
+	if err != nil {
+		return err
+	}
+
 	// Update the volume
 	vol.Config[key] = value
 	err = client.UpdateStoragePoolVolume(pool, vol.Type, vol.Name, vol.Writable(), etag)
@@ -911,6 +1006,24 @@ func (c *storageCmd) doStoragePoolVolumeShow(client lxd.ContainerServer, pool st
 	// Parse the input
 	volName, volType := c.parseVolume(volume)
 
+	// Get the storage volume snapshot entry
+	parentName, snapName := c.parseVolumeSnapshot(volName, volType)
+	if snapName != "" {
+		snapshot, err := client.GetStoragePoolVolumeSnapshot(pool, volType, parentName, snapName)
+		if err != nil {
+			return err
+		}
+
+		data, err := yaml.Marshal(&snapshot)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s", data)
+
+		return nil
+	}
+
 	// Get the storage volume entry
 	vol, _, err := client.GetStoragePoolVolume(pool, volType, volName)
 	if err != nil {
@@ -999,6 +1112,33 @@ func (c *storageCmd) doStoragePoolVolumeRename(client lxd.ContainerServer, pool
 	// Parse the input
 	volName, volType := c.parseVolume(volume)
 
+	// Rename a snapshot
+	parentName, snapName := c.parseVolumeSnapshot(volName, volType)
+	if snapName != "" {
+		newName := args[4]
+		fields := strings.SplitN(newName, shared.SnapshotDelimiter, 2)
+		if len(fields) == 2 {
+			if fields[0] != parentName {
+				return fmt.Errorf(i18n.G("Storage volume snapshots can't be moved to a different volume"))
+			}
+
+			newName = fields[1]
+		}
+
+		op, err := client.RenameStoragePoolVolumeSnapshot(pool, volType, parentName, snapName, api.StorageVolumeSnapshotPost{Name: newName})
+		if err != nil {
+			return err
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(i18n.G(`Renamed storage volume snapshot from "%s" to "%s"`)+"\n", snapName, newName)
+		return nil
+	}
+
 	// Create the storage volume entry
 	vol := api.StorageVolumePost{}
 	vol.Name = args[4]
@@ -1012,3 +1152,111 @@ func (c *storageCmd) doStoragePoolVolumeRename(client lxd.ContainerServer, pool
 
 	return nil
 }
+
+func (c *storageCmd) doStoragePoolVolumeCopy(conf *config.Config, client lxd.ContainerServer, remote string, source string, target string, move bool) error {
+	// Parse the input
+	fields := strings.SplitN(source, "/", 2)
+	if len(fields) != 2 {
+		return fmt.Errorf(i18n.G("Invalid source %s"), source)
+	}
+	srcPool, srcVolume := fields[0], fields[1]
+
+	dstRemote, dst, err := conf.ParseRemote(target)
+	if err != nil {
+		return err
+	}
+
+	fields = strings.SplitN(dst, "/", 2)
+	if len(fields) != 2 {
+		return fmt.Errorf(i18n.G("Invalid target %s"), target)
+	}
+	dstPool, dstVolume := fields[0], fields[1]
+
+	// A move within the same storage pool is just a rename
+	if move && dstRemote == remote && dstPool == srcPool {
+		err := client.RenameStoragePoolVolume(srcPool, "custom", srcVolume, api.StorageVolumePost{Name: dstVolume})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(i18n.G("Storage volume moved successfully!") + "\n")
+		return nil
+	}
+
+	dstClient := client
+	if dstRemote != remote {
+		dstClient, err = conf.GetContainerServer(dstRemote)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Get the source volume
+	vol, _, err := client.GetStoragePoolVolume(srcPool, "custom", srcVolume)
+	if err != nil {
+		return err
+	}
+
+	args := lxd.StoragePoolVolumeCopyArgs{
+		Name:       dstVolume,
+		Mode:       c.mode,
+		VolumeOnly: c.volumeOnly,
+	}
+
+	op, err := dstClient.CopyStoragePoolVolume(dstPool, client, srcPool, *vol, &args)
+	if err != nil {
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	if !move {
+		fmt.Printf(i18n.G("Storage volume copied successfully!") + "\n")
+		return nil
+	}
+
+	// Remove the source volume (along with its snapshots)
+	err = client.DeleteStoragePoolVolume(srcPool, "custom", srcVolume)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Storage volume moved successfully!") + "\n")
+
+	return nil
+}
+
+func (c *storageCmd) doStoragePoolVolumeSnapshot(client lxd.ContainerServer, pool string, volume string, snapshot string) error {
+	// Parse the input
+	volName, volType := c.parseVolume(volume)
+
+	req := api.StorageVolumeSnapshotsPost{
+		Name: snapshot,
+	}
+
+	op, err := client.CreateStoragePoolVolumeSnapshot(pool, volType, volName, req)
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
+
+func (c *storageCmd) doStoragePoolVolumeRestore(client lxd.ContainerServer, pool string, volume string, snapshot string) error {
+	// Parse the input
+	volName, volType := c.parseVolume(volume)
+
+	// Get the storage volume entry
+	vol, etag, err := client.GetStoragePoolVolume(pool, volType, volName)
+	if err != nil {
+		return err
+	}
+
+	req := vol.Writable()
+	req.Restore = snapshot
+
+	return client.UpdateStoragePoolVolume(pool, volType, volName, req, etag)
+}
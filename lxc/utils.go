@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/i18n"
 )
@@ -293,6 +295,71 @@ func getLocalErr(err error) error {
 	return nil
 }
 
+// Find the description of a configuration key, expanding key patterns
+// such as "user.*" or "volatile.<name>.hwaddr"
+func configKeyLookup(keys map[string]api.MetadataConfigurationKey, key string) (api.MetadataConfigurationKey, bool) {
+	entry, ok := keys[key]
+	if ok {
+		return entry, true
+	}
+
+	for name, entry := range keys {
+		if !strings.ContainsAny(name, "*<") && !strings.Contains(name, "TARGET") {
+			continue
+		}
+
+		pattern := regexp.QuoteMeta(name)
+		pattern = strings.Replace(pattern, `\*`, ".+", -1)
+		pattern = regexp.MustCompile(`<[^>]+>|TARGET`).ReplaceAllString(pattern, "[^.]+")
+
+		if regexp.MustCompile("^" + pattern + "$").MatchString(key) {
+			return entry, true
+		}
+	}
+
+	return api.MetadataConfigurationKey{}, false
+}
+
+// Validate a configuration key and value against the server's description
+// of the given scope before sending it. This is a no-op on servers which
+// don't describe their configuration.
+func configKeyValidate(client lxd.ContainerServer, scope string, key string, value string) error {
+	// Unsetting is always allowed
+	if value == "" {
+		return nil
+	}
+
+	if !client.HasExtension("metadata_configuration") {
+		return nil
+	}
+
+	metadata, err := client.GetMetadataConfiguration()
+	if err != nil {
+		return err
+	}
+
+	entry, ok := configKeyLookup(metadata.Configs[scope], key)
+	if !ok {
+		return fmt.Errorf(i18n.G("Unknown configuration key: %s"), key)
+	}
+
+	switch entry.Type {
+	case "bool":
+		err = shared.IsBool(value)
+	case "integer":
+		err = shared.IsInt64(value)
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.G("Invalid value for %s: %v"), key, err)
+	}
+
+	if len(entry.AllowedValues) > 0 && !shared.StringInSlice(value, entry.AllowedValues) {
+		return fmt.Errorf(i18n.G("Invalid value for %s: must be one of %s"), key, strings.Join(entry.AllowedValues, ", "))
+	}
+
+	return nil
+}
+
 // Add a device to a container
 func containerDeviceAdd(client lxd.ContainerServer, name string, devName string, dev map[string]string) error {
 	// Get the container entry
@@ -1,7 +1,11 @@
 package benchmark
 
 import (
+	"crypto/rand"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +18,12 @@ import (
 
 const userConfigKey = "user.lxd-benchmark"
 
+// Name of the snapshots taken by the benchmark.
+const snapshotName = "lxd-benchmark"
+
+// Path of the file transferred to containers by the benchmark.
+const transferPath = "/tmp/lxd-benchmark"
+
 // PrintServerInfo prints out information about the server.
 func PrintServerInfo(c lxd.ContainerServer) error {
 	server, _, err := c.GetServer()
@@ -221,6 +231,344 @@ func DeleteContainers(c lxd.ContainerServer, containers []api.Container, paralle
 	return duration, nil
 }
 
+// SnapshotContainers snapshots containers created by the benchmark, restores
+// the snapshots and deletes them.
+func SnapshotContainers(c lxd.ContainerServer, containers []api.Container, parallel int) (time.Duration, error) {
+	var duration time.Duration
+
+	batchSize, err := getBatchSize(parallel)
+	if err != nil {
+		return duration, err
+	}
+
+	count := len(containers)
+	logf("Snapshotting %d containers", count)
+
+	snapshotContainer := func(index int, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		name := containers[index].Name
+		err := createSnapshot(c, name, snapshotName)
+		if err != nil {
+			logf("Failed to snapshot container '%s': %s", name, err)
+			return
+		}
+
+		err = restoreSnapshot(c, name, snapshotName)
+		if err != nil {
+			logf("Failed to restore container '%s': %s", name, err)
+		}
+
+		err = deleteSnapshot(c, name, snapshotName)
+		if err != nil {
+			logf("Failed to delete snapshot of container '%s': %s", name, err)
+			return
+		}
+	}
+
+	duration = processBatch(count, batchSize, snapshotContainer)
+	return duration, nil
+}
+
+// CopyContainers copies containers created by the benchmark. The copies are
+// deleted afterwards.
+func CopyContainers(c lxd.ContainerServer, containers []api.Container, parallel int) (time.Duration, error) {
+	var duration time.Duration
+
+	batchSize, err := getBatchSize(parallel)
+	if err != nil {
+		return duration, err
+	}
+
+	count := len(containers)
+	logf("Copying %d containers", count)
+
+	copied := make([]bool, count)
+	copyContainer := func(index int, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		container := containers[index]
+		err := copyContainer(c, container, container.Name+"-copy")
+		if err != nil {
+			logf("Failed to copy container '%s': %s", container.Name, err)
+			return
+		}
+
+		copied[index] = true
+	}
+
+	duration = processBatch(count, batchSize, copyContainer)
+
+	logf("Deleting the copies")
+	deleteCopy := func(index int, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		if !copied[index] {
+			return
+		}
+
+		name := containers[index].Name + "-copy"
+		err := deleteContainer(c, name)
+		if err != nil {
+			logf("Failed to delete container: %s", name)
+			return
+		}
+	}
+
+	processBatch(count, batchSize, deleteCopy)
+	return duration, nil
+}
+
+// MoveContainers moves stopped containers created by the benchmark to the
+// given storage pool.
+func MoveContainers(c lxd.ContainerServer, containers []api.Container, parallel int, pool string) (time.Duration, error) {
+	var duration time.Duration
+
+	if pool == "" {
+		return duration, fmt.Errorf("A target storage pool must be passed")
+	}
+
+	batchSize, err := getBatchSize(parallel)
+	if err != nil {
+		return duration, err
+	}
+
+	// Storage drivers can't copy containers between pools, so they're
+	// migrated instead, relaying the data from a second connection.
+	source, err := lxd.ConnectLXDUnix("", nil)
+	if err != nil {
+		return duration, err
+	}
+
+	count := len(containers)
+	logf("Moving %d containers to storage pool %s", count, pool)
+
+	moveContainer := func(index int, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		container := containers[index]
+		name := container.Name
+		if container.IsActive() {
+			logf("Skipping running container '%s'", name)
+			return
+		}
+
+		target := container
+		target.Devices = map[string]map[string]string{}
+		for key, device := range container.Devices {
+			target.Devices[key] = device
+		}
+
+		for key, device := range container.ExpandedDevices {
+			if device["type"] != "disk" || device["path"] != "/" {
+				continue
+			}
+
+			root := map[string]string{}
+			for k, v := range device {
+				root[k] = v
+			}
+			root["pool"] = pool
+			target.Devices[key] = root
+		}
+
+		err := timeOperation("move", func() error {
+			err := migrateContainer(c, source, target, name+"-move")
+			if err != nil {
+				return err
+			}
+
+			err = deleteContainer(c, name)
+			if err != nil {
+				return err
+			}
+
+			return renameContainer(c, name+"-move", name)
+		})
+		if err != nil {
+			logf("Failed to move container '%s': %s", name, err)
+			return
+		}
+	}
+
+	duration = processBatch(count, batchSize, moveContainer)
+	return duration, nil
+}
+
+// ExecContainers runs a no-op command in running containers created by the
+// benchmark the given number of times, measuring the round-trip latency.
+func ExecContainers(c lxd.ContainerServer, containers []api.Container, parallel int, iterations int) (time.Duration, error) {
+	var duration time.Duration
+
+	batchSize, err := getBatchSize(parallel)
+	if err != nil {
+		return duration, err
+	}
+
+	count := len(containers)
+	logf("Running %d commands in %d containers", iterations, count)
+
+	execContainer := func(index int, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		container := containers[index]
+		if !container.IsActive() {
+			logf("Skipping stopped container '%s'", container.Name)
+			return
+		}
+
+		for i := 0; i < iterations; i++ {
+			err := execContainer(c, container.Name, []string{"true"})
+			if err != nil {
+				logf("Failed to exec in container '%s': %s", container.Name, err)
+				return
+			}
+		}
+	}
+
+	duration = processBatch(count, batchSize, execContainer)
+	return duration, nil
+}
+
+// TransferFiles pushes a file of the given size to containers created by the
+// benchmark and pulls it back, the given number of times.
+func TransferFiles(c lxd.ContainerServer, containers []api.Container, parallel int, iterations int, size int64) (time.Duration, error) {
+	var duration time.Duration
+
+	batchSize, err := getBatchSize(parallel)
+	if err != nil {
+		return duration, err
+	}
+
+	content := make([]byte, size)
+	_, err = rand.Read(content)
+	if err != nil {
+		return duration, err
+	}
+
+	count := len(containers)
+	logf("Transferring %d files of %d bytes with %d containers", iterations, size, count)
+
+	transferFile := func(index int, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		name := containers[index].Name
+		for i := 0; i < iterations; i++ {
+			err := pushFile(c, name, transferPath, content)
+			if err != nil {
+				logf("Failed to push file to container '%s': %s", name, err)
+				return
+			}
+
+			err = pullFile(c, name, transferPath, size)
+			if err != nil {
+				logf("Failed to pull file from container '%s': %s", name, err)
+				return
+			}
+		}
+
+		err := c.DeleteContainerFile(name, transferPath)
+		if err != nil {
+			logf("Failed to delete file from container '%s': %s", name, err)
+		}
+	}
+
+	duration = processBatch(count, batchSize, transferFile)
+	return duration, nil
+}
+
+// ImportImage imports the given image the given number of times, deleting it
+// from the local store in between. The image is exported first and left in
+// the store, with its properties and aliases, at the end.
+func ImportImage(c lxd.ContainerServer, image string, iterations int) (time.Duration, error) {
+	var duration time.Duration
+
+	fingerprint, err := ensureImage(c, image)
+	if err != nil {
+		return duration, err
+	}
+
+	info, _, err := c.GetImage(fingerprint)
+	if err != nil {
+		return duration, err
+	}
+
+	// Export the image
+	dir, err := ioutil.TempDir("", "lxd-benchmark_")
+	if err != nil {
+		return duration, err
+	}
+	defer os.RemoveAll(dir)
+
+	metaFile, err := os.Create(filepath.Join(dir, "meta"))
+	if err != nil {
+		return duration, err
+	}
+	defer metaFile.Close()
+
+	rootfsFile, err := os.Create(filepath.Join(dir, "rootfs"))
+	if err != nil {
+		return duration, err
+	}
+	defer rootfsFile.Close()
+
+	logf("Exporting image %s", fingerprint)
+	resp, err := c.GetImageFile(fingerprint, lxd.ImageFileRequest{MetaFile: metaFile, RootfsFile: rootfsFile})
+	if err != nil {
+		return duration, err
+	}
+
+	metaPath := filepath.Join(dir, resp.MetaName)
+	err = os.Rename(metaFile.Name(), metaPath)
+	if err != nil {
+		return duration, err
+	}
+
+	rootfsPath := ""
+	if resp.RootfsSize > 0 {
+		rootfsPath = filepath.Join(dir, resp.RootfsName)
+		err = os.Rename(rootfsFile.Name(), rootfsPath)
+		if err != nil {
+			return duration, err
+		}
+	}
+
+	req := api.ImagesPost{
+		ImagePut: info.Writable(),
+		Filename: info.Filename,
+	}
+
+	logf("Importing image %s %d times", fingerprint, iterations)
+	timeStart := time.Now()
+	for i := 0; i < iterations; i++ {
+		err := deleteImage(c, fingerprint)
+		if err != nil {
+			return duration, err
+		}
+
+		err = importImage(c, req, metaPath, rootfsPath)
+		if err != nil {
+			return duration, err
+		}
+	}
+	duration = time.Since(timeStart)
+
+	for _, alias := range info.Aliases {
+		req := api.ImageAliasesPost{}
+		req.Name = alias.Name
+		req.Description = alias.Description
+		req.Target = fingerprint
+
+		err := c.CreateImageAlias(req)
+		if err != nil {
+			return duration, err
+		}
+	}
+
+	logf("Image imports completed in %.3fs", duration.Seconds())
+	return duration, nil
+}
+
 func ensureImage(c lxd.ContainerServer, image string) (string, error) {
 	var fingerprint string
 
@@ -0,0 +1,115 @@
+package benchmark
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Comparison compares a latency metric between two benchmark runs.
+type Comparison struct {
+	Label     string
+	Operation string
+	Metric    string
+
+	// Values of the metric, in milliseconds
+	Old float64
+	New float64
+
+	// Change of the metric, in percent
+	Change float64
+
+	// Whether the change is above the threshold
+	Regression bool
+}
+
+// CompareReports compares the last run of each label present in both reports.
+// The total elapsed time and the p50/p95/p99 latencies of the operations run
+// in both are compared, a metric being a regression if it grew by more than
+// the given threshold (in percent).
+func CompareReports(oldReport *JSONReport, newReport *JSONReport, threshold float64) []Comparison {
+	comparisons := []Comparison{}
+
+	oldRuns := oldReport.lastRuns()
+	newRuns := newReport.lastRuns()
+
+	labels := []string{}
+	for label := range newRuns {
+		_, ok := oldRuns[label]
+		if ok {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+
+	compare := func(label string, operation string, metric string, oldValue float64, newValue float64) {
+		if oldValue <= 0 {
+			return
+		}
+
+		change := (newValue - oldValue) / oldValue * 100
+		comparisons = append(comparisons, Comparison{
+			Label:      label,
+			Operation:  operation,
+			Metric:     metric,
+			Old:        oldValue,
+			New:        newValue,
+			Change:     change,
+			Regression: change > threshold,
+		})
+	}
+
+	for _, label := range labels {
+		oldRun := oldRuns[label]
+		newRun := newRuns[label]
+
+		compare(label, "", "elapsed", oldRun.Elapsed, newRun.Elapsed)
+
+		operations := []string{}
+		for operation := range newRun.Operations {
+			operations = append(operations, operation)
+		}
+		sort.Strings(operations)
+
+		for _, operation := range operations {
+			oldStats, ok := oldRun.Operations[operation]
+			if !ok || oldStats.Count == 0 {
+				continue
+			}
+
+			newStats := newRun.Operations[operation]
+			if newStats.Count == 0 {
+				continue
+			}
+
+			compare(label, operation, "p50", oldStats.P50, newStats.P50)
+			compare(label, operation, "p95", oldStats.P95, newStats.P95)
+			compare(label, operation, "p99", oldStats.P99, newStats.P99)
+		}
+	}
+
+	return comparisons
+}
+
+// PrintComparisons prints out the given comparisons and returns the number
+// of regressions among them.
+func PrintComparisons(comparisons []Comparison) int {
+	regressions := 0
+
+	fmt.Printf("  %-12s %-18s %-8s %12s %12s %9s\n", "LABEL", "OPERATION", "METRIC", "OLD (ms)", "NEW (ms)", "CHANGE")
+	for _, c := range comparisons {
+		operation := c.Operation
+		if operation == "" {
+			operation = "-"
+		}
+
+		mark := ""
+		if c.Regression {
+			mark = " REGRESSION"
+			regressions++
+		}
+
+		fmt.Printf("  %-12s %-18s %-8s %12.1f %12.1f %+8.1f%%%s\n", c.Label, operation, c.Metric, c.Old, c.New, c.Change, mark)
+	}
+
+	return regressions
+}
@@ -1,6 +1,12 @@
 package benchmark
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/shared/api"
 )
@@ -21,46 +27,215 @@ func createContainer(c lxd.ContainerServer, fingerprint string, name string, pri
 	}
 	req.Config = config
 
-	op, err := c.CreateContainer(req)
-	if err != nil {
-		return err
-	}
+	return timeOperation("create", func() error {
+		op, err := c.CreateContainer(req)
+		if err != nil {
+			return err
+		}
 
-	return op.Wait()
+		return op.Wait()
+	})
 }
 
 func startContainer(c lxd.ContainerServer, name string) error {
-	op, err := c.UpdateContainerState(
-		name, api.ContainerStatePut{Action: "start", Timeout: -1}, "")
+	return updateContainerState(c, name, api.ContainerStatePut{Action: "start", Timeout: -1})
+}
+
+func stopContainer(c lxd.ContainerServer, name string) error {
+	return updateContainerState(c, name, api.ContainerStatePut{Action: "stop", Timeout: -1, Force: true})
+}
+
+func freezeContainer(c lxd.ContainerServer, name string) error {
+	return updateContainerState(c, name, api.ContainerStatePut{Action: "freeze", Timeout: -1})
+}
+
+func updateContainerState(c lxd.ContainerServer, name string, state api.ContainerStatePut) error {
+	return timeOperation(state.Action, func() error {
+		op, err := c.UpdateContainerState(name, state, "")
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+}
+
+func deleteContainer(c lxd.ContainerServer, name string) error {
+	return timeOperation("delete", func() error {
+		op, err := c.DeleteContainer(name)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+}
+
+func copyContainer(c lxd.ContainerServer, container api.Container, name string) error {
+	return timeOperation("copy", func() error {
+		op, err := c.CopyContainer(c, container, &lxd.ContainerCopyArgs{Name: name})
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+}
+
+func renameContainer(c lxd.ContainerServer, name string, newName string) error {
+	return timeOperation("rename", func() error {
+		op, err := c.RenameContainer(name, api.ContainerPost{Name: newName})
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+}
+
+func createSnapshot(c lxd.ContainerServer, name string, snapshot string) error {
+	return timeOperation("snapshot-create", func() error {
+		op, err := c.CreateContainerSnapshot(name, api.ContainerSnapshotsPost{Name: snapshot})
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+}
+
+func restoreSnapshot(c lxd.ContainerServer, name string, snapshot string) error {
+	container, etag, err := c.GetContainer(name)
 	if err != nil {
 		return err
 	}
 
-	return op.Wait()
+	req := container.Writable()
+	req.Restore = snapshot
+
+	return timeOperation("snapshot-restore", func() error {
+		op, err := c.UpdateContainer(name, req, etag)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
 }
 
-func stopContainer(c lxd.ContainerServer, name string) error {
-	op, err := c.UpdateContainerState(
-		name, api.ContainerStatePut{Action: "stop", Timeout: -1, Force: true}, "")
+func deleteSnapshot(c lxd.ContainerServer, name string, snapshot string) error {
+	return timeOperation("snapshot-delete", func() error {
+		op, err := c.DeleteContainerSnapshot(name, snapshot)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+}
+
+func execContainer(c lxd.ContainerServer, name string, command []string) error {
+	req := api.ContainerExecPost{
+		Command: command,
+	}
+
+	return timeOperation("exec", func() error {
+		op, err := c.ExecContainer(name, req, nil)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+}
+
+func pushFile(c lxd.ContainerServer, name string, path string, content []byte) error {
+	args := lxd.ContainerFileArgs{
+		Content:   bytes.NewReader(content),
+		Mode:      0644,
+		Type:      "file",
+		WriteMode: "overwrite",
+	}
+
+	return timeTransfer("file-push", int64(len(content)), func() error {
+		return c.CreateContainerFile(name, path, args)
+	})
+}
+
+func pullFile(c lxd.ContainerServer, name string, path string, size int64) error {
+	return timeTransfer("file-pull", size, func() error {
+		content, _, err := c.GetContainerFile(name, path)
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+
+		_, err = io.Copy(ioutil.Discard, content)
+		return err
+	})
+}
+
+func migrateContainer(c lxd.ContainerServer, source lxd.ContainerServer, container api.Container, name string) error {
+	return timeOperation("migrate", func() error {
+		op, err := c.CopyContainer(source, container, &lxd.ContainerCopyArgs{Name: name, Mode: "relay"})
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+}
+
+func importImage(c lxd.ContainerServer, req api.ImagesPost, metaPath string, rootfsPath string) error {
+	var size int64
+	args := lxd.ImageCreateArgs{
+		ProgressHandler: func(progress lxd.ProgressData) {},
+	}
+
+	meta, err := os.Open(metaPath)
 	if err != nil {
 		return err
 	}
+	defer meta.Close()
 
-	return op.Wait()
-}
+	args.MetaFile = meta
+	args.MetaName = filepath.Base(metaPath)
 
-func freezeContainer(c lxd.ContainerServer, name string) error {
-	op, err := c.UpdateContainerState(
-		name, api.ContainerStatePut{Action: "freeze", Timeout: -1}, "")
+	info, err := meta.Stat()
 	if err != nil {
 		return err
 	}
+	size += info.Size()
 
-	return op.Wait()
+	if rootfsPath != "" {
+		rootfs, err := os.Open(rootfsPath)
+		if err != nil {
+			return err
+		}
+		defer rootfs.Close()
+
+		args.RootfsFile = rootfs
+		args.RootfsName = filepath.Base(rootfsPath)
+
+		info, err := rootfs.Stat()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+	}
+
+	return timeTransfer("image-import", size, func() error {
+		op, err := c.CreateImage(req, &args)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
 }
 
-func deleteContainer(c lxd.ContainerServer, name string) error {
-	op, err := c.DeleteContainer(name)
+func deleteImage(c lxd.ContainerServer, fingerprint string) error {
+	op, err := c.DeleteImage(fingerprint)
 	if err != nil {
 		return err
 	}
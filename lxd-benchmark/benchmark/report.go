@@ -2,12 +2,23 @@ package benchmark
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"time"
+
+	"github.com/lxc/lxd/client"
 )
 
+// Report is a report file benchmark results are added to.
+type Report interface {
+	Load() error
+	Write() error
+	AddRecord(label string, elapsed time.Duration) error
+}
+
 // Subset of JMeter CSV log format that are required by Jenkins performance
 // plugin
 // (see http://jmeter.apache.org/usermanual/listeners.html#csvlogformat)
@@ -93,3 +104,107 @@ func (r *CSVReport) addRecord(record []string) error {
 	r.records = append(r.records, record)
 	return nil
 }
+
+// ReportEnvironment describes the server a benchmark ran against.
+type ReportEnvironment struct {
+	ServerVersion  string `json:"server_version"`
+	Kernel         string `json:"kernel"`
+	KernelVersion  string `json:"kernel_version"`
+	Storage        string `json:"storage"`
+	StorageVersion string `json:"storage_version"`
+	Driver         string `json:"driver"`
+	DriverVersion  string `json:"driver_version"`
+}
+
+// GetReportEnvironment returns the environment of the given server.
+func GetReportEnvironment(c lxd.ContainerServer) (ReportEnvironment, error) {
+	server, _, err := c.GetServer()
+	if err != nil {
+		return ReportEnvironment{}, err
+	}
+
+	env := server.Environment
+	return ReportEnvironment{
+		ServerVersion:  env.ServerVersion,
+		Kernel:         env.Kernel,
+		KernelVersion:  env.KernelVersion,
+		Storage:        env.Storage,
+		StorageVersion: env.StorageVersion,
+		Driver:         env.Driver,
+		DriverVersion:  env.DriverVersion,
+	}, nil
+}
+
+// ReportRun holds the results of a benchmark run.
+type ReportRun struct {
+	Label       string                    `json:"label"`
+	Timestamp   time.Time                 `json:"timestamp"`
+	Elapsed     float64                   `json:"elapsed_ms"`
+	Environment ReportEnvironment         `json:"environment"`
+	Operations  map[string]OperationStats `json:"operations"`
+}
+
+// JSONReport reads/writes a JSON report file, holding the latency
+// statistics of each operation along with the total elapsed time.
+type JSONReport struct {
+	Filename    string            `json:"-"`
+	Environment ReportEnvironment `json:"-"`
+
+	Runs []ReportRun `json:"runs"`
+}
+
+// Load reads current content of the filename and loads runs.
+func (r *JSONReport) Load() error {
+	content, err := ioutil.ReadFile(r.Filename)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(content, r)
+	if err != nil {
+		return fmt.Errorf("Failed to parse report file %s: %v", r.Filename, err)
+	}
+
+	logf("Loaded report file %s", r.Filename)
+	return nil
+}
+
+// Write writes current runs to file.
+func (r *JSONReport) Write() error {
+	content, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(r.Filename, content, 0640)
+	if err != nil {
+		return err
+	}
+
+	logf("Written report file %s", r.Filename)
+	return nil
+}
+
+// AddRecord adds a run to the report, with the statistics of the operations
+// run so far.
+func (r *JSONReport) AddRecord(label string, elapsed time.Duration) error {
+	r.Runs = append(r.Runs, ReportRun{
+		Label:       label,
+		Timestamp:   time.Now().UTC(),
+		Elapsed:     float64(elapsed) / float64(time.Millisecond),
+		Environment: r.Environment,
+		Operations:  GetOperationStats(),
+	})
+
+	return nil
+}
+
+// lastRuns returns the last run of each label.
+func (r *JSONReport) lastRuns() map[string]ReportRun {
+	runs := map[string]ReportRun{}
+	for _, run := range r.Runs {
+		runs[run.Label] = run
+	}
+
+	return runs
+}
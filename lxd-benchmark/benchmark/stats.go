@@ -0,0 +1,217 @@
+package benchmark
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Upper bounds of the latency histogram buckets, in milliseconds. Bigger
+// values are added as needed.
+var histogramBounds = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000, 10000}
+
+// HistogramBucket is a bucket of a latency histogram.
+type HistogramBucket struct {
+	// Upper bound of the bucket, in milliseconds
+	UpperBound float64 `json:"le_ms"`
+
+	// Number of operations which took at most UpperBound and more than
+	// the upper bound of the previous bucket
+	Count int `json:"count"`
+}
+
+// OperationStats holds the latency statistics of a type of operation, all
+// latencies being in milliseconds.
+type OperationStats struct {
+	Count    int `json:"count"`
+	Failures int `json:"failures"`
+
+	Min  float64 `json:"min_ms"`
+	Mean float64 `json:"mean_ms"`
+	P50  float64 `json:"p50_ms"`
+	P95  float64 `json:"p95_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+
+	// Bytes transferred per second, for operations moving data around
+	Throughput float64 `json:"throughput,omitempty"`
+
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+type operationSamples struct {
+	latencies []time.Duration
+	failures  int
+	bytes     int64
+}
+
+var operations = struct {
+	sync.Mutex
+	samples map[string]*operationSamples
+}{samples: map[string]*operationSamples{}}
+
+// timeOperation runs the given function and records how long it took under
+// the given operation name.
+func timeOperation(name string, f func() error) error {
+	return timeTransfer(name, 0, f)
+}
+
+// timeTransfer is like timeOperation, for operations moving the given amount
+// of bytes around.
+func timeTransfer(name string, bytes int64, f func() error) error {
+	start := time.Now()
+	err := f()
+	elapsed := time.Since(start)
+
+	operations.Lock()
+	defer operations.Unlock()
+
+	samples, ok := operations.samples[name]
+	if !ok {
+		samples = &operationSamples{}
+		operations.samples[name] = samples
+	}
+
+	if err != nil {
+		samples.failures++
+		return err
+	}
+
+	samples.latencies = append(samples.latencies, elapsed)
+	samples.bytes += bytes
+	return nil
+}
+
+// GetOperationStats returns the latency statistics of the operations run so
+// far, indexed by operation name.
+func GetOperationStats() map[string]OperationStats {
+	operations.Lock()
+	defer operations.Unlock()
+
+	stats := map[string]OperationStats{}
+	for name, samples := range operations.samples {
+		stats[name] = computeStats(samples.latencies, samples.failures, samples.bytes)
+	}
+
+	return stats
+}
+
+func computeStats(latencies []time.Duration, failures int, bytes int64) OperationStats {
+	stats := OperationStats{
+		Count:     len(latencies),
+		Failures:  failures,
+		Histogram: []HistogramBucket{},
+	}
+
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	values := make([]float64, len(latencies))
+	total := time.Duration(0)
+	for i, latency := range latencies {
+		values[i] = float64(latency) / float64(time.Millisecond)
+		total += latency
+	}
+	sort.Float64s(values)
+
+	stats.Min = values[0]
+	stats.Max = values[len(values)-1]
+	stats.Mean = float64(total) / float64(time.Millisecond) / float64(len(values))
+	stats.P50 = percentile(values, 50)
+	stats.P95 = percentile(values, 95)
+	stats.P99 = percentile(values, 99)
+
+	if bytes > 0 && total > 0 {
+		stats.Throughput = float64(bytes) / total.Seconds()
+	}
+
+	stats.Histogram = histogram(values)
+	return stats
+}
+
+// percentile returns the p-th percentile of the given sorted values, using
+// the nearest-rank method.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return values[rank-1]
+}
+
+// histogram distributes the given sorted values in buckets, up to the first
+// bucket holding the biggest value.
+func histogram(values []float64) []HistogramBucket {
+	buckets := []HistogramBucket{}
+	if len(values) == 0 {
+		return buckets
+	}
+
+	bounds := append([]float64{}, histogramBounds...)
+	for bounds[len(bounds)-1] < values[len(values)-1] {
+		bounds = append(bounds, bounds[len(bounds)-1]*2)
+	}
+
+	i := 0
+	for _, bound := range bounds {
+		bucket := HistogramBucket{UpperBound: bound}
+		for i < len(values) && values[i] <= bound {
+			bucket.Count++
+			i++
+		}
+
+		buckets = append(buckets, bucket)
+		if i == len(values) {
+			break
+		}
+	}
+
+	return buckets
+}
+
+// PrintOperationStats prints out the latency statistics of the given
+// operations.
+func PrintOperationStats(stats map[string]OperationStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	names := []string{}
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("\nOperation latencies (ms):\n")
+	fmt.Printf("  %-18s %7s %7s %10s %10s %10s %10s %10s\n", "OPERATION", "COUNT", "FAILED", "MIN", "P50", "P95", "P99", "MAX")
+	for _, name := range names {
+		s := stats[name]
+		fmt.Printf("  %-18s %7d %7d %10.1f %10.1f %10.1f %10.1f %10.1f\n", name, s.Count, s.Failures, s.Min, s.P50, s.P95, s.P99, s.Max)
+		if s.Throughput > 0 {
+			fmt.Printf("  %-18s throughput: %.2f MB/s\n", "", s.Throughput/1000/1000)
+		}
+	}
+
+	for _, name := range names {
+		s := stats[name]
+		if len(s.Histogram) == 0 {
+			continue
+		}
+
+		fmt.Printf("\nLatency histogram for %s:\n", name)
+		for _, bucket := range s.Histogram {
+			bar := strings.Repeat("#", bucket.Count*40/s.Count)
+			fmt.Printf("  <= %8.0fms %7d %s\n", bucket.UpperBound, bucket.Count, bar)
+		}
+	}
+	fmt.Printf("\n")
+}
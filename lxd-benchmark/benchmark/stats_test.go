@@ -0,0 +1,65 @@
+package benchmark
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeStats(t *testing.T) {
+	latencies := []time.Duration{}
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := computeStats(latencies, 2, 100*1000*1000)
+	assert.Equal(t, 100, stats.Count)
+	assert.Equal(t, 2, stats.Failures)
+	assert.Equal(t, float64(1), stats.Min)
+	assert.Equal(t, float64(100), stats.Max)
+	assert.Equal(t, 50.5, stats.Mean)
+	assert.Equal(t, float64(50), stats.P50)
+	assert.Equal(t, float64(95), stats.P95)
+	assert.Equal(t, float64(99), stats.P99)
+	assert.InDelta(t, 100*1000*1000/5.05, stats.Throughput, 1)
+
+	assert.Equal(t, []HistogramBucket{
+		{UpperBound: 1, Count: 1},
+		{UpperBound: 2, Count: 1},
+		{UpperBound: 5, Count: 3},
+		{UpperBound: 10, Count: 5},
+		{UpperBound: 20, Count: 10},
+		{UpperBound: 50, Count: 30},
+		{UpperBound: 100, Count: 50},
+	}, stats.Histogram)
+}
+
+func TestComputeStats_Empty(t *testing.T) {
+	stats := computeStats(nil, 1, 0)
+	assert.Equal(t, OperationStats{Failures: 1, Histogram: []HistogramBucket{}}, stats)
+}
+
+func TestCompareReports(t *testing.T) {
+	oldReport := &JSONReport{Runs: []ReportRun{
+		{Label: "launch", Elapsed: 1000, Operations: map[string]OperationStats{
+			"create": {Count: 10, P50: 100, P95: 200, P99: 300},
+		}},
+		{Label: "exec", Elapsed: 50},
+	}}
+
+	newReport := &JSONReport{Runs: []ReportRun{
+		{Label: "launch", Elapsed: 1050, Operations: map[string]OperationStats{
+			"create": {Count: 10, P50: 100, P95: 250, P99: 300},
+			"start":  {Count: 10, P50: 100, P95: 250, P99: 300},
+		}},
+	}}
+
+	comparisons := CompareReports(oldReport, newReport, 10)
+	assert.Equal(t, []Comparison{
+		{Label: "launch", Metric: "elapsed", Old: 1000, New: 1050, Change: 5},
+		{Label: "launch", Operation: "create", Metric: "p50", Old: 100, New: 100},
+		{Label: "launch", Operation: "create", Metric: "p95", Old: 200, New: 250, Change: 25, Regression: true},
+		{Label: "launch", Operation: "create", Metric: "p99", Old: 300, New: 300},
+	}, comparisons)
+}
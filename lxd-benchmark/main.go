@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/lxc/lxd/client"
@@ -18,8 +19,15 @@ var argImage = gnuflag.String("image", "ubuntu:", "Image to use for the test")
 var argPrivileged = gnuflag.Bool("privileged", false, "Use privileged containers")
 var argStart = gnuflag.Bool("start", true, "Start the container after creation")
 var argFreeze = gnuflag.Bool("freeze", false, "Freeze the container right after start")
-var argReportFile = gnuflag.String("report-file", "", "A CSV file to write test file to. If the file is present, it will be appended to.")
+var argIterations = gnuflag.Int("iterations", 10, "Number of iterations per container for exec and file, number of imports for import")
+var argFileSize = gnuflag.String("file-size", "10MB", "Size of the file transferred by file")
+var argPool = gnuflag.String("pool", "", "Storage pool to move the containers to")
+var argReportFile = gnuflag.String("report-file", "", "A CSV or JSON file to write test file to. If the file is present, it will be appended to.")
+var argReportFormat = gnuflag.String("report-format", "", "Format of the report file (csv or json). By default, it's detected from the file extension.")
 var argReportLabel = gnuflag.String("report-label", "", "A label for the report entry. By default, the action is used.")
+var argThreshold = gnuflag.Float64("threshold", 10, "Latency increase (in percent) above which compare reports a regression")
+
+var actions = []string{"launch", "spawn", "start", "stop", "delete", "snapshot", "copy", "move", "exec", "file", "import", "compare"}
 
 func main() {
 	err := run(os.Args)
@@ -34,7 +42,7 @@ func main() {
 func run(args []string) error {
 	// Parse command line
 	// "spawn" is being deprecated, use "launch" instead.
-	if len(os.Args) == 1 || !shared.StringInSlice(os.Args[1], []string{"launch", "spawn", "start", "stop", "delete"}) {
+	if len(os.Args) == 1 || !shared.StringInSlice(os.Args[1], actions) {
 		if len(os.Args) > 1 && os.Args[1] == "--version" {
 			fmt.Println(version.Version)
 			return nil
@@ -49,7 +57,14 @@ func run(args []string) error {
 		fmt.Fprintf(out, "Usage: %s launch [--count=COUNT] [--image=IMAGE] [--privileged=BOOL] [--start=BOOL] [--freeze=BOOL] [--parallel=COUNT]\n", os.Args[0])
 		fmt.Fprintf(out, "       %s start [--parallel=COUNT]\n", os.Args[0])
 		fmt.Fprintf(out, "       %s stop [--parallel=COUNT]\n", os.Args[0])
-		fmt.Fprintf(out, "       %s delete [--parallel=COUNT]\n\n", os.Args[0])
+		fmt.Fprintf(out, "       %s delete [--parallel=COUNT]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s snapshot [--parallel=COUNT]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s copy [--parallel=COUNT]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s move --pool=POOL [--parallel=COUNT]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s exec [--iterations=COUNT] [--parallel=COUNT]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s file [--iterations=COUNT] [--file-size=SIZE] [--parallel=COUNT]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s import [--image=IMAGE] [--iterations=COUNT]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s compare [--threshold=PERCENT] <old report> <new report>\n\n", os.Args[0])
 		gnuflag.PrintDefaults()
 		fmt.Fprintf(out, "\n")
 
@@ -57,11 +72,16 @@ func run(args []string) error {
 			return nil
 		}
 
-		return fmt.Errorf("A valid action (launch, start, stop, delete) must be passed.")
+		return fmt.Errorf("A valid action (launch, start, stop, delete, snapshot, copy, move, exec, file, import, compare) must be passed.")
 	}
 
 	gnuflag.Parse(true)
 
+	action := os.Args[1]
+	if action == "compare" {
+		return compareReports(gnuflag.Args()[1:])
+	}
+
 	// Connect to LXD
 	c, err := lxd.ConnectLXDUnix("", nil)
 	if err != nil {
@@ -70,9 +90,30 @@ func run(args []string) error {
 
 	benchmark.PrintServerInfo(c)
 
-	var report *benchmark.CSVReport
+	var report benchmark.Report
 	if *argReportFile != "" {
-		report = &benchmark.CSVReport{Filename: *argReportFile}
+		format := *argReportFormat
+		if format == "" {
+			format = "csv"
+			if strings.HasSuffix(*argReportFile, ".json") {
+				format = "json"
+			}
+		}
+
+		switch format {
+		case "csv":
+			report = &benchmark.CSVReport{Filename: *argReportFile}
+		case "json":
+			env, err := benchmark.GetReportEnvironment(c)
+			if err != nil {
+				return err
+			}
+
+			report = &benchmark.JSONReport{Filename: *argReportFile, Environment: env}
+		default:
+			return fmt.Errorf("Unknown report format: %s", format)
+		}
+
 		if shared.PathExists(*argReportFile) {
 			err := report.Load()
 			if err != nil {
@@ -81,7 +122,6 @@ func run(args []string) error {
 		}
 	}
 
-	action := os.Args[1]
 	var duration time.Duration
 	switch action {
 	// "spawn" is being deprecated.
@@ -118,8 +158,65 @@ func run(args []string) error {
 		if err != nil {
 			return err
 		}
+	case "snapshot":
+		containers, err := benchmark.GetContainers(c)
+		if err != nil {
+			return err
+		}
+		duration, err = benchmark.SnapshotContainers(c, containers, *argParallel)
+		if err != nil {
+			return err
+		}
+	case "copy":
+		containers, err := benchmark.GetContainers(c)
+		if err != nil {
+			return err
+		}
+		duration, err = benchmark.CopyContainers(c, containers, *argParallel)
+		if err != nil {
+			return err
+		}
+	case "move":
+		containers, err := benchmark.GetContainers(c)
+		if err != nil {
+			return err
+		}
+		duration, err = benchmark.MoveContainers(c, containers, *argParallel, *argPool)
+		if err != nil {
+			return err
+		}
+	case "exec":
+		containers, err := benchmark.GetContainers(c)
+		if err != nil {
+			return err
+		}
+		duration, err = benchmark.ExecContainers(c, containers, *argParallel, *argIterations)
+		if err != nil {
+			return err
+		}
+	case "file":
+		size, err := shared.ParseByteSizeString(*argFileSize)
+		if err != nil {
+			return err
+		}
+
+		containers, err := benchmark.GetContainers(c)
+		if err != nil {
+			return err
+		}
+		duration, err = benchmark.TransferFiles(c, containers, *argParallel, *argIterations, size)
+		if err != nil {
+			return err
+		}
+	case "import":
+		duration, err = benchmark.ImportImage(c, *argImage, *argIterations)
+		if err != nil {
+			return err
+		}
 	}
 
+	benchmark.PrintOperationStats(benchmark.GetOperationStats())
+
 	if report != nil {
 		label := action
 		if *argReportLabel != "" {
@@ -133,3 +230,32 @@ func run(args []string) error {
 	}
 	return nil
 }
+
+func compareReports(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("Two JSON report files must be passed")
+	}
+
+	reports := []*benchmark.JSONReport{}
+	for _, filename := range args {
+		report := &benchmark.JSONReport{Filename: filename}
+		err := report.Load()
+		if err != nil {
+			return err
+		}
+
+		reports = append(reports, report)
+	}
+
+	comparisons := benchmark.CompareReports(reports[0], reports[1], *argThreshold)
+	if len(comparisons) == 0 {
+		return fmt.Errorf("The reports have no run in common")
+	}
+
+	regressions := benchmark.PrintComparisons(comparisons)
+	if regressions > 0 {
+		return fmt.Errorf("Found %d regressions above %.1f%%", regressions, *argThreshold)
+	}
+
+	return nil
+}
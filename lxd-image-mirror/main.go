@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxd-image-mirror/mirror"
+	"github.com/lxc/lxd/shared/gnuflag"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var argProducts = gnuflag.String("products", "", "Comma separated list of product patterns to mirror (e.g. \"com.ubuntu.cloud:server:18.04:*\")")
+var argArchitectures = gnuflag.String("architectures", "", "Comma separated list of architectures to mirror")
+var argReleases = gnuflag.String("releases", "", "Comma separated list of releases to mirror")
+var argKeep = gnuflag.Int("keep", 3, "Number of versions of each product to keep (0 for all)")
+var argDeltas = gnuflag.Bool("deltas", false, "Generate squashfs deltas between versions (requires xdelta3)")
+var argQuiet = gnuflag.Bool("quiet", false, "Don't report the changes made to the mirror")
+
+func main() {
+	err := run(os.Args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+func usage(out *os.File) {
+	gnuflag.SetOut(out)
+
+	fmt.Fprintf(out, "Usage: %s [--products=PATTERNS] [--architectures=ARCHITECTURES] [--releases=RELEASES] [--keep=COUNT] [--deltas] <source> <directory>\n\n", os.Args[0])
+	fmt.Fprintf(out, "Mirror the images of a simplestreams server (http://, https:// or file:// URL)\n")
+	fmt.Fprintf(out, "into a directory, which can then be served as a simplestreams server itself.\n\n")
+	gnuflag.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+func run(args []string) error {
+	if len(args) > 1 && args[1] == "--version" {
+		fmt.Println(version.Version)
+		return nil
+	}
+
+	if len(args) > 1 && (args[1] == "--help" || args[1] == "-h") {
+		usage(os.Stdout)
+		return nil
+	}
+
+	gnuflag.Parse(true)
+	if gnuflag.NArg() != 2 {
+		usage(os.Stderr)
+		return fmt.Errorf("A source and a target directory must be passed.")
+	}
+
+	source := gnuflag.Arg(0)
+	target, err := filepath.Abs(gnuflag.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	logger := func(message string) {
+		if !*argQuiet {
+			fmt.Println(message)
+		}
+	}
+
+	err = mirror.Sync(source, target, mirror.Args{
+		Products:      splitList(*argProducts),
+		Architectures: splitList(*argArchitectures),
+		Releases:      splitList(*argReleases),
+		Keep:          *argKeep,
+		Deltas:        *argDeltas,
+		Logger:        logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Check that the result can be consumed by LXD
+	remote, err := lxd.ConnectSimpleStreams(fmt.Sprintf("file://%s", target), nil)
+	if err != nil {
+		return err
+	}
+
+	images, err := remote.GetImages()
+	if err != nil {
+		return fmt.Errorf("The mirror isn't usable: %v", err)
+	}
+
+	logger(fmt.Sprintf("The mirror contains %d images", len(images)))
+
+	return nil
+}
+
+func splitList(value string) []string {
+	result := []string{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry != "" {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
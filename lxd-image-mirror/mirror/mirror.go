@@ -0,0 +1,522 @@
+package mirror
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/simplestreams"
+	"github.com/lxc/lxd/shared/version"
+)
+
+// The file types which are mirrored, everything else is only of interest to
+// other consumers of simplestreams.
+var mirrorFileTypes = []string{"lxd.tar.xz", "lxd_combined.tar.gz", "root.tar.xz", "squashfs", "squashfs.vcdiff"}
+
+// Args selects what to mirror and how.
+type Args struct {
+	// Patterns matching the product names (e.g. "com.ubuntu.cloud:server:*"), all
+	// products are mirrored if empty.
+	Products []string
+
+	// Architectures and releases (names or codenames) to mirror, all of
+	// them if empty.
+	Architectures []string
+	Releases      []string
+
+	// Number of versions of each product to keep, all of them if 0.
+	Keep int
+
+	// Whether to generate squashfs deltas between consecutive versions.
+	Deltas bool
+
+	// Function called with a message for every change made to the mirror.
+	Logger func(message string)
+}
+
+// Sync updates the simplestreams repository in the target directory with
+// the selected products of the source, which is either a http(s):// or a
+// file:// URL.
+//
+// Files already present in the target are only downloaded again if their
+// hash doesn't match, and versions which are no longer kept are removed.
+func Sync(source string, target string, args Args) error {
+	if args.Logger == nil {
+		args.Logger = func(message string) {}
+	}
+
+	if args.Deltas {
+		_, err := exec.LookPath("xdelta3")
+		if err != nil {
+			return fmt.Errorf("Generating deltas requires xdelta3")
+		}
+	}
+
+	transport := &http.Transport{
+		Dial:  shared.RFC3493Dialer,
+		Proxy: shared.ProxyFromEnvironment,
+	}
+	transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+
+	ss := simplestreams.NewClient(strings.TrimSuffix(source, "/"), http.Client{Transport: transport}, version.UserAgent)
+
+	index, err := ss.GetIndex()
+	if err != nil {
+		return err
+	}
+
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return err
+	}
+
+	// Keep the streams of the existing mirror which are no longer listed
+	// in the source.
+	mirrorIndex := simplestreams.SimpleStreamsIndex{}
+	err = readJSON(filepath.Join(target, "streams", "v1", "index.json"), &mirrorIndex)
+	if err != nil {
+		return err
+	}
+
+	if mirrorIndex.Index == nil {
+		mirrorIndex.Index = map[string]simplestreams.SimpleStreamsIndexStream{}
+	}
+	mirrorIndex.Format = "index:1.0"
+	mirrorIndex.Updated = time.Now().UTC().Format(time.RFC1123Z)
+
+	names := []string{}
+	for name := range index.Index {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		stream := index.Index[name]
+		if stream.DataType != "image-downloads" {
+			continue
+		}
+
+		_, err := targetPath(target, stream.Path)
+		if err != nil {
+			return err
+		}
+
+		manifest, err := ss.GetManifest(stream.Path)
+		if err != nil {
+			return err
+		}
+
+		m := &mirror{ss: ss, target: target, args: args}
+		products, err := m.syncManifest(stream.Path, manifest)
+		if err != nil {
+			return err
+		}
+
+		stream.Updated = mirrorIndex.Updated
+		stream.Products = products
+		mirrorIndex.Index[name] = stream
+	}
+
+	return writeJSON(filepath.Join(target, "streams", "v1", "index.json"), mirrorIndex)
+}
+
+type mirror struct {
+	ss     *simplestreams.SimpleStreams
+	target string
+	args   Args
+}
+
+// syncManifest mirrors the selected products of a manifest and returns their
+// names.
+func (m *mirror) syncManifest(manifestPath string, manifest *simplestreams.SimpleStreamsManifest) ([]string, error) {
+	// Load the existing manifest, keeping track of its files to remove
+	// those which are no longer referenced.
+	manifestTarget, err := targetPath(m.target, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	current := simplestreams.SimpleStreamsManifest{}
+	err = readJSON(manifestTarget, &current)
+	if err != nil {
+		return nil, err
+	}
+
+	oldFiles := map[string]bool{}
+	for _, product := range current.Products {
+		for _, version := range product.Versions {
+			for _, item := range version.Items {
+				oldFiles[item.Path] = true
+			}
+		}
+	}
+
+	result := simplestreams.SimpleStreamsManifest{
+		Updated:  time.Now().UTC().Format(time.RFC1123Z),
+		DataType: manifest.DataType,
+		Format:   manifest.Format,
+		License:  manifest.License,
+		Products: map[string]simplestreams.SimpleStreamsManifestProduct{},
+	}
+
+	names := []string{}
+	for name, product := range manifest.Products {
+		if m.selected(name, product) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		product := manifest.Products[name]
+
+		// Versions mirrored previously are kept until they're
+		// superseded, even if the source dropped them.
+		versions := map[string]simplestreams.SimpleStreamsManifestProductVersion{}
+		for serial, version := range current.Products[name].Versions {
+			versions[serial] = version
+		}
+
+		for serial, version := range product.Versions {
+			items := map[string]simplestreams.SimpleStreamsManifestProductVersionItem{}
+			for key, item := range version.Items {
+				if shared.StringInSlice(item.FileType, mirrorFileTypes) {
+					items[key] = item
+				}
+			}
+
+			if len(items) == 0 {
+				continue
+			}
+
+			// Keep the deltas generated by previous runs
+			for key, item := range versions[serial].Items {
+				_, ok := items[key]
+				if !ok && item.FileType == "squashfs.vcdiff" {
+					items[key] = item
+				}
+			}
+
+			version.Items = items
+			versions[serial] = version
+		}
+
+		product.Versions = m.keep(versions)
+
+		err := m.syncProduct(name, &product)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Products[name] = product
+	}
+
+	// Remove the files of the versions which were dropped
+	for _, product := range result.Products {
+		for _, version := range product.Versions {
+			for _, item := range version.Items {
+				delete(oldFiles, item.Path)
+			}
+		}
+	}
+
+	for file := range oldFiles {
+		target, err := targetPath(m.target, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m.args.Logger(fmt.Sprintf("Removing %s", file))
+
+		err = os.Remove(target)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	err = writeJSON(manifestTarget, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// selected returns whether the given product should be mirrored.
+func (m *mirror) selected(name string, product simplestreams.SimpleStreamsManifestProduct) bool {
+	if len(m.args.Architectures) > 0 && !shared.StringInSlice(product.Architecture, m.args.Architectures) {
+		return false
+	}
+
+	if len(m.args.Releases) > 0 && !shared.StringInSlice(product.Release, m.args.Releases) && !shared.StringInSlice(product.ReleaseCodename, m.args.Releases) {
+		return false
+	}
+
+	if len(m.args.Products) == 0 {
+		return true
+	}
+
+	for _, pattern := range m.args.Products {
+		match, err := path.Match(pattern, name)
+		if err == nil && match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keep returns the most recent versions to keep. Version names start with
+// their date, so sorting them sorts the versions.
+func (m *mirror) keep(versions map[string]simplestreams.SimpleStreamsManifestProductVersion) map[string]simplestreams.SimpleStreamsManifestProductVersion {
+	if m.args.Keep <= 0 || len(versions) <= m.args.Keep {
+		return versions
+	}
+
+	serials := []string{}
+	for serial := range versions {
+		serials = append(serials, serial)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(serials)))
+
+	result := map[string]simplestreams.SimpleStreamsManifestProductVersion{}
+	for _, serial := range serials[:m.args.Keep] {
+		result[serial] = versions[serial]
+	}
+
+	return result
+}
+
+// syncProduct downloads the files of all the versions of a product and
+// generates the missing deltas.
+func (m *mirror) syncProduct(name string, product *simplestreams.SimpleStreamsManifestProduct) error {
+	serials := []string{}
+	for serial := range product.Versions {
+		serials = append(serials, serial)
+	}
+	sort.Strings(serials)
+
+	for i, serial := range serials {
+		version := product.Versions[serial]
+
+		for key, item := range version.Items {
+			// Deltas against versions which aren't kept are useless
+			if item.FileType == "squashfs.vcdiff" {
+				_, ok := product.Versions[item.DeltaBase]
+				if !ok {
+					delete(version.Items, key)
+					continue
+				}
+			}
+
+			err := m.syncFile(item)
+			if err != nil {
+				// Deltas are optional and may be generated again
+				if item.FileType == "squashfs.vcdiff" {
+					delete(version.Items, key)
+					continue
+				}
+
+				return err
+			}
+		}
+
+		if m.args.Deltas && i > 0 {
+			err := m.generateDelta(name, serials[i-1], product.Versions[serials[i-1]], serial, &version)
+			if err != nil {
+				return err
+			}
+		}
+
+		product.Versions[serial] = version
+	}
+
+	return nil
+}
+
+// syncFile downloads a file unless it's already present with the right hash.
+func (m *mirror) syncFile(item simplestreams.SimpleStreamsManifestProductVersionItem) error {
+	target, err := targetPath(m.target, item.Path)
+	if err != nil {
+		return err
+	}
+
+	if shared.PathExists(target) {
+		hash, err := fileHash(target)
+		if err != nil {
+			return err
+		}
+
+		if hash == item.HashSha256 {
+			return nil
+		}
+	}
+
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+
+	m.args.Logger(fmt.Sprintf("Downloading %s", item.Path))
+
+	err = m.ss.DownloadFile(item.Path, item.HashSha256, target+".tmp", nil)
+	if err != nil {
+		os.Remove(target + ".tmp")
+		return err
+	}
+
+	return os.Rename(target+".tmp", target)
+}
+
+// generateDelta adds a delta from the squashfs of the previous version to
+// the one of the given version, unless it already has one.
+func (m *mirror) generateDelta(name string, baseSerial string, base simplestreams.SimpleStreamsManifestProductVersion, serial string, version *simplestreams.SimpleStreamsManifestProductVersion) error {
+	var baseSquashfs, squashfs *simplestreams.SimpleStreamsManifestProductVersionItem
+	for _, item := range base.Items {
+		if item.FileType == "squashfs" {
+			found := item
+			baseSquashfs = &found
+		}
+	}
+
+	for _, item := range version.Items {
+		if item.FileType == "squashfs" {
+			found := item
+			squashfs = &found
+		}
+
+		if item.FileType == "squashfs.vcdiff" && item.DeltaBase == baseSerial {
+			return nil
+		}
+	}
+
+	if baseSquashfs == nil || squashfs == nil {
+		return nil
+	}
+
+	deltaPath := path.Join(path.Dir(squashfs.Path), fmt.Sprintf("%s.vcdiff", baseSerial))
+	target, err := targetPath(m.target, deltaPath)
+	if err != nil {
+		return err
+	}
+
+	baseSource, err := targetPath(m.target, baseSquashfs.Path)
+	if err != nil {
+		return err
+	}
+
+	source, err := targetPath(m.target, squashfs.Path)
+	if err != nil {
+		return err
+	}
+
+	m.args.Logger(fmt.Sprintf("Generating delta %s for %s", deltaPath, name))
+
+	_, err = shared.RunCommand("xdelta3", "-f", "-e", "-s", baseSource, source, target)
+	if err != nil {
+		return err
+	}
+
+	hash, err := fileHash(target)
+	if err != nil {
+		return err
+	}
+
+	st, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	version.Items[fmt.Sprintf("delta-%s", baseSerial)] = simplestreams.SimpleStreamsManifestProductVersionItem{
+		Path:       deltaPath,
+		FileType:   "squashfs.vcdiff",
+		HashSha256: hash,
+		Size:       st.Size(),
+		DeltaBase:  baseSerial,
+	}
+
+	return nil
+}
+
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, f)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// targetPath returns where a path listed by the source is stored in the
+// mirror, refusing the paths which would end up outside of it.
+func targetPath(target string, name string) (string, error) {
+	if name == "" || filepath.IsAbs(name) {
+		return "", fmt.Errorf("Invalid path in source: %q", name)
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("Invalid path in source: %q", name)
+		}
+	}
+
+	full := filepath.Join(target, name)
+	if !strings.HasPrefix(full, filepath.Clean(target)+string(filepath.Separator)) {
+		return "", fmt.Errorf("Invalid path in source: %q", name)
+	}
+
+	return full, nil
+}
+
+// readJSON loads a JSON file, leaving the target untouched if it doesn't
+// exist.
+func readJSON(path string, target interface{}) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	return json.Unmarshal(content, target)
+}
+
+// writeJSON atomically replaces a JSON file.
+func writeJSON(path string, content interface{}) error {
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path+".tmp", data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
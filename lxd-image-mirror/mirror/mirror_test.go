@@ -0,0 +1,190 @@
+package mirror_test
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd-image-mirror/mirror"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/simplestreams"
+)
+
+func TestSync(t *testing.T) {
+	source, cleanup := newTestDir(t)
+	defer cleanup()
+
+	target, cleanup := newTestDir(t)
+	defer cleanup()
+
+	products := map[string][]string{
+		"busybox:1.0:amd64": {"20180101_0000", "20180102_0000", "20180103_0000"},
+		"busybox:1.0:i386":  {"20180101_0000"},
+	}
+	writeSource(t, source, products)
+
+	args := mirror.Args{Products: []string{"busybox:*:amd64"}, Keep: 2}
+	require.NoError(t, mirror.Sync("file://"+source, target, args))
+
+	// Only the two most recent versions of the selected product are there
+	manifest := readManifest(t, target)
+	assert.Len(t, manifest.Products, 1)
+	assert.Len(t, manifest.Products["busybox:1.0:amd64"].Versions, 2)
+	assert.False(t, shared.PathExists(filepath.Join(target, "images", "busybox:1.0:amd64", "20180101_0000", "lxd.tar.xz")))
+	assert.True(t, shared.PathExists(filepath.Join(target, "images", "busybox:1.0:amd64", "20180103_0000", "lxd.tar.xz")))
+	assert.False(t, shared.PathExists(filepath.Join(target, "images", "busybox:1.0:i386")))
+
+	// Corrupted files are downloaded again and older versions dropped
+	rootfs := filepath.Join(target, "images", "busybox:1.0:amd64", "20180103_0000", "root.tar.xz")
+	require.NoError(t, ioutil.WriteFile(rootfs, []byte("corrupted"), 0644))
+
+	products["busybox:1.0:amd64"] = append(products["busybox:1.0:amd64"], "20180104_0000")
+	writeSource(t, source, products)
+	require.NoError(t, mirror.Sync("file://"+source, target, args))
+
+	content, err := ioutil.ReadFile(rootfs)
+	require.NoError(t, err)
+	assert.Equal(t, "root busybox:1.0:amd64 20180103_0000", string(content))
+	assert.False(t, shared.PathExists(filepath.Join(target, "images", "busybox:1.0:amd64", "20180102_0000", "lxd.tar.xz")))
+
+	manifest = readManifest(t, target)
+	versions := manifest.Products["busybox:1.0:amd64"].Versions
+	assert.Len(t, versions, 2)
+	assert.Contains(t, versions, "20180104_0000")
+
+	// The index lists the mirrored products
+	index := simplestreams.SimpleStreamsIndex{}
+	readJSON(t, filepath.Join(target, "streams", "v1", "index.json"), &index)
+	assert.Equal(t, []string{"busybox:1.0:amd64"}, index.Index["images"].Products)
+}
+
+func TestSyncInvalidPaths(t *testing.T) {
+	source, cleanup := newTestDir(t)
+	defer cleanup()
+
+	target, cleanup := newTestDir(t)
+	defer cleanup()
+
+	products := map[string][]string{"busybox:1.0:amd64": {"20180101_0000"}}
+	args := mirror.Args{Products: []string{"busybox:*:amd64"}}
+
+	// Files outside of the target aren't written
+	for _, path := range []string{"../escape", "images/../../escape", "/tmp/escape"} {
+		writeSource(t, source, products)
+		manifest := readManifest(t, source)
+		version := manifest.Products["busybox:1.0:amd64"].Versions["20180101_0000"]
+		item := version.Items["lxd.tar.xz"]
+		item.Path = path
+		version.Items["lxd.tar.xz"] = item
+		writeJSON(t, filepath.Join(source, "streams", "v1", "images.json"), manifest)
+
+		assert.Error(t, mirror.Sync("file://"+source, filepath.Join(target, "mirror"), args), path)
+		assert.False(t, shared.PathExists(filepath.Join(target, "escape")))
+	}
+
+	// Nor removed
+	victim := filepath.Join(target, "victim")
+	require.NoError(t, ioutil.WriteFile(victim, []byte("victim"), 0644))
+
+	current := simplestreams.SimpleStreamsManifest{
+		Products: map[string]simplestreams.SimpleStreamsManifestProduct{
+			"busybox:1.0:amd64": {Versions: map[string]simplestreams.SimpleStreamsManifestProductVersion{
+				"20170101_0000": {Items: map[string]simplestreams.SimpleStreamsManifestProductVersionItem{
+					"lxd.tar.xz": {Path: "../victim", FileType: "lxd.tar.xz"},
+				}},
+			}},
+		},
+	}
+	writeJSON(t, filepath.Join(target, "mirror", "streams", "v1", "images.json"), current)
+
+	writeSource(t, source, products)
+	assert.Error(t, mirror.Sync("file://"+source, filepath.Join(target, "mirror"), args))
+	assert.True(t, shared.PathExists(victim))
+}
+
+// writeSource generates a simplestreams repository with the given versions of
+// each product.
+func writeSource(t *testing.T, dir string, products map[string][]string) {
+	manifest := simplestreams.SimpleStreamsManifest{
+		DataType: "image-downloads",
+		Format:   "products:1.0",
+		Products: map[string]simplestreams.SimpleStreamsManifestProduct{},
+	}
+
+	names := []string{}
+	for name, serials := range products {
+		names = append(names, name)
+
+		product := simplestreams.SimpleStreamsManifestProduct{
+			Architecture: name[strings.LastIndex(name, ":")+1:],
+			Versions:     map[string]simplestreams.SimpleStreamsManifestProductVersion{},
+		}
+
+		for _, serial := range serials {
+			items := map[string]simplestreams.SimpleStreamsManifestProductVersionItem{}
+			for file, kind := range map[string]string{"lxd.tar.xz": "meta", "root.tar.xz": "root"} {
+				path := fmt.Sprintf("images/%s/%s/%s", name, serial, file)
+				content := fmt.Sprintf("%s %s %s", kind, name, serial)
+
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+
+				items[file] = simplestreams.SimpleStreamsManifestProductVersionItem{
+					Path:       path,
+					FileType:   file,
+					HashSha256: fmt.Sprintf("%x", sha256.Sum256([]byte(content))),
+					Size:       int64(len(content)),
+				}
+			}
+
+			product.Versions[serial] = simplestreams.SimpleStreamsManifestProductVersion{Items: items}
+		}
+
+		manifest.Products[name] = product
+	}
+
+	index := simplestreams.SimpleStreamsIndex{
+		Format: "index:1.0",
+		Index: map[string]simplestreams.SimpleStreamsIndexStream{
+			"images": {DataType: "image-downloads", Path: "streams/v1/images.json", Products: names},
+		},
+	}
+
+	writeJSON(t, filepath.Join(dir, "streams", "v1", "index.json"), index)
+	writeJSON(t, filepath.Join(dir, "streams", "v1", "images.json"), manifest)
+}
+
+func readManifest(t *testing.T, dir string) simplestreams.SimpleStreamsManifest {
+	manifest := simplestreams.SimpleStreamsManifest{}
+	readJSON(t, filepath.Join(dir, "streams", "v1", "images.json"), &manifest)
+
+	return manifest
+}
+
+func readJSON(t *testing.T, path string, target interface{}) {
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, target))
+}
+
+func writeJSON(t *testing.T, path string, content interface{}) {
+	data, err := json.Marshal(content)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, data, 0644))
+}
+
+func newTestDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "lxd-image-mirror-")
+	require.NoError(t, err)
+
+	return dir, func() { os.RemoveAll(dir) }
+}
@@ -21,11 +21,15 @@ var api10 = []Command{
 	containerCmd,
 	containerConsoleCmd,
 	containerStateCmd,
+	containerRebuildCmd,
 	containerFileCmd,
 	containerLogsCmd,
 	containerLogCmd,
 	containerSnapshotsCmd,
 	containerSnapshotCmd,
+	containerBackupsCmd,
+	containerBackupCmd,
+	containerBackupExportCmd,
 	containerExecCmd,
 	containerMetadataCmd,
 	containerMetadataTemplatesCmd,
@@ -43,17 +47,25 @@ var api10 = []Command{
 	operationWebsocket,
 	networksCmd,
 	networkCmd,
+	networkLeasesCmd,
+	networkStateCmd,
 	api10Cmd,
 	certificatesCmd,
 	certificateFingerprintCmd,
+	metadataConfigurationCmd,
+	metricsCmd,
 	profilesCmd,
 	profileCmd,
+	projectsCmd,
+	projectCmd,
 	serverResourceCmd,
 	storagePoolsCmd,
 	storagePoolCmd,
 	storagePoolResourcesCmd,
 	storagePoolVolumesCmd,
 	storagePoolVolumesTypeCmd,
+	storagePoolVolumeSnapshotsTypeCmd,
+	storagePoolVolumeSnapshotTypeCmd,
 	storagePoolVolumeTypeCmd,
 	serverResourceCmd,
 }
@@ -149,6 +161,11 @@ func api10Get(d *Daemon, r *http.Request) Response {
 	fullSrv.Environment = env
 	fullSrv.Config = daemonConfigRender()
 
+	// Restricted clients don't get to see the server configuration
+	if d.clientRestrictedProjects(r) != nil {
+		fullSrv.Config = map[string]interface{}{}
+	}
+
 	return SyncResponseETag(true, fullSrv, fullSrv.Config)
 }
 
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// /1.0/metadata/configuration
+// Describe every configuration key supported by the server
+func metadataConfigurationGet(d *Daemon, r *http.Request) Response {
+	return SyncResponse(true, configMetadata())
+}
+
+var metadataConfigurationCmd = Command{name: "metadata/configuration", get: metadataConfigurationGet}
+
+func configMetadata() api.MetadataConfiguration {
+	containerKeys := configMetadataKeys(shared.KnownContainerConfigKeys, containerConfigKeyDocs)
+
+	// Volatile keys are internal to containers
+	profileKeys := map[string]api.MetadataConfigurationKey{}
+	for name, key := range containerKeys {
+		if strings.HasPrefix(name, "volatile.") {
+			continue
+		}
+
+		profileKeys[name] = key
+	}
+
+	serverKeys := map[string]api.MetadataConfigurationKey{}
+	for name, key := range daemonConfig {
+		doc := serverConfigKeyDocs[name]
+		doc.Default = key.defaultValue
+		doc.Values = key.validValues
+
+		valueType := key.valueType
+		if valueType == "int" {
+			valueType = "integer"
+		}
+
+		serverKeys[name] = configMetadataKey(valueType, doc)
+	}
+
+	return api.MetadataConfiguration{
+		Configs: map[string]map[string]api.MetadataConfigurationKey{
+			"container": containerKeys,
+			"profile":   profileKeys,
+			"pool":      configMetadataKeys(storagePoolConfigKeys, storagePoolConfigKeyDocs),
+			"volume":    configMetadataKeys(storageVolumeConfigKeys, storageVolumeConfigKeyDocs),
+			"network":   configMetadataKeys(networkConfigKeys, networkConfigKeyDocs),
+			"server":    serverKeys,
+		},
+	}
+}
+
+// configMetadataKeys describes the keys of the given validation table. Key
+// patterns which are only validated by prefix (e.g. user.*) come from the
+// documentation alone.
+func configMetadataKeys(validators map[string]func(string) error, docs map[string]configKeyDoc) map[string]api.MetadataConfigurationKey {
+	keys := map[string]api.MetadataConfigurationKey{}
+
+	for name, validator := range validators {
+		keys[name] = configMetadataKey(configValidatorType(validator), docs[name])
+	}
+
+	for name, doc := range docs {
+		_, ok := keys[name]
+		if ok {
+			continue
+		}
+
+		keys[name] = configMetadataKey("string", doc)
+	}
+
+	return keys
+}
+
+func configMetadataKey(valueType string, doc configKeyDoc) api.MetadataConfigurationKey {
+	key := api.MetadataConfigurationKey{
+		Type:          valueType,
+		Default:       doc.Default,
+		AllowedValues: doc.Values,
+		Drivers:       doc.Drivers,
+		Description:   doc.Description,
+	}
+
+	if key.AllowedValues == nil {
+		key.AllowedValues = []string{}
+	}
+
+	if key.Drivers == nil {
+		key.Drivers = []string{}
+	}
+
+	return key
+}
+
+// configValidatorType returns the type of the values accepted by the given
+// validator, which is a string unless it's one of the shared type checkers.
+func configValidatorType(validator func(string) error) string {
+	switch reflect.ValueOf(validator).Pointer() {
+	case reflect.ValueOf(shared.IsBool).Pointer():
+		return "bool"
+	case reflect.ValueOf(shared.IsInt64).Pointer(), reflect.ValueOf(shared.IsUint32).Pointer(), reflect.ValueOf(shared.IsPriority).Pointer():
+		return "integer"
+	}
+
+	return "string"
+}
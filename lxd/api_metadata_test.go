@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/lxc/lxd/shared"
+)
+
+type metadataTestSuite struct {
+	lxdTestSuite
+}
+
+// Every configuration key is documented, and documentation-only keys are
+// patterns which the validators match by prefix.
+func (suite *metadataTestSuite) TestConfigMetadata_Descriptions() {
+	metadata := configMetadata()
+
+	for scope, keys := range metadata.Configs {
+		for name, key := range keys {
+			suite.Req.NotEqual("", key.Description, "%s key %s has no description", scope, name)
+		}
+	}
+
+	tables := map[string]map[string]func(string) error{
+		"container": shared.KnownContainerConfigKeys,
+		"pool":      storagePoolConfigKeys,
+		"volume":    storageVolumeConfigKeys,
+		"network":   networkConfigKeys,
+	}
+
+	for scope, validators := range tables {
+		for name := range metadata.Configs[scope] {
+			_, ok := validators[name]
+			if ok {
+				continue
+			}
+
+			isPattern := strings.ContainsAny(name, "*<") || strings.Contains(name, "TARGET")
+			suite.Req.True(isPattern, "%s key %s is unknown to the server", scope, name)
+		}
+	}
+
+	for name := range serverConfigKeyDocs {
+		_, ok := daemonConfig[name]
+		suite.Req.True(ok, "server key %s is unknown to the server", name)
+	}
+}
+
+func (suite *metadataTestSuite) TestConfigMetadata_Types() {
+	metadata := configMetadata()
+
+	suite.Req.Equal("bool", metadata.Configs["container"]["security.nesting"].Type)
+	suite.Req.Equal("integer", metadata.Configs["container"]["limits.cpu.priority"].Type)
+	suite.Req.Equal("string", metadata.Configs["container"]["limits.memory"].Type)
+	suite.Req.Equal("integer", metadata.Configs["server"]["images.remote_cache_expiry"].Type)
+	suite.Req.Equal("10", metadata.Configs["server"]["images.remote_cache_expiry"].Default)
+
+	_, ok := metadata.Configs["profile"]["volatile.base_image"]
+	suite.Req.False(ok)
+}
+
+func TestMetadataTestSuite(t *testing.T) {
+	suite.Run(t, new(metadataTestSuite))
+}
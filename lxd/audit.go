@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// auditEntry is an entry of the audit log, describing a mutating API
+// request.
+type auditEntry struct {
+	Timestamp  time.Time                    `json:"timestamp"`
+	Requestor  *api.EventLifecycleRequestor `json:"requestor"`
+	Address    string                       `json:"address"`
+	Method     string                       `json:"method"`
+	URL        string                       `json:"url"`
+	StatusCode int                          `json:"status_code"`
+	Operation  string                       `json:"operation,omitempty"`
+}
+
+// auditLog is an append-only log of the mutating API requests, one JSON
+// entry per line.
+type auditLog struct {
+	file *os.File
+	lock sync.Mutex
+}
+
+func auditLogOpen(path string) (*auditLog, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open the audit log: %v", err)
+	}
+
+	return &auditLog{file: file}, nil
+}
+
+// Record appends the given entry to the log.
+func (l *auditLog) Record(entry auditEntry) error {
+	if l == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the log.
+func (l *auditLog) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.file.Close()
+}
+
+// auditResponseWriter keeps track of the status code sent back to the
+// client.
+type auditResponseWriter struct {
+	http.ResponseWriter
+
+	statusCode int
+}
+
+func (w *auditResponseWriter) WriteHeader(code int) {
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Hijack passes through to the underlying writer, for websocket upgrades.
+func (w *auditResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Connection can't be hijacked")
+	}
+
+	return hijacker.Hijack()
+}
@@ -0,0 +1,749 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/task"
+	"github.com/lxc/lxd/lxd/types"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/osarch"
+
+	log "github.com/lxc/lxd/shared/log15"
+)
+
+// backup represents a container backup.
+type backup struct {
+	state     *state.State
+	container container
+
+	// Properties
+	id               int
+	name             string
+	creationDate     time.Time
+	expiryDate       time.Time
+	containerOnly    bool
+	optimizedStorage bool
+}
+
+// backupInfo is the index of a backup tarball, stored as backup/index.yaml.
+// Next to it, the tarball holds the container in backup/container and its
+// snapshots in backup/snapshots/<name>, or, for optimized backups, the
+// storage driver's send streams in backup/container.bin and
+// backup/snapshots/<name>.bin.
+type backupInfo struct {
+	Name             string                   `yaml:"name"`
+	Backend          string                   `yaml:"backend"`
+	Pool             string                   `yaml:"pool"`
+	OptimizedStorage bool                     `yaml:"optimized_storage"`
+	Container        *api.Container           `yaml:"container"`
+	Snapshots        []*api.ContainerSnapshot `yaml:"snapshots"`
+}
+
+// backupLoadByName loads the given backup of the given container.
+func backupLoadByName(s *state.State, containerName string, name string) (*backup, error) {
+	c, err := containerLoadByName(s, containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := s.DB.ContainerGetBackup(containerName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backup{
+		state:            s,
+		container:        c,
+		id:               args.ID,
+		name:             name,
+		creationDate:     args.CreationDate,
+		expiryDate:       args.ExpiryDate,
+		containerOnly:    args.ContainerOnly,
+		optimizedStorage: args.OptimizedStorage,
+	}, nil
+}
+
+// backupCreate creates a new backup of the given container.
+func backupCreate(s *state.State, args db.ContainerBackupArgs, sourceContainer container) error {
+	err := s.DB.ContainerBackupCreate(args)
+	if err != nil {
+		return err
+	}
+
+	b, err := backupLoadByName(s, sourceContainer.Name(), args.Name)
+	if err != nil {
+		s.DB.ContainerBackupRemove(sourceContainer.Name(), args.Name)
+		return err
+	}
+
+	err = backupCreateTarball(b)
+	if err != nil {
+		s.DB.ContainerBackupRemove(sourceContainer.Name(), args.Name)
+		return err
+	}
+
+	return nil
+}
+
+// Name returns the name of the backup.
+func (b *backup) Name() string {
+	return b.name
+}
+
+// Path returns the path of the backup tarball.
+func (b *backup) Path() string {
+	return shared.VarPath("backups", b.container.Name(), b.name)
+}
+
+// Rename renames the backup.
+func (b *backup) Rename(newName string) error {
+	newPath := shared.VarPath("backups", b.container.Name(), newName)
+	if shared.PathExists(newPath) {
+		return fmt.Errorf("Backup '%s' already exists", newName)
+	}
+
+	err := os.Rename(b.Path(), newPath)
+	if err != nil {
+		return err
+	}
+
+	err = b.state.DB.ContainerBackupRename(b.container.Name(), b.name, newName)
+	if err != nil {
+		os.Rename(newPath, b.Path())
+		return err
+	}
+
+	b.name = newName
+	return nil
+}
+
+// Delete removes the backup tarball and its database entry.
+func (b *backup) Delete() error {
+	err := os.Remove(b.Path())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Remove the container's backup directory once it's empty
+	backupsPath := shared.VarPath("backups", b.container.Name())
+	empty, _ := shared.PathIsEmpty(backupsPath)
+	if empty {
+		os.Remove(backupsPath)
+	}
+
+	return b.state.DB.ContainerBackupRemove(b.container.Name(), b.name)
+}
+
+// Render returns the API representation of the backup.
+func (b *backup) Render() *api.ContainerBackup {
+	return &api.ContainerBackup{
+		Name:             b.name,
+		CreationDate:     b.creationDate,
+		ExpiresAt:        b.expiryDate,
+		ContainerOnly:    b.containerOnly,
+		OptimizedStorage: b.optimizedStorage,
+	}
+}
+
+// backupCreateTarball has the storage driver dump the container into a
+// staging directory and packs it, together with its index, into the
+// backup tarball.
+func backupCreateTarball(b *backup) error {
+	c := b.container
+
+	tmpPath, err := ioutil.TempDir(shared.VarPath("backups"), "lxd_backup_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpPath)
+
+	err = c.Storage().ContainerBackupCreate(*b, c, tmpPath)
+	if err != nil {
+		return err
+	}
+
+	// Write the index
+	poolName, err := c.StoragePool()
+	if err != nil {
+		return err
+	}
+
+	render, _, err := c.Render()
+	if err != nil {
+		return err
+	}
+
+	info := backupInfo{
+		Name:             projectStripPrefix(c.Project(), c.Name()),
+		Backend:          c.Storage().GetStorageTypeName(),
+		Pool:             poolName,
+		OptimizedStorage: b.optimizedStorage,
+		Container:        render.(*api.Container),
+		Snapshots:        []*api.ContainerSnapshot{},
+	}
+
+	if !b.containerOnly {
+		snapshots, err := c.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		for _, snap := range snapshots {
+			render, _, err := snap.Render()
+			if err != nil {
+				return err
+			}
+
+			info.Snapshots = append(info.Snapshots, render.(*api.ContainerSnapshot))
+		}
+	}
+
+	data, err := yaml.Marshal(&info)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filepath.Join(tmpPath, "index.yaml"), data, 0600)
+	if err != nil {
+		return err
+	}
+
+	// Create the tarball
+	err = os.MkdirAll(shared.VarPath("backups", c.Name()), 0700)
+	if err != nil {
+		return err
+	}
+
+	backupPath := b.Path()
+	success := false
+	defer func() {
+		if !success {
+			os.Remove(backupPath)
+		}
+	}()
+
+	output, err := shared.RunCommand("tar", "-cf", backupPath, "--numeric-owner", "--xattrs", "-C", tmpPath, "--transform", "s,^./,backup/,", ".")
+	if err != nil {
+		return fmt.Errorf("Failed to create the backup tarball: %s", strings.TrimSpace(output))
+	}
+
+	compress := daemonConfig["backups.compression_algorithm"].Get()
+	if compress != "none" {
+		compressedPath, err := compressFile(backupPath, compress)
+		if err != nil {
+			return err
+		}
+
+		err = os.Rename(compressedPath, backupPath)
+		if err != nil {
+			os.Remove(compressedPath)
+			return err
+		}
+	}
+
+	err = os.Chmod(backupPath, 0600)
+	if err != nil {
+		return err
+	}
+
+	success = true
+	return nil
+}
+
+// rsyncContainerBackupCreate dumps the container and, unless the backup is
+// container only, its snapshots into the given directory using rsync.
+func rsyncContainerBackupCreate(b backup, sourceContainer container, target string, bwlimit string) error {
+	if b.optimizedStorage {
+		return fmt.Errorf("Optimized backups aren't supported by the %s storage driver", sourceContainer.Storage().GetStorageTypeName())
+	}
+
+	rsync := func(c container, path string) error {
+		ourStart, err := c.StorageStart()
+		if err != nil {
+			return err
+		}
+		if ourStart {
+			defer c.StorageStop()
+		}
+
+		output, err := rsyncLocalCopy(c.Path(), path, bwlimit)
+		if err != nil {
+			return fmt.Errorf("Failed to rsync: %s: %s", output, err)
+		}
+
+		return nil
+	}
+
+	if !b.containerOnly {
+		snapshots, err := sourceContainer.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		for _, snap := range snapshots {
+			_, snapName, _ := containerGetParentAndSnapshotName(snap.Name())
+			err := rsync(snap, filepath.Join(target, "snapshots", snapName))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	containerPath := filepath.Join(target, "container")
+	err := rsync(sourceContainer, containerPath)
+	if err != nil {
+		return err
+	}
+
+	if !sourceContainer.IsRunning() {
+		return nil
+	}
+
+	// Copy the container a second time while frozen so the backup is
+	// consistent, the first pass did most of the work already.
+	err = sourceContainer.Freeze()
+	if err != nil {
+		logger.Warn("Unable to freeze container for backup", log.Ctx{"container": sourceContainer.Name(), "err": err})
+		return nil
+	}
+	defer sourceContainer.Unfreeze()
+
+	return rsync(sourceContainer, containerPath)
+}
+
+// backupGetInfo reads the index of the given backup tarball.
+func backupGetInfo(tarball string) (*backupInfo, error) {
+	extractArgs, extension, err := detectCompression(tarball)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(extension, ".tar") {
+		return nil, fmt.Errorf("Unsupported backup format: %s", extension)
+	}
+
+	args := append([]string{}, extractArgs...)
+	args = append(args, tarball, "--to-stdout", "backup/index.yaml")
+	output, err := shared.RunCommand("tar", args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the backup index: %s", strings.TrimSpace(output))
+	}
+
+	info := backupInfo{}
+	err = yaml.Unmarshal([]byte(output), &info)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Name == "" || info.Container == nil {
+		return nil, fmt.Errorf("Invalid backup index")
+	}
+
+	return &info, nil
+}
+
+// backupSnapshotName returns the snapshot part of the name of a snapshot
+// listed in the index of a backup of the given container.
+func backupSnapshotName(containerName string, name string) (string, error) {
+	parent, snapName, isSnap := containerGetParentAndSnapshotName(name)
+	if !isSnap || parent != containerName {
+		return "", fmt.Errorf("Snapshot \"%s\" doesn't belong to container \"%s\"", name, containerName)
+	}
+
+	if snapName == "" || snapName == "." || snapName == ".." || strings.Contains(snapName, shared.SnapshotDelimiter) || strings.Contains(snapName, "/") {
+		return "", fmt.Errorf("Invalid snapshot name \"%s\"", name)
+	}
+
+	return snapName, nil
+}
+
+// backupUnpack extracts the content of the given directory of a backup
+// tarball into target.
+func backupUnpack(tarball string, source string, target string) error {
+	extractArgs, _, err := detectCompression(tarball)
+	if err != nil {
+		return err
+	}
+
+	args := append([]string{}, extractArgs...)
+	args = append(args, tarball,
+		"--numeric-owner",
+		"--xattrs",
+		"--xattrs-include=*",
+		fmt.Sprintf("--strip-components=%d", strings.Count(source, "/")+1),
+		"-C", target,
+		source)
+	output, err := shared.RunCommand("tar", args...)
+	if err != nil {
+		return fmt.Errorf("Failed to unpack %s: %s", source, strings.TrimSpace(output))
+	}
+
+	return nil
+}
+
+// backupReceive pipes the given file of a backup tarball into the standard
+// input of the given command.
+func backupReceive(tarball string, source string, name string, arg ...string) error {
+	extractArgs, _, err := detectCompression(tarball)
+	if err != nil {
+		return err
+	}
+
+	args := append([]string{}, extractArgs...)
+	args = append(args, tarball, "--to-stdout", source)
+	tarCmd := exec.Command("tar", args...)
+	tarStderr := bytes.Buffer{}
+	tarCmd.Stderr = &tarStderr
+
+	stdout, err := tarCmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	recvCmd := exec.Command(name, arg...)
+	recvCmd.Stdin = stdout
+	recvOutput := bytes.Buffer{}
+	recvCmd.Stdout = &recvOutput
+	recvCmd.Stderr = &recvOutput
+
+	err = tarCmd.Start()
+	if err != nil {
+		return err
+	}
+
+	recvErr := recvCmd.Run()
+	tarErr := tarCmd.Wait()
+	if tarErr != nil {
+		return fmt.Errorf("Failed to read %s: %s", source, strings.TrimSpace(tarStderr.String()))
+	}
+
+	if recvErr != nil {
+		return fmt.Errorf("Failed to receive %s: %s", source, strings.TrimSpace(recvOutput.String()))
+	}
+
+	return nil
+}
+
+// backupSend writes the standard output of the given command to the given
+// file of the backup staging directory.
+func backupSend(path string, name string, arg ...string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cmd := exec.Command(name, arg...)
+	cmd.Stdout = f
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("Failed to run %s: %s", name, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// backupSetRootDiskPool makes sure the root disk device resulting from the
+// given devices and profiles uses the given storage pool.
+func backupSetRootDiskPool(s *state.State, devices types.Devices, profiles []string, poolName string) error {
+	key, _, _ := containerGetRootDiskDevice(devices)
+	if key != "" {
+		devices[key]["pool"] = poolName
+		return nil
+	}
+
+	profilePool := ""
+	for _, name := range profiles {
+		_, profile, err := s.DB.ProfileGet(name)
+		if err != nil {
+			return err
+		}
+
+		// Keep going as we want the last one in the profile chain
+		k, v, _ := containerGetRootDiskDevice(profile.Devices)
+		if k != "" {
+			profilePool = v["pool"]
+		}
+	}
+
+	if profilePool == poolName {
+		return nil
+	}
+
+	// Make sure that we do not overwrite a device the user is currently
+	// using under the name "root".
+	rootDevName := "root"
+	for i := 0; devices[rootDevName] != nil; i++ {
+		rootDevName = fmt.Sprintf("root%d", i)
+	}
+
+	devices[rootDevName] = types.Device{"type": "disk", "path": "/", "pool": poolName}
+	return nil
+}
+
+// containerCreateFromBackup restores the container held by the given backup
+// tarball into the given project. The container is put on the storage pool
+// it was backed up from if it exists, or on the storage pool of the default
+// profile otherwise.
+func containerCreateFromBackup(s *state.State, project string, info backupInfo, tarball string) (container, error) {
+	// The index can't be trusted, only accept snapshots of the container
+	// being restored.
+	snapNames := []string{}
+	for _, snap := range info.Snapshots {
+		snapName, err := backupSnapshotName(info.Name, snap.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		snapNames = append(snapNames, snapName)
+	}
+
+	info.Name = projectPrefix(project, info.Name)
+
+	profileScope, err := projectProfileScope(s.DB, project)
+	if err != nil {
+		return nil, err
+	}
+
+	poolName := info.Pool
+	_, err = s.DB.StoragePoolGetID(poolName)
+	if err != nil {
+		if err != db.NoSuchObjectError {
+			return nil, err
+		}
+
+		_, profile, err := s.DB.ProfileGet(projectPrefix(profileScope, "default"))
+		if err != nil {
+			return nil, err
+		}
+
+		_, rootDiskDevice, err := containerGetRootDiskDevice(profile.Devices)
+		if err != nil {
+			return nil, fmt.Errorf("The storage pool \"%s\" doesn't exist and the default profile has no root disk device", info.Pool)
+		}
+
+		poolName = rootDiskDevice["pool"]
+	}
+
+	pool, err := storagePoolInit(s, poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.OptimizedStorage && pool.GetStorageTypeName() != info.Backend {
+		return nil, fmt.Errorf("Optimized %s backups can't be restored onto the %s storage pool \"%s\"", info.Backend, pool.GetStorageTypeName(), poolName)
+	}
+
+	// Prepare the database entries
+	containerArgs := func(ctype db.ContainerType, name string, architecture string, config map[string]string, devices types.Devices, profiles []string) (db.ContainerArgs, error) {
+		arch, err := osarch.ArchitectureId(architecture)
+		if err != nil {
+			return db.ContainerArgs{}, err
+		}
+
+		if devices == nil {
+			devices = types.Devices{}
+		}
+
+		profiles, err = projectProfileNames(s.DB, project, profiles)
+		if err != nil {
+			return db.ContainerArgs{}, err
+		}
+
+		err = backupSetRootDiskPool(s, devices, profiles, poolName)
+		if err != nil {
+			return db.ContainerArgs{}, err
+		}
+
+		return db.ContainerArgs{
+			Architecture: arch,
+			BaseImage:    config["volatile.base_image"],
+			Config:       config,
+			Ctype:        ctype,
+			Devices:      devices,
+			Name:         name,
+			Profiles:     profiles,
+		}, nil
+	}
+
+	args, err := containerArgs(db.CTypeRegular, info.Name, info.Container.Architecture, info.Container.Config, info.Container.Devices, info.Container.Profiles)
+	if err != nil {
+		return nil, err
+	}
+	args.Ephemeral = info.Container.Ephemeral
+	args.Stateful = info.Container.Stateful
+
+	c, err := containerCreateInternal(s, args)
+	if err != nil {
+		return nil, err
+	}
+
+	success := false
+	defer func() {
+		if !success {
+			c.Delete()
+		}
+	}()
+
+	snapshotArgs := []db.ContainerArgs{}
+	for i, snap := range info.Snapshots {
+		name := info.Name + shared.SnapshotDelimiter + snapNames[i]
+		args, err := containerArgs(db.CTypeSnapshot, name, snap.Architecture, snap.Config, snap.Devices, snap.Profiles)
+		if err != nil {
+			return nil, err
+		}
+		args.Ephemeral = snap.Ephemeral
+		args.ExpiryDate = snap.ExpiresAt
+
+		snapshotArgs = append(snapshotArgs, args)
+	}
+
+	if info.OptimizedStorage {
+		// The storage driver receives the container and its
+		// snapshots in one go, so create all the entries first.
+		for i, args := range snapshotArgs {
+			args.Stateful = info.Snapshots[i].Stateful
+			_, err := containerCreateInternal(s, args)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = c.Storage().ContainerBackupLoad(info, tarball)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err = c.Storage().ContainerCreate(c)
+		if err != nil {
+			return nil, err
+		}
+
+		ourStart, err := c.StorageStart()
+		if err != nil {
+			return nil, err
+		}
+		if ourStart {
+			defer c.StorageStop()
+		}
+
+		// Restore every snapshot into the container and snapshot it,
+		// then restore the container itself.
+		for i, args := range snapshotArgs {
+			_, snapName, _ := containerGetParentAndSnapshotName(args.Name)
+			err := backupRestorePath(tarball, fmt.Sprintf("backup/snapshots/%s", snapName), c.Path())
+			if err != nil {
+				return nil, err
+			}
+
+			// Stateful snapshots can only be taken of running
+			// containers, the state is part of the restored data.
+			sc, err := containerCreateAsSnapshot(s, args, c)
+			if err != nil {
+				return nil, err
+			}
+
+			if info.Snapshots[i].Stateful {
+				err = s.DB.ContainerSetStateful(sc.Id(), true)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		err = backupRestorePath(tarball, "backup/container", c.Path())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = containerConfigureInternal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	success = true
+	return c, nil
+}
+
+// backupRestorePath replaces the content of target with the given directory
+// of a backup tarball.
+func backupRestorePath(tarball string, source string, target string) error {
+	entries, err := ioutil.ReadDir(target)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err := os.RemoveAll(filepath.Join(target, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return backupUnpack(tarball, source, target)
+}
+
+func pruneExpiredContainerBackupsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		pruneExpiredContainerBackups(ctx, d)
+	}
+
+	return f, task.Every(time.Hour)
+}
+
+func pruneExpiredContainerBackups(ctx context.Context, d *Daemon) {
+	s := d.State()
+
+	backups, err := s.DB.ContainerGetExpiredBackups(time.Now())
+	if err != nil {
+		logger.Error("Unable to retrieve the list of expired backups", log.Ctx{"err": err})
+		return
+	}
+
+	for _, name := range backups {
+		// It is safe to abort here since anything not deleted now
+		// will still be expired at the next run.
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		containerName, backupName, _ := containerGetParentAndSnapshotName(name)
+		b, err := backupLoadByName(s, containerName, backupName)
+		if err != nil {
+			logger.Error("Error loading expired backup", log.Ctx{"err": err, "backup": name})
+			continue
+		}
+
+		logger.Info("Deleting expired backup", log.Ctx{"backup": name})
+		err = b.Delete()
+		if err != nil {
+			logger.Error("Error deleting expired backup", log.Ctx{"err": err, "backup": name})
+			continue
+		}
+	}
+}
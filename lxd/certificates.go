@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/subtle"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
@@ -8,6 +9,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -33,6 +35,8 @@ func certificatesGet(d *Daemon, r *http.Request) Response {
 			resp := api.Certificate{}
 			resp.Fingerprint = baseCert.Fingerprint
 			resp.Certificate = baseCert.Certificate
+			resp.Restricted = baseCert.Restricted
+			resp.Projects = baseCert.Projects
 			if baseCert.Type == 1 {
 				resp.Type = "client"
 			} else {
@@ -44,7 +48,8 @@ func certificatesGet(d *Daemon, r *http.Request) Response {
 	}
 
 	body := []string{}
-	for _, cert := range d.clientCerts {
+	clientCerts, _ := d.clientCertificates()
+	for _, cert := range clientCerts {
 		fingerprint := fmt.Sprintf("/%s/certificates/%s", version.APIVersion, shared.CertFingerprint(&cert))
 		body = append(body, fingerprint)
 	}
@@ -53,7 +58,16 @@ func certificatesGet(d *Daemon, r *http.Request) Response {
 }
 
 func readSavedClientCAList(d *Daemon) {
-	d.clientCerts = []x509.Certificate{}
+	clientCerts := []x509.Certificate{}
+	clientCertsRestricted := map[string][]string{}
+
+	// Requests keep using the previous lists until the new ones are ready
+	defer func() {
+		d.clientCertsLock.Lock()
+		d.clientCerts = clientCerts
+		d.clientCertsRestricted = clientCertsRestricted
+		d.clientCertsLock.Unlock()
+	}()
 
 	dbCerts, err := d.db.CertificatesGet()
 	if err != nil {
@@ -73,15 +87,21 @@ func readSavedClientCAList(d *Daemon) {
 			logger.Infof("Error reading certificate for %s: %s", dbCert.Name, err)
 			continue
 		}
-		d.clientCerts = append(d.clientCerts, *cert)
+		clientCerts = append(clientCerts, *cert)
+
+		if dbCert.Restricted {
+			clientCertsRestricted[dbCert.Fingerprint] = dbCert.Projects
+		}
 	}
 }
 
-func saveCert(dbObj *db.Node, host string, cert *x509.Certificate) error {
+func saveCert(dbObj *db.Node, host string, cert *x509.Certificate, restricted bool, projects []string) error {
 	baseCert := new(db.CertInfo)
 	baseCert.Fingerprint = shared.CertFingerprint(cert)
 	baseCert.Type = 1
 	baseCert.Name = host
+	baseCert.Restricted = restricted
+	baseCert.Projects = projects
 	baseCert.Certificate = string(
 		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
 	)
@@ -96,16 +116,45 @@ func certificatesPost(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
+	trusted := d.checkTrustedClient(r) == nil
+
+	// Issue a join token
+	if shared.IsTrue(r.FormValue("token")) {
+		if !trusted {
+			return Forbidden
+		}
+
+		return certificateTokenCreate(d, req)
+	}
+
 	// Access check
-	secret := daemonConfig["core.trust_password"].Get()
-	if d.checkTrustedClient(r) != nil && util.PasswordCheck(secret, req.Password) != nil {
-		return Forbidden
+	tokenName := ""
+	if !trusted && req.TrustToken != "" {
+		op := certificateTokenRedeem(req.TrustToken)
+		if op == nil {
+			return Forbidden
+		}
+
+		// The token dictates what the client gets added as
+		tokenName, _ = op.metadata["client_name"].(string)
+		req.Restricted, _ = op.metadata["restricted"].(bool)
+		req.Projects, _ = op.metadata["projects"].([]string)
+	} else if !trusted {
+		secret := daemonConfig["core.trust_password"].Get()
+		if util.PasswordCheck(secret, req.Password) != nil {
+			return Forbidden
+		}
 	}
 
 	if req.Type != "client" {
 		return BadRequest(fmt.Errorf("Unknown request type %s", req.Type))
 	}
 
+	err := certificateValidateProjects(d, req.CertificatePut)
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	// Extract the certificate
 	var cert *x509.Certificate
 	var name string
@@ -136,23 +185,144 @@ func certificatesPost(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("Can't use TLS data on non-TLS link"))
 	}
 
+	if tokenName != "" {
+		name = tokenName
+	}
+
 	fingerprint := shared.CertFingerprint(cert)
-	for _, existingCert := range d.clientCerts {
+	clientCerts, _ := d.clientCertificates()
+	for _, existingCert := range clientCerts {
 		if fingerprint == shared.CertFingerprint(&existingCert) {
 			return BadRequest(fmt.Errorf("Certificate already in trust store"))
 		}
 	}
 
-	err := saveCert(d.db, name, cert)
+	err = saveCert(d.db, name, cert, req.Restricted, req.Projects)
 	if err != nil {
 		return SmartError(err)
 	}
 
-	d.clientCerts = append(d.clientCerts, *cert)
+	readSavedClientCAList(d)
 
 	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/certificates/%s", version.APIVersion, fingerprint))
 }
 
+// certificateTokenCreate issues a single-use join token, letting the client
+// holding it add itself to the trust store as described by the request.
+func certificateTokenCreate(d *Daemon, req api.CertificatesPost) Response {
+	if req.Name == "" {
+		return BadRequest(fmt.Errorf("A client name must be provided for the token"))
+	}
+
+	if req.Type == "" {
+		req.Type = "client"
+	}
+
+	if req.Type != "client" {
+		return BadRequest(fmt.Errorf("Unknown request type %s", req.Type))
+	}
+
+	err := certificateValidateProjects(d, req.CertificatePut)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	addresses, err := util.ListenAddresses(daemonConfig["core.https_address"].Get())
+	if err != nil {
+		return InternalError(err)
+	}
+
+	if len(addresses) == 0 {
+		return BadRequest(fmt.Errorf("Can't issue a token while the server isn't listening on the network"))
+	}
+
+	fingerprint, err := shared.CertFingerprintStr(string(d.endpoints.NetworkPublicKey()))
+	if err != nil {
+		return InternalError(err)
+	}
+
+	secret, err := shared.RandomCryptoString()
+	if err != nil {
+		return InternalError(err)
+	}
+
+	meta := shared.Jmap{}
+	meta["client_name"] = req.Name
+	meta["fingerprint"] = fingerprint
+	meta["addresses"] = addresses
+	meta["secret"] = secret
+	meta["restricted"] = req.Restricted
+	meta["projects"] = req.Projects
+
+	expiry := daemonConfig["core.remote_token_expiry"].GetInt64()
+	if expiry > 0 {
+		meta["expires_at"] = time.Now().Add(time.Duration(expiry) * time.Hour)
+	}
+
+	op, err := operationCreate("", operationClassToken, nil, meta, nil, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	if expiry > 0 {
+		time.AfterFunc(time.Duration(expiry)*time.Hour, func() {
+			op.Cancel()
+		})
+	}
+
+	return OperationResponse(op)
+}
+
+// certificateTokenRedeem returns the join token operation holding the given
+// secret, if any. Tokens being single-use, the operation gets cancelled.
+func certificateTokenRedeem(secret string) *operation {
+	var match *operation
+
+	operationsLock.Lock()
+	for _, op := range operations {
+		if op.class != operationClassToken {
+			continue
+		}
+
+		op.lock.Lock()
+		if op.status != api.Running {
+			op.lock.Unlock()
+			continue
+		}
+
+		_, ok := op.metadata["client_name"]
+		if !ok {
+			op.lock.Unlock()
+			continue
+		}
+
+		expiresAt, ok := op.metadata["expires_at"].(time.Time)
+		if ok && time.Now().After(expiresAt) {
+			op.lock.Unlock()
+			continue
+		}
+
+		// Claim the token, so concurrent requests can't use it too
+		opSecret, ok := op.metadata["secret"].(string)
+		if ok && subtle.ConstantTimeCompare([]byte(opSecret), []byte(secret)) == 1 {
+			delete(op.metadata, "secret")
+			match = op
+		}
+		op.lock.Unlock()
+
+		if match != nil {
+			break
+		}
+	}
+	operationsLock.Unlock()
+
+	if match != nil {
+		match.Cancel()
+	}
+
+	return match
+}
+
 var certificatesCmd = Command{name: "certificates", untrustedPost: true, get: certificatesGet, post: certificatesPost}
 
 func certificateFingerprintGet(d *Daemon, r *http.Request) Response {
@@ -177,6 +347,8 @@ func doCertificateGet(db *db.Node, fingerprint string) (api.Certificate, error)
 	resp.Fingerprint = dbCertInfo.Fingerprint
 	resp.Certificate = dbCertInfo.Certificate
 	resp.Name = dbCertInfo.Name
+	resp.Restricted = dbCertInfo.Restricted
+	resp.Projects = dbCertInfo.Projects
 	if dbCertInfo.Type == 1 {
 		resp.Type = "client"
 	} else {
@@ -240,6 +412,26 @@ func certificateFingerprintPatch(d *Daemon, r *http.Request) Response {
 		req.Type = value
 	}
 
+	// Get restricted
+	restricted, err := reqRaw.GetBool("restricted")
+	if err == nil {
+		req.Restricted = restricted
+	}
+
+	// Get projects
+	projects, ok := reqRaw["projects"].([]interface{})
+	if ok {
+		req.Projects = []string{}
+		for _, entry := range projects {
+			project, ok := entry.(string)
+			if !ok {
+				return BadRequest(fmt.Errorf("Invalid project name: %v", entry))
+			}
+
+			req.Projects = append(req.Projects, project)
+		}
+	}
+
 	return doCertificateUpdate(d, fingerprint, req.Writable())
 }
 
@@ -248,14 +440,37 @@ func doCertificateUpdate(d *Daemon, fingerprint string, req api.CertificatePut)
 		return BadRequest(fmt.Errorf("Unknown request type %s", req.Type))
 	}
 
-	err := d.db.CertUpdate(fingerprint, req.Name, 1)
+	err := certificateValidateProjects(d, req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	err = d.db.CertUpdate(fingerprint, req.Name, 1, req.Restricted, req.Projects)
 	if err != nil {
 		return SmartError(err)
 	}
+	readSavedClientCAList(d)
 
 	return EmptySyncResponse
 }
 
+// certificateValidateProjects checks that the projects a restricted
+// certificate is given access to exist.
+func certificateValidateProjects(d *Daemon, req api.CertificatePut) error {
+	if !req.Restricted && len(req.Projects) > 0 {
+		return fmt.Errorf("Projects can only be set on restricted certificates")
+	}
+
+	for _, project := range req.Projects {
+		_, _, err := d.db.ProjectGet(project)
+		if err != nil {
+			return fmt.Errorf("Project '%s' doesn't exist", project)
+		}
+	}
+
+	return nil
+}
+
 func certificateFingerprintDelete(d *Daemon, r *http.Request) Response {
 	fingerprint := mux.Vars(r)["fingerprint"]
 
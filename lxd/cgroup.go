@@ -1,50 +1,32 @@
 package main
 
 import (
-	"bufio"
 	"io/ioutil"
 	"os"
 	"path"
 	"strings"
+
+	"github.com/lxc/lxd/lxd/cgroup"
 )
 
-func getInitCgroupPath(controller string) string {
-	f, err := os.Open("/proc/1/cgroup")
+func getInitCgroupPath(layout cgroup.Layout, controller string) string {
+	initPath, err := cgroup.ProcessPath(layout, 1, controller)
 	if err != nil {
 		return "/"
 	}
-	defer f.Close()
-
-	scan := bufio.NewScanner(f)
-	for scan.Scan() {
-		line := scan.Text()
-
-		fields := strings.Split(line, ":")
-		if len(fields) != 3 {
-			return "/"
-		}
-
-		if fields[2] != controller {
-			continue
-		}
-
-		initPath := string(fields[3])
-
-		// ignore trailing /init.scope if it is there
-		dir, file := path.Split(initPath)
-		if file == "init.scope" {
-			return dir
-		} else {
-			return initPath
-		}
+
+	// ignore trailing /init.scope if it is there
+	dir, file := path.Split(initPath)
+	if file == "init.scope" {
+		return dir
 	}
 
-	return "/"
+	return initPath
 }
 
-func cGroupGet(controller, cgroup, file string) (string, error) {
-	initPath := getInitCgroupPath(controller)
-	path := path.Join("/sys/fs/cgroup", controller, initPath, cgroup, file)
+func cGroupGet(layout cgroup.Layout, controller, group, file string) (string, error) {
+	initPath := getInitCgroupPath(layout, controller)
+	path := cgroup.HostPath(layout, controller, path.Join(initPath, group), file)
 
 	contents, err := ioutil.ReadFile(path)
 	if err != nil {
@@ -53,9 +35,16 @@ func cGroupGet(controller, cgroup, file string) (string, error) {
 	return strings.Trim(string(contents), "\n"), nil
 }
 
-func cGroupSet(controller, cgroup, file string, value string) error {
-	initPath := getInitCgroupPath(controller)
-	path := path.Join("/sys/fs/cgroup", controller, initPath, cgroup, file)
+func cGroupSet(layout cgroup.Layout, controller, group, file string, value string) error {
+	initPath := getInitCgroupPath(layout, controller)
+	path := cgroup.HostPath(layout, controller, path.Join(initPath, group), file)
 
 	return ioutil.WriteFile(path, []byte(value), 0755)
 }
+
+// cGroupExists returns whether the given cgroup exists on the host.
+func cGroupExists(layout cgroup.Layout, controller, group string) bool {
+	initPath := getInitCgroupPath(layout, controller)
+	_, err := os.Stat(cgroup.HostPath(layout, controller, path.Join(initPath, group), ""))
+	return err == nil
+}
@@ -0,0 +1,491 @@
+// Package cgroup maps the container limits and resource readings of LXD onto
+// the files of the cgroup layout in use on the host, either the legacy one
+// (cgroup v1, one hierarchy per controller) or the unified one (cgroup v2).
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Layout is the cgroup layout used by the host.
+type Layout int
+
+const (
+	// Legacy is the cgroup v1 layout, also used for hybrid setups where the
+	// controllers are all attached to v1 hierarchies.
+	Legacy Layout = iota
+
+	// Unified is the cgroup v2 layout, with all controllers in one hierarchy.
+	Unified
+)
+
+func (l Layout) String() string {
+	if l == Unified {
+		return "unified"
+	}
+
+	return "legacy"
+}
+
+// ErrUnsupported is returned for settings and readings which have no
+// equivalent in the cgroup layout of the host.
+var ErrUnsupported = errors.New("Not supported by the cgroup layout of the host")
+
+// ReadWriter reads and writes the cgroup files of a container, named
+// relative to its cgroup (e.g. "memory.max").
+type ReadWriter interface {
+	Get(key string) (string, error)
+	Set(key string, value string) error
+}
+
+// CGroup gives access to the limits and resource usage of a container.
+type CGroup struct {
+	rw     ReadWriter
+	layout Layout
+}
+
+// New returns a CGroup using the given ReadWriter to access the files of the
+// given layout.
+func New(rw ReadWriter, layout Layout) *CGroup {
+	return &CGroup{rw: rw, layout: layout}
+}
+
+// Layout returns the cgroup layout used by this CGroup.
+func (cg *CGroup) Layout() Layout {
+	return cg.layout
+}
+
+// limitValue formats a limit, with -1 meaning no limit.
+func (cg *CGroup) limitValue(limit int64) string {
+	if limit < 0 {
+		if cg.layout == Unified {
+			return "max"
+		}
+
+		return "-1"
+	}
+
+	return fmt.Sprintf("%d", limit)
+}
+
+// SetMemoryLimit sets the hard memory limit in bytes.
+func (cg *CGroup) SetMemoryLimit(limit int64) error {
+	if cg.layout == Unified {
+		return cg.rw.Set("memory.max", cg.limitValue(limit))
+	}
+
+	return cg.rw.Set("memory.limit_in_bytes", cg.limitValue(limit))
+}
+
+// SetMemorySoftLimit sets the amount of memory in bytes above which the
+// container is reclaimed from first when the host is under memory pressure.
+func (cg *CGroup) SetMemorySoftLimit(limit int64) error {
+	if cg.layout == Unified {
+		// memory.low has no "max" value, an unlimited soft limit is 0.
+		if limit < 0 {
+			limit = 0
+		}
+
+		return cg.rw.Set("memory.low", fmt.Sprintf("%d", limit))
+	}
+
+	return cg.rw.Set("memory.soft_limit_in_bytes", cg.limitValue(limit))
+}
+
+// SetMemorySwapLimit limits the use of swap. With the legacy layout, the
+// limit applies to memory and swap combined, with the unified one to swap
+// alone.
+func (cg *CGroup) SetMemorySwapLimit(limit int64) error {
+	if cg.layout == Unified {
+		return cg.rw.Set("memory.swap.max", cg.limitValue(limit))
+	}
+
+	return cg.rw.Set("memory.memsw.limit_in_bytes", cg.limitValue(limit))
+}
+
+// SetMemorySwappiness sets how eagerly the memory of the container is
+// swapped out. The unified layout has no per-cgroup swappiness.
+func (cg *CGroup) SetMemorySwappiness(swappiness int64) error {
+	if cg.layout == Unified {
+		return ErrUnsupported
+	}
+
+	return cg.rw.Set("memory.swappiness", fmt.Sprintf("%d", swappiness))
+}
+
+// SetCPUShares sets the relative CPU weight, using the legacy cpu.shares
+// scale (2 to 262144, 1024 by default).
+func (cg *CGroup) SetCPUShares(shares int64) error {
+	if cg.layout == Unified {
+		return cg.rw.Set("cpu.weight", fmt.Sprintf("%d", CPUWeight(shares)))
+	}
+
+	return cg.rw.Set("cpu.shares", fmt.Sprintf("%d", shares))
+}
+
+// SetCPUCfsLimit sets the CPU time the container may use over each period,
+// both in microseconds. A quota of -1 means no limit.
+func (cg *CGroup) SetCPUCfsLimit(period int64, quota int64) error {
+	if cg.layout == Unified {
+		return cg.rw.Set("cpu.max", fmt.Sprintf("%s %d", cg.limitValue(quota), period))
+	}
+
+	err := cg.rw.Set("cpu.cfs_period_us", fmt.Sprintf("%d", period))
+	if err != nil {
+		return err
+	}
+
+	return cg.rw.Set("cpu.cfs_quota_us", fmt.Sprintf("%d", quota))
+}
+
+// SetBlkioWeight sets the relative I/O weight, using the legacy blkio.weight
+// scale (10 to 1000, 500 by default).
+func (cg *CGroup) SetBlkioWeight(weight int64) error {
+	if cg.layout == Unified {
+		return cg.rw.Set("io.weight", fmt.Sprintf("default %d", IOWeight(weight)))
+	}
+
+	return cg.rw.Set("blkio.weight", fmt.Sprintf("%d", weight))
+}
+
+// SetBlkioLimit sets the I/O limits of the given block device ("major:minor"),
+// 0 meaning no limit. With the legacy layout, zero limits are only cleared if
+// clear is true.
+func (cg *CGroup) SetBlkioLimit(device string, readBps int64, readIops int64, writeBps int64, writeIops int64, clear bool) error {
+	if cg.layout == Unified {
+		value := func(limit int64) string {
+			if limit <= 0 {
+				return "max"
+			}
+
+			return fmt.Sprintf("%d", limit)
+		}
+
+		return cg.rw.Set("io.max", fmt.Sprintf("%s rbps=%s riops=%s wbps=%s wiops=%s",
+			device, value(readBps), value(readIops), value(writeBps), value(writeIops)))
+	}
+
+	limits := []struct {
+		file  string
+		value int64
+	}{
+		{"blkio.throttle.read_bps_device", readBps},
+		{"blkio.throttle.read_iops_device", readIops},
+		{"blkio.throttle.write_bps_device", writeBps},
+		{"blkio.throttle.write_iops_device", writeIops},
+	}
+
+	for _, limit := range limits {
+		if limit.value <= 0 && !clear {
+			continue
+		}
+
+		err := cg.rw.Set(limit.file, fmt.Sprintf("%s %d", device, limit.value))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetMaxProcesses sets the maximum number of processes, -1 meaning no limit.
+func (cg *CGroup) SetMaxProcesses(limit int64) error {
+	if limit < 0 {
+		return cg.rw.Set("pids.max", "max")
+	}
+
+	return cg.rw.Set("pids.max", fmt.Sprintf("%d", limit))
+}
+
+// SetNetIfPrio sets the priority of the traffic sent on the given host
+// interface. The unified layout has no network controller.
+func (cg *CGroup) SetNetIfPrio(iface string, priority int64) error {
+	if cg.layout == Unified {
+		return ErrUnsupported
+	}
+
+	return cg.rw.Set("net_prio.ifpriomap", fmt.Sprintf("%s %d", iface, priority))
+}
+
+// GetMemoryLimit returns the hard memory limit in bytes, -1 if unlimited.
+func (cg *CGroup) GetMemoryLimit() (int64, error) {
+	if cg.layout == Unified {
+		return cg.getLimit("memory.max")
+	}
+
+	return cg.getLimit("memory.limit_in_bytes")
+}
+
+// GetMemorySoftLimit returns the soft memory limit in bytes.
+func (cg *CGroup) GetMemorySoftLimit() (int64, error) {
+	if cg.layout == Unified {
+		return cg.getLimit("memory.low")
+	}
+
+	return cg.getLimit("memory.soft_limit_in_bytes")
+}
+
+// GetMemorySwapLimit returns the swap limit in bytes, -1 if unlimited. See
+// SetMemorySwapLimit for the meaning of the value.
+func (cg *CGroup) GetMemorySwapLimit() (int64, error) {
+	if cg.layout == Unified {
+		return cg.getLimit("memory.swap.max")
+	}
+
+	return cg.getLimit("memory.memsw.limit_in_bytes")
+}
+
+// GetMemoryUsage returns the current memory usage in bytes.
+func (cg *CGroup) GetMemoryUsage() (int64, error) {
+	if cg.layout == Unified {
+		return cg.getInt("memory.current")
+	}
+
+	return cg.getInt("memory.usage_in_bytes")
+}
+
+// GetMemoryMaxUsage returns the peak memory usage in bytes. The unified
+// layout only provides it with recent kernels.
+func (cg *CGroup) GetMemoryMaxUsage() (int64, error) {
+	if cg.layout == Unified {
+		return cg.getOptionalInt("memory.peak")
+	}
+
+	return cg.getInt("memory.max_usage_in_bytes")
+}
+
+// GetMemorySwapUsage returns the current swap usage in bytes.
+func (cg *CGroup) GetMemorySwapUsage() (int64, error) {
+	if cg.layout == Unified {
+		return cg.getInt("memory.swap.current")
+	}
+
+	return cg.getMemswDelta("memory.memsw.usage_in_bytes", "memory.usage_in_bytes")
+}
+
+// GetMemorySwapMaxUsage returns the peak swap usage in bytes. The unified
+// layout only provides it with recent kernels.
+func (cg *CGroup) GetMemorySwapMaxUsage() (int64, error) {
+	if cg.layout == Unified {
+		return cg.getOptionalInt("memory.swap.peak")
+	}
+
+	return cg.getMemswDelta("memory.memsw.max_usage_in_bytes", "memory.max_usage_in_bytes")
+}
+
+// GetCPUAcctUsage returns the CPU time consumed in nanoseconds.
+func (cg *CGroup) GetCPUAcctUsage() (int64, error) {
+	if cg.layout == Unified {
+		stats, err := cg.getStats("cpu.stat")
+		if err != nil {
+			return -1, err
+		}
+
+		usage, ok := stats["usage_usec"]
+		if !ok {
+			return -1, fmt.Errorf("No usage_usec in cpu.stat")
+		}
+
+		return usage * 1000, nil
+	}
+
+	return cg.getInt("cpuacct.usage")
+}
+
+// GetProcessesUsage returns the number of processes.
+func (cg *CGroup) GetProcessesUsage() (int64, error) {
+	return cg.getInt("pids.current")
+}
+
+// GetIOStats returns the bytes read and written by the container, per block
+// device ("major:minor").
+func (cg *CGroup) GetIOStats() (map[string][2]int64, error) {
+	if cg.layout == Unified {
+		value, err := cg.rw.Get("io.stat")
+		if err != nil {
+			return nil, err
+		}
+
+		return ParseIOStat(value), nil
+	}
+
+	value, err := cg.rw.Get("blkio.throttle.io_service_bytes")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBlkioServiceBytes(value), nil
+}
+
+func (cg *CGroup) getInt(key string) (int64, error) {
+	value, err := cg.rw.Get(key)
+	if err != nil {
+		return -1, err
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+}
+
+// getLimit reads a limit, with "max" meaning no limit.
+func (cg *CGroup) getLimit(key string) (int64, error) {
+	value, err := cg.rw.Get(key)
+	if err != nil {
+		return -1, err
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "max" {
+		return -1, nil
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// getOptionalInt reads a file which may not exist, reporting it as
+// unsupported if so.
+func (cg *CGroup) getOptionalInt(key string) (int64, error) {
+	value, err := cg.getInt(key)
+	if err != nil {
+		return -1, ErrUnsupported
+	}
+
+	return value, nil
+}
+
+// getMemswDelta returns the swap part of a legacy memory+swap reading.
+func (cg *CGroup) getMemswDelta(memswKey string, memoryKey string) (int64, error) {
+	memsw, err := cg.getInt(memswKey)
+	if err != nil {
+		return -1, err
+	}
+
+	memory, err := cg.getInt(memoryKey)
+	if err != nil {
+		return -1, err
+	}
+
+	return memsw - memory, nil
+}
+
+// getStats reads a flat keyed file such as cpu.stat.
+func (cg *CGroup) getStats(key string) (map[string]int64, error) {
+	value, err := cg.rw.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[string]int64{}
+	for _, line := range strings.Split(value, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		valueInt, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		stats[fields[0]] = valueInt
+	}
+
+	return stats, nil
+}
+
+// CPUWeight converts a legacy cpu.shares value to the cpu.weight scale
+// (1 to 10000, 100 by default).
+func CPUWeight(shares int64) int64 {
+	if shares < 2 {
+		shares = 2
+	} else if shares > 262144 {
+		shares = 262144
+	}
+
+	return 1 + ((shares-2)*9999)/262142
+}
+
+// IOWeight converts a legacy blkio.weight value to the io.weight scale
+// (1 to 10000, 100 by default).
+func IOWeight(weight int64) int64 {
+	if weight < 10 {
+		weight = 10
+	} else if weight > 1000 {
+		weight = 1000
+	}
+
+	return 1 + ((weight-10)*9999)/990
+}
+
+// ParseBlkioServiceBytes parses the content of a legacy
+// blkio.throttle.io_service_bytes file into a map of device to read and
+// written bytes.
+func ParseBlkioServiceBytes(content string) map[string][2]int64 {
+	result := map[string][2]int64{}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+
+		value, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		counters := result[fields[0]]
+		switch fields[1] {
+		case "Read":
+			counters[0] = value
+		case "Write":
+			counters[1] = value
+		default:
+			continue
+		}
+
+		result[fields[0]] = counters
+	}
+
+	return result
+}
+
+// ParseIOStat parses the content of an io.stat file into a map of device to
+// read and written bytes.
+func ParseIOStat(content string) map[string][2]int64 {
+	result := map[string][2]int64{}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		counters := [2]int64{}
+		for _, field := range fields[1:] {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			value, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				continue
+			}
+
+			switch parts[0] {
+			case "rbytes":
+				counters[0] = value
+			case "wbytes":
+				counters[1] = value
+			}
+		}
+
+		result[fields[0]] = counters
+	}
+
+	return result
+}
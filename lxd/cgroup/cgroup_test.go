@@ -0,0 +1,116 @@
+package cgroup_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/cgroup"
+)
+
+// fakeReadWriter keeps the cgroup files in memory.
+type fakeReadWriter map[string]string
+
+func (rw fakeReadWriter) Get(key string) (string, error) {
+	value, ok := rw[key]
+	if !ok {
+		return "", fmt.Errorf("No such file %s", key)
+	}
+
+	return value, nil
+}
+
+func (rw fakeReadWriter) Set(key string, value string) error {
+	rw[key] = value
+	return nil
+}
+
+func TestCGroup_Legacy(t *testing.T) {
+	rw := fakeReadWriter{}
+	cg := cgroup.New(rw, cgroup.Legacy)
+
+	require.NoError(t, cg.SetMemoryLimit(1024))
+	require.NoError(t, cg.SetMemorySwapLimit(-1))
+	require.NoError(t, cg.SetCPUShares(512))
+	require.NoError(t, cg.SetCPUCfsLimit(100000, 50000))
+	require.NoError(t, cg.SetBlkioLimit("8:0", 100, 0, 0, 0, false))
+	require.NoError(t, cg.SetMemorySwappiness(0))
+
+	assert.Equal(t, fakeReadWriter{
+		"memory.limit_in_bytes":          "1024",
+		"memory.memsw.limit_in_bytes":    "-1",
+		"cpu.shares":                     "512",
+		"cpu.cfs_period_us":              "100000",
+		"cpu.cfs_quota_us":               "50000",
+		"blkio.throttle.read_bps_device": "8:0 100",
+		"memory.swappiness":              "0",
+	}, rw)
+
+	rw["memory.memsw.usage_in_bytes"] = "3000"
+	rw["memory.usage_in_bytes"] = "2000"
+	swap, err := cg.GetMemorySwapUsage()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), swap)
+}
+
+func TestCGroup_Unified(t *testing.T) {
+	rw := fakeReadWriter{}
+	cg := cgroup.New(rw, cgroup.Unified)
+
+	require.NoError(t, cg.SetMemoryLimit(1024))
+	require.NoError(t, cg.SetMemorySoftLimit(-1))
+	require.NoError(t, cg.SetMemorySwapLimit(-1))
+	require.NoError(t, cg.SetCPUShares(1024))
+	require.NoError(t, cg.SetCPUCfsLimit(100000, -1))
+	require.NoError(t, cg.SetBlkioWeight(500))
+	require.NoError(t, cg.SetBlkioLimit("8:0", 100, 0, 0, 20, false))
+	require.NoError(t, cg.SetMaxProcesses(-1))
+
+	assert.Equal(t, fakeReadWriter{
+		"memory.max":      "1024",
+		"memory.low":      "0",
+		"memory.swap.max": "max",
+		"cpu.weight":      "39",
+		"cpu.max":         "max 100000",
+		"io.weight":       "default 4950",
+		"io.max":          "8:0 rbps=100 riops=max wbps=max wiops=20",
+		"pids.max":        "max",
+	}, rw)
+
+	assert.Equal(t, cgroup.ErrUnsupported, cg.SetMemorySwappiness(0))
+	assert.Equal(t, cgroup.ErrUnsupported, cg.SetNetIfPrio("eth0", 1))
+
+	limit, err := cg.GetMemorySwapLimit()
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), limit)
+
+	rw["cpu.stat"] = "usage_usec 1500\nuser_usec 1000\nsystem_usec 500"
+	usage, err := cg.GetCPUAcctUsage()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1500000), usage)
+
+	_, err = cg.GetMemoryMaxUsage()
+	assert.Equal(t, cgroup.ErrUnsupported, err)
+}
+
+func TestParseBlkioServiceBytes(t *testing.T) {
+	content := `8:0 Read 4096
+8:0 Write 8192
+8:0 Sync 0
+8:0 Async 12288
+8:0 Total 12288
+Total 12288`
+
+	result := cgroup.ParseBlkioServiceBytes(content)
+	assert.Equal(t, map[string][2]int64{"8:0": {4096, 8192}}, result)
+}
+
+func TestParseIOStat(t *testing.T) {
+	content := `8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0
+253:1 rbytes=0 wbytes=512 rios=0 wios=1`
+
+	result := cgroup.ParseIOStat(content)
+	assert.Equal(t, map[string][2]int64{"8:0": {4096, 8192}, "253:1": {0, 512}}, result)
+}
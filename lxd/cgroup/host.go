@@ -0,0 +1,74 @@
+package cgroup
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/lxd/shared"
+)
+
+// DetectLayout returns the cgroup layout used by the host.
+func DetectLayout() Layout {
+	if shared.PathExists("/sys/fs/cgroup/cgroup.controllers") {
+		return Unified
+	}
+
+	return Legacy
+}
+
+// UnifiedControllers returns the controllers available in the unified
+// hierarchy.
+func UnifiedControllers() ([]string, error) {
+	content, err := ioutil.ReadFile("/sys/fs/cgroup/cgroup.controllers")
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(content)), nil
+}
+
+// ProcessPath returns the cgroup of the process with the given PID for the
+// given controller, relative to the root of its hierarchy. The controller is
+// ignored with the unified layout.
+func ProcessPath(layout Layout, pid int, controller string) (string, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scan := bufio.NewScanner(f)
+	for scan.Scan() {
+		fields := strings.SplitN(scan.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		if layout == Unified {
+			if fields[0] == "0" && fields[1] == "" {
+				return fields[2], nil
+			}
+
+			continue
+		}
+
+		if shared.StringInSlice(controller, strings.Split(fields[1], ",")) {
+			return fields[2], nil
+		}
+	}
+
+	return "", fmt.Errorf("No cgroup found for controller %q", controller)
+}
+
+// HostPath returns the path of a cgroup file on the host.
+func HostPath(layout Layout, controller string, cgroup string, file string) string {
+	if layout == Unified {
+		return filepath.Join("/sys/fs/cgroup", cgroup, file)
+	}
+
+	return filepath.Join("/sys/fs/cgroup", controller, cgroup, file)
+}
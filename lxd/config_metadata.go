@@ -0,0 +1,177 @@
+package main
+
+// configKeyDoc documents a configuration key, as exposed through the
+// configuration metadata API. The keys themselves and their types come from
+// the validation tables, the documentation only adding what can't be derived
+// from the validators.
+type configKeyDoc struct {
+	Default     string
+	Values      []string
+	Drivers     []string
+	Description string
+}
+
+// containerConfigKeyDocs documents the container and profile configuration keys.
+var containerConfigKeyDocs = map[string]configKeyDoc{
+	"boot.autostart":                       {Description: "Always start the container when LXD starts (if not set, restore last state)"},
+	"boot.autostart.delay":                 {Default: "0", Description: "Number of seconds to wait after the container started before starting the next one"},
+	"boot.autostart.priority":              {Default: "0", Description: "What order to start the containers in (starting with highest)"},
+	"boot.host_shutdown_timeout":           {Default: "30", Description: "Seconds to wait for container to shutdown before it is force stopped"},
+	"environment.*":                        {Description: "key/value environment variables to export to the container and set on exec"},
+	"health.command":                       {Description: "Command run through /bin/sh -c inside the container to check its health (exit code 0 means healthy)"},
+	"health.interval":                      {Default: "30", Description: "Number of seconds between two health checks"},
+	"health.retries":                       {Default: "3", Description: "Number of consecutive failed checks after which the container is considered unhealthy"},
+	"health.timeout":                       {Default: "10", Description: "Number of seconds after which a health check is killed and counted as failed"},
+	"limits.cpu":                           {Description: "Number or range of CPUs to expose to the container"},
+	"limits.cpu.allowance":                 {Default: "100%", Description: "How much of the CPU can be used. Can be a percentage (e.g. 50%) for a soft limit or hard a chunk of time (25ms/100ms)"},
+	"limits.cpu.priority":                  {Default: "10", Description: "CPU scheduling priority compared to other containers sharing the same CPUs (overcommit) (integer between 0 and 10)"},
+	"limits.disk.priority":                 {Default: "5", Description: "When under load, how much priority to give to the container's I/O requests (integer between 0 and 10)"},
+	"limits.kernel.*":                      {Description: "This limits kernel resources per container (e.g. number of open files)"},
+	"limits.memory":                        {Description: "Percentage of the host's memory or fixed value in bytes (supports kB, MB, GB, TB, PB and EB suffixes)"},
+	"limits.memory.enforce":                {Values: []string{"soft", "hard"}, Default: "hard", Description: "If hard, container can't exceed its memory limit. If soft, the container can exceed its memory limit when extra host memory is available."},
+	"limits.memory.swap":                   {Default: "true", Description: "Whether to allow some of the container's memory to be swapped out to disk"},
+	"limits.memory.swap.priority":          {Default: "10", Description: "The higher this is set, the least likely the container is to be swapped to disk (integer between 0 and 10)"},
+	"limits.network.priority":              {Default: "0", Description: "When under load, how much priority to give to the container's network requests (integer between 0 and 10)"},
+	"limits.processes":                     {Description: "Maximum number of processes that can run in the container"},
+	"linux.kernel_modules":                 {Description: "Comma separated list of kernel modules to load before starting the container"},
+	"linux.sysctl.*":                       {Description: "Value of a namespaced sysctl for the container (e.g. linux.sysctl.net.core.somaxconn)"},
+	"raw.apparmor":                         {Description: "Apparmor profile entries to be appended to the generated profile"},
+	"raw.idmap":                            {Description: "Raw idmap configuration (e.g. \"both 1000 1000\")"},
+	"raw.lxc":                              {Description: "Raw LXC configuration to be appended to the generated one"},
+	"raw.seccomp":                          {Description: "Raw Seccomp configuration"},
+	"restart.backoff":                      {Default: "1", Description: "Number of seconds to wait before the first automatic restart, doubled for each consecutive restart (up to 5 minutes)"},
+	"restart.max_retries":                  {Default: "0", Description: "Maximum number of consecutive restarts with the on-failure policy"},
+	"restart.policy":                       {Values: []string{"never", "on-failure", "always"}, Default: "never", Description: "When to restart the container automatically (never, on-failure or always)"},
+	"security.idmap.base":                  {Description: "The base host ID to use for the allocation (overrides auto-detection)"},
+	"security.idmap.isolated":              {Default: "false", Description: "Use an idmap for this container that is unique among containers with isolated set."},
+	"security.idmap.size":                  {Description: "The size of the idmap to use"},
+	"security.nesting":                     {Default: "false", Description: "Support running lxd (nested) inside the container"},
+	"security.privileged":                  {Default: "false", Description: "Runs the container in privileged mode"},
+	"security.syscalls.blacklist":          {Description: "A '\\n' separated list of syscalls to blacklist"},
+	"security.syscalls.blacklist_compat":   {Default: "false", Description: "On x86_64 this enables blocking of compat_* syscalls, it is a no-op on other arches"},
+	"security.syscalls.blacklist_default":  {Default: "true", Description: "Enables the default syscall blacklist"},
+	"security.syscalls.intercept.mknod":    {Default: "false", Description: "Handles the mknod and mknodat system calls (allows creation of a limited subset of char devices)"},
+	"security.syscalls.intercept.setxattr": {Default: "false", Description: "Handles the setxattr system call (allows setting a limited subset of restricted extended attributes)"},
+	"security.syscalls.whitelist":          {Description: "A '\\n' separated list of syscalls to whitelist (mutually exclusive with security.syscalls.blacklist*)"},
+	"snapshots.expiry":                     {Description: "Controls when snapshots are to be deleted (expects expression like 1M 2H 3d 4w 5m 6y)"},
+	"snapshots.pattern":                    {Default: "snap%d", Description: "Pongo2 template string which represents the snapshot name (used for scheduled snapshots and unnamed snapshots)"},
+	"snapshots.schedule":                   {Description: "Cron expression (<minute> <hour> <dom> <month> <dow>) or a descriptor like @daily or @hourly"},
+	"snapshots.schedule.stopped":           {Default: "false", Description: "Controls whether or not stopped containers are to be snapshoted automatically"},
+	"user.*":                               {Description: "Free form user key/value storage (can be used in search)"},
+	"volatile.apply_quota":                 {Description: "Disk quota to be applied on next container start"},
+	"volatile.apply_template":              {Description: "The name of a template hook which should be triggered upon next startup"},
+	"volatile.base_image":                  {Description: "The hash of the image the container was created from, if any."},
+	"volatile.idmap.base":                  {Description: "The first id in the container's primary idmap range"},
+	"volatile.idmap.next":                  {Description: "The idmap to use next time the container starts"},
+	"volatile.last_state.idmap":            {Description: "Serialized container uid/gid map"},
+	"volatile.last_state.power":            {Description: "Container state as of last host shutdown"},
+	"volatile.<name>.host_name":            {Description: "Network device name on the host (for nictype=bridged or nictype=p2p, or nictype=sriov)"},
+	"volatile.<name>.ipv4.address":         {Description: "Network device IPv4 address used for IP filtering (when no ipv4.address property is set on the device itself)"},
+	"volatile.<name>.ipv6.address":         {Description: "Network device IPv6 address used for IP filtering (when no ipv6.address property is set on the device itself)"},
+	"volatile.<name>.hwaddr":               {Description: "Network device MAC address (when no hwaddr property is set on the device itself)"},
+	"volatile.<name>.name":                 {Description: "Network device name (when no name propery is set on the device itself)"},
+}
+
+// networkConfigKeyDocs documents the network configuration keys.
+var networkConfigKeyDocs = map[string]configKeyDoc{
+	"bridge.driver":              {Values: []string{"native", "openvswitch"}, Default: "native", Description: "Bridge driver (\"native\" or \"openvswitch\")"},
+	"bridge.external_interfaces": {Description: "Comma separate list of unconfigured network interfaces to include in the bridge"},
+	"bridge.mode":                {Values: []string{"standard", "fan"}, Default: "standard", Description: "Bridge operation mode (\"standard\" or \"fan\")"},
+	"bridge.mtu":                 {Default: "1500", Description: "Bridge MTU (default varies if tunnel or fan setup)"},
+	"dns.domain":                 {Default: "lxd", Description: "Domain to advertise to DHCP clients and use for DNS resolution"},
+	"dns.mode":                   {Values: []string{"dynamic", "managed", "none"}, Default: "managed", Description: "DNS registration mode (\"none\" for no DNS record, \"managed\" for LXD generated static records or \"dynamic\" for client generated records)"},
+	"fan.overlay_subnet":         {Default: "240.0.0.0/8", Description: "Subnet to use as the overlay for the FAN (CIDR notation)"},
+	"fan.type":                   {Values: []string{"vxlan", "ipip"}, Default: "vxlan", Description: "The tunneling type for the FAN (\"vxlan\" or \"ipip\")"},
+	"fan.underlay_subnet":        {Description: "Subnet to use as the underlay for the FAN (CIDR notation)"},
+	"ipv4.address":               {Description: "IPv4 address for the bridge (CIDR notation). Use \"none\" to turn off IPv4 or \"auto\" to generate a new one"},
+	"ipv4.dhcp":                  {Default: "true", Description: "Whether to allocate addresses using DHCP"},
+	"ipv4.dhcp.expiry":           {Default: "1h", Description: "When to expire DHCP leases"},
+	"ipv4.dhcp.ranges":           {Description: "Comma separated list of IP ranges to use for DHCP (FIRST-LAST format)"},
+	"ipv4.firewall":              {Default: "true", Description: "Whether to generate filtering firewall rules for this network"},
+	"ipv4.nat":                   {Default: "false", Description: "Whether to NAT (will default to true if unset and a random ipv4.address is generated)"},
+	"ipv4.routes":                {Description: "Comma separated list of additional IPv4 CIDR subnets to route to the bridge"},
+	"ipv4.routing":               {Default: "true", Description: "Whether to route traffic in and out of the bridge"},
+	"ipv6.address":               {Description: "IPv6 address for the bridge (CIDR notation). Use \"none\" to turn off IPv6 or \"auto\" to generate a new one"},
+	"ipv6.dhcp":                  {Default: "true", Description: "Whether to provide additional network configuration over DHCP"},
+	"ipv6.dhcp.expiry":           {Default: "1h", Description: "When to expire DHCP leases"},
+	"ipv6.dhcp.ranges":           {Description: "Comma separated list of IPv6 ranges to use for DHCP (FIRST-LAST format)"},
+	"ipv6.dhcp.stateful":         {Default: "false", Description: "Whether to allocate addresses using DHCP"},
+	"ipv6.firewall":              {Default: "true", Description: "Whether to generate filtering firewall rules for this network"},
+	"ipv6.nat":                   {Default: "false", Description: "Whether to NAT (will default to true if unset and a random ipv6.address is generated)"},
+	"ipv6.routes":                {Description: "Comma separated list of additional IPv6 CIDR subnets to route to the bridge"},
+	"ipv6.routing":               {Default: "true", Description: "Whether to route traffic in and out of the bridge"},
+	"raw.dnsmasq":                {Description: "Additional dnsmasq configuration to append to the configuration"},
+	"tunnel.TARGET.group":        {Default: "239.0.0.1", Description: "Multicast address for vxlan (used if local and remote aren't set)"},
+	"tunnel.TARGET.id":           {Default: "0", Description: "Specific tunnel ID to use for the vxlan tunnel"},
+	"tunnel.TARGET.interface":    {Description: "Specific host interface to use for the tunnel"},
+	"tunnel.TARGET.local":        {Description: "Local address for the tunnel (not necessary for multicast vxlan)"},
+	"tunnel.TARGET.port":         {Default: "0", Description: "Specific port to use for the vxlan tunnel"},
+	"tunnel.TARGET.protocol":     {Values: []string{"gre", "vxlan"}, Description: "Tunneling protocol (\"vxlan\" or \"gre\")"},
+	"tunnel.TARGET.remote":       {Description: "Remote address for the tunnel (not necessary for multicast vxlan)"},
+}
+
+// storagePoolConfigKeyDocs documents the storage pool configuration keys.
+var storagePoolConfigKeyDocs = map[string]configKeyDoc{
+	"size":                        {Drivers: []string{"btrfs", "lvm", "zfs"}, Default: "0", Description: "Size of the storage pool in bytes (suffixes supported). (Currently valid for loop based pools and zfs.)"},
+	"source":                      {Drivers: []string{"btrfs", "dir", "lvm", "zfs"}, Description: "Path to block device or loop file or filesystem entry"},
+	"btrfs.mount_options":         {Drivers: []string{"btrfs"}, Default: "user_subvol_rm_allowed", Description: "Mount options for block devices"},
+	"ceph.cluster_name":           {Drivers: []string{"ceph"}, Default: "ceph", Description: "Name of the ceph cluster in which to create new storage pools."},
+	"ceph.osd.force_reuse":        {Drivers: []string{"ceph"}, Default: "false", Description: "Force using an osd storage pool that is already in use by another LXD instance."},
+	"ceph.osd.pg_num":             {Drivers: []string{"ceph"}, Default: "32", Description: "Number of placement groups for the osd storage pool."},
+	"ceph.osd.pool_name":          {Drivers: []string{"ceph"}, Description: "Name of the osd storage pool."},
+	"ceph.rbd.clone_copy":         {Drivers: []string{"ceph"}, Default: "true", Description: "Whether to use RBD lightweight clones rather than full dataset copies."},
+	"ceph.user.name":              {Drivers: []string{"ceph"}, Default: "admin", Description: "The ceph user to use when creating storage pools and volumes."},
+	"lvm.thinpool_name":           {Drivers: []string{"lvm"}, Default: "LXDPool", Description: "Thin pool where images and containers are created."},
+	"lvm.use_thinpool":            {Drivers: []string{"lvm"}, Default: "true", Description: "Whether the storage pool uses a thinpool for logical volumes."},
+	"lvm.vg_name":                 {Drivers: []string{"lvm"}, Description: "Name of the volume group to create."},
+	"rsync.bwlimit":               {Default: "0", Description: "Specifies the upper limit to be placed on the socket I/O whenever rsync has to be used to transfer storage entities."},
+	"volatile.initial_source":     {Description: "Records the actual source passed during creating (e.g. /dev/sdb)."},
+	"volatile.pool.pristine":      {Drivers: []string{"ceph"}, Default: "true", Description: "Whether the pool has been empty on creation time."},
+	"volume.block.filesystem":     {Values: []string{"btrfs", "ext4", "xfs"}, Drivers: []string{"ceph", "lvm"}, Default: "ext4", Description: "Filesystem to use for new volumes"},
+	"volume.block.mount_options":  {Drivers: []string{"ceph", "lvm"}, Default: "discard", Description: "Mount options for block devices"},
+	"volume.size":                 {Drivers: []string{"ceph", "lvm"}, Default: "0", Description: "Default volume size"},
+	"volume.zfs.remove_snapshots": {Drivers: []string{"zfs"}, Default: "false", Description: "Remove snapshots as needed"},
+	"volume.zfs.use_refquota":     {Drivers: []string{"zfs"}, Default: "false", Description: "Use refquota instead of quota for space."},
+	"zfs.clone_copy":              {Drivers: []string{"zfs"}, Default: "true", Description: "Whether to use ZFS lightweight clones rather than full dataset copies."},
+	"zfs.pool_name":               {Drivers: []string{"zfs"}, Description: "Name of the zpool"},
+}
+
+// storageVolumeConfigKeyDocs documents the storage volume configuration keys.
+var storageVolumeConfigKeyDocs = map[string]configKeyDoc{
+	"size":                 {Drivers: []string{"btrfs", "ceph", "lvm", "zfs"}, Description: "Size of the storage volume"},
+	"block.filesystem":     {Values: []string{"btrfs", "ext4", "xfs"}, Drivers: []string{"ceph", "lvm"}, Description: "Filesystem of the storage volume"},
+	"block.mount_options":  {Drivers: []string{"ceph", "lvm"}, Description: "Mount options for block devices"},
+	"zfs.remove_snapshots": {Drivers: []string{"zfs"}, Description: "Remove snapshots as needed"},
+	"zfs.use_refquota":     {Drivers: []string{"zfs"}, Description: "Use refquota instead of quota for space."},
+	"volatile.idmap.last":  {Description: "Serialized idmap the volume was last shifted to"},
+	"volatile.idmap.next":  {Description: "The idmap to shift the volume to next time it's used"},
+}
+
+// serverConfigKeyDocs documents the server configuration keys.
+var serverConfigKeyDocs = map[string]configKeyDoc{
+	"backups.compression_algorithm":  {Default: "gzip", Description: "Compression algorithm to use for new container backups (bzip2, gzip, lzma, xz or none)"},
+	"core.https_address":             {Description: "Address to bind for the remote API"},
+	"core.https_allowed_credentials": {Description: "Whether to set Access-Control-Allow-Credentials http header value to \"true\""},
+	"core.https_allowed_headers":     {Description: "Access-Control-Allow-Headers http header value"},
+	"core.https_allowed_methods":     {Description: "Access-Control-Allow-Methods http header value"},
+	"core.https_allowed_origin":      {Description: "Access-Control-Allow-Origin http header value"},
+	"core.macaroon.endpoint":         {Description: "URL of the the external authentication endpoint using Macaroons"},
+	"core.operations_history_expiry": {Default: "7", Description: "Number of days after which finished operations are removed from the history (0 disables it)"},
+	"core.proxy_https":               {Description: "https proxy to use, if any (falls back to HTTPS_PROXY environment variable)"},
+	"core.proxy_http":                {Description: "http proxy to use, if any (falls back to HTTP_PROXY environment variable)"},
+	"core.proxy_ignore_hosts":        {Description: "hosts which don't need the proxy for use (similar format to NO_PROXY, e.g. 1.2.3.4,1.2.3.5, falls back to NO_PROXY environment variable)"},
+	"core.remote_token_expiry":       {Default: "24", Description: "Number of hours after which an unused join token expires (0 disables it)"},
+	"core.trust_password":            {Description: "Password to be provided by clients to setup a trust"},
+	"images.auto_update_cached":      {Default: "true", Description: "Whether to automatically update any image that LXD caches"},
+	"images.auto_update_interval":    {Default: "6", Description: "Interval in hours at which to look for update to cached images (0 disables it)"},
+	"images.compression_algorithm":   {Default: "gzip", Description: "Compression algorithm to use for new images (bzip2, gzip, lzma, xz or none)"},
+	"images.remote_cache_expiry":     {Default: "10", Description: "Number of days after which an unused cached remote image will be flushed"},
+	"storage.lvm_fstype":             {Description: "Deprecated, use the volume.block.filesystem storage pool key instead"},
+	"storage.lvm_mount_options":      {Description: "Deprecated, use the volume.block.mount_options storage pool key instead"},
+	"storage.lvm_thinpool_name":      {Description: "Deprecated, use the lvm.thinpool_name storage pool key instead"},
+	"storage.lvm_vg_name":            {Description: "Deprecated, use the lvm.vg_name storage pool key instead"},
+	"storage.lvm_volume_size":        {Description: "Deprecated, use the volume.size storage pool key instead"},
+	"storage.zfs_pool_name":          {Description: "Deprecated, use the zfs.pool_name storage pool key instead"},
+	"storage.zfs_remove_snapshots":   {Description: "Deprecated, use the volume.zfs.remove_snapshots storage pool key instead"},
+	"storage.zfs_use_refquota":       {Description: "Deprecated, use the volume.zfs.use_refquota storage pool key instead"},
+}
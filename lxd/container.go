@@ -6,19 +6,28 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/robfig/cron"
+	"golang.org/x/net/context"
+	"gopkg.in/flosch/pongo2.v3"
 	"gopkg.in/lxc/go-lxc.v2"
 
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/sys"
+	"github.com/lxc/lxd/lxd/task"
 	"github.com/lxc/lxd/lxd/types"
+	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/idmap"
+	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/osarch"
+
+	log "github.com/lxc/lxd/shared/log15"
 )
 
 // Helper functions
@@ -67,6 +76,15 @@ func containerValidConfigKey(os *sys.OS, key string, value string) error {
 	if key == "raw.lxc" {
 		return lxcValidConfig(value)
 	}
+	if key == "snapshots.schedule" && value != "" {
+		_, err := cron.ParseStandard(value)
+		if err != nil {
+			return fmt.Errorf("Invalid schedule: %s: %v", value, err)
+		}
+	}
+	if strings.HasPrefix(key, "linux.sysctl.") && !util.RuntimeLiblxcVersionAtLeast(3, 0, 0) {
+		return fmt.Errorf("linux.sysctl keys require liblxc >= 3.0")
+	}
 	if key == "security.syscalls.blacklist_compat" {
 		for _, arch := range os.Architectures {
 			if arch == osarch.ARCH_64BIT_INTEL_X86 ||
@@ -135,6 +153,10 @@ func containerValidDeviceConfigKey(t, k string) bool {
 			return true
 		case "security.mac_filtering":
 			return true
+		case "security.ipv4_filtering":
+			return true
+		case "security.ipv6_filtering":
+			return true
 		default:
 			return false
 		}
@@ -199,6 +221,17 @@ func containerValidDeviceConfigKey(t, k string) bool {
 		default:
 			return false
 		}
+	case "proxy":
+		switch k {
+		case "bind":
+			return true
+		case "connect":
+			return true
+		case "listen":
+			return true
+		default:
+			return false
+		}
 	case "none":
 		return false
 	default:
@@ -231,8 +264,9 @@ func containerValidConfig(os *sys.OS, config map[string]string, profile bool, ex
 	_, blacklist := config["security.syscalls.blacklist"]
 	blacklistDefault := shared.IsTrue(config["security.syscalls.blacklist_default"])
 	blacklistCompat := shared.IsTrue(config["security.syscalls.blacklist_compat"])
+	intercept := shared.IsTrue(config["security.syscalls.intercept.mknod"]) || shared.IsTrue(config["security.syscalls.intercept.setxattr"])
 
-	if rawSeccomp && (whitelist || blacklist || blacklistDefault || blacklistCompat) {
+	if rawSeccomp && (whitelist || blacklist || blacklistDefault || blacklistCompat || intercept) {
 		return fmt.Errorf("raw.seccomp is mutually exclusive with security.syscalls*")
 	}
 
@@ -240,6 +274,10 @@ func containerValidConfig(os *sys.OS, config map[string]string, profile bool, ex
 		return fmt.Errorf("security.syscalls.whitelist is mutually exclusive with security.syscalls.blacklist*")
 	}
 
+	if whitelist && intercept {
+		return fmt.Errorf("security.syscalls.whitelist is mutually exclusive with security.syscalls.intercept*")
+	}
+
 	if expanded && (config["security.privileged"] == "" || !shared.IsTrue(config["security.privileged"])) && os.IdmapSet == nil {
 		return fmt.Errorf("LXD doesn't have a uid/gid allocation. In this mode, only privileged containers are supported.")
 	}
@@ -290,7 +328,7 @@ func containerValidDevices(db *db.Node, devices types.Devices, profile bool, exp
 			return fmt.Errorf("Missing device type for device '%s'", name)
 		}
 
-		if !shared.StringInSlice(m["type"], []string{"none", "nic", "disk", "unix-char", "unix-block", "usb", "gpu"}) {
+		if !shared.StringInSlice(m["type"], []string{"none", "nic", "disk", "unix-char", "unix-block", "usb", "gpu", "proxy"}) {
 			return fmt.Errorf("Invalid device type for device '%s'", name)
 		}
 
@@ -384,6 +422,32 @@ func containerValidDevices(db *db.Node, devices types.Devices, profile bool, exp
 		} else if m["type"] == "gpu" {
 			// Probably no checks needed, since we allow users to
 			// pass in all GPUs.
+		} else if m["type"] == "proxy" {
+			if m["listen"] == "" {
+				return fmt.Errorf("Proxy device entry is missing the required \"listen\" property.")
+			}
+
+			if m["connect"] == "" {
+				return fmt.Errorf("Proxy device entry is missing the required \"connect\" property.")
+			}
+
+			listenProto, _, err := proxyParseAddr(m["listen"])
+			if err != nil {
+				return err
+			}
+
+			connectProto, _, err := proxyParseAddr(m["connect"])
+			if err != nil {
+				return err
+			}
+
+			if (listenProto == "udp") != (connectProto == "udp") {
+				return fmt.Errorf("Proxying between UDP and stream sockets isn't supported.")
+			}
+
+			if !shared.StringInSlice(m["bind"], []string{"", "host", "container"}) {
+				return fmt.Errorf("Invalid proxy bind value: %s", m["bind"])
+			}
 		} else if m["type"] == "none" {
 			continue
 		} else {
@@ -429,6 +493,7 @@ type container interface {
 	// Live configuration
 	CGroupGet(key string) (string, error)
 	CGroupSet(key string, value string) error
+	CGroupIOStats() (map[string][2]int64, error)
 	ConfigKeySet(key string, value string) error
 
 	// File handling
@@ -457,11 +522,12 @@ type container interface {
 	         *      (the PID returned in the first return argument). It can however
 	         *      be used to e.g. forward signals.)
 	*/
-	Exec(command []string, env map[string]string, stdin *os.File, stdout *os.File, stderr *os.File, wait bool) (*exec.Cmd, int, int, error)
+	Exec(command []string, env map[string]string, stdin *os.File, stdout *os.File, stderr *os.File, wait bool, cwd string, uid uint32, gid uint32) (*exec.Cmd, int, int, error)
 
 	// Status
 	Render() (interface{}, interface{}, error)
 	RenderState() (*api.ContainerState, error)
+	RenderUsage() (*api.ContainerState, error)
 	IsPrivileged() bool
 	IsRunning() bool
 	IsFrozen() bool
@@ -477,10 +543,12 @@ type container interface {
 	// Properties
 	Id() int
 	Name() string
+	Project() string
 	Description() string
 	Architecture() int
 	CreationDate() time.Time
 	LastUsedDate() time.Time
+	ExpiryDate() time.Time
 	ExpandedConfig() map[string]string
 	ExpandedDevices() types.Devices
 	LocalConfig() map[string]string
@@ -625,6 +693,7 @@ func containerCreateAsCopy(s *state.State, args db.ContainerArgs, sourceContaine
 				Ephemeral:    snap.IsEphemeral(),
 				Name:         newSnapName,
 				Profiles:     snap.Profiles(),
+				ExpiryDate:   snap.ExpiryDate(),
 			}
 
 			// Create the snapshots.
@@ -740,8 +809,17 @@ func containerCreateAsSnapshot(s *state.State, args db.ContainerArgs, sourceCont
 
 func containerCreateInternal(s *state.State, args db.ContainerArgs) (container, error) {
 	// Set default values
+	if args.Project == "" {
+		args.Project = projectFromContainerName(args.Name)
+	}
+
 	if args.Profiles == nil {
-		args.Profiles = []string{"default"}
+		scope, err := projectProfileScope(s.DB, args.Project)
+		if err != nil {
+			return nil, err
+		}
+
+		args.Profiles = []string{projectPrefix(scope, "default")}
 	}
 
 	if args.Config == nil {
@@ -762,7 +840,7 @@ func containerCreateInternal(s *state.State, args db.ContainerArgs) (container,
 
 	// Validate container name
 	if args.Ctype == db.CTypeRegular {
-		err := containerValidName(args.Name)
+		err := containerValidName(projectStripPrefix(args.Project, args.Name))
 		if err != nil {
 			return nil, err
 		}
@@ -791,7 +869,12 @@ func containerCreateInternal(s *state.State, args db.ContainerArgs) (container,
 	}
 
 	// Validate profiles
-	profiles, err := s.DB.Profiles()
+	profileProject, err := projectProfileScope(s.DB, args.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.DB.ProfilesForProject(profileProject)
 	if err != nil {
 		return nil, err
 	}
@@ -904,3 +987,189 @@ func containerLoadByName(s *state.State, name string) (container, error) {
 
 	return containerLXCLoad(s, args)
 }
+
+// containerDetermineNextSnapshotName renders the snapshots.pattern of the
+// given container (or defaultPattern if unset) into a snapshot name which
+// isn't in use yet.
+func containerDetermineNextSnapshotName(s *state.State, c container, defaultPattern string) (string, error) {
+	pattern := c.ExpandedConfig()["snapshots.pattern"]
+	if pattern == "" {
+		pattern = defaultPattern
+	}
+
+	tpl, err := pongo2.FromString("{% autoescape off %}" + pattern + "{% endautoescape %}")
+	if err != nil {
+		return "", err
+	}
+
+	pattern, err = tpl.Execute(pongo2.Context{"creation_date": time.Now()})
+	if err != nil {
+		return "", err
+	}
+
+	count := strings.Count(pattern, "%d")
+	if count > 1 {
+		return "", fmt.Errorf("Snapshot pattern may contain '%%d' only once")
+	}
+
+	if count == 0 {
+		snapshots, err := s.DB.ContainerGetSnapshots(c.Name())
+		if err != nil {
+			return "", err
+		}
+
+		// Fallback to a numbered name if the rendered one is taken.
+		if !shared.StringInSlice(c.Name()+shared.SnapshotDelimiter+pattern, snapshots) {
+			return pattern, nil
+		}
+
+		pattern = fmt.Sprintf("%s-%%d", pattern)
+	}
+
+	i := s.DB.ContainerNextSnapshot(c.Name(), pattern)
+	return strings.Replace(pattern, "%d", strconv.Itoa(i), 1), nil
+}
+
+func autoCreateContainerSnapshotsTask(d *Daemon) (task.Func, task.Schedule) {
+	// Schedules are evaluated against the window elapsed since the
+	// previous run, so that a late timer never skips a snapshot.
+	last := time.Now()
+
+	f := func(ctx context.Context) {
+		now := time.Now()
+		autoCreateContainerSnapshots(ctx, d, last, now)
+		last = now
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+func autoCreateContainerSnapshots(ctx context.Context, d *Daemon, since time.Time, now time.Time) {
+	s := d.State()
+
+	names, err := s.DB.ContainersList(db.CTypeRegular)
+	if err != nil {
+		logger.Error("Unable to retrieve the list of containers", log.Ctx{"err": err})
+		return
+	}
+
+	for _, name := range names {
+		// It is safe to abort here since the next run will only look
+		// at schedules triggered after this one.
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		c, err := containerLoadByName(s, name)
+		if err != nil {
+			logger.Error("Error loading container", log.Ctx{"err": err, "container": name})
+			continue
+		}
+
+		config := c.ExpandedConfig()
+		spec := config["snapshots.schedule"]
+		if spec == "" {
+			continue
+		}
+
+		if !c.IsRunning() && !shared.IsTrue(config["snapshots.schedule.stopped"]) {
+			continue
+		}
+
+		schedule, err := cron.ParseStandard(spec)
+		if err != nil {
+			logger.Error("Invalid snapshot schedule", log.Ctx{"err": err, "container": name, "schedule": spec})
+			continue
+		}
+
+		if schedule.Next(since).After(now) {
+			continue
+		}
+
+		err = autoCreateContainerSnapshot(s, c)
+		if err != nil {
+			logger.Error("Error creating scheduled snapshot", log.Ctx{"err": err, "container": name})
+			continue
+		}
+	}
+}
+
+func autoCreateContainerSnapshot(s *state.State, c container) error {
+	snapName, err := containerDetermineNextSnapshotName(s, c, "snap%d")
+	if err != nil {
+		return err
+	}
+
+	expiry, err := shared.GetSnapshotExpiry(time.Now(), c.ExpandedConfig()["snapshots.expiry"])
+	if err != nil {
+		return err
+	}
+
+	ourStart, err := c.StorageStart()
+	if err != nil {
+		return err
+	}
+	if ourStart {
+		defer c.StorageStop()
+	}
+
+	args := db.ContainerArgs{
+		Name:         c.Name() + shared.SnapshotDelimiter + snapName,
+		Ctype:        db.CTypeSnapshot,
+		Config:       c.LocalConfig(),
+		Profiles:     c.Profiles(),
+		Ephemeral:    c.IsEphemeral(),
+		BaseImage:    c.ExpandedConfig()["volatile.base_image"],
+		Architecture: c.Architecture(),
+		Devices:      c.LocalDevices(),
+		Stateful:     false,
+		ExpiryDate:   expiry,
+	}
+
+	logger.Info("Creating scheduled snapshot", log.Ctx{"container": c.Name(), "snapshot": snapName})
+	_, err = containerCreateAsSnapshot(s, args, c)
+	return err
+}
+
+func pruneExpiredContainerSnapshotsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		pruneExpiredContainerSnapshots(ctx, d)
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+func pruneExpiredContainerSnapshots(ctx context.Context, d *Daemon) {
+	s := d.State()
+
+	snapshots, err := s.DB.ContainerGetExpiredSnapshots(time.Now())
+	if err != nil {
+		logger.Error("Unable to retrieve the list of expired snapshots", log.Ctx{"err": err})
+		return
+	}
+
+	for _, name := range snapshots {
+		// It is safe to abort here since anything not deleted now
+		// will still be expired at the next run.
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		sc, err := containerLoadByName(s, name)
+		if err != nil {
+			logger.Error("Error loading expired snapshot", log.Ctx{"err": err, "snapshot": name})
+			continue
+		}
+
+		logger.Info("Deleting expired snapshot", log.Ctx{"snapshot": name})
+		err = sc.Delete()
+		if err != nil {
+			logger.Error("Error deleting expired snapshot", log.Ctx{"err": err, "snapshot": name})
+			continue
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+func containerBackupsGet(d *Daemon, r *http.Request) Response {
+	recursionStr := r.FormValue("recursion")
+	recursion, err := strconv.Atoi(recursionStr)
+	if err != nil {
+		recursion = 0
+	}
+
+	project := projectParam(r)
+	cname := mux.Vars(r)["name"]
+	_, err = containerLoadByName(d.State(), projectPrefix(project, cname))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	backups, err := d.db.ContainerGetBackups(projectPrefix(project, cname))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []*api.ContainerBackup{}
+
+	for _, name := range backups {
+		if recursion == 0 {
+			url := fmt.Sprintf("/%s/containers/%s/backups/%s", version.APIVersion, cname, name)
+			resultString = append(resultString, projectURL(project, url))
+		} else {
+			b, err := backupLoadByName(d.State(), projectPrefix(project, cname), name)
+			if err != nil {
+				continue
+			}
+
+			resultMap = append(resultMap, b.Render())
+		}
+	}
+
+	if recursion == 0 {
+		return SyncResponse(true, resultString)
+	}
+
+	return SyncResponse(true, resultMap)
+}
+
+func containerBackupsPost(d *Daemon, r *http.Request) Response {
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
+
+	c, err := containerLoadByName(d.State(), name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	req := api.ContainerBackupsPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	backups, err := d.db.ContainerGetBackups(name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if req.Name == "" {
+		// come up with a name
+		for i := 0; ; i++ {
+			req.Name = fmt.Sprintf("backup%d", i)
+			if !shared.StringInSlice(req.Name, backups) {
+				break
+			}
+		}
+	}
+
+	if strings.Contains(req.Name, "/") {
+		return BadRequest(fmt.Errorf("Backup names may not contain slashes"))
+	}
+
+	if shared.StringInSlice(req.Name, backups) {
+		return Conflict
+	}
+
+	if req.OptimizedStorage && !shared.StringInSlice(c.Storage().GetStorageTypeName(), []string{"btrfs", "zfs"}) {
+		return BadRequest(fmt.Errorf("Optimized backups are only supported on btrfs and zfs storage pools"))
+	}
+
+	var expiry time.Time
+	if req.ExpiresAt != nil {
+		expiry = *req.ExpiresAt
+	}
+
+	backup := func(op *operation) error {
+		args := db.ContainerBackupArgs{
+			ContainerID:      c.Id(),
+			Name:             req.Name,
+			CreationDate:     time.Now(),
+			ExpiryDate:       expiry,
+			ContainerOnly:    req.ContainerOnly,
+			OptimizedStorage: req.OptimizedStorage,
+		}
+
+		return backupCreate(d.State(), args, c)
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{name}
+
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, backup, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func containerBackupGet(d *Daemon, r *http.Request) Response {
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
+	backupName := mux.Vars(r)["backupName"]
+
+	backup, err := backupLoadByName(d.State(), name, backupName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	return SyncResponse(true, backup.Render())
+}
+
+func containerBackupPost(d *Daemon, r *http.Request) Response {
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
+	backupName := mux.Vars(r)["backupName"]
+
+	req := api.ContainerBackupPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	if req.Name == "" {
+		return BadRequest(fmt.Errorf("A new name must be provided"))
+	}
+
+	if strings.Contains(req.Name, "/") {
+		return BadRequest(fmt.Errorf("Backup names may not contain slashes"))
+	}
+
+	backup, err := backupLoadByName(d.State(), name, backupName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	backups, err := d.db.ContainerGetBackups(name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if shared.StringInSlice(req.Name, backups) {
+		return Conflict
+	}
+
+	rename := func(op *operation) error {
+		return backup.Rename(req.Name)
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{name}
+
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, rename, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func containerBackupDelete(d *Daemon, r *http.Request) Response {
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
+	backupName := mux.Vars(r)["backupName"]
+
+	backup, err := backupLoadByName(d.State(), name, backupName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	remove := func(op *operation) error {
+		return backup.Delete()
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{name}
+
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, remove, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func containerBackupExportGet(d *Daemon, r *http.Request) Response {
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
+	backupName := mux.Vars(r)["backupName"]
+
+	backup, err := backupLoadByName(d.State(), name, backupName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, ext, err := detectCompression(backup.Path())
+	if err != nil {
+		return InternalError(err)
+	}
+
+	ent := fileResponseEntry{
+		path:     backup.Path(),
+		filename: fmt.Sprintf("backup%s", ext),
+	}
+
+	return FileResponse(r, []fileResponseEntry{ent}, nil, false)
+}
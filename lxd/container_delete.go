@@ -8,7 +8,7 @@ import (
 )
 
 func containerDelete(d *Daemon, r *http.Request) Response {
-	name := mux.Vars(r)["name"]
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
 	c, err := containerLoadByName(d.State(), name)
 	if err != nil {
 		return SmartError(err)
@@ -18,14 +18,21 @@ func containerDelete(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("container is running"))
 	}
 
+	requestor := d.requestor(r)
 	rmct := func(op *operation) error {
-		return c.Delete()
+		err := c.Delete()
+		if err != nil {
+			return err
+		}
+
+		eventSendContainerLifecycle(name, "container-deleted", requestor, nil)
+		return nil
 	}
 
 	resources := map[string][]string{}
 	resources["containers"] = []string{name}
 
-	op, err := operationCreate(operationClassTask, resources, nil, rmct, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, rmct, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -28,6 +28,9 @@ type execWs struct {
 	command   []string
 	container container
 	env       map[string]string
+	cwd       string
+	uid       uint32
+	gid       uint32
 
 	rootUid          int64
 	rootGid          int64
@@ -299,7 +302,7 @@ func (s *execWs) Do(op *operation) error {
 		return cmdErr
 	}
 
-	cmd, _, attachedPid, err := s.container.Exec(s.command, s.env, stdin, stdout, stderr, false)
+	cmd, _, attachedPid, err := s.container.Exec(s.command, s.env, stdin, stdout, stderr, false, s.cwd, s.uid, s.gid)
 	if err != nil {
 		return err
 	}
@@ -330,7 +333,7 @@ func (s *execWs) Do(op *operation) error {
 }
 
 func containerExecPost(d *Daemon, r *http.Request) Response {
-	name := mux.Vars(r)["name"]
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
 	c, err := containerLoadByName(d.State(), name)
 	if err != nil {
 		return SmartError(err)
@@ -377,16 +380,32 @@ func containerExecPost(d *Daemon, r *http.Request) Response {
 		}
 	}
 
+	// Resolve the user and group to run as
+	uid, gid, user, home, err := containerExecUser(c, post.User, post.Group)
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	// Set default value for HOME
 	_, ok = env["HOME"]
 	if !ok {
-		env["HOME"] = "/root"
+		env["HOME"] = home
 	}
 
 	// Set default value for USER
 	_, ok = env["USER"]
-	if !ok {
-		env["USER"] = "root"
+	if !ok && user != "" {
+		env["USER"] = user
+	}
+
+	// Run from the home directory unless told otherwise
+	cwd := post.Cwd
+	if cwd == "" {
+		cwd = env["HOME"]
+	}
+
+	if !filepath.IsAbs(cwd) {
+		return BadRequest(fmt.Errorf("The working directory must be an absolute path"))
 	}
 
 	// Set default value for USER
@@ -428,6 +447,9 @@ func containerExecPost(d *Daemon, r *http.Request) Response {
 		ws.command = post.Command
 		ws.container = c
 		ws.env = env
+		ws.cwd = cwd
+		ws.uid = uid
+		ws.gid = gid
 
 		ws.width = post.Width
 		ws.height = post.Height
@@ -435,7 +457,7 @@ func containerExecPost(d *Daemon, r *http.Request) Response {
 		resources := map[string][]string{}
 		resources["containers"] = []string{ws.container.Name()}
 
-		op, err := operationCreate(operationClassWebsocket, resources, ws.Metadata(), ws.Do, nil, ws.Connect)
+		op, err := operationCreate(projectParam(r), operationClassWebsocket, resources, ws.Metadata(), ws.Do, nil, ws.Connect)
 		if err != nil {
 			return InternalError(err)
 		}
@@ -463,16 +485,17 @@ func containerExecPost(d *Daemon, r *http.Request) Response {
 			defer stderr.Close()
 
 			// Run the command
-			_, cmdResult, _, cmdErr = c.Exec(post.Command, env, nil, stdout, stderr, true)
+			_, cmdResult, _, cmdErr = c.Exec(post.Command, env, nil, stdout, stderr, true, cwd, uid, gid)
 
 			// Update metadata with the right URLs
 			metadata["return"] = cmdResult
+			cname := projectStripPrefix(c.Project(), c.Name())
 			metadata["output"] = shared.Jmap{
-				"1": fmt.Sprintf("/%s/containers/%s/logs/%s", version.APIVersion, c.Name(), filepath.Base(stdout.Name())),
-				"2": fmt.Sprintf("/%s/containers/%s/logs/%s", version.APIVersion, c.Name(), filepath.Base(stderr.Name())),
+				"1": projectURL(c.Project(), fmt.Sprintf("/%s/containers/%s/logs/%s", version.APIVersion, cname, filepath.Base(stdout.Name()))),
+				"2": projectURL(c.Project(), fmt.Sprintf("/%s/containers/%s/logs/%s", version.APIVersion, cname, filepath.Base(stderr.Name()))),
 			}
 		} else {
-			_, cmdResult, _, cmdErr = c.Exec(post.Command, env, nil, nil, nil, true)
+			_, cmdResult, _, cmdErr = c.Exec(post.Command, env, nil, nil, nil, true, cwd, uid, gid)
 			metadata["return"] = cmdResult
 		}
 
@@ -487,10 +510,142 @@ func containerExecPost(d *Daemon, r *http.Request) Response {
 	resources := map[string][]string{}
 	resources["containers"] = []string{name}
 
-	op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, run, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
 
 	return OperationResponse(op)
 }
+
+// containerExecUser resolves the user and group an exec session runs as,
+// using the container's /etc/passwd and /etc/group. Both can be given either
+// as a name or as a numeric ID, the latter not needing to exist in the
+// container. The user's primary group is used if no group is given.
+//
+// Returns the uid, gid, user name (if known) and home directory.
+func containerExecUser(c container, user string, group string) (uint32, uint32, string, string, error) {
+	if user == "" && group == "" {
+		return 0, 0, "root", "/root", nil
+	}
+
+	uid := uint32(0)
+	gid := uint32(0)
+	name := "root"
+	home := "/root"
+
+	if user != "" {
+		entries, err := containerExecReadDatabase(c, "/etc/passwd")
+		if err != nil {
+			return 0, 0, "", "", err
+		}
+
+		found := false
+		for _, fields := range entries {
+			if len(fields) < 6 || (fields[0] != user && fields[2] != user) {
+				continue
+			}
+
+			entryUid, err := strconv.ParseUint(fields[2], 10, 32)
+			if err != nil {
+				continue
+			}
+
+			entryGid, err := strconv.ParseUint(fields[3], 10, 32)
+			if err != nil {
+				continue
+			}
+
+			uid = uint32(entryUid)
+			gid = uint32(entryGid)
+			name = fields[0]
+			home = fields[5]
+			found = true
+			break
+		}
+
+		if !found {
+			id, err := strconv.ParseUint(user, 10, 32)
+			if err != nil {
+				return 0, 0, "", "", fmt.Errorf("User '%s' doesn't exist in the container", user)
+			}
+
+			// Unknown users get a group with the same ID
+			uid = uint32(id)
+			gid = uint32(id)
+			name = ""
+			home = "/"
+		}
+	}
+
+	if group != "" {
+		entries, err := containerExecReadDatabase(c, "/etc/group")
+		if err != nil {
+			return 0, 0, "", "", err
+		}
+
+		found := false
+		for _, fields := range entries {
+			if len(fields) < 3 || (fields[0] != group && fields[2] != group) {
+				continue
+			}
+
+			entryGid, err := strconv.ParseUint(fields[2], 10, 32)
+			if err != nil {
+				continue
+			}
+
+			gid = uint32(entryGid)
+			found = true
+			break
+		}
+
+		if !found {
+			id, err := strconv.ParseUint(group, 10, 32)
+			if err != nil {
+				return 0, 0, "", "", fmt.Errorf("Group '%s' doesn't exist in the container", group)
+			}
+
+			gid = uint32(id)
+		}
+	}
+
+	return uid, gid, name, home, nil
+}
+
+// containerExecReadDatabase returns the colon separated fields of each entry
+// of a passwd(5) style file in the container. A missing file has no entries.
+func containerExecReadDatabase(c container, path string) ([][]string, error) {
+	f, err := ioutil.TempFile("", "lxd_exec_")
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	_, _, _, _, _, err = c.FilePull(path, f.Name())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return [][]string{}, nil
+		}
+
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	entries := [][]string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entries = append(entries, strings.Split(line, ":"))
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared/logger"
+
+	log "github.com/lxc/lxd/shared/log15"
+)
+
+// The init process of a container is a child of the LXC monitor, not of LXD,
+// so its exit status is collected from the process events the kernel sends
+// through the netlink connector.
+const (
+	netlinkConnector = 11
+
+	cnIdxProc          = 1
+	cnValProc          = 1
+	procCnMcastListen  = 1
+	procEventExit      = 0x80000000
+	procEventExitSize  = 16
+	procEventHeaderLen = 16
+)
+
+// Header of the netlink connector messages (struct cn_msg).
+type cnMsg struct {
+	idx   uint32
+	val   uint32
+	seq   uint32
+	ack   uint32
+	len   uint16
+	flags uint16
+}
+
+// Header of the process events (struct proc_event) followed by the part of
+// the exit event (struct exit_proc_event) that's needed.
+type procEvent struct {
+	what       uint32
+	cpu        uint32
+	timestamp  uint64
+	pid        int32
+	tgid       int32
+	exitCode   uint32
+	exitSignal uint32
+}
+
+var containerExitsLock sync.Mutex
+
+// Container IDs by the PID of their init process.
+var containerInits = map[int]int{}
+
+// Exit status of the init process of stopped containers by container ID.
+var containerExits = map[int]syscall.WaitStatus{}
+
+// containerInitTrack records the PID of the init process of a container
+// which just started, so its exit status can be collected.
+func containerInitTrack(id int, pid int) {
+	if pid <= 0 {
+		return
+	}
+
+	containerExitsLock.Lock()
+	defer containerExitsLock.Unlock()
+
+	for initPid, initId := range containerInits {
+		if initId == id {
+			delete(containerInits, initPid)
+		}
+	}
+
+	containerInits[pid] = id
+	delete(containerExits, id)
+}
+
+// containerInitExitStatus returns the exit status of the init process of a
+// stopped container, if it's known.
+func containerInitExitStatus(id int) (syscall.WaitStatus, bool) {
+	containerExitsLock.Lock()
+	defer containerExitsLock.Unlock()
+
+	for initPid, initId := range containerInits {
+		if initId == id {
+			delete(containerInits, initPid)
+		}
+	}
+
+	status, ok := containerExits[id]
+	delete(containerExits, id)
+
+	return status, ok
+}
+
+// containerInitCrashed returns whether the init process of a container exited
+// with a non-zero status or got killed. Shutting down from the inside has the
+// kernel kill init with SIGINT (poweroff) or SIGHUP (reboot), which isn't a
+// crash.
+func containerInitCrashed(status syscall.WaitStatus) bool {
+	if status.Signaled() {
+		return status.Signal() != syscall.SIGINT && status.Signal() != syscall.SIGHUP
+	}
+
+	return status.ExitStatus() != 0
+}
+
+// containerExitContext returns the lifecycle event context describing how
+// the init process of a container exited.
+func containerExitContext(status syscall.WaitStatus) map[string]interface{} {
+	if status.Signaled() {
+		return map[string]interface{}{"signal": int(status.Signal())}
+	}
+
+	return map[string]interface{}{"exit_code": status.ExitStatus()}
+}
+
+// containerExitListener collects the exit status of the init process of
+// the running containers. It's only available to a LXD running in the
+// initial user and PID namespaces.
+func containerExitListener(s *state.State) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM, netlinkConnector)
+	if err != nil {
+		logger.Warn("Unable to track container exits", log.Ctx{"err": err})
+		return
+	}
+	defer syscall.Close(fd)
+
+	nl := syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: cnIdxProc,
+	}
+
+	err = syscall.Bind(fd, &nl)
+	if err != nil {
+		logger.Warn("Unable to track container exits", log.Ctx{"err": err})
+		return
+	}
+
+	// Subscribe to the process events
+	hdrLen := syscall.NLMSG_HDRLEN + int(unsafe.Sizeof(cnMsg{}))
+	req := make([]byte, hdrLen+4)
+
+	hdr := (*syscall.NlMsghdr)(unsafe.Pointer(&req[0]))
+	hdr.Len = uint32(len(req))
+	hdr.Type = syscall.NLMSG_DONE
+
+	msg := (*cnMsg)(unsafe.Pointer(&req[syscall.NLMSG_HDRLEN]))
+	msg.idx = cnIdxProc
+	msg.val = cnValProc
+	msg.len = 4
+
+	*(*uint32)(unsafe.Pointer(&req[hdrLen])) = procCnMcastListen
+
+	err = syscall.Sendto(fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK})
+	if err != nil {
+		logger.Warn("Unable to track container exits", log.Ctx{"err": err})
+		return
+	}
+
+	// Track the containers which were already running
+	names, err := s.DB.ContainersList(db.CTypeRegular)
+	if err != nil {
+		logger.Error("Unable to retrieve the list of containers", log.Ctx{"err": err})
+	}
+
+	for _, name := range names {
+		c, err := containerLoadByName(s, name)
+		if err != nil {
+			continue
+		}
+
+		if c.IsRunning() {
+			containerInitTrack(c.Id(), c.InitPID())
+		}
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			if err == syscall.EINTR || err == syscall.ENOBUFS {
+				continue
+			}
+
+			logger.Error("Failed to read process events", log.Ctx{"err": err})
+			return
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+
+		for _, m := range msgs {
+			if len(m.Data) < int(unsafe.Sizeof(cnMsg{}))+procEventHeaderLen+procEventExitSize {
+				continue
+			}
+
+			ev := (*procEvent)(unsafe.Pointer(&m.Data[unsafe.Sizeof(cnMsg{})]))
+			if ev.what != procEventExit || ev.pid != ev.tgid {
+				continue
+			}
+
+			containerExitsLock.Lock()
+			id, ok := containerInits[int(ev.pid)]
+			if ok {
+				delete(containerInits, int(ev.pid))
+				containerExits[id] = syscall.WaitStatus(ev.exitCode)
+			}
+			containerExitsLock.Unlock()
+		}
+	}
+}
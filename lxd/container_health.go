@@ -0,0 +1,381 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/task"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+
+	log "github.com/lxc/lxd/shared/log15"
+)
+
+// Maximum delay between two automatic restarts of a container.
+const containerRestartMaxBackoff = 5 * time.Minute
+
+// Time a container must stay up for its restart retries to be reset.
+const containerRestartResetDelay = 10 * time.Minute
+
+// containerHealth tracks the health checks and automatic restarts of a
+// container. It's only kept in memory and so starts afresh with the daemon.
+type containerHealth struct {
+	// Init PID of the container when last checked, used to detect restarts.
+	pid int
+
+	status        string
+	failingStreak int
+	lastCheck     time.Time
+	lastExitCode  int
+	checking      bool
+
+	restarts    int
+	retries     int
+	lastRestart time.Time
+}
+
+var containerHealthLock sync.Mutex
+var containerHealthStates = map[int]*containerHealth{}
+
+// containerHealthGet returns the tracked state of the container with the
+// given ID, creating it as needed. The caller must hold containerHealthLock.
+func containerHealthGet(id int) *containerHealth {
+	h, ok := containerHealthStates[id]
+	if !ok {
+		h = &containerHealth{}
+		containerHealthStates[id] = h
+	}
+
+	return h
+}
+
+// containerHealthForget drops the tracked state of a deleted container.
+func containerHealthForget(id int) {
+	containerHealthLock.Lock()
+	delete(containerHealthStates, id)
+	containerHealthLock.Unlock()
+}
+
+// containerHealthRender returns the health status of the container, or nil
+// if it has no health check or hasn't been checked since it started, along
+// with the number of times it was restarted automatically.
+func containerHealthRender(c container) (*api.ContainerStateHealth, int) {
+	containerHealthLock.Lock()
+	defer containerHealthLock.Unlock()
+
+	h, ok := containerHealthStates[c.Id()]
+	if !ok {
+		return nil, 0
+	}
+
+	if c.ExpandedConfig()["health.command"] == "" || h.pid != c.InitPID() {
+		return nil, h.restarts
+	}
+
+	health := api.ContainerStateHealth{
+		Status:        h.status,
+		FailingStreak: h.failingStreak,
+		LastCheck:     h.lastCheck,
+		LastExitCode:  h.lastExitCode,
+	}
+
+	return &health, h.restarts
+}
+
+// containerConfigUint parses an unsigned integer configuration value,
+// falling back to the given default if it's unset.
+func containerConfigUint(value string, fallback int) int {
+	if value == "" {
+		return fallback
+	}
+
+	result, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return fallback
+	}
+
+	return int(result)
+}
+
+func containerHealthCheckTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		containerHealthCheck(ctx, d)
+	}
+
+	return f, task.Every(10 * time.Second)
+}
+
+// containerHealthCheck runs the health check command of all the running
+// containers whose interval elapsed.
+func containerHealthCheck(ctx context.Context, d *Daemon) {
+	s := d.State()
+
+	names, err := s.DB.ContainersListWithConfigKey(db.CTypeRegular, "health.command")
+	if err != nil {
+		logger.Error("Unable to retrieve the list of containers", log.Ctx{"err": err})
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		c, err := containerLoadByName(s, name)
+		if err != nil {
+			logger.Error("Error loading container", log.Ctx{"err": err, "container": name})
+			continue
+		}
+
+		config := c.ExpandedConfig()
+		if config["health.command"] == "" || !c.IsRunning() {
+			continue
+		}
+
+		interval := time.Duration(containerConfigUint(config["health.interval"], 30)) * time.Second
+		pid := c.InitPID()
+
+		containerHealthLock.Lock()
+		h := containerHealthGet(c.Id())
+		if h.pid != pid {
+			// The container started since the last check, give it
+			// a full interval before checking it.
+			h.pid = pid
+			h.status = "starting"
+			h.failingStreak = 0
+			h.lastCheck = time.Now()
+			h.lastExitCode = 0
+		}
+
+		due := !h.checking && time.Since(h.lastCheck) >= interval
+		if due {
+			h.checking = true
+		}
+		containerHealthLock.Unlock()
+
+		if !due {
+			continue
+		}
+
+		wg.Add(1)
+		go func(c container) {
+			defer wg.Done()
+			containerHealthCheckRun(s, c)
+		}(c)
+	}
+
+	wg.Wait()
+}
+
+// containerHealthCheckRun runs the health check of a container and records
+// its result, reporting the transitions between healthy and unhealthy.
+func containerHealthCheckRun(s *state.State, c container) {
+	config := c.ExpandedConfig()
+	timeout := time.Duration(containerConfigUint(config["health.timeout"], 10)) * time.Second
+
+	retries := containerConfigUint(config["health.retries"], 3)
+	if retries < 1 {
+		retries = 1
+	}
+
+	exitCode, err := containerHealthExec(c, config["health.command"], timeout)
+	if err != nil {
+		logger.Warn("Failed to run container health check", log.Ctx{"container": c.Name(), "err": err})
+		exitCode = -1
+	}
+
+	containerHealthLock.Lock()
+	h := containerHealthGet(c.Id())
+	previous := h.status
+
+	h.checking = false
+	h.lastCheck = time.Now()
+	h.lastExitCode = exitCode
+	if exitCode == 0 {
+		h.failingStreak = 0
+		h.status = "healthy"
+	} else {
+		h.failingStreak++
+		if h.failingStreak >= retries {
+			h.status = "unhealthy"
+		}
+	}
+
+	status := h.status
+	failingStreak := h.failingStreak
+	containerHealthLock.Unlock()
+
+	if status == previous {
+		return
+	}
+
+	if status == "unhealthy" {
+		logger.Warn("Container is unhealthy", log.Ctx{"container": c.Name(), "failures": failingStreak, "exitcode": exitCode})
+		eventSendContainerLifecycle(c.Name(), "container-unhealthy", nil,
+			map[string]interface{}{"failing_streak": failingStreak, "exit_code": exitCode})
+
+		containerAutoRestart(s, c, "unhealthy")
+	} else if status == "healthy" && previous == "unhealthy" {
+		logger.Info("Container is healthy again", log.Ctx{"container": c.Name()})
+		eventSendContainerLifecycle(c.Name(), "container-healthy", nil, nil)
+	}
+}
+
+// containerHealthExec runs the given command in the container through a
+// shell and returns its exit code. Commands which don't return within the
+// timeout are killed and reported as failed.
+func containerHealthExec(c container, command string, timeout time.Duration) (int, error) {
+	devnull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return -1, err
+	}
+	defer devnull.Close()
+
+	env := map[string]string{
+		"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME": "/root",
+		"USER": "root",
+	}
+
+	cmd, _, attachedPid, err := c.Exec([]string{"/bin/sh", "-c", command}, env, devnull, devnull, devnull, false, "/", 0, 0)
+	if err != nil {
+		return -1, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(timeout):
+		syscall.Kill(attachedPid, syscall.SIGKILL)
+		cmd.Process.Kill()
+		<-done
+
+		logger.Debugf("Health check of %s timed out after %s", c.Name(), timeout)
+		return -1, nil
+	}
+
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if ok {
+			status, ok := exitErr.Sys().(syscall.WaitStatus)
+			if ok {
+				if status.Signaled() {
+					return 128 + int(status.Signal()), nil
+				}
+
+				return status.ExitStatus(), nil
+			}
+		}
+
+		return -1, err
+	}
+
+	return 0, nil
+}
+
+// containerAutoRestart restarts a container which stopped on its own, failed
+// or was found unhealthy, as allowed by its restart policy. The on-failure
+// policy ignores containers which were cleanly stopped from the inside.
+// Consecutive restarts are delayed by an exponential back-off.
+func containerAutoRestart(s *state.State, c container, reason string) {
+	config := c.ExpandedConfig()
+	policy := config["restart.policy"]
+	if policy != "on-failure" && policy != "always" {
+		return
+	}
+
+	if policy == "on-failure" && reason == "stopped" {
+		return
+	}
+
+	// Ephemeral containers are gone once stopped
+	if c.IsEphemeral() {
+		return
+	}
+
+	maxRetries := containerConfigUint(config["restart.max_retries"], 0)
+	backoff := time.Duration(containerConfigUint(config["restart.backoff"], 1)) * time.Second
+
+	containerHealthLock.Lock()
+	h := containerHealthGet(c.Id())
+	if time.Since(h.lastRestart) > containerRestartResetDelay {
+		h.retries = 0
+	}
+
+	if policy == "on-failure" && maxRetries > 0 && h.retries >= maxRetries {
+		containerHealthLock.Unlock()
+		logger.Warn("Giving up on restarting container", log.Ctx{"container": c.Name(), "retries": maxRetries})
+		return
+	}
+
+	delay := backoff
+	for i := 0; i < h.retries && delay < containerRestartMaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > containerRestartMaxBackoff {
+		delay = containerRestartMaxBackoff
+	}
+
+	h.retries++
+	h.restarts++
+	h.lastRestart = time.Now()
+	restarts := h.restarts
+	containerHealthLock.Unlock()
+
+	logger.Info("Scheduling container restart", log.Ctx{"container": c.Name(), "reason": reason, "delay": delay})
+
+	go func(id int) {
+		time.Sleep(delay)
+
+		// The container may have changed in the meantime
+		c, err := containerLoadById(s, id)
+		if err != nil {
+			return
+		}
+
+		policy := c.ExpandedConfig()["restart.policy"]
+		if policy != "on-failure" && policy != "always" {
+			return
+		}
+
+		if reason == "unhealthy" {
+			if !c.IsRunning() {
+				return
+			}
+
+			err = c.Stop(false)
+			if err != nil {
+				logger.Error("Failed to stop unhealthy container", log.Ctx{"container": c.Name(), "err": err})
+				return
+			}
+		} else if c.IsRunning() {
+			return
+		}
+
+		err = c.Start(false)
+		if err != nil {
+			logger.Error("Failed to restart container", log.Ctx{"container": c.Name(), "err": err})
+			containerAutoRestart(s, c, "failed")
+			return
+		}
+
+		eventSendContainerLifecycle(c.Name(), "container-restarted", nil,
+			map[string]interface{}{"reason": reason, "restarts": restarts})
+	}(c.Id())
+}
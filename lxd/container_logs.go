@@ -21,6 +21,7 @@ func containerLogsGet(d *Daemon, r *http.Request) Response {
 	 * However, we should check this name and ensure it's a valid container
 	 * name just so that people can't list arbitrary directories.
 	 */
+	project := projectParam(r)
 	name := mux.Vars(r)["name"]
 
 	if err := containerValidName(name); err != nil {
@@ -29,7 +30,7 @@ func containerLogsGet(d *Daemon, r *http.Request) Response {
 
 	result := []string{}
 
-	dents, err := ioutil.ReadDir(shared.LogPath(name))
+	dents, err := ioutil.ReadDir(shared.LogPath(projectPrefix(project, name)))
 	if err != nil {
 		return SmartError(err)
 	}
@@ -39,7 +40,8 @@ func containerLogsGet(d *Daemon, r *http.Request) Response {
 			continue
 		}
 
-		result = append(result, fmt.Sprintf("/%s/containers/%s/logs/%s", version.APIVersion, name, f.Name()))
+		url := fmt.Sprintf("/%s/containers/%s/logs/%s", version.APIVersion, name, f.Name())
+		result = append(result, projectURL(project, url))
 	}
 
 	return SyncResponse(true, result)
@@ -69,6 +71,8 @@ func containerLogGet(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
+	name = projectPrefix(projectParam(r), name)
+
 	if !validLogFileName(file) {
 		return BadRequest(fmt.Errorf("log file name %s not valid", file))
 	}
@@ -89,6 +93,8 @@ func containerLogDelete(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
+	name = projectPrefix(projectParam(r), name)
+
 	if !validLogFileName(file) {
 		return BadRequest(fmt.Errorf("log file name %s not valid", file))
 	}
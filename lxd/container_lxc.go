@@ -24,6 +24,7 @@ import (
 	"gopkg.in/lxc/go-lxc.v2"
 	"gopkg.in/yaml.v2"
 
+	"github.com/lxc/lxd/lxd/cgroup"
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/types"
@@ -273,6 +274,7 @@ func containerLXCCreate(s *state.State, args db.ContainerArgs) (container, error
 		db:           s.DB,
 		id:           args.Id,
 		name:         args.Name,
+		project:      args.Project,
 		description:  args.Description,
 		ephemeral:    args.Ephemeral,
 		architecture: args.Architecture,
@@ -280,6 +282,7 @@ func containerLXCCreate(s *state.State, args db.ContainerArgs) (container, error
 		stateful:     args.Stateful,
 		creationDate: args.CreationDate,
 		lastUsedDate: args.LastUsedDate,
+		expiryDate:   args.ExpiryDate,
 		profiles:     args.Profiles,
 		localConfig:  args.Config,
 		localDevices: args.Devices,
@@ -441,12 +444,14 @@ func containerLXCLoad(s *state.State, args db.ContainerArgs) (container, error)
 		db:           s.DB,
 		id:           args.Id,
 		name:         args.Name,
+		project:      args.Project,
 		description:  args.Description,
 		ephemeral:    args.Ephemeral,
 		architecture: args.Architecture,
 		cType:        args.Ctype,
 		creationDate: args.CreationDate,
 		lastUsedDate: args.LastUsedDate,
+		expiryDate:   args.ExpiryDate,
 		profiles:     args.Profiles,
 		localConfig:  args.Config,
 		localDevices: args.Devices,
@@ -469,9 +474,11 @@ type containerLXC struct {
 	cType        db.ContainerType
 	creationDate time.Time
 	lastUsedDate time.Time
+	expiryDate   time.Time
 	ephemeral    bool
 	id           int
 	name         string
+	project      string
 	description  string
 	stateful     bool
 
@@ -1048,7 +1055,7 @@ func (c *containerLXC) initLXC(config bool) error {
 	}
 
 	// Setup the hostname
-	err = lxcSetConfigItem(cc, "lxc.uts.name", c.Name())
+	err = lxcSetConfigItem(cc, "lxc.uts.name", projectStripPrefix(c.project, c.Name()))
 	if err != nil {
 		return err
 	}
@@ -1097,6 +1104,23 @@ func (c *containerLXC) initLXC(config bool) error {
 		if err != nil {
 			return err
 		}
+
+		// Forward the intercepted system calls to LXD
+		if SeccompContainerNeedsIntercept(c) {
+			if !c.state.OS.SeccompListener {
+				return fmt.Errorf("System call interception isn't supported on this system")
+			}
+
+			err = lxcSetConfigItem(cc, "lxc.seccomp.notify.proxy", fmt.Sprintf("unix:%s", shared.VarPath("seccomp.socket")))
+			if err != nil {
+				return err
+			}
+
+			err = lxcSetConfigItem(cc, "lxc.seccomp.notify.cookie", fmt.Sprintf("%d", c.id))
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Setup idmap
@@ -1125,6 +1149,8 @@ func (c *containerLXC) initLXC(config bool) error {
 		}
 	}
 
+	cg := c.cgroup(cc)
+
 	// Memory limits
 	if c.state.OS.CGroupMemoryController {
 		memory := c.expandedConfig["limits.memory"]
@@ -1155,28 +1181,25 @@ func (c *containerLXC) initLXC(config bool) error {
 			}
 
 			if memoryEnforce == "soft" {
-				err = lxcSetConfigItem(cc, "lxc.cgroup.memory.soft_limit_in_bytes", fmt.Sprintf("%d", valueInt))
+				err = cg.SetMemorySoftLimit(valueInt)
 				if err != nil {
 					return err
 				}
 			} else {
+				err = cg.SetMemoryLimit(valueInt)
+				if err != nil {
+					return err
+				}
+
 				if c.state.OS.CGroupSwapAccounting && (memorySwap == "" || shared.IsTrue(memorySwap)) {
-					err = lxcSetConfigItem(cc, "lxc.cgroup.memory.limit_in_bytes", fmt.Sprintf("%d", valueInt))
-					if err != nil {
-						return err
-					}
-					err = lxcSetConfigItem(cc, "lxc.cgroup.memory.memsw.limit_in_bytes", fmt.Sprintf("%d", valueInt))
-					if err != nil {
-						return err
-					}
-				} else {
-					err = lxcSetConfigItem(cc, "lxc.cgroup.memory.limit_in_bytes", fmt.Sprintf("%d", valueInt))
+					err = cg.SetMemorySwapLimit(valueInt)
 					if err != nil {
 						return err
 					}
 				}
+
 				// Set soft limit to value 10% less than hard limit
-				err = lxcSetConfigItem(cc, "lxc.cgroup.memory.soft_limit_in_bytes", fmt.Sprintf("%.0f", float64(valueInt)*0.9))
+				err = cg.SetMemorySoftLimit(int64(float64(valueInt) * 0.9))
 				if err != nil {
 					return err
 				}
@@ -1185,7 +1208,7 @@ func (c *containerLXC) initLXC(config bool) error {
 
 		// Configure the swappiness
 		if memorySwap != "" && !shared.IsTrue(memorySwap) {
-			err = lxcSetConfigItem(cc, "lxc.cgroup.memory.swappiness", "0")
+			err = c.cgroupDisableSwap(cg)
 			if err != nil {
 				return err
 			}
@@ -1195,8 +1218,10 @@ func (c *containerLXC) initLXC(config bool) error {
 				return err
 			}
 
-			err = lxcSetConfigItem(cc, "lxc.cgroup.memory.swappiness", fmt.Sprintf("%d", 60-10+priority))
-			if err != nil {
+			err = cg.SetMemorySwappiness(int64(60 - 10 + priority))
+			if err == cgroup.ErrUnsupported {
+				logger.Warn("Ignoring unsupported configuration key", log.Ctx{"container": c.name, "key": "limits.memory.swap.priority", "layout": cg.Layout()})
+			} else if err != nil {
 				return err
 			}
 		}
@@ -1212,22 +1237,15 @@ func (c *containerLXC) initLXC(config bool) error {
 			return err
 		}
 
-		if cpuShares != "1024" {
-			err = lxcSetConfigItem(cc, "lxc.cgroup.cpu.shares", cpuShares)
-			if err != nil {
-				return err
-			}
-		}
-
-		if cpuCfsPeriod != "-1" {
-			err = lxcSetConfigItem(cc, "lxc.cgroup.cpu.cfs_period_us", cpuCfsPeriod)
+		if cpuShares != 1024 {
+			err = cg.SetCPUShares(cpuShares)
 			if err != nil {
 				return err
 			}
 		}
 
-		if cpuCfsQuota != "-1" {
-			err = lxcSetConfigItem(cc, "lxc.cgroup.cpu.cfs_quota_us", cpuCfsQuota)
+		if cpuCfsQuota != -1 {
+			err = cg.SetCPUCfsLimit(cpuCfsPeriod, cpuCfsQuota)
 			if err != nil {
 				return err
 			}
@@ -1249,7 +1267,7 @@ func (c *containerLXC) initLXC(config bool) error {
 				priority = 10
 			}
 
-			err = lxcSetConfigItem(cc, "lxc.cgroup.blkio.weight", fmt.Sprintf("%d", priority))
+			err = cg.SetBlkioWeight(int64(priority))
 			if err != nil {
 				return err
 			}
@@ -1275,32 +1293,9 @@ func (c *containerLXC) initLXC(config bool) error {
 			}
 
 			for block, limit := range diskLimits {
-				if limit.readBps > 0 {
-					err = lxcSetConfigItem(cc, "lxc.cgroup.blkio.throttle.read_bps_device", fmt.Sprintf("%s %d", block, limit.readBps))
-					if err != nil {
-						return err
-					}
-				}
-
-				if limit.readIops > 0 {
-					err = lxcSetConfigItem(cc, "lxc.cgroup.blkio.throttle.read_iops_device", fmt.Sprintf("%s %d", block, limit.readIops))
-					if err != nil {
-						return err
-					}
-				}
-
-				if limit.writeBps > 0 {
-					err = lxcSetConfigItem(cc, "lxc.cgroup.blkio.throttle.write_bps_device", fmt.Sprintf("%s %d", block, limit.writeBps))
-					if err != nil {
-						return err
-					}
-				}
-
-				if limit.writeIops > 0 {
-					err = lxcSetConfigItem(cc, "lxc.cgroup.blkio.throttle.write_iops_device", fmt.Sprintf("%s %d", block, limit.writeIops))
-					if err != nil {
-						return err
-					}
+				err = cg.SetBlkioLimit(block, limit.readBps, limit.readIops, limit.writeBps, limit.writeIops, false)
+				if err != nil {
+					return err
 				}
 			}
 		}
@@ -1315,7 +1310,7 @@ func (c *containerLXC) initLXC(config bool) error {
 				return err
 			}
 
-			err = lxcSetConfigItem(cc, "lxc.cgroup.pids.max", fmt.Sprintf("%d", valueInt))
+			err = cg.SetMaxProcesses(valueInt)
 			if err != nil {
 				return err
 			}
@@ -1334,6 +1329,18 @@ func (c *containerLXC) initLXC(config bool) error {
 		}
 	}
 
+	// Setup sysctls
+	for k, v := range c.expandedConfig {
+		if strings.HasPrefix(k, "linux.sysctl.") {
+			sysctlSuffix := strings.TrimPrefix(k, "linux.sysctl.")
+			sysctlKey := fmt.Sprintf("lxc.sysctl.%s", sysctlSuffix)
+			err = lxcSetConfigItem(cc, sysctlKey, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// Setup devices
 	networkidx := 0
 	for _, k := range c.expandedDevices.DeviceNames() {
@@ -1419,8 +1426,8 @@ func (c *containerLXC) initLXC(config bool) error {
 			vethName := ""
 			if m["host_name"] != "" && m["nictype"] != "sriov" {
 				vethName = m["host_name"]
-			} else if shared.IsTrue(m["security.mac_filtering"]) {
-				// We need a known device name for MAC filtering
+			} else if networkFilterEnabled(m) {
+				// We need a known device name for MAC and IP filtering
 				vethName = deviceNextVeth()
 			}
 
@@ -1844,6 +1851,7 @@ func (c *containerLXC) startCommon() (string, error) {
 	c.removeUnixDevices()
 	c.removeDiskDevices()
 	c.removeNetworkFilters()
+	c.removeProxyDevices()
 
 	var usbs []usbDevice
 	var gpus []gpuDevice
@@ -1988,7 +1996,7 @@ func (c *containerLXC) startCommon() (string, error) {
 				}
 			}
 
-			if m["nictype"] == "bridged" && shared.IsTrue(m["security.mac_filtering"]) {
+			if m["nictype"] == "bridged" && networkFilterEnabled(m) {
 				// Read device name from config
 				vethName := ""
 				for i := 0; i < len(c.c.ConfigItem(networkKeyPrefix)); i++ {
@@ -2012,10 +2020,10 @@ func (c *containerLXC) startCommon() (string, error) {
 				}
 
 				if vethName == "" {
-					return "", fmt.Errorf("Failed to find device name for mac_filtering")
+					return "", fmt.Errorf("Failed to find device name for network filtering")
 				}
 
-				err = c.createNetworkFilter(vethName, m["parent"], m["hwaddr"])
+				err = c.createNetworkFilter(vethName, m)
 				if err != nil {
 					return "", err
 				}
@@ -2159,6 +2167,8 @@ func (c *containerLXC) Start(stateful bool) error {
 			return err
 		}
 
+		containerInitTrack(c.id, c.InitPID())
+
 		os.RemoveAll(c.StatePath())
 		c.stateful = false
 
@@ -2234,6 +2244,16 @@ func (c *containerLXC) Start(stateful bool) error {
 		return err
 	}
 
+	containerInitTrack(c.id, c.InitPID())
+
+	// Start the proxy devices
+	err = c.startProxyDevices()
+	if err != nil {
+		logger.Error("Failed starting container", ctxMap)
+		c.Stop(false)
+		return err
+	}
+
 	logger.Info("Started container", ctxMap)
 
 	return nil
@@ -2295,7 +2315,9 @@ func (c *containerLXC) OnStart() error {
 	deviceTaskSchedulerTrigger("container", c.name, "started")
 
 	// Apply network priority
-	if c.expandedConfig["limits.network.priority"] != "" {
+	if c.expandedConfig["limits.network.priority"] != "" && c.state.OS.CGroupLayout == cgroup.Unified {
+		logger.Warn("Ignoring unsupported configuration key", log.Ctx{"container": c.name, "key": "limits.network.priority", "layout": c.state.OS.CGroupLayout})
+	} else if c.expandedConfig["limits.network.priority"] != "" {
 		go func(c *containerLXC) {
 			c.fromHook = false
 			err := c.setNetworkPriority()
@@ -2529,6 +2551,9 @@ func (c *containerLXC) OnStop(target string) error {
 		// Wait for other post-stop actions to be done
 		c.IsRunning()
 
+		// Collect how the container's init exited
+		status, exited := containerInitExitStatus(c.id)
+
 		// Unload the apparmor profile
 		err = AADestroy(c)
 		if err != nil {
@@ -2553,10 +2578,19 @@ func (c *containerLXC) OnStop(target string) error {
 			logger.Error("Unable to remove network filters", log.Ctx{"container": c.Name(), "err": err})
 		}
 
+		// Stop all the proxy devices
+		err = c.removeProxyDevices()
+		if err != nil {
+			logger.Error("Unable to remove proxy devices", log.Ctx{"container": c.Name(), "err": err})
+		}
+
 		// Reboot the container
 		if target == "reboot" {
 			// Start the container again
 			err = c.Start(false)
+			if err == nil && op == nil {
+				eventSendContainerLifecycle(c.name, "container-restarted", nil, nil)
+			}
 			return
 		}
 
@@ -2569,9 +2603,27 @@ func (c *containerLXC) OnStop(target string) error {
 			logger.Error("Failed to set container state", log.Ctx{"container": c.Name(), "err": err})
 		}
 
+		// Report containers which stopped on their own and restart them
+		// if their restart policy says so
+		if op == nil {
+			reason := "stopped"
+			if exited && containerInitCrashed(status) {
+				reason = "failed"
+				logger.Warn("Container crashed", log.Ctx{"container": c.Name(), "status": int(status)})
+				eventSendContainerLifecycle(c.name, "container-crashed", nil, containerExitContext(status))
+			} else {
+				eventSendContainerLifecycle(c.name, "container-shutdown", nil, nil)
+			}
+
+			containerAutoRestart(c.state, c, reason)
+		}
+
 		// Destroy ephemeral containers
 		if c.ephemeral {
 			err = c.Delete()
+			if err == nil {
+				eventSendContainerLifecycle(c.name, "container-deleted", nil, nil)
+			}
 		}
 	}(c, target, op)
 
@@ -2698,9 +2750,10 @@ func (c *containerLXC) Render() (interface{}, interface{}, error) {
 			ExpandedConfig:  c.expandedConfig,
 			ExpandedDevices: c.expandedDevices,
 			LastUsedDate:    c.lastUsedDate,
-			Name:            c.name,
-			Profiles:        c.profiles,
+			Name:            projectStripPrefix(c.project, c.name),
+			Profiles:        c.renderProfiles(),
 			Stateful:        c.stateful,
+			ExpiresAt:       c.expiryDate,
 		}, etag, nil
 	} else {
 		// FIXME: Render shouldn't directly access the go-lxc struct
@@ -2713,7 +2766,7 @@ func (c *containerLXC) Render() (interface{}, interface{}, error) {
 		ct := api.Container{
 			ExpandedConfig:  c.expandedConfig,
 			ExpandedDevices: c.expandedDevices,
-			Name:            c.name,
+			Name:            projectStripPrefix(c.project, c.name),
 			Status:          statusCode.String(),
 			StatusCode:      statusCode,
 		}
@@ -2725,13 +2778,24 @@ func (c *containerLXC) Render() (interface{}, interface{}, error) {
 		ct.Devices = c.localDevices
 		ct.Ephemeral = c.ephemeral
 		ct.LastUsedAt = c.lastUsedDate
-		ct.Profiles = c.profiles
+		ct.Profiles = c.renderProfiles()
 		ct.Stateful = c.stateful
 
 		return &ct, etag, nil
 	}
 }
 
+// renderProfiles returns the names of the container's profiles as known by
+// the users of its project.
+func (c *containerLXC) renderProfiles() []string {
+	profiles := []string{}
+	for _, profile := range c.profiles {
+		profiles = append(profiles, projectStripPrefix(c.project, profile))
+	}
+
+	return profiles
+}
+
 func (c *containerLXC) RenderState() (*api.ContainerState, error) {
 	cState, err := c.getLxcState()
 	if err != nil {
@@ -2753,6 +2817,92 @@ func (c *containerLXC) RenderState() (*api.ContainerState, error) {
 		status.Processes = c.processesState()
 	}
 
+	status.Health, status.Restarts = containerHealthRender(c)
+
+	return &status, nil
+}
+
+// Filesystem usage is expensive to query on some storage drivers, so the
+// usage reported by RenderUsage is only refreshed once a minute.
+const containerDiskUsageCacheTime = time.Minute
+
+type containerDiskUsage struct {
+	disk    map[string]api.ContainerStateDisk
+	updated time.Time
+}
+
+var containerDiskUsageLock sync.Mutex
+var containerDiskUsageCache = map[int]containerDiskUsage{}
+
+// RenderUsage returns the resource usage of a running container. Unlike
+// RenderState, it only reads counters from the host, including the network
+// counters which are taken from the host side of the container interfaces,
+// so it's cheap enough to be collected frequently.
+func (c *containerLXC) RenderUsage() (*api.ContainerState, error) {
+	if !c.IsRunning() {
+		return nil, fmt.Errorf("The container isn't running")
+	}
+
+	status := api.ContainerState{
+		Status:     api.Running.String(),
+		StatusCode: api.Running,
+		CPU:        c.cpuState(),
+		Memory:     c.memoryState(),
+		Pid:        int64(c.InitPID()),
+		Processes:  c.processesState(),
+		Network:    map[string]api.ContainerStateNetwork{},
+	}
+
+	containerDiskUsageLock.Lock()
+	usage, ok := containerDiskUsageCache[c.id]
+	containerDiskUsageLock.Unlock()
+
+	if !ok || time.Since(usage.updated) > containerDiskUsageCacheTime {
+		usage = containerDiskUsage{disk: c.diskState(), updated: time.Now()}
+
+		containerDiskUsageLock.Lock()
+		containerDiskUsageCache[c.id] = usage
+		containerDiskUsageLock.Unlock()
+	}
+
+	status.Disk = usage.disk
+
+	counters := networkGetCounters()
+	for _, k := range c.expandedDevices.DeviceNames() {
+		m := c.expandedDevices[k]
+		if m["type"] != "nic" || !shared.StringInSlice(m["nictype"], []string{"bridged", "p2p"}) {
+			continue
+		}
+
+		name := m["name"]
+		if name == "" {
+			name = c.localConfig[fmt.Sprintf("volatile.%s.name", k)]
+		}
+
+		hostName := m["host_name"]
+		if hostName == "" {
+			hostName = c.localConfig[fmt.Sprintf("volatile.%s.host_name", k)]
+		}
+
+		hostCounters, ok := counters[hostName]
+		if name == "" || !ok {
+			continue
+		}
+
+		// What the host side of the veth pair receives was sent by
+		// the container and the other way around
+		status.Network[name] = api.ContainerStateNetwork{
+			Counters: api.ContainerStateNetworkCounters{
+				BytesReceived:   hostCounters.BytesSent,
+				BytesSent:       hostCounters.BytesReceived,
+				PacketsReceived: hostCounters.PacketsSent,
+				PacketsSent:     hostCounters.PacketsReceived,
+			},
+			HostName: hostName,
+			Type:     "broadcast",
+		}
+	}
+
 	return &status, nil
 }
 
@@ -2916,6 +3066,7 @@ func (c *containerLXC) cleanup() {
 	c.removeUnixDevices()
 	c.removeDiskDevices()
 	c.removeNetworkFilters()
+	c.removeProxyDevices()
 
 	// Remove the security profiles
 	AADeleteProfile(c)
@@ -2959,6 +3110,13 @@ func (c *containerLXC) Delete() error {
 		// Clean things up
 		c.cleanup()
 
+		// Remove all backups, the database entries go with the container
+		err = os.RemoveAll(shared.VarPath("backups", c.Name()))
+		if err != nil {
+			logger.Warn("Failed to delete backups", log.Ctx{"name": c.Name(), "err": err})
+			return err
+		}
+
 		// Delete the container from disk
 		if c.storage != nil {
 			_, poolName, _ := c.storage.GetContainerPoolInfo()
@@ -3009,6 +3167,12 @@ func (c *containerLXC) Delete() error {
 		networkClearLease(c.state, m["parent"], m["hwaddr"])
 	}
 
+	containerHealthForget(c.id)
+
+	containerDiskUsageLock.Lock()
+	delete(containerDiskUsageCache, c.id)
+	containerDiskUsageLock.Unlock()
+
 	logger.Info("Deleted container", ctxMap)
 
 	return nil
@@ -3052,6 +3216,15 @@ func (c *containerLXC) Rename(newName string) error {
 		}
 	}
 
+	// Rename the backups path
+	if !c.IsSnapshot() && shared.PathExists(shared.VarPath("backups", oldName)) {
+		err := os.Rename(shared.VarPath("backups", oldName), shared.VarPath("backups", newName))
+		if err != nil {
+			logger.Error("Failed renaming container", ctxMap)
+			return err
+		}
+	}
+
 	// Rename the storage entry
 	if c.IsSnapshot() {
 		err := c.storage.ContainerSnapshotRename(c, newName)
@@ -3164,6 +3337,75 @@ func (c *containerLXC) CGroupSet(key string, value string) error {
 	return nil
 }
 
+// CGroupIOStats returns the bytes read and written by the container, per
+// block device.
+func (c *containerLXC) CGroupIOStats() (map[string][2]int64, error) {
+	return c.cgroup(nil).GetIOStats()
+}
+
+// cgroup returns the limits and resource usage of the container. Settings are
+// written to the given liblxc configuration when passed, for use before the
+// container is started, or applied to the running container otherwise.
+func (c *containerLXC) cgroup(cc *lxc.Container) *cgroup.CGroup {
+	if cc != nil {
+		return cgroup.New(&cgroupConfigWriter{cc: cc, layout: c.state.OS.CGroupLayout}, c.state.OS.CGroupLayout)
+	}
+
+	return cgroup.New(&cgroupRunningReadWriter{c: c}, c.state.OS.CGroupLayout)
+}
+
+// cgroupDisableSwap prevents the memory of the container from being swapped
+// out, as requested by limits.memory.swap.
+func (c *containerLXC) cgroupDisableSwap(cg *cgroup.CGroup) error {
+	// There's no swappiness with the unified layout, limit swap instead
+	if cg.Layout() == cgroup.Unified {
+		if !c.state.OS.CGroupSwapAccounting {
+			return nil
+		}
+
+		return cg.SetMemorySwapLimit(0)
+	}
+
+	return cg.SetMemorySwappiness(0)
+}
+
+// cgroupUnsupported returns the error reported when a configuration key can't
+// be applied with the cgroup layout of the host.
+func cgroupUnsupported(key string, layout cgroup.Layout) error {
+	return fmt.Errorf("The \"%s\" configuration key isn't supported on hosts using the %s cgroup layout", key, layout)
+}
+
+// cgroupConfigWriter sets cgroup limits through the liblxc configuration.
+type cgroupConfigWriter struct {
+	cc     *lxc.Container
+	layout cgroup.Layout
+}
+
+func (w *cgroupConfigWriter) Get(key string) (string, error) {
+	return "", fmt.Errorf("Can't get cgroups on a stopped container")
+}
+
+func (w *cgroupConfigWriter) Set(key string, value string) error {
+	if w.layout == cgroup.Unified {
+		return lxcSetConfigItem(w.cc, fmt.Sprintf("lxc.cgroup2.%s", key), value)
+	}
+
+	return lxcSetConfigItem(w.cc, fmt.Sprintf("lxc.cgroup.%s", key), value)
+}
+
+// cgroupRunningReadWriter accesses the cgroup of a running container.
+type cgroupRunningReadWriter struct {
+	c *containerLXC
+}
+
+func (rw *cgroupRunningReadWriter) Get(key string) (string, error) {
+	return rw.c.CGroupGet(key)
+}
+
+func (rw *cgroupRunningReadWriter) Set(key string, value string) error {
+	return rw.c.CGroupSet(key, value)
+}
+
 func (c *containerLXC) ConfigKeySet(key string, value string) error {
 	c.localConfig[key] = value
 
@@ -3600,6 +3842,8 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 
 	// Apply the live changes
 	if isRunning {
+		cg := c.cgroup(nil)
+
 		// Live update the container config
 		for _, key := range changedConfig {
 			value := c.expandedConfig[key]
@@ -3638,7 +3882,7 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 					priority = 10
 				}
 
-				err = c.CGroupSet("blkio.weight", fmt.Sprintf("%d", priority))
+				err = cg.SetBlkioWeight(int64(priority))
 				if err != nil {
 					return err
 				}
@@ -3654,8 +3898,9 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 				memorySwap := c.expandedConfig["limits.memory.swap"]
 
 				// Parse memory
+				memoryInt := int64(-1)
 				if memory == "" {
-					memory = "-1"
+					memoryInt = -1
 				} else if strings.HasSuffix(memory, "%") {
 					percent, err := strconv.ParseInt(strings.TrimSuffix(memory, "%"), 10, 64)
 					if err != nil {
@@ -3667,64 +3912,53 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 						return err
 					}
 
-					memory = fmt.Sprintf("%d", int64((memoryTotal/100)*percent))
+					memoryInt = int64((memoryTotal / 100) * percent)
 				} else {
-					valueInt, err := shared.ParseByteSizeString(memory)
+					memoryInt, err = shared.ParseByteSizeString(memory)
 					if err != nil {
 						return err
 					}
-					memory = fmt.Sprintf("%d", valueInt)
 				}
 
 				// Store the old values for revert
-				oldMemswLimit := ""
+				oldSwapLimit, errSwapLimit := int64(-1), fmt.Errorf("No swap accounting")
 				if c.state.OS.CGroupSwapAccounting {
-					oldMemswLimit, err = c.CGroupGet("memory.memsw.limit_in_bytes")
-					if err != nil {
-						oldMemswLimit = ""
-					}
-				}
-
-				oldLimit, err := c.CGroupGet("memory.limit_in_bytes")
-				if err != nil {
-					oldLimit = ""
+					oldSwapLimit, errSwapLimit = cg.GetMemorySwapLimit()
 				}
 
-				oldSoftLimit, err := c.CGroupGet("memory.soft_limit_in_bytes")
-				if err != nil {
-					oldSoftLimit = ""
-				}
+				oldLimit, errLimit := cg.GetMemoryLimit()
+				oldSoftLimit, errSoftLimit := cg.GetMemorySoftLimit()
 
 				revertMemory := func() {
-					if oldSoftLimit != "" {
-						c.CGroupSet("memory.soft_limit_in_bytes", oldSoftLimit)
+					if errSoftLimit == nil {
+						cg.SetMemorySoftLimit(oldSoftLimit)
 					}
 
-					if oldLimit != "" {
-						c.CGroupSet("memory.limit_in_bytes", oldLimit)
+					if errLimit == nil {
+						cg.SetMemoryLimit(oldLimit)
 					}
 
-					if oldMemswLimit != "" {
-						c.CGroupSet("memory.memsw.limit_in_bytes", oldMemswLimit)
+					if errSwapLimit == nil {
+						cg.SetMemorySwapLimit(oldSwapLimit)
 					}
 				}
 
 				// Reset everything
 				if c.state.OS.CGroupSwapAccounting {
-					err = c.CGroupSet("memory.memsw.limit_in_bytes", "-1")
+					err = cg.SetMemorySwapLimit(-1)
 					if err != nil {
 						revertMemory()
 						return err
 					}
 				}
 
-				err = c.CGroupSet("memory.limit_in_bytes", "-1")
+				err = cg.SetMemoryLimit(-1)
 				if err != nil {
 					revertMemory()
 					return err
 				}
 
-				err = c.CGroupSet("memory.soft_limit_in_bytes", "-1")
+				err = cg.SetMemorySoftLimit(-1)
 				if err != nil {
 					revertMemory()
 					return err
@@ -3733,26 +3967,20 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 				// Set the new values
 				if memoryEnforce == "soft" {
 					// Set new limit
-					err = c.CGroupSet("memory.soft_limit_in_bytes", memory)
+					err = cg.SetMemorySoftLimit(memoryInt)
 					if err != nil {
 						revertMemory()
 						return err
 					}
 				} else {
-					if c.state.OS.CGroupSwapAccounting && (memorySwap == "" || shared.IsTrue(memorySwap)) {
-						err = c.CGroupSet("memory.limit_in_bytes", memory)
-						if err != nil {
-							revertMemory()
-							return err
-						}
+					err = cg.SetMemoryLimit(memoryInt)
+					if err != nil {
+						revertMemory()
+						return err
+					}
 
-						err = c.CGroupSet("memory.memsw.limit_in_bytes", memory)
-						if err != nil {
-							revertMemory()
-							return err
-						}
-					} else {
-						err = c.CGroupSet("memory.limit_in_bytes", memory)
+					if c.state.OS.CGroupSwapAccounting && (memorySwap == "" || shared.IsTrue(memorySwap)) {
+						err = cg.SetMemorySwapLimit(memoryInt)
 						if err != nil {
 							revertMemory()
 							return err
@@ -3760,28 +3988,33 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 					}
 
 					// Set soft limit to value 10% less than hard limit
-					valueInt, err := strconv.ParseInt(memory, 10, 64)
-					if err != nil {
-						revertMemory()
-						return err
+					softLimit := int64(-1)
+					if memoryInt >= 0 {
+						softLimit = int64(float64(memoryInt) * 0.9)
 					}
 
-					err = c.CGroupSet("memory.soft_limit_in_bytes", fmt.Sprintf("%.0f", float64(valueInt)*0.9))
+					err = cg.SetMemorySoftLimit(softLimit)
 					if err != nil {
 						revertMemory()
 						return err
 					}
 				}
 
-				// Configure the swappiness
-				if key == "limits.memory.swap" || key == "limits.memory.swap.priority" {
+				// Configure the swappiness. Swap is disabled through the
+				// swap limit with the unified layout, so it needs to be
+				// set again after the reset above.
+				if key == "limits.memory.swap" || key == "limits.memory.swap.priority" || cg.Layout() == cgroup.Unified {
 					memorySwap := c.expandedConfig["limits.memory.swap"]
 					memorySwapPriority := c.expandedConfig["limits.memory.swap.priority"]
 					if memorySwap != "" && !shared.IsTrue(memorySwap) {
-						err = c.CGroupSet("memory.swappiness", "0")
+						err = c.cgroupDisableSwap(cg)
 						if err != nil {
 							return err
 						}
+					} else if cg.Layout() == cgroup.Unified {
+						if key == "limits.memory.swap.priority" && memorySwapPriority != "" {
+							return cgroupUnsupported(key, cg.Layout())
+						}
 					} else {
 						priority := 0
 						if memorySwapPriority != "" {
@@ -3791,13 +4024,17 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 							}
 						}
 
-						err = c.CGroupSet("memory.swappiness", fmt.Sprintf("%d", 60-10+priority))
+						err = cg.SetMemorySwappiness(int64(60 - 10 + priority))
 						if err != nil {
 							return err
 						}
 					}
 				}
 			} else if key == "limits.network.priority" {
+				if cg.Layout() == cgroup.Unified && value != "" {
+					return cgroupUnsupported(key, cg.Layout())
+				}
+
 				err := c.setNetworkPriority()
 				if err != nil {
 					return err
@@ -3817,17 +4054,18 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 					return err
 				}
 
-				err = c.CGroupSet("cpu.shares", cpuShares)
+				err = cg.SetCPUShares(cpuShares)
 				if err != nil {
 					return err
 				}
 
-				err = c.CGroupSet("cpu.cfs_period_us", cpuCfsPeriod)
+				err = cg.SetCPUCfsLimit(cpuCfsPeriod, cpuCfsQuota)
 				if err != nil {
 					return err
 				}
-
-				err = c.CGroupSet("cpu.cfs_quota_us", cpuCfsQuota)
+			} else if strings.HasPrefix(key, "linux.sysctl.") && value != "" {
+				// Unset sysctls keep their value until the container restarts
+				err = c.setSysctl(strings.TrimPrefix(key, "linux.sysctl."), value)
 				if err != nil {
 					return err
 				}
@@ -3837,7 +4075,7 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 				}
 
 				if value == "" {
-					err = c.CGroupSet("pids.max", "max")
+					err = cg.SetMaxProcesses(-1)
 					if err != nil {
 						return err
 					}
@@ -3847,7 +4085,7 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 						return err
 					}
 
-					err = c.CGroupSet("pids.max", fmt.Sprintf("%d", valueInt))
+					err = cg.SetMaxProcesses(valueInt)
 					if err != nil {
 						return err
 					}
@@ -3866,6 +4104,11 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 				if err != nil {
 					return err
 				}
+			} else if m["type"] == "proxy" {
+				err = c.removeProxyDevice(k)
+				if err != nil {
+					return err
+				}
 			} else if m["type"] == "disk" && m["path"] != "/" {
 				err = c.removeDiskDevice(k, m)
 				if err != nil {
@@ -3961,6 +4204,11 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 				if err != nil {
 					return err
 				}
+			} else if m["type"] == "proxy" {
+				err = c.insertProxyDevice(k, m)
+				if err != nil {
+					return err
+				}
 			} else if m["type"] == "disk" && m["path"] != "/" {
 				diskDevices[k] = m
 			} else if m["type"] == "nic" {
@@ -4055,6 +4303,17 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 		for k, m := range updateDevices {
 			if m["type"] == "disk" {
 				updateDiskLimit = true
+			} else if m["type"] == "proxy" {
+				// Restart the proxy with its new configuration
+				err = c.removeProxyDevice(k)
+				if err != nil {
+					return err
+				}
+
+				err = c.insertProxyDevice(k, m)
+				if err != nil {
+					return err
+				}
 			} else if m["type"] == "nic" {
 				needsUpdate := false
 				for _, v := range containerNetworkLimitKeys {
@@ -4071,6 +4330,14 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 						return err
 					}
 				}
+
+				if m["nictype"] == "bridged" && networkFilterEnabled(m) && (shared.StringInSlice("ipv4.address", updateDiff) || shared.StringInSlice("ipv6.address", updateDiff)) {
+					// Refresh the filters with the new addresses
+					err = c.updateNetworkFilter(k, m)
+					if err != nil {
+						return err
+					}
+				}
 			}
 		}
 
@@ -4082,22 +4349,7 @@ func (c *containerLXC) Update(args db.ContainerArgs, userRequested bool) error {
 			}
 
 			for block, limit := range diskLimits {
-				err = c.CGroupSet("blkio.throttle.read_bps_device", fmt.Sprintf("%s %d", block, limit.readBps))
-				if err != nil {
-					return err
-				}
-
-				err = c.CGroupSet("blkio.throttle.read_iops_device", fmt.Sprintf("%s %d", block, limit.readIops))
-				if err != nil {
-					return err
-				}
-
-				err = c.CGroupSet("blkio.throttle.write_bps_device", fmt.Sprintf("%s %d", block, limit.writeBps))
-				if err != nil {
-					return err
-				}
-
-				err = c.CGroupSet("blkio.throttle.write_iops_device", fmt.Sprintf("%s %d", block, limit.writeIops))
+				err = c.cgroup(nil).SetBlkioLimit(block, limit.readBps, limit.readIops, limit.writeBps, limit.writeIops, true)
 				if err != nil {
 					return err
 				}
@@ -5121,14 +5373,14 @@ func (c *containerLXC) ConsoleLog(opts lxc.ConsoleLogOptions) (string, error) {
 	return string(msg), nil
 }
 
-func (c *containerLXC) Exec(command []string, env map[string]string, stdin *os.File, stdout *os.File, stderr *os.File, wait bool) (*exec.Cmd, int, int, error) {
+func (c *containerLXC) Exec(command []string, env map[string]string, stdin *os.File, stdout *os.File, stderr *os.File, wait bool, cwd string, uid uint32, gid uint32) (*exec.Cmd, int, int, error) {
 	envSlice := []string{}
 
 	for k, v := range env {
 		envSlice = append(envSlice, fmt.Sprintf("%s=%s", k, v))
 	}
 
-	args := []string{c.state.OS.ExecPath, "forkexec", c.name, c.state.OS.LxcPath, filepath.Join(c.LogPath(), "lxc.conf")}
+	args := []string{c.state.OS.ExecPath, "forkexec", c.name, c.state.OS.LxcPath, filepath.Join(c.LogPath(), "lxc.conf"), cwd, fmt.Sprintf("%d", uid), fmt.Sprintf("%d", gid)}
 
 	args = append(args, "--")
 	args = append(args, "env")
@@ -5200,19 +5452,13 @@ func (c *containerLXC) cpuState() api.ContainerStateCPU {
 	}
 
 	// CPU usage in seconds
-	value, err := c.CGroupGet("cpuacct.usage")
-	if err != nil {
-		cpu.Usage = -1
-		return cpu
-	}
-
-	valueInt, err := strconv.ParseInt(value, 10, 64)
+	value, err := c.cgroup(nil).GetCPUAcctUsage()
 	if err != nil {
 		cpu.Usage = -1
 		return cpu
 	}
 
-	cpu.Usage = valueInt
+	cpu.Usage = value
 
 	return cpu
 }
@@ -5254,36 +5500,34 @@ func (c *containerLXC) memoryState() api.ContainerStateMemory {
 		return memory
 	}
 
+	cg := c.cgroup(nil)
+
 	// Memory in bytes
-	value, err := c.CGroupGet("memory.usage_in_bytes")
-	valueInt, err1 := strconv.ParseInt(value, 10, 64)
-	if err == nil && err1 == nil {
-		memory.Usage = valueInt
+	value, err := cg.GetMemoryUsage()
+	if err == nil {
+		memory.Usage = value
 	}
 
 	// Memory peak in bytes
-	value, err = c.CGroupGet("memory.max_usage_in_bytes")
-	valueInt, err1 = strconv.ParseInt(value, 10, 64)
-	if err == nil && err1 == nil {
-		memory.UsagePeak = valueInt
+	value, err = cg.GetMemoryMaxUsage()
+	if err == nil {
+		memory.UsagePeak = value
 	}
 
 	if c.state.OS.CGroupSwapAccounting {
 		// Swap in bytes
 		if memory.Usage > 0 {
-			value, err := c.CGroupGet("memory.memsw.usage_in_bytes")
-			valueInt, err1 := strconv.ParseInt(value, 10, 64)
-			if err == nil && err1 == nil {
-				memory.SwapUsage = valueInt - memory.Usage
+			value, err := cg.GetMemorySwapUsage()
+			if err == nil {
+				memory.SwapUsage = value
 			}
 		}
 
 		// Swap peak in bytes
 		if memory.UsagePeak > 0 {
-			value, err = c.CGroupGet("memory.memsw.max_usage_in_bytes")
-			valueInt, err1 = strconv.ParseInt(value, 10, 64)
-			if err == nil && err1 == nil {
-				memory.SwapUsagePeak = valueInt - memory.UsagePeak
+			value, err = cg.GetMemorySwapMaxUsage()
+			if err == nil {
+				memory.SwapUsagePeak = value
 			}
 		}
 	}
@@ -5336,17 +5580,12 @@ func (c *containerLXC) processesState() int64 {
 	}
 
 	if c.state.OS.CGroupPidsController {
-		value, err := c.CGroupGet("pids.current")
-		if err != nil {
-			return -1
-		}
-
-		valueInt, err := strconv.ParseInt(value, 10, 64)
+		value, err := c.cgroup(nil).GetProcessesUsage()
 		if err != nil {
 			return -1
 		}
 
-		return valueInt
+		return value
 	}
 
 	pids := []int64{int64(pid)}
@@ -5596,6 +5835,36 @@ func (c *containerLXC) removeMount(mount string) error {
 	return nil
 }
 
+func (c *containerLXC) setSysctl(sysctl string, value string) error {
+	// Get the init PID
+	pid := c.InitPID()
+	if pid == -1 {
+		// Container isn't running
+		return fmt.Errorf("Can't set sysctls in a stopped container")
+	}
+
+	path, err := shared.SysctlPath(sysctl)
+	if err != nil {
+		return err
+	}
+
+	// Write the value from within the container's namespaces
+	pidStr := fmt.Sprintf("%d", pid)
+	out, err := shared.RunCommand(c.state.OS.ExecPath, "forksysctl", pidStr, path, value)
+
+	if out != "" {
+		for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+			logger.Debugf("forksysctl: %s", line)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed to set sysctl %s: %v", sysctl, err)
+	}
+
+	return nil
+}
+
 // Check if the unix device already exists.
 func (c *containerLXC) deviceExists(path string) bool {
 	tgtPath := strings.TrimPrefix(path, "/")
@@ -5969,6 +6238,125 @@ func (c *containerLXC) removeUnixDevices() error {
 	return nil
 }
 
+// Proxy device handling
+func (c *containerLXC) insertProxyDevice(name string, m types.Device) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("Can't add proxy device to stopped container")
+	}
+
+	// Figure out which side of the proxy lives in the container
+	listenPid := os.Getpid()
+	connectPid := c.InitPID()
+	if m["bind"] == "container" {
+		listenPid, connectPid = connectPid, listenPid
+	}
+
+	// Create the devices directory if missing
+	if !shared.PathExists(c.DevicesPath()) {
+		err := os.Mkdir(c.DevicesPath(), 0711)
+		if err != nil {
+			return err
+		}
+	}
+
+	devName := fmt.Sprintf("proxy.%s", strings.Replace(name, "/", "-", -1))
+	pidPath := filepath.Join(c.DevicesPath(), devName)
+	logPath := filepath.Join(c.LogPath(), fmt.Sprintf("%s.log", devName))
+
+	_, err := shared.RunCommand(
+		c.state.OS.ExecPath,
+		"forkproxy",
+		fmt.Sprintf("%d", listenPid),
+		m["listen"],
+		fmt.Sprintf("%d", connectPid),
+		m["connect"],
+		logPath,
+		pidPath)
+	if err != nil {
+		return fmt.Errorf("Failed to start proxy device '%s': %s", name, err)
+	}
+
+	return nil
+}
+
+func (c *containerLXC) removeProxyDevice(name string) error {
+	devName := fmt.Sprintf("proxy.%s", strings.Replace(name, "/", "-", -1))
+	return c.killProxyDevice(filepath.Join(c.DevicesPath(), devName))
+}
+
+func (c *containerLXC) killProxyDevice(pidPath string) error {
+	if !shared.PathExists(pidPath) {
+		return nil
+	}
+
+	content, err := ioutil.ReadFile(pidPath)
+	if err != nil {
+		return err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return err
+	}
+
+	// Only kill the process if it's still the proxy we started
+	cmdline, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err == nil && strings.Contains(string(cmdline), "forkproxy") {
+		err = syscall.Kill(pid, syscall.SIGKILL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(pidPath)
+}
+
+func (c *containerLXC) startProxyDevices() error {
+	for _, k := range c.expandedDevices.DeviceNames() {
+		m := c.expandedDevices[k]
+		if m["type"] != "proxy" {
+			continue
+		}
+
+		err := c.insertProxyDevice(k, m)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *containerLXC) removeProxyDevices() error {
+	// Check that we indeed have devices to remove
+	if !shared.PathExists(c.DevicesPath()) {
+		return nil
+	}
+
+	// Load the directory listing
+	dents, err := ioutil.ReadDir(c.DevicesPath())
+	if err != nil {
+		return err
+	}
+
+	// Go through all the proxy devices
+	for _, f := range dents {
+		// Skip non-proxy devices
+		if !strings.HasPrefix(f.Name(), "proxy.") {
+			continue
+		}
+
+		// Stop the proxy
+		pidPath := filepath.Join(c.DevicesPath(), f.Name())
+		err := c.killProxyDevice(pidPath)
+		if err != nil {
+			logger.Error("failed stopping proxy device", log.Ctx{"err": err, "path": pidPath})
+		}
+	}
+
+	return nil
+}
+
 // Network device handling
 func (c *containerLXC) createNetworkDevice(name string, m types.Device) (string, error) {
 	var dev, n1 string
@@ -6064,8 +6452,8 @@ func (c *containerLXC) createNetworkDevice(name string, m types.Device) (string,
 	}
 
 	// Set the filter
-	if m["nictype"] == "bridged" && shared.IsTrue(m["security.mac_filtering"]) {
-		err = c.createNetworkFilter(dev, m["parent"], m["hwaddr"])
+	if m["nictype"] == "bridged" && networkFilterEnabled(m) {
+		err = c.createNetworkFilter(dev, m)
 		if err != nil {
 			return "", err
 		}
@@ -6353,55 +6741,78 @@ func (c *containerLXC) fillNetworkDevice(name string, m types.Device) (types.Dev
 		newDevice["host_name"] = c.localConfig[configKey]
 	}
 
-	return newDevice, nil
-}
+	// Fill in the addresses to restrict the container to for IP filtering
+	for _, family := range []string{"ipv4", "ipv6"} {
+		if m["nictype"] != "bridged" || !shared.IsTrue(m[fmt.Sprintf("security.%s_filtering", family)]) {
+			continue
+		}
 
-func (c *containerLXC) createNetworkFilter(name string, bridge string, hwaddr string) error {
-	_, err := shared.RunCommand("ebtables", "-A", "FORWARD", "-s", "!", hwaddr, "-i", name, "-o", bridge, "-j", "DROP")
-	if err != nil {
-		return err
+		addressKey := fmt.Sprintf("%s.address", family)
+		if m[addressKey] != "" {
+			continue
+		}
+
+		configKey := fmt.Sprintf("volatile.%s.%s", name, addressKey)
+		volatileAddress := c.localConfig[configKey]
+		if volatileAddress == "" {
+			// Reserve an address on the network
+			volatileAddress, err = networkAllocateFilterAddress(c.state, m["parent"], family, newDevice["hwaddr"])
+			if err != nil {
+				return nil, err
+			}
+
+			if volatileAddress == "" {
+				continue
+			}
+
+			// Update the database
+			err = updateKey(configKey, volatileAddress)
+			if err != nil {
+				return nil, err
+			}
+
+			c.localConfig[configKey] = volatileAddress
+			c.expandedConfig[configKey] = volatileAddress
+		}
+		newDevice[addressKey] = volatileAddress
 	}
 
-	_, err = shared.RunCommand("ebtables", "-A", "INPUT", "-s", "!", hwaddr, "-i", name, "-j", "DROP")
-	if err != nil {
-		return err
+	return newDevice, nil
+}
+
+func (c *containerLXC) createNetworkFilter(name string, m types.Device) error {
+	for _, rule := range networkEbtablesFilterRules(name, m) {
+		_, err := shared.RunCommand("ebtables", rule...)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (c *containerLXC) removeNetworkFilter(hwaddr string, bridge string) error {
-	out, err := shared.RunCommand("ebtables", "-L", "--Lmac2", "--Lx")
+func (c *containerLXC) updateNetworkFilter(name string, m types.Device) error {
+	m, err := c.fillNetworkDevice(name, m)
 	if err != nil {
 		return err
 	}
 
-	for _, line := range strings.Split(out, "\n") {
-		line = strings.TrimSpace(line)
-		fields := strings.Fields(line)
+	// Look for the host side interface name
+	veth := c.getHostInterface(m["name"])
+	if veth == "" {
+		return fmt.Errorf("Failed to find device name for network filtering")
+	}
 
-		if len(fields) == 12 {
-			match := []string{"ebtables", "-t", "filter", "-A", "INPUT", "-s", "!", hwaddr, "-i", fields[9], "-j", "DROP"}
-			if reflect.DeepEqual(fields, match) {
-				fields[3] = "-D"
-				_, err = shared.RunCommand(fields[0], fields[1:]...)
-				if err != nil {
-					return err
-				}
-			}
-		} else if len(fields) == 14 {
-			match := []string{"ebtables", "-t", "filter", "-A", "FORWARD", "-s", "!", hwaddr, "-i", fields[9], "-o", bridge, "-j", "DROP"}
-			if reflect.DeepEqual(fields, match) {
-				fields[3] = "-D"
-				_, err = shared.RunCommand(fields[0], fields[1:]...)
-				if err != nil {
-					return err
-				}
-			}
-		}
+	err = c.removeNetworkFilter(m["hwaddr"])
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return c.createNetworkFilter(veth, m)
+}
+
+func (c *containerLXC) removeNetworkFilter(hwaddr string) error {
+	return networkEbtablesFilterClear(hwaddr)
 }
 
 func (c *containerLXC) removeNetworkFilters() error {
@@ -6415,7 +6826,7 @@ func (c *containerLXC) removeNetworkFilters() error {
 			return err
 		}
 
-		err = c.removeNetworkFilter(m["hwaddr"], m["parent"])
+		err = c.removeNetworkFilter(m["hwaddr"])
 		if err != nil {
 			return err
 		}
@@ -6508,7 +6919,7 @@ func (c *containerLXC) removeNetworkDevice(name string, m types.Device) error {
 
 	// Remove any filter
 	if m["nictype"] == "bridged" {
-		err = c.removeNetworkFilter(m["hwaddr"], m["parent"])
+		err = c.removeNetworkFilter(m["hwaddr"])
 		if err != nil {
 			return err
 		}
@@ -6961,7 +7372,7 @@ func (c *containerLXC) setNetworkPriority() error {
 	success := false
 	var last_error error
 	for _, netif := range netifs {
-		err = c.CGroupSet("net_prio.ifpriomap", fmt.Sprintf("%s %d", netif.Name, networkInt))
+		err = c.cgroup(nil).SetNetIfPrio(netif.Name, int64(networkInt))
 		if err == nil {
 			success = true
 		} else {
@@ -7141,6 +7552,9 @@ func (c *containerLXC) CreationDate() time.Time {
 func (c *containerLXC) LastUsedDate() time.Time {
 	return c.lastUsedDate
 }
+func (c *containerLXC) ExpiryDate() time.Time {
+	return c.expiryDate
+}
 func (c *containerLXC) ExpandedConfig() map[string]string {
 	return c.expandedConfig
 }
@@ -7230,6 +7644,10 @@ func (c *containerLXC) Name() string {
 	return c.name
 }
 
+func (c *containerLXC) Project() string {
+	return c.project
+}
+
 func (c *containerLXC) Description() string {
 	return c.description
 }
@@ -18,7 +18,7 @@ import (
 
 func containerPatch(d *Daemon, r *http.Request) Response {
 	// Get the container
-	name := mux.Vars(r)["name"]
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
 	c, err := containerLoadByName(d.State(), name)
 	if err != nil {
 		return NotFound
@@ -74,6 +74,11 @@ func containerPatch(d *Daemon, r *http.Request) Response {
 	// Check if profiles was passed
 	if req.Profiles == nil {
 		req.Profiles = c.Profiles()
+	} else {
+		req.Profiles, err = projectProfileNames(d.db, c.Project(), req.Profiles)
+		if err != nil {
+			return SmartError(err)
+		}
 	}
 
 	// Check if config was passed
@@ -114,5 +119,7 @@ func containerPatch(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendContainerLifecycle(name, "container-updated", d.requestor(r), nil)
+
 	return EmptySyncResponse
 }
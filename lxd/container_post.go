@@ -13,7 +13,7 @@ import (
 )
 
 func containerPost(d *Daemon, r *http.Request) Response {
-	name := mux.Vars(r)["name"]
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
 	c, err := containerLoadByName(d.State(), name)
 	if err != nil {
 		return SmartError(err)
@@ -62,7 +62,7 @@ func containerPost(d *Daemon, r *http.Request) Response {
 				return InternalError(err)
 			}
 
-			op, err := operationCreate(operationClassTask, resources, nil, ws.Do, nil, nil)
+			op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, ws.Do, nil, nil)
 			if err != nil {
 				return InternalError(err)
 			}
@@ -71,7 +71,7 @@ func containerPost(d *Daemon, r *http.Request) Response {
 		}
 
 		// Pull mode
-		op, err := operationCreate(operationClassWebsocket, resources, ws.Metadata(), ws.Do, nil, ws.Connect)
+		op, err := operationCreate(projectParam(r), operationClassWebsocket, resources, ws.Metadata(), ws.Do, nil, ws.Connect)
 		if err != nil {
 			return InternalError(err)
 		}
@@ -80,19 +80,28 @@ func containerPost(d *Daemon, r *http.Request) Response {
 	}
 
 	// Check that the name isn't already in use
-	id, _ := d.db.ContainerId(req.Name)
+	newName := projectPrefix(c.Project(), req.Name)
+	id, _ := d.db.ContainerId(newName)
 	if id > 0 {
 		return Conflict
 	}
 
+	requestor := d.requestor(r)
 	run := func(*operation) error {
-		return c.Rename(req.Name)
+		err := c.Rename(newName)
+		if err != nil {
+			return err
+		}
+
+		eventSendContainerLifecycle(newName, "container-renamed", requestor,
+			map[string]interface{}{"old_name": projectStripPrefix(c.Project(), name)})
+		return nil
 	}
 
 	resources := map[string][]string{}
 	resources["containers"] = []string{name}
 
-	op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, run, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -22,7 +22,7 @@ import (
  */
 func containerPut(d *Daemon, r *http.Request) Response {
 	// Get the container
-	name := mux.Vars(r)["name"]
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
 	c, err := containerLoadByName(d.State(), name)
 	if err != nil {
 		return NotFound
@@ -45,6 +45,13 @@ func containerPut(d *Daemon, r *http.Request) Response {
 		architecture = 0
 	}
 
+	profiles, err := projectProfileNames(d.db, c.Project(), configRaw.Profiles)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	requestor := d.requestor(r)
+
 	var do func(*operation) error
 	if configRaw.Restore == "" {
 		// Update container configuration
@@ -55,7 +62,7 @@ func containerPut(d *Daemon, r *http.Request) Response {
 				Config:       configRaw.Config,
 				Devices:      configRaw.Devices,
 				Ephemeral:    configRaw.Ephemeral,
-				Profiles:     configRaw.Profiles}
+				Profiles:     profiles}
 
 			// FIXME: should set to true when not migrating
 			err = c.Update(args, false)
@@ -63,19 +70,32 @@ func containerPut(d *Daemon, r *http.Request) Response {
 				return err
 			}
 
+			eventSendContainerLifecycle(name, "container-updated", requestor, nil)
 			return nil
 		}
 	} else {
 		// Snapshot Restore
+		restore := configRaw.Restore
+		if shared.IsSnapshot(restore) {
+			restore = projectPrefix(c.Project(), restore)
+		}
+
 		do = func(op *operation) error {
-			return containerSnapRestore(d.State(), name, configRaw.Restore, configRaw.Stateful)
+			err := containerSnapRestore(d.State(), name, restore, configRaw.Stateful)
+			if err != nil {
+				return err
+			}
+
+			eventSendContainerLifecycle(name, "container-restored", requestor,
+				map[string]interface{}{"snapshot": configRaw.Restore})
+			return nil
 		}
 	}
 
 	resources := map[string][]string{}
 	resources["containers"] = []string{name}
 
-	op, err := operationCreate(operationClassTask, resources, nil, do, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, do, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/osarch"
+)
+
+func containerRebuildPost(d *Daemon, r *http.Request) Response {
+	project := projectParam(r)
+	name := projectPrefix(project, mux.Vars(r)["name"])
+
+	req := api.ContainerRebuildPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	c, err := containerLoadByName(d.State(), name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if c.IsRunning() {
+		return BadRequest(fmt.Errorf("The container must be stopped to be rebuilt"))
+	}
+
+	imageProject, err := projectImageScope(d.db, project)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	hash := ""
+	switch req.Source.Type {
+	case "image":
+		var resp Response
+		hash, resp = containerImageHash(d, imageProject, req.Source)
+		if resp != nil {
+			return resp
+		}
+	case "none":
+	default:
+		return BadRequest(fmt.Errorf("Unknown source type %s", req.Source.Type))
+	}
+
+	requestor := d.requestor(r)
+	run := func(op *operation) error {
+		fingerprint := ""
+		if hash != "" {
+			info, err := containerImageFetch(d, op, imageProject, req.Source, hash)
+			if err != nil {
+				return err
+			}
+
+			architecture, err := osarch.ArchitectureId(info.Architecture)
+			if err != nil {
+				return err
+			}
+
+			if architecture != c.Architecture() {
+				return fmt.Errorf("The image architecture doesn't match the container's")
+			}
+
+			fingerprint = info.Fingerprint
+		}
+
+		// The container may have been started in the meantime
+		if c.IsRunning() {
+			return fmt.Errorf("The container must be stopped to be rebuilt")
+		}
+
+		err := containerRebuild(d.State(), c, fingerprint)
+		if err != nil {
+			return err
+		}
+
+		eventSendContainerLifecycle(name, "container-rebuilt", requestor, nil)
+		return nil
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{name}
+
+	op, err := operationCreate(project, operationClassTask, resources, nil, run, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+// containerRebuild replaces the root filesystem of a stopped container with
+// the content of the given image, or with an empty one if no fingerprint is
+// passed. The configuration, devices, profiles and snapshots of the container
+// are kept, only its "image.*" keys are refreshed.
+func containerRebuild(s *state.State, c container, fingerprint string) error {
+	snapshots, err := c.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	// Some storage drivers keep the snapshots as part of the container
+	// volume, so it's only re-created when there are none. Otherwise the
+	// content of the volume is replaced instead.
+	inPlace := len(snapshots) > 0
+	if inPlace {
+		err = containerRebuildRootfs(s, c, fingerprint)
+	} else {
+		err = containerRebuildVolume(c, fingerprint)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to rebuild the root filesystem: %v", err)
+	}
+
+	config := map[string]string{}
+	for key, value := range c.LocalConfig() {
+		if strings.HasPrefix(key, "image.") {
+			continue
+		}
+
+		config[key] = value
+	}
+
+	delete(config, "volatile.base_image")
+	delete(config, "volatile.apply_template")
+
+	if fingerprint != "" {
+		_, img, err := s.DB.ImageGet(fingerprint, false, false)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range img.Properties {
+			config[fmt.Sprintf("image.%s", key)] = value
+		}
+
+		config["volatile.base_image"] = fingerprint
+		config["volatile.apply_template"] = "create"
+
+		err = s.DB.ImageLastAccessUpdate(fingerprint, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+	}
+
+	// A freshly unpacked root filesystem isn't shifted yet, have it
+	// remapped on next start.
+	if inPlace && !c.IsPrivileged() {
+		config["volatile.last_state.idmap"] = "[]"
+	}
+
+	args := db.ContainerArgs{
+		Architecture: c.Architecture(),
+		Config:       config,
+		Description:  c.Description(),
+		Devices:      c.LocalDevices(),
+		Ephemeral:    c.IsEphemeral(),
+		Profiles:     c.Profiles(),
+	}
+
+	err = c.Update(args, false)
+	if err != nil {
+		return err
+	}
+
+	// Apply the quota and write the backup file again
+	return containerConfigureInternal(c)
+}
+
+// containerRebuildVolume re-creates the storage volume of a container. If
+// that fails, the container is left with an empty volume.
+func containerRebuildVolume(c container, fingerprint string) error {
+	err := c.Storage().ContainerDelete(c)
+	if err != nil {
+		return err
+	}
+
+	if fingerprint == "" {
+		return c.Storage().ContainerCreate(c)
+	}
+
+	err = c.Storage().ContainerCreateFromImage(c, fingerprint)
+	if err != nil {
+		// Clear whatever got created before failing
+		c.Storage().ContainerDelete(c)
+
+		createErr := c.Storage().ContainerCreate(c)
+		if createErr != nil {
+			return fmt.Errorf("%v, the container has no storage volume anymore and needs repair: %v", err, createErr)
+		}
+
+		return fmt.Errorf("%v, the container was left with an empty root filesystem and needs repair", err)
+	}
+
+	return nil
+}
+
+// containerRebuildRootfs replaces the content of the storage volume of a
+// container, leaving the volume itself alone.
+func containerRebuildRootfs(s *state.State, c container, fingerprint string) error {
+	ourStart, err := c.StorageStart()
+	if err != nil {
+		return err
+	}
+
+	if ourStart {
+		defer c.StorageStop()
+	}
+
+	entries, err := ioutil.ReadDir(c.Path())
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == "lost+found" {
+			continue
+		}
+
+		err := os.RemoveAll(filepath.Join(c.Path(), entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	if fingerprint == "" {
+		return os.MkdirAll(c.RootfsPath(), 0755)
+	}
+
+	imagePath := shared.VarPath("images", fingerprint)
+	return unpackImage(imagePath, c.Path(), c.Storage().GetStorageType(), s.OS.RunningInUserNS)
+}
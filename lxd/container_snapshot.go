@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -23,8 +24,9 @@ func containerSnapshotsGet(d *Daemon, r *http.Request) Response {
 		recursion = 0
 	}
 
+	project := projectParam(r)
 	cname := mux.Vars(r)["name"]
-	c, err := containerLoadByName(d.State(), cname)
+	c, err := containerLoadByName(d.State(), projectPrefix(project, cname))
 	if err != nil {
 		return SmartError(err)
 	}
@@ -41,7 +43,7 @@ func containerSnapshotsGet(d *Daemon, r *http.Request) Response {
 		_, snapName, _ := containerGetParentAndSnapshotName(snap.Name())
 		if recursion == 0 {
 			url := fmt.Sprintf("/%s/containers/%s/snapshots/%s", version.APIVersion, cname, snapName)
-			resultString = append(resultString, url)
+			resultString = append(resultString, projectURL(project, url))
 		} else {
 			render, _, err := snap.Render()
 			if err != nil {
@@ -60,7 +62,7 @@ func containerSnapshotsGet(d *Daemon, r *http.Request) Response {
 }
 
 func containerSnapshotsPost(d *Daemon, r *http.Request) Response {
-	name := mux.Vars(r)["name"]
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
 
 	/*
 	 * snapshot is a three step operation:
@@ -88,14 +90,27 @@ func containerSnapshotsPost(d *Daemon, r *http.Request) Response {
 
 	if req.Name == "" {
 		// come up with a name
-		i := d.db.ContainerNextSnapshot(name)
-		req.Name = fmt.Sprintf("snap%d", i)
+		req.Name, err = containerDetermineNextSnapshotName(d.State(), c, "snap%d")
+		if err != nil {
+			return SmartError(err)
+		}
+	}
+
+	var expiry time.Time
+	if req.ExpiresAt != nil {
+		expiry = *req.ExpiresAt
+	} else {
+		expiry, err = shared.GetSnapshotExpiry(time.Now(), c.ExpandedConfig()["snapshots.expiry"])
+		if err != nil {
+			return BadRequest(err)
+		}
 	}
 
 	fullName := name +
 		shared.SnapshotDelimiter +
 		req.Name
 
+	requestor := d.requestor(r)
 	snapshot := func(op *operation) error {
 		args := db.ContainerArgs{
 			Name:         fullName,
@@ -107,6 +122,7 @@ func containerSnapshotsPost(d *Daemon, r *http.Request) Response {
 			Architecture: c.Architecture(),
 			Devices:      c.LocalDevices(),
 			Stateful:     req.Stateful,
+			ExpiryDate:   expiry,
 		}
 
 		_, err := containerCreateAsSnapshot(d.State(), args, c)
@@ -114,13 +130,14 @@ func containerSnapshotsPost(d *Daemon, r *http.Request) Response {
 			return err
 		}
 
+		eventSendContainerLifecycle(fullName, "container-snapshot-created", requestor, nil)
 		return nil
 	}
 
 	resources := map[string][]string{}
 	resources["containers"] = []string{name}
 
-	op, err := operationCreate(operationClassTask, resources, nil, snapshot, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, snapshot, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -129,7 +146,7 @@ func containerSnapshotsPost(d *Daemon, r *http.Request) Response {
 }
 
 func snapshotHandler(d *Daemon, r *http.Request) Response {
-	containerName := mux.Vars(r)["name"]
+	containerName := projectPrefix(projectParam(r), mux.Vars(r)["name"])
 	snapshotName := mux.Vars(r)["snapshotName"]
 
 	sc, err := containerLoadByName(
@@ -147,7 +164,7 @@ func snapshotHandler(d *Daemon, r *http.Request) Response {
 	case "POST":
 		return snapshotPost(d, r, sc, containerName)
 	case "DELETE":
-		return snapshotDelete(sc, snapshotName)
+		return snapshotDelete(d, r, sc, snapshotName)
 	default:
 		return NotFound
 	}
@@ -199,6 +216,7 @@ func snapshotPost(d *Daemon, r *http.Request, sc container, containerName string
 
 		if reqNew.Live {
 			sourceName, _, _ := containerGetParentAndSnapshotName(containerName)
+			sourceName = projectStripPrefix(sc.Project(), sourceName)
 			if sourceName != reqNew.Name {
 				return BadRequest(fmt.Errorf(`Copying `+
 					`stateful containers requires that `+
@@ -222,7 +240,7 @@ func snapshotPost(d *Daemon, r *http.Request, sc container, containerName string
 				return InternalError(err)
 			}
 
-			op, err := operationCreate(operationClassTask, resources, nil, ws.Do, nil, nil)
+			op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, ws.Do, nil, nil)
 			if err != nil {
 				return InternalError(err)
 			}
@@ -231,7 +249,7 @@ func snapshotPost(d *Daemon, r *http.Request, sc container, containerName string
 		}
 
 		// Pull mode
-		op, err := operationCreate(operationClassWebsocket, resources, ws.Metadata(), ws.Do, nil, ws.Connect)
+		op, err := operationCreate(projectParam(r), operationClassWebsocket, resources, ws.Metadata(), ws.Do, nil, ws.Connect)
 		if err != nil {
 			return InternalError(err)
 		}
@@ -252,14 +270,23 @@ func snapshotPost(d *Daemon, r *http.Request, sc container, containerName string
 		return Conflict
 	}
 
+	_, oldName, _ := containerGetParentAndSnapshotName(sc.Name())
+	requestor := d.requestor(r)
 	rename := func(op *operation) error {
-		return sc.Rename(fullName)
+		err := sc.Rename(fullName)
+		if err != nil {
+			return err
+		}
+
+		eventSendContainerLifecycle(fullName, "container-snapshot-renamed", requestor,
+			map[string]interface{}{"old_name": oldName})
+		return nil
 	}
 
 	resources := map[string][]string{}
 	resources["containers"] = []string{containerName}
 
-	op, err := operationCreate(operationClassTask, resources, nil, rename, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, rename, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -267,15 +294,22 @@ func snapshotPost(d *Daemon, r *http.Request, sc container, containerName string
 	return OperationResponse(op)
 }
 
-func snapshotDelete(sc container, name string) Response {
+func snapshotDelete(d *Daemon, r *http.Request, sc container, name string) Response {
+	requestor := d.requestor(r)
 	remove := func(op *operation) error {
-		return sc.Delete()
+		err := sc.Delete()
+		if err != nil {
+			return err
+		}
+
+		eventSendContainerLifecycle(sc.Name(), "container-snapshot-deleted", requestor, nil)
+		return nil
 	}
 
 	resources := map[string][]string{}
 	resources["containers"] = []string{sc.Name()}
 
-	op, err := operationCreate(operationClassTask, resources, nil, remove, nil, nil)
+	op, err := operationCreate(sc.Project(), operationClassTask, resources, nil, remove, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
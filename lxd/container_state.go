@@ -14,7 +14,7 @@ import (
 )
 
 func containerState(d *Daemon, r *http.Request) Response {
-	name := mux.Vars(r)["name"]
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
 	c, err := containerLoadByName(d.State(), name)
 	if err != nil {
 		return SmartError(err)
@@ -29,7 +29,7 @@ func containerState(d *Daemon, r *http.Request) Response {
 }
 
 func containerStatePut(d *Daemon, r *http.Request) Response {
-	name := mux.Vars(r)["name"]
+	name := projectPrefix(projectParam(r), mux.Vars(r)["name"])
 
 	raw := api.ContainerStatePut{}
 
@@ -156,10 +156,30 @@ func containerStatePut(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("unknown action %s", raw.Action))
 	}
 
+	// Report the state change once it succeeded
+	action := map[shared.ContainerAction]string{
+		shared.Start:    "container-started",
+		shared.Stop:     "container-stopped",
+		shared.Restart:  "container-restarted",
+		shared.Freeze:   "container-paused",
+		shared.Unfreeze: "container-resumed",
+	}[shared.ContainerAction(raw.Action)]
+
+	requestor := d.requestor(r)
+	run := func(op *operation) error {
+		err := do(op)
+		if err != nil {
+			return err
+		}
+
+		eventSendContainerLifecycle(name, action, requestor, nil)
+		return nil
+	}
+
 	resources := map[string][]string{}
 	resources["containers"] = []string{name}
 
-	op, err := operationCreate(operationClassTask, resources, nil, do, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, run, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -42,6 +42,7 @@ func (suite *containerTestSuite) TestContainer_ProfilesDefault() {
 func (suite *containerTestSuite) TestContainer_ProfilesMulti() {
 	// Create an unprivileged profile
 	_, err := suite.d.db.ProfileCreate(
+		"default",
 		"unprivileged",
 		"unprivileged",
 		map[string]string{"security.privileged": "true"},
@@ -394,6 +395,18 @@ func (suite *containerTestSuite) TestContainer_findIdmap_maxed() {
 	}
 }
 
+func (suite *containerTestSuite) TestContainer_validConfigKeySnapshotsSchedule() {
+	for _, value := range []string{"", "0 6 * * *", "*/5 * * * 1-5", "@daily", "@hourly", "@every 1h"} {
+		err := containerValidConfigKey(suite.d.os, "snapshots.schedule", value)
+		suite.Nil(err, fmt.Sprintf("%q should be valid", value))
+	}
+
+	for _, value := range []string{"foo", "0 6 * *", "0 6 * * * *", "@sometimes"} {
+		err := containerValidConfigKey(suite.d.os, "snapshots.schedule", value)
+		suite.NotNil(err, fmt.Sprintf("%q should be rejected", value))
+	}
+}
+
 func TestContainerTestSuite(t *testing.T) {
 	suite.Run(t, new(containerTestSuite))
 }
@@ -35,6 +35,11 @@ var containerStateCmd = Command{
 	put:  containerStatePut,
 }
 
+var containerRebuildCmd = Command{
+	name: "containers/{name}/rebuild",
+	post: containerRebuildPost,
+}
+
 var containerFileCmd = Command{
 	name:   "containers/{name}/files",
 	get:    containerFileHandler,
@@ -55,6 +60,24 @@ var containerSnapshotCmd = Command{
 	delete: snapshotHandler,
 }
 
+var containerBackupsCmd = Command{
+	name: "containers/{name}/backups",
+	get:  containerBackupsGet,
+	post: containerBackupsPost,
+}
+
+var containerBackupCmd = Command{
+	name:   "containers/{name}/backups/{backupName}",
+	get:    containerBackupGet,
+	post:   containerBackupPost,
+	delete: containerBackupDelete,
+}
+
+var containerBackupExportCmd = Command{
+	name: "containers/{name}/backups/{backupName}/export",
+	get:  containerBackupExportGet,
+}
+
 var containerConsoleCmd = Command{
 	name:   "containers/{name}/console",
 	get:    containerConsoleLogGet,
@@ -132,7 +155,11 @@ func containersRestart(s *state.State) error {
 		autoStart := config["boot.autostart"]
 		autoStartDelay := config["boot.autostart.delay"]
 
-		if shared.IsTrue(autoStart) || (autoStart == "" && lastState == "RUNNING") {
+		// Containers with the "always" restart policy are started
+		// unless autostart is explicitly disabled
+		alwaysRestart := config["restart.policy"] == "always"
+
+		if shared.IsTrue(autoStart) || (autoStart == "" && (lastState == "RUNNING" || alwaysRestart)) {
 			if c.IsRunning() {
 				continue
 			}
@@ -6,16 +6,30 @@ import (
 	"time"
 
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/filter"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/version"
 )
 
 func containersGet(d *Daemon, r *http.Request) Response {
+	f, err := filter.FromRequest(r)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	fields := filter.Fields(r)
+	for _, field := range fields {
+		if !shared.StringInSlice(field, []string{"state", "snapshots"}) {
+			return BadRequest(fmt.Errorf("Unknown field '%s'", field))
+		}
+	}
+
 	for i := 0; i < 100; i++ {
-		result, err := doContainersGet(d.State(), util.IsRecursionRequest(r))
+		result, err := doContainersGet(d.State(), projectParam(r), util.IsRecursionRequest(r), f, fields)
 		if err == nil {
 			return SyncResponse(true, result)
 		}
@@ -33,32 +47,61 @@ func containersGet(d *Daemon, r *http.Request) Response {
 	return InternalError(fmt.Errorf("DB is locked"))
 }
 
-func doContainersGet(s *state.State, recursion bool) (interface{}, error) {
-	result, err := s.DB.ContainersList(db.CTypeRegular)
+func doContainersGet(s *state.State, project string, recursion bool, f *filter.Filter, fields []string) (interface{}, error) {
+	result, err := s.DB.ContainersListForProject(project, db.CTypeRegular)
 	if err != nil {
 		return nil, err
 	}
 
-	resultString := []string{}
-	resultList := []*api.Container{}
-	if err != nil {
-		return []string{}, err
-	}
+	withState := shared.StringInSlice("state", fields)
+	withSnapshots := shared.StringInSlice("snapshots", fields)
 
+	resultString := []string{}
+	resultList := []*api.ContainerFull{}
 	for _, container := range result {
-		if !recursion {
-			url := fmt.Sprintf("/%s/containers/%s", version.APIVersion, container)
-			resultString = append(resultString, url)
-		} else {
-			c, err := doContainerGet(s, container)
-			if err != nil {
-				c = &api.Container{
-					Name:       container,
+		// Only load the containers when there's something to render
+		// or to filter on.
+		if !recursion && f.Empty() {
+			url := fmt.Sprintf("/%s/containers/%s", version.APIVersion, projectStripPrefix(project, container))
+			resultString = append(resultString, projectURL(project, url))
+			continue
+		}
+
+		c, err := doContainerGetFull(s, container, withState || f.Uses("state"), withSnapshots || f.Uses("snapshots"))
+		if err != nil {
+			c = &api.ContainerFull{
+				Container: api.Container{
+					Name:       projectStripPrefix(project, container),
 					Status:     api.Error.String(),
-					StatusCode: api.Error}
+					StatusCode: api.Error},
 			}
-			resultList = append(resultList, c)
 		}
+
+		match, err := f.Match(c, "name", "expanded_config")
+		if err != nil {
+			return nil, err
+		}
+
+		if !match {
+			continue
+		}
+
+		if !recursion {
+			url := fmt.Sprintf("/%s/containers/%s", version.APIVersion, projectStripPrefix(project, container))
+			resultString = append(resultString, projectURL(project, url))
+			continue
+		}
+
+		// Drop the fields which were only computed for the filter
+		if !withState {
+			c.State = nil
+		}
+
+		if !withSnapshots {
+			c.Snapshots = nil
+		}
+
+		resultList = append(resultList, c)
 	}
 
 	if !recursion {
@@ -68,16 +111,44 @@ func doContainersGet(s *state.State, recursion bool) (interface{}, error) {
 	return resultList, nil
 }
 
-func doContainerGet(s *state.State, cname string) (*api.Container, error) {
+// doContainerGetFull renders a container along with, optionally, its state
+// and snapshots.
+func doContainerGetFull(s *state.State, cname string, withState bool, withSnapshots bool) (*api.ContainerFull, error) {
 	c, err := containerLoadByName(s, cname)
 	if err != nil {
 		return nil, err
 	}
 
-	cts, _, err := c.Render()
+	render, _, err := c.Render()
 	if err != nil {
 		return nil, err
 	}
 
-	return cts.(*api.Container), nil
+	result := &api.ContainerFull{Container: *render.(*api.Container)}
+
+	if withState {
+		result.State, err = c.RenderState()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if withSnapshots {
+		snaps, err := c.Snapshots()
+		if err != nil {
+			return nil, err
+		}
+
+		result.Snapshots = []api.ContainerSnapshot{}
+		for _, snap := range snaps {
+			render, _, err := snap.Render()
+			if err != nil {
+				continue
+			}
+
+			result.Snapshots = append(result.Snapshots, *render.(*api.ContainerSnapshot))
+		}
+	}
+
+	return result, nil
 }
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/dustinkirkland/golang-petname"
@@ -21,31 +24,34 @@ import (
 	log "github.com/lxc/lxd/shared/log15"
 )
 
-func createFromImage(d *Daemon, req *api.ContainersPost) Response {
-	var hash string
-	var err error
+// containerImageHash resolves the image referenced by a container source into
+// the fingerprint (or alias for remote servers) of the image to use.
+func containerImageHash(d *Daemon, imageProject string, source api.ContainerSource) (string, Response) {
+	if source.Fingerprint != "" {
+		return source.Fingerprint, nil
+	}
 
-	if req.Source.Fingerprint != "" {
-		hash = req.Source.Fingerprint
-	} else if req.Source.Alias != "" {
-		if req.Source.Server != "" {
-			hash = req.Source.Alias
-		} else {
-			_, alias, err := d.db.ImageAliasGet(req.Source.Alias, true)
-			if err != nil {
-				return SmartError(err)
-			}
+	if source.Alias != "" {
+		if source.Server != "" {
+			return source.Alias, nil
+		}
 
-			hash = alias.Target
+		_, alias, err := d.db.ImageAliasGet(projectPrefix(imageProject, source.Alias), true)
+		if err != nil {
+			return "", SmartError(err)
 		}
-	} else if req.Source.Properties != nil {
-		if req.Source.Server != "" {
-			return BadRequest(fmt.Errorf("Property match is only supported for local images"))
+
+		return alias.Target, nil
+	}
+
+	if source.Properties != nil {
+		if source.Server != "" {
+			return "", BadRequest(fmt.Errorf("Property match is only supported for local images"))
 		}
 
-		hashes, err := d.db.ImagesGet(false)
+		hashes, err := d.db.ImagesGetForProject(imageProject, false)
 		if err != nil {
-			return SmartError(err)
+			return "", SmartError(err)
 		}
 
 		var image *api.Image
@@ -61,7 +67,7 @@ func createFromImage(d *Daemon, req *api.ContainersPost) Response {
 			}
 
 			match := true
-			for key, value := range req.Source.Properties {
+			for key, value := range source.Properties {
 				if img.Properties[key] != value {
 					match = false
 					break
@@ -76,14 +82,49 @@ func createFromImage(d *Daemon, req *api.ContainersPost) Response {
 		}
 
 		if image == nil {
-			return BadRequest(fmt.Errorf("No matching image could be found"))
+			return "", BadRequest(fmt.Errorf("No matching image could be found"))
 		}
 
-		hash = image.Fingerprint
-	} else {
-		return BadRequest(fmt.Errorf("Must specify one of alias, fingerprint or properties for init from image"))
+		return image.Fingerprint, nil
 	}
 
+	return "", BadRequest(fmt.Errorf("Must specify one of alias, fingerprint or properties for init from image"))
+}
+
+// containerImageFetch returns the image a container is to be created from,
+// downloading it first if it comes from a remote server.
+func containerImageFetch(d *Daemon, op *operation, imageProject string, source api.ContainerSource, hash string) (*api.Image, error) {
+	if source.Server != "" {
+		return d.ImageDownload(
+			op, imageProject, source.Server, source.Protocol, source.Certificate, source.Secret,
+			hash, true, daemonConfig["images.auto_update_cached"].GetBool(), "", true)
+	}
+
+	imgID, info, err := d.db.ImageGet(hash, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = imageCheckProject(d, imgID, imageProject)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func createFromImage(d *Daemon, r *http.Request, project string, req *api.ContainersPost) Response {
+	imageProject, err := projectImageScope(d.db, project)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	hash, resp := containerImageHash(d, imageProject, req.Source)
+	if resp != nil {
+		return resp
+	}
+
+	requestor := d.requestor(r)
 	run := func(op *operation) error {
 		args := db.ContainerArgs{
 			Config:    req.Config,
@@ -94,19 +135,9 @@ func createFromImage(d *Daemon, req *api.ContainersPost) Response {
 			Profiles:  req.Profiles,
 		}
 
-		var info *api.Image
-		if req.Source.Server != "" {
-			info, err = d.ImageDownload(
-				op, req.Source.Server, req.Source.Protocol, req.Source.Certificate, req.Source.Secret,
-				hash, true, daemonConfig["images.auto_update_cached"].GetBool(), "", true)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, info, err = d.db.ImageGet(hash, false, false)
-			if err != nil {
-				return err
-			}
+		info, err := containerImageFetch(d, op, imageProject, req.Source, hash)
+		if err != nil {
+			return err
 		}
 
 		args.Architecture, err = osarch.ArchitectureId(info.Architecture)
@@ -115,13 +146,18 @@ func createFromImage(d *Daemon, req *api.ContainersPost) Response {
 		}
 
 		_, err = containerCreateFromImage(d.State(), args, info.Fingerprint)
-		return err
+		if err != nil {
+			return err
+		}
+
+		eventSendContainerLifecycle(req.Name, "container-created", requestor, nil)
+		return nil
 	}
 
 	resources := map[string][]string{}
 	resources["containers"] = []string{req.Name}
 
-	op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
+	op, err := operationCreate(project, operationClassTask, resources, nil, run, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -129,7 +165,7 @@ func createFromImage(d *Daemon, req *api.ContainersPost) Response {
 	return OperationResponse(op)
 }
 
-func createFromNone(d *Daemon, req *api.ContainersPost) Response {
+func createFromNone(d *Daemon, r *http.Request, project string, req *api.ContainersPost) Response {
 	args := db.ContainerArgs{
 		Config:    req.Config,
 		Ctype:     db.CTypeRegular,
@@ -147,15 +183,21 @@ func createFromNone(d *Daemon, req *api.ContainersPost) Response {
 		args.Architecture = architecture
 	}
 
+	requestor := d.requestor(r)
 	run := func(op *operation) error {
 		_, err := containerCreateAsEmpty(d, args)
-		return err
+		if err != nil {
+			return err
+		}
+
+		eventSendContainerLifecycle(req.Name, "container-created", requestor, nil)
+		return nil
 	}
 
 	resources := map[string][]string{}
 	resources["containers"] = []string{req.Name}
 
-	op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
+	op, err := operationCreate(project, operationClassTask, resources, nil, run, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -163,7 +205,7 @@ func createFromNone(d *Daemon, req *api.ContainersPost) Response {
 	return OperationResponse(op)
 }
 
-func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
+func createFromMigration(d *Daemon, r *http.Request, project string, req *api.ContainersPost) Response {
 	// Validate migration mode
 	if req.Source.Mode != "pull" && req.Source.Mode != "push" {
 		return NotImplemented
@@ -373,6 +415,7 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 		return InternalError(err)
 	}
 
+	requestor := d.requestor(r)
 	run := func(op *operation) error {
 		// And finally run the migration.
 		err = sink.Do(op)
@@ -388,6 +431,8 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 			return err
 		}
 
+		eventSendContainerLifecycle(req.Name, "container-created", requestor, nil)
+
 		if !migrationArgs.Live {
 			if req.Config["volatile.last_state.power"] == "RUNNING" {
 				return c.Start(false)
@@ -402,12 +447,12 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 
 	var op *operation
 	if push {
-		op, err = operationCreate(operationClassWebsocket, resources, sink.Metadata(), run, nil, sink.Connect)
+		op, err = operationCreate(project, operationClassWebsocket, resources, sink.Metadata(), run, nil, sink.Connect)
 		if err != nil {
 			return InternalError(err)
 		}
 	} else {
-		op, err = operationCreate(operationClassTask, resources, nil, run, nil, nil)
+		op, err = operationCreate(project, operationClassTask, resources, nil, run, nil, nil)
 		if err != nil {
 			return InternalError(err)
 		}
@@ -416,11 +461,12 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 	return OperationResponse(op)
 }
 
-func createFromCopy(d *Daemon, req *api.ContainersPost) Response {
+func createFromCopy(d *Daemon, r *http.Request, project string, req *api.ContainersPost) Response {
 	if req.Source.Source == "" {
 		return BadRequest(fmt.Errorf("must specify a source container"))
 	}
 
+	req.Source.Source = projectPrefix(project, req.Source.Source)
 	source, err := containerLoadByName(d.State(), req.Source.Source)
 	if err != nil {
 		return SmartError(err)
@@ -491,18 +537,21 @@ func createFromCopy(d *Daemon, req *api.ContainersPost) Response {
 		Stateful:     req.Stateful,
 	}
 
+	requestor := d.requestor(r)
 	run := func(op *operation) error {
 		_, err := containerCreateAsCopy(d.State(), args, source, req.Source.ContainerOnly)
 		if err != nil {
 			return err
 		}
+
+		eventSendContainerLifecycle(req.Name, "container-created", requestor, nil)
 		return nil
 	}
 
 	resources := map[string][]string{}
 	resources["containers"] = []string{req.Name, req.Source.Source}
 
-	op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
+	op, err := operationCreate(project, operationClassTask, resources, nil, run, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -510,9 +559,77 @@ func createFromCopy(d *Daemon, req *api.ContainersPost) Response {
 	return OperationResponse(op)
 }
 
+func createFromBackup(d *Daemon, r *http.Request, project string, data io.Reader) Response {
+	// Store the backup to disk
+	f, err := ioutil.TempFile(shared.VarPath("backups"), "lxd_backup_")
+	if err != nil {
+		return InternalError(err)
+	}
+	defer f.Close()
+
+	cleanup := func() {
+		os.Remove(f.Name())
+	}
+
+	_, err = io.Copy(f, data)
+	if err != nil {
+		cleanup()
+		return InternalError(err)
+	}
+
+	// Parse the backup index
+	info, err := backupGetInfo(f.Name())
+	if err != nil {
+		cleanup()
+		return BadRequest(err)
+	}
+
+	cs, err := d.db.ContainersList(db.CTypeRegular)
+	if err != nil {
+		cleanup()
+		return SmartError(err)
+	}
+
+	if shared.StringInSlice(projectPrefix(project, info.Name), cs) {
+		cleanup()
+		return BadRequest(fmt.Errorf("A container named \"%s\" already exists", info.Name))
+	}
+
+	requestor := d.requestor(r)
+	run := func(op *operation) error {
+		defer cleanup()
+
+		_, err := containerCreateFromBackup(d.State(), project, *info, f.Name())
+		if err != nil {
+			return err
+		}
+
+		eventSendContainerLifecycle(projectPrefix(project, info.Name), "container-created", requestor, nil)
+		return nil
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{projectPrefix(project, info.Name)}
+
+	op, err := operationCreate(project, operationClassTask, resources, nil, run, nil, nil)
+	if err != nil {
+		cleanup()
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
 func containersPost(d *Daemon, r *http.Request) Response {
 	logger.Debugf("Responding to container create")
 
+	project := projectParam(r)
+
+	// Backup uploads are sent as the raw tarball
+	if r.Header.Get("Content-Type") == "application/octet-stream" {
+		return createFromBackup(d, r, project, r.Body)
+	}
+
 	req := api.ContainersPost{}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return BadRequest(err)
@@ -534,7 +651,7 @@ func containersPost(d *Daemon, r *http.Request) Response {
 		for {
 			i++
 			req.Name = strings.ToLower(petname.Generate(2, "-"))
-			if !shared.StringInSlice(req.Name, cs) {
+			if !shared.StringInSlice(projectPrefix(project, req.Name), cs) {
 				break
 			}
 
@@ -570,15 +687,22 @@ func containersPost(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("Invalid container name: '%s' is reserved for snapshots", shared.SnapshotDelimiter))
 	}
 
+	// Translate the names into the ones used for the project
+	req.Name = projectPrefix(project, req.Name)
+	req.Profiles, err = projectProfileNames(d.db, project, req.Profiles)
+	if err != nil {
+		return SmartError(err)
+	}
+
 	switch req.Source.Type {
 	case "image":
-		return createFromImage(d, &req)
+		return createFromImage(d, r, project, &req)
 	case "none":
-		return createFromNone(d, &req)
+		return createFromNone(d, r, project, &req)
 	case "migration":
-		return createFromMigration(d, &req)
+		return createFromMigration(d, r, project, &req)
 	case "copy":
-		return createFromCopy(d, &req)
+		return createFromCopy(d, r, project, &req)
 	default:
 		return BadRequest(fmt.Errorf("unknown source type %s", req.Source.Type))
 	}
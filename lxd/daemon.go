@@ -32,6 +32,7 @@ import (
 	"github.com/lxc/lxd/lxd/task"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/version"
 
@@ -46,6 +47,14 @@ type Daemon struct {
 	readyChan    chan bool
 	shutdownChan chan bool
 
+	// Projects the restricted client certificates have access to, indexed
+	// by fingerprint.
+	clientCertsRestricted map[string][]string
+
+	// Guards clientCerts and clientCertsRestricted, which get replaced
+	// whenever the trust store changes.
+	clientCertsLock sync.RWMutex
+
 	// Tasks registry for long-running background tasks.
 	tasks task.Group
 
@@ -60,6 +69,12 @@ type Daemon struct {
 	proxy func(req *http.Request) (*url.URL, error)
 
 	externalAuth *externalAuth
+
+	// Append-only log of the mutating API requests.
+	audit *auditLog
+
+	// Handler of the system calls intercepted in containers.
+	seccomp *SeccompServer
 }
 
 type externalAuth struct {
@@ -119,14 +134,152 @@ func (d *Daemon) checkTrustedClient(r *http.Request) error {
 		return err
 	}
 
+	clientCerts, _ := d.clientCertificates()
 	for i := range r.TLS.PeerCertificates {
-		if util.CheckTrustState(*r.TLS.PeerCertificates[i], d.clientCerts) {
+		if util.CheckTrustState(*r.TLS.PeerCertificates[i], clientCerts) {
 			return nil
 		}
 	}
 	return fmt.Errorf("unauthorized")
 }
 
+// Return the trusted client certificates and the projects the restricted ones
+// have access to.
+func (d *Daemon) clientCertificates() ([]x509.Certificate, map[string][]string) {
+	d.clientCertsLock.RLock()
+	defer d.clientCertsLock.RUnlock()
+
+	return d.clientCerts, d.clientCertsRestricted
+}
+
+// Return the projects a client using a restricted certificate has access to,
+// or nil if the client isn't restricted.
+func (d *Daemon) clientRestrictedProjects(r *http.Request) []string {
+	if r.RemoteAddr == "@" || r.TLS == nil {
+		return nil
+	}
+
+	if d.externalAuth != nil && r.Header.Get(httpbakery.BakeryProtocolHeader) != "" {
+		return nil
+	}
+
+	clientCerts, clientCertsRestricted := d.clientCertificates()
+	for i := range r.TLS.PeerCertificates {
+		cert := r.TLS.PeerCertificates[i]
+		if !util.CheckTrustState(*cert, clientCerts) {
+			continue
+		}
+
+		projects, ok := clientCertsRestricted[shared.CertFingerprint(cert)]
+		if !ok {
+			return nil
+		}
+
+		return projects
+	}
+
+	return nil
+}
+
+// ucredKey is the request context key holding the credentials of local
+// clients.
+type ucredKey struct{}
+
+// Return the identity of the client which sent the request, as reported in
+// lifecycle events, operations and the audit log. Local clients are
+// identified by their uid.
+func (d *Daemon) requestor(r *http.Request) *api.EventLifecycleRequestor {
+	if r.RemoteAddr == "@" || r.TLS == nil {
+		requestor := &api.EventLifecycleRequestor{Protocol: "unix"}
+
+		cred, ok := r.Context().Value(ucredKey{}).(*ucred)
+		if ok {
+			requestor.Username = fmt.Sprintf("%d", cred.uid)
+		}
+
+		return requestor
+	}
+
+	if d.externalAuth != nil && r.Header.Get(httpbakery.BakeryProtocolHeader) != "" {
+		requestor := &api.EventLifecycleRequestor{Protocol: "candid"}
+
+		ctx := httpbakery.ContextWithRequest(context.TODO(), r)
+		authChecker := d.externalAuth.bakery.Checker.Auth(
+			httpbakery.RequestMacaroons(r)...)
+		info, err := authChecker.Allow(ctx, getBakeryOps(r)...)
+		if err == nil && info.Identity != nil {
+			requestor.Username = info.Identity.Id()
+		}
+
+		return requestor
+	}
+
+	clientCerts, _ := d.clientCertificates()
+	for i := range r.TLS.PeerCertificates {
+		cert := r.TLS.PeerCertificates[i]
+		if util.CheckTrustState(*cert, clientCerts) {
+			return &api.EventLifecycleRequestor{Protocol: "tls", Username: shared.CertFingerprint(cert)}
+		}
+	}
+
+	return &api.EventLifecycleRequestor{Protocol: "tls"}
+}
+
+// Check whether a client using a restricted certificate may run the given
+// request. Restricted clients may only deal with the containers, images and
+// profiles of the projects they have access to, as well as the operations
+// and events of those projects.
+func (d *Daemon) checkRestrictedClient(c Command, r *http.Request, projects []string) error {
+	project := projectParam(r)
+
+	switch {
+	case c.name == "":
+		if r.Method == "GET" {
+			return nil
+		}
+	case c.name == "events" || strings.HasPrefix(c.name, "operations"):
+		// Filtered by project by the handlers themselves
+		return nil
+	case c.name == "metadata/configuration":
+		return nil
+	case c.name == "projects/{name}":
+		if r.Method == "GET" && shared.StringInSlice(mux.Vars(r)["name"], projects) {
+			return nil
+		}
+	case strings.HasPrefix(c.name, "containers"):
+		if shared.StringInSlice(project, projects) {
+			return nil
+		}
+	case strings.HasPrefix(c.name, "images"), strings.HasPrefix(c.name, "profiles"):
+		if !shared.StringInSlice(project, projects) {
+			break
+		}
+
+		if r.Method == "GET" {
+			return nil
+		}
+
+		// Changing images or profiles shared with the default project
+		// requires access to the default project.
+		var scope string
+		var err error
+		if strings.HasPrefix(c.name, "images") {
+			scope, err = projectImageScope(d.db, project)
+		} else {
+			scope, err = projectProfileScope(d.db, project)
+		}
+		if err != nil {
+			return err
+		}
+
+		if shared.StringInSlice(scope, projects) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("not allowed for restricted client")
+}
+
 // Return the bakery operations implied by the given HTTP request
 func getBakeryOps(r *http.Request) []bakery.Op {
 	return []bakery.Op{{
@@ -192,6 +345,38 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c Command) {
 	restAPI.HandleFunc(uri, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
+		// Keep track of the local user sending the request
+		conn := extractUnderlyingConn(w)
+		if conn != nil {
+			cred, err := getCred(conn)
+			if err == nil {
+				r = r.WithContext(context.WithValue(r.Context(), ucredKey{}, cred))
+			}
+		}
+
+		// Record the mutating requests in the audit log
+		if shared.StringInSlice(r.Method, []string{"PUT", "POST", "DELETE", "PATCH"}) {
+			aw := &auditResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			w = aw
+
+			defer func() {
+				entry := auditEntry{
+					Timestamp:  time.Now().UTC(),
+					Requestor:  d.requestor(r),
+					Address:    r.RemoteAddr,
+					Method:     r.Method,
+					URL:        r.URL.RequestURI(),
+					StatusCode: aw.statusCode,
+					Operation:  aw.Header().Get("Location"),
+				}
+
+				err := d.audit.Record(entry)
+				if err != nil {
+					logger.Error("Failed to write to the audit log", log.Ctx{"err": err})
+				}
+			}()
+		}
+
 		untrustedOk := (r.Method == "GET" && c.untrustedGet) || (r.Method == "POST" && c.untrustedPost)
 		err := d.checkTrustedClient(r)
 		if err == nil {
@@ -226,6 +411,28 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c Command) {
 			shared.DebugJson(captured)
 		}
 
+		// Restricted clients can only access their own projects
+		if err == nil {
+			projects := d.clientRestrictedProjects(r)
+			if projects != nil && d.checkRestrictedClient(c, r, projects) != nil {
+				logger.Warn(
+					"rejecting request from restricted client",
+					log.Ctx{"method": r.Method, "url": r.URL.RequestURI(), "ip": r.RemoteAddr})
+				Forbidden.Render(w)
+				return
+			}
+		}
+
+		// Check that the requested project exists
+		project := r.URL.Query().Get("project")
+		if project != "" {
+			_, _, err := d.db.ProjectGet(project)
+			if err != nil {
+				SmartError(err).Render(w)
+				return
+			}
+		}
+
 		var resp Response
 		resp = NotImplemented
 
@@ -254,6 +461,12 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c Command) {
 			resp = NotFound
 		}
 
+		// Keep track of the background operations
+		opResp, ok := resp.(*operationResponse)
+		if ok {
+			opResp.op.track(d.requestor(r))
+		}
+
 		if err := resp.Render(w); err != nil {
 			err := InternalError(err).Render(w)
 			if err != nil {
@@ -362,6 +575,12 @@ func (d *Daemon) init() error {
 		return err
 	}
 
+	/* Open the audit log */
+	d.audit, err = auditLogOpen(filepath.Join(d.os.LogDir, "audit.log"))
+	if err != nil {
+		return err
+	}
+
 	/* Read the storage pools */
 	err = SetupStorageDriver(d.State(), false)
 	if err != nil {
@@ -407,9 +626,18 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	/* Setup the handler of intercepted system calls */
+	if !d.os.MockMode && d.os.SeccompListener {
+		d.seccomp, err = NewSeccompServer(d, shared.VarPath("seccomp.socket"))
+		if err != nil {
+			return err
+		}
+	}
+
 	if !d.os.MockMode {
 		/* Start the scheduler */
 		go deviceEventListener(d.State())
+		go containerExitListener(d.State())
 		readSavedClientCAList(d)
 	}
 
@@ -456,6 +684,21 @@ func (d *Daemon) Ready() error {
 	/* Auto-update instance types */
 	d.tasks.Add(instanceRefreshTypesTask(d))
 
+	/* Scheduled container snapshots */
+	d.tasks.Add(autoCreateContainerSnapshotsTask(d))
+
+	/* Expired container snapshots */
+	d.tasks.Add(pruneExpiredContainerSnapshotsTask(d))
+
+	/* Expired container backups */
+	d.tasks.Add(pruneExpiredContainerBackupsTask(d))
+
+	// Prune the operations history daily
+	d.tasks.Add(pruneOperationsHistoryTask(d))
+
+	/* Container health checks */
+	d.tasks.Add(containerHealthCheckTask(d))
+
 	// FIXME: There's no hard reason for which we should not run tasks in
 	//        mock mode. However it requires that we tweak the tasks so
 	//        they exit gracefully without blocking (something we should
@@ -514,6 +757,11 @@ func (d *Daemon) Stop() error {
 	}
 
 	trackError(d.tasks.Stop(time.Second)) // Give tasks at most a second to cleanup.
+	trackError(d.audit.Close())
+
+	if d.seccomp != nil {
+		trackError(d.seccomp.Stop())
+	}
 
 	if d.db != nil {
 		if n, err := d.numRunningContainers(); err != nil || n == 0 {
@@ -528,6 +776,10 @@ func (d *Daemon) Stop() error {
 				"Not unmounting temporary filesystems (containers are still running)")
 		}
 
+		operationsLock.Lock()
+		operationsHistory = nil
+		operationsLock.Unlock()
+
 		logger.Infof("Closing the database")
 		trackError(d.db.Close())
 	}
@@ -627,5 +879,10 @@ func initializeDbObject(d *Daemon) error {
 		return fmt.Errorf("Error creating database: %s", err)
 	}
 
+	// Record all the finished operations
+	operationsLock.Lock()
+	operationsHistory = d.db
+	operationsLock.Unlock()
+
 	return nil
 }
@@ -19,7 +19,7 @@ type daemonImagesTestSuite struct {
 // newer image even if available, and just use the cached one.
 func (suite *daemonImagesTestSuite) TestUseCachedImagesIfAvailable() {
 	// Create an image with alias "test" and fingerprint "abcd".
-	err := suite.d.db.ImageInsert("abcd", "foo.xz", 1, false, true, "amd64", time.Now(), time.Now(), map[string]string{})
+	err := suite.d.db.ImageInsert("default", "abcd", "foo.xz", 1, false, true, "amd64", time.Now(), time.Now(), map[string]string{})
 	suite.Req.Nil(err)
 	id, _, err := suite.d.db.ImageGet("abcd", false, true)
 	suite.Req.Nil(err)
@@ -39,9 +39,9 @@ func (suite *daemonImagesTestSuite) TestUseCachedImagesIfAvailable() {
 
 	// Request an image with alias "test" and check that it's the
 	// one we created above.
-	op, err := operationCreate(operationClassTask, map[string][]string{}, nil, nil, nil, nil)
+	op, err := operationCreate("", operationClassTask, map[string][]string{}, nil, nil, nil, nil)
 	suite.Req.Nil(err)
-	image, err := suite.d.ImageDownload(op, "img.srv", "simplestreams", "", "", "test", false, false, "", true)
+	image, err := suite.d.ImageDownload(op, "default", "img.srv", "simplestreams", "", "", "test", false, false, "", true)
 	suite.Req.Nil(err)
 	suite.Req.Equal("abcd", image.Fingerprint)
 }
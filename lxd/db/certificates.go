@@ -1,5 +1,10 @@
 package db
 
+import (
+	"database/sql"
+	"fmt"
+)
+
 // CertInfo is here to pass the certificates content
 // from the database around
 type CertInfo struct {
@@ -8,13 +13,15 @@ type CertInfo struct {
 	Type        int
 	Name        string
 	Certificate string
+	Restricted  bool
+	Projects    []string
 }
 
 // CertificatesGet returns all certificates from the DB as CertBaseInfo objects.
 func (n *Node) CertificatesGet() (certs []*CertInfo, err error) {
 	rows, err := dbQuery(
 		n.db,
-		"SELECT id, fingerprint, type, name, certificate FROM certificates",
+		"SELECT id, fingerprint, type, name, certificate, restricted FROM certificates",
 	)
 	if err != nil {
 		return certs, err
@@ -30,9 +37,18 @@ func (n *Node) CertificatesGet() (certs []*CertInfo, err error) {
 			&cert.Type,
 			&cert.Name,
 			&cert.Certificate,
+			&cert.Restricted,
 		)
 		certs = append(certs, cert)
 	}
+	rows.Close()
+
+	for _, cert := range certs {
+		cert.Projects, err = n.certificateProjectsGet(cert.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return certs, nil
 }
@@ -52,11 +68,12 @@ func (n *Node) CertificateGet(fingerprint string) (cert *CertInfo, err error) {
 		&cert.Type,
 		&cert.Name,
 		&cert.Certificate,
+		&cert.Restricted,
 	}
 
 	query := `
 		SELECT
-			id, fingerprint, type, name, certificate
+			id, fingerprint, type, name, certificate, restricted
 		FROM
 			certificates
 		WHERE fingerprint LIKE ?`
@@ -65,9 +82,65 @@ func (n *Node) CertificateGet(fingerprint string) (cert *CertInfo, err error) {
 		return nil, err
 	}
 
+	cert.Projects, err = n.certificateProjectsGet(cert.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return cert, err
 }
 
+// Return the names of the projects a restricted certificate has access to.
+func (n *Node) certificateProjectsGet(id int) ([]string, error) {
+	q := `
+SELECT projects.name FROM certificates_projects
+    JOIN projects ON projects.id = certificates_projects.project_id
+    WHERE certificates_projects.certificate_id=?
+    ORDER BY projects.name`
+	inargs := []interface{}{id}
+	var name string
+	outfmt := []interface{}{name}
+	result, err := queryScan(n.db, q, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []string{}
+	for _, r := range result {
+		projects = append(projects, r[0].(string))
+	}
+
+	return projects, nil
+}
+
+// Replace the projects a restricted certificate has access to.
+func certificateProjectsSet(tx *sql.Tx, id int64, projects []string) error {
+	_, err := tx.Exec("DELETE FROM certificates_projects WHERE certificate_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		result, err := tx.Exec(`
+INSERT INTO certificates_projects (certificate_id, project_id)
+    SELECT ?, id FROM projects WHERE name=?`, id, project)
+		if err != nil {
+			return err
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if count != 1 {
+			return fmt.Errorf("Project '%s' doesn't exist", project)
+		}
+	}
+
+	return nil
+}
+
 // CertSave stores a CertBaseInfo object in the db,
 // it will ignore the ID field from the CertInfo.
 func (n *Node) CertSave(cert *CertInfo) error {
@@ -80,25 +153,39 @@ func (n *Node) CertSave(cert *CertInfo) error {
 				fingerprint,
 				type,
 				name,
-				certificate
-			) VALUES (?, ?, ?, ?)`,
+				certificate,
+				restricted
+			) VALUES (?, ?, ?, ?, ?)`,
 	)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 	defer stmt.Close()
-	_, err = stmt.Exec(
+	result, err := stmt.Exec(
 		cert.Fingerprint,
 		cert.Type,
 		cert.Name,
 		cert.Certificate,
+		cert.Restricted,
 	)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = certificateProjectsSet(tx, id, cert.Projects)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return TxCommit(tx)
 }
 
@@ -112,13 +199,24 @@ func (n *Node) CertDelete(fingerprint string) error {
 	return nil
 }
 
-func (n *Node) CertUpdate(fingerprint string, certName string, certType int) error {
+func (n *Node) CertUpdate(fingerprint string, certName string, certType int, restricted bool, projects []string) error {
+	cert, err := n.CertificateGet(fingerprint)
+	if err != nil {
+		return err
+	}
+
 	tx, err := begin(n.db)
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.Exec("UPDATE certificates SET name=?, type=? WHERE fingerprint=?", certName, certType, fingerprint)
+	_, err = tx.Exec("UPDATE certificates SET name=?, type=?, restricted=? WHERE id=?", certName, certType, restricted, cert.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = certificateProjectsSet(tx, int64(cert.ID), projects)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -3,6 +3,8 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/lxc/lxd/lxd/types"
@@ -24,11 +26,13 @@ type ContainerArgs struct {
 	Config       map[string]string
 	CreationDate time.Time
 	LastUsedDate time.Time
+	ExpiryDate   time.Time
 	Ctype        ContainerType
 	Devices      types.Devices
 	Ephemeral    bool
 	Name         string
 	Profiles     []string
+	Project      string
 	Stateful     bool
 }
 
@@ -73,7 +77,8 @@ func (n *Node) ContainerId(name string) (int, error) {
 }
 
 func (n *Node) ContainerGet(name string) (ContainerArgs, error) {
-	var used *time.Time // Hold the db-returned time
+	var used *time.Time   // Hold the db-returned time
+	var expiry *time.Time // Hold the db-returned time
 	description := sql.NullString{}
 
 	args := ContainerArgs{}
@@ -81,9 +86,12 @@ func (n *Node) ContainerGet(name string) (ContainerArgs, error) {
 
 	ephemInt := -1
 	statefulInt := -1
-	q := "SELECT id, description, architecture, type, ephemeral, stateful, creation_date, last_use_date FROM containers WHERE name=?"
+	q := `
+SELECT containers.id, containers.description, architecture, type, ephemeral, stateful, creation_date, last_use_date, expiry_date, projects.name
+    FROM containers JOIN projects ON containers.project_id=projects.id
+    WHERE containers.name=?`
 	arg1 := []interface{}{name}
-	arg2 := []interface{}{&args.Id, &description, &args.Architecture, &args.Ctype, &ephemInt, &statefulInt, &args.CreationDate, &used}
+	arg2 := []interface{}{&args.Id, &description, &args.Architecture, &args.Ctype, &ephemInt, &statefulInt, &args.CreationDate, &used, &expiry, &args.Project}
 	err := dbQueryRowScan(n.db, q, arg1, arg2)
 	if err != nil {
 		return args, err
@@ -109,6 +117,12 @@ func (n *Node) ContainerGet(name string) (ContainerArgs, error) {
 		args.LastUsedDate = time.Unix(0, 0).UTC()
 	}
 
+	if expiry != nil {
+		args.ExpiryDate = *expiry
+	} else {
+		args.ExpiryDate = time.Time{}
+	}
+
 	config, err := n.ContainerConfig(args.Id)
 	if err != nil {
 		return args, err
@@ -159,14 +173,25 @@ func (n *Node) ContainerCreate(args ContainerArgs) (int, error) {
 	args.CreationDate = time.Now().UTC()
 	args.LastUsedDate = time.Unix(0, 0).UTC()
 
-	str := fmt.Sprintf("INSERT INTO containers (name, architecture, type, ephemeral, creation_date, last_use_date, stateful) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	// Only snapshots can expire, store a NULL otherwise.
+	var expiryDate interface{}
+	if !args.ExpiryDate.IsZero() {
+		expiryDate = args.ExpiryDate.UTC()
+	}
+
+	project := args.Project
+	if project == "" {
+		project = "default"
+	}
+
+	str := fmt.Sprintf("INSERT INTO containers (name, architecture, type, ephemeral, creation_date, last_use_date, stateful, expiry_date, project_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, (SELECT id FROM projects WHERE name=?))")
 	stmt, err := tx.Prepare(str)
 	if err != nil {
 		tx.Rollback()
 		return 0, err
 	}
 	defer stmt.Close()
-	result, err := stmt.Exec(args.Name, args.Architecture, args.Ctype, ephemInt, args.CreationDate.Unix(), args.LastUsedDate.Unix(), statefulInt)
+	result, err := stmt.Exec(args.Name, args.Architecture, args.Ctype, ephemInt, args.CreationDate.Unix(), args.LastUsedDate.Unix(), statefulInt, expiryDate, project)
 	if err != nil {
 		tx.Rollback()
 		return 0, err
@@ -355,6 +380,57 @@ func (n *Node) ContainersList(cType ContainerType) ([]string, error) {
 	return ret, nil
 }
 
+// ContainersListForProject returns the names of the containers of the given
+// type which belong to the given project.
+func (n *Node) ContainersListForProject(project string, cType ContainerType) ([]string, error) {
+	q := `
+SELECT containers.name FROM containers JOIN projects ON containers.project_id=projects.id
+    WHERE type=? AND projects.name=? ORDER BY containers.name`
+	inargs := []interface{}{cType, project}
+	var container string
+	outfmt := []interface{}{container}
+	result, err := queryScan(n.db, q, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []string{}
+	for _, container := range result {
+		ret = append(ret, container[0].(string))
+	}
+
+	return ret, nil
+}
+
+// ContainersListWithConfigKey returns the names of the containers of the
+// given type which have the given configuration key set, either directly or
+// through one of their profiles.
+func (n *Node) ContainersListWithConfigKey(cType ContainerType, key string) ([]string, error) {
+	q := `
+SELECT containers.name FROM containers
+    WHERE type=? AND (
+        containers.id IN (SELECT container_id FROM containers_config WHERE key=?)
+        OR containers.id IN (
+            SELECT containers_profiles.container_id FROM containers_profiles
+            JOIN profiles_config ON containers_profiles.profile_id=profiles_config.profile_id
+            WHERE profiles_config.key=?))
+    ORDER BY containers.name`
+	inargs := []interface{}{cType, key, key}
+	var container string
+	outfmt := []interface{}{container}
+	result, err := queryScan(n.db, q, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []string{}
+	for _, container := range result {
+		ret = append(ret, container[0].(string))
+	}
+
+	return ret, nil
+}
+
 func (n *Node) ContainersResetState() error {
 	// Reset all container states
 	_, err := exec(n.db, "DELETE FROM containers_config WHERE key='volatile.last_state.power'")
@@ -472,12 +548,48 @@ func (n *Node) ContainerGetSnapshots(name string) ([]string, error) {
 	return result, nil
 }
 
+// ContainerGetExpiredSnapshots returns the names of all snapshots whose
+// expiry date is set and lies before the given date.
+func (n *Node) ContainerGetExpiredSnapshots(date time.Time) ([]string, error) {
+	result := []string{}
+
+	q := "SELECT name, expiry_date FROM containers WHERE type=? AND expiry_date IS NOT NULL"
+	rows, err := n.db.Query(q, CTypeSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var expiry time.Time
+
+		err := rows.Scan(&name, &expiry)
+		if err != nil {
+			return nil, err
+		}
+
+		if expiry.IsZero() || expiry.After(date) {
+			continue
+		}
+
+		result = append(result, name)
+	}
+
+	return result, rows.Err()
+}
+
 /*
  * Note, the code below doesn't deal with snapshots of snapshots.
  * To do that, we'll need to weed out based on # slashes in names
  */
-func (n *Node) ContainerNextSnapshot(name string) int {
-	base := name + shared.SnapshotDelimiter + "snap"
+func (n *Node) ContainerNextSnapshot(name string, pattern string) int {
+	fields := strings.SplitN(pattern, "%d", 2)
+	if len(fields) != 2 {
+		return 0
+	}
+
+	base := name + shared.SnapshotDelimiter
 	length := len(base)
 	q := fmt.Sprintf("SELECT name FROM containers WHERE type=? AND SUBSTR(name,1,?)=?")
 	var numstr string
@@ -490,16 +602,28 @@ func (n *Node) ContainerNextSnapshot(name string) int {
 	max := 0
 
 	for _, r := range results {
-		numstr = r[0].(string)
-		if len(numstr) <= length {
+		snapOnlyName := strings.SplitN(r[0].(string), shared.SnapshotDelimiter, 2)[1]
+
+		// Only count names made of the pattern's prefix, a number and
+		// its suffix.
+		if len(snapOnlyName) <= len(fields[0])+len(fields[1]) {
 			continue
 		}
-		substr := numstr[length:]
-		var num int
-		count, err := fmt.Sscanf(substr, "%d", &num)
-		if err != nil || count != 1 {
+
+		if !strings.HasPrefix(snapOnlyName, fields[0]) || !strings.HasSuffix(snapOnlyName, fields[1]) {
 			continue
 		}
+
+		digits := snapOnlyName[len(fields[0]) : len(snapOnlyName)-len(fields[1])]
+		if strings.Trim(digits, "0123456789") != "" {
+			continue
+		}
+
+		num, err := strconv.Atoi(digits)
+		if err != nil {
+			continue
+		}
+
 		if num >= max {
 			max = num + 1
 		}
@@ -531,3 +655,174 @@ WHERE storage_volumes.name=? AND storage_volumes.type=?`
 
 	return poolName, nil
 }
+
+// ContainerBackupArgs is a value object holding all db-related details
+// about a container backup.
+type ContainerBackupArgs struct {
+	// Don't set manually
+	ID int
+
+	ContainerID      int
+	Name             string
+	CreationDate     time.Time
+	ExpiryDate       time.Time
+	ContainerOnly    bool
+	OptimizedStorage bool
+}
+
+// ContainerGetBackup returns the backup with the given name of the given
+// container.
+func (n *Node) ContainerGetBackup(containerName string, name string) (ContainerBackupArgs, error) {
+	args := ContainerBackupArgs{}
+	args.Name = name
+
+	var expiry *time.Time // Hold the db-returned time
+	containerOnlyInt := -1
+	optimizedStorageInt := -1
+	q := `SELECT containers_backups.id, containers_backups.container_id,
+    containers_backups.creation_date, containers_backups.expiry_date,
+    containers_backups.container_only, containers_backups.optimized_storage
+FROM containers_backups
+JOIN containers ON containers.id=containers_backups.container_id
+WHERE containers.name=? AND containers_backups.name=?`
+	arg1 := []interface{}{containerName, name}
+	arg2 := []interface{}{&args.ID, &args.ContainerID, &args.CreationDate, &expiry, &containerOnlyInt, &optimizedStorageInt}
+	err := dbQueryRowScan(n.db, q, arg1, arg2)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return args, NoSuchObjectError
+		}
+
+		return args, err
+	}
+
+	if expiry != nil {
+		args.ExpiryDate = *expiry
+	}
+
+	if containerOnlyInt == 1 {
+		args.ContainerOnly = true
+	}
+
+	if optimizedStorageInt == 1 {
+		args.OptimizedStorage = true
+	}
+
+	return args, nil
+}
+
+// ContainerGetBackups returns the names of all backups of the given
+// container.
+func (n *Node) ContainerGetBackups(containerName string) ([]string, error) {
+	result := []string{}
+
+	q := `SELECT containers_backups.name FROM containers_backups
+JOIN containers ON containers.id=containers_backups.container_id
+WHERE containers.name=?`
+	inargs := []interface{}{containerName}
+	outfmt := []interface{}{containerName}
+	dbResults, err := queryScan(n.db, q, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range dbResults {
+		result = append(result, r[0].(string))
+	}
+
+	return result, nil
+}
+
+// ContainerBackupCreate creates a new backup entry.
+func (n *Node) ContainerBackupCreate(args ContainerBackupArgs) error {
+	containerOnlyInt := 0
+	if args.ContainerOnly {
+		containerOnlyInt = 1
+	}
+
+	optimizedStorageInt := 0
+	if args.OptimizedStorage {
+		optimizedStorageInt = 1
+	}
+
+	var expiryDate interface{}
+	if !args.ExpiryDate.IsZero() {
+		expiryDate = args.ExpiryDate.UTC()
+	}
+
+	_, err := exec(n.db, `INSERT INTO containers_backups
+    (container_id, name, creation_date, expiry_date, container_only, optimized_storage)
+    VALUES (?, ?, ?, ?, ?, ?)`,
+		args.ContainerID, args.Name, args.CreationDate.UTC(), expiryDate, containerOnlyInt, optimizedStorageInt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ContainerBackupRemove removes the given backup of the given container.
+func (n *Node) ContainerBackupRemove(containerName string, name string) error {
+	backup, err := n.ContainerGetBackup(containerName, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = exec(n.db, "DELETE FROM containers_backups WHERE id=?", backup.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ContainerBackupRename renames a backup of the given container.
+func (n *Node) ContainerBackupRename(containerName string, oldName string, newName string) error {
+	backup, err := n.ContainerGetBackup(containerName, oldName)
+	if err != nil {
+		return err
+	}
+
+	_, err = exec(n.db, "UPDATE containers_backups SET name=? WHERE id=?", newName, backup.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ContainerGetExpiredBackups returns the names of all backups whose expiry
+// date is set and lies before the given date, in the
+// <container>/<backup> form.
+func (n *Node) ContainerGetExpiredBackups(date time.Time) ([]string, error) {
+	result := []string{}
+
+	q := `SELECT containers.name, containers_backups.name, containers_backups.expiry_date
+FROM containers_backups
+JOIN containers ON containers.id=containers_backups.container_id
+WHERE containers_backups.expiry_date IS NOT NULL`
+	rows, err := n.db.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var containerName string
+		var name string
+		var expiry time.Time
+
+		err := rows.Scan(&containerName, &name, &expiry)
+		if err != nil {
+			return nil, err
+		}
+
+		if expiry.IsZero() || expiry.After(date) {
+			continue
+		}
+
+		result = append(result, containerName+shared.SnapshotDelimiter+name)
+	}
+
+	return result, rows.Err()
+}
@@ -58,7 +58,12 @@ func OpenNode(dir string, fresh func(*Node) error, legacyPatches map[int]*Legacy
 	}
 
 	if initial == 0 {
-		err := node.ProfileCreateDefault()
+		err := node.ProjectCreateDefault()
+		if err != nil {
+			return nil, err
+		}
+
+		err = node.ProfileCreateDefault()
 		if err != nil {
 			return nil, err
 		}
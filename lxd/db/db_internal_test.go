@@ -372,7 +372,7 @@ func (s *dbTestSuite) Test_ImageAliasGet_alias_does_not_exists() {
 func (s *dbTestSuite) Test_ImageAliasAdd() {
 	var err error
 
-	err = s.db.ImageAliasAdd("Chaosphere", 1, "Someone will like the name")
+	err = s.db.ImageAliasAdd("default", "Chaosphere", 1, "Someone will like the name")
 	s.Nil(err)
 
 	_, alias, err := s.db.ImageAliasGet("Chaosphere", true)
@@ -422,6 +422,136 @@ func (s *dbTestSuite) Test_ContainerConfig() {
 	}
 }
 
+func (s *dbTestSuite) Test_ContainerCreate_snapshot_expiry() {
+	expiry := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := s.db.ContainerCreate(ContainerArgs{
+		Name:       "c1/snap0",
+		Ctype:      CTypeSnapshot,
+		ExpiryDate: expiry,
+	})
+	s.Nil(err)
+
+	args, err := s.db.ContainerGet("c1/snap0")
+	s.Nil(err)
+	s.True(args.ExpiryDate.Equal(expiry))
+
+	_, err = s.db.ContainerCreate(ContainerArgs{
+		Name:  "c1/snap1",
+		Ctype: CTypeSnapshot,
+	})
+	s.Nil(err)
+
+	args, err = s.db.ContainerGet("c1/snap1")
+	s.Nil(err)
+	s.True(args.ExpiryDate.IsZero())
+}
+
+func (s *dbTestSuite) Test_ContainerGetExpiredSnapshots() {
+	now := time.Now().UTC()
+
+	snapshots := map[string]time.Time{
+		"c1/expired": now.Add(-time.Hour),
+		"c1/valid":   now.Add(time.Hour),
+		"c1/forever": {},
+	}
+
+	for name, expiry := range snapshots {
+		_, err := s.db.ContainerCreate(ContainerArgs{Name: name, Ctype: CTypeSnapshot, ExpiryDate: expiry})
+		s.Nil(err)
+	}
+
+	expired, err := s.db.ContainerGetExpiredSnapshots(now)
+	s.Nil(err)
+	s.Equal([]string{"c1/expired"}, expired)
+}
+
+func (s *dbTestSuite) Test_ContainersListWithConfigKey() {
+	_, err := s.db.DB().Exec("INSERT INTO profiles_config (profile_id, key, value) VALUES (2, 'health.command', 'true');")
+	s.Nil(err)
+
+	_, err = s.db.ContainerCreate(ContainerArgs{Name: "c1", Ctype: CTypeRegular, Config: map[string]string{"health.command": "true"}})
+	s.Nil(err)
+
+	_, err = s.db.ContainerCreate(ContainerArgs{Name: "c2", Ctype: CTypeRegular, Profiles: []string{"theprofile"}})
+	s.Nil(err)
+
+	_, err = s.db.ContainerCreate(ContainerArgs{Name: "c3", Ctype: CTypeRegular, Config: map[string]string{"other": "true"}})
+	s.Nil(err)
+
+	names, err := s.db.ContainersListWithConfigKey(CTypeRegular, "health.command")
+	s.Nil(err)
+	s.Equal([]string{"c1", "c2"}, names)
+}
+
+func (s *dbTestSuite) Test_ContainerNextSnapshot() {
+	for _, name := range []string{"c1/snap0", "c1/snap3", "c1/snap7x", "c1/xsnap8", "c1/snap+9", "c1/daily-5", "c1/other"} {
+		_, err := s.db.ContainerCreate(ContainerArgs{Name: name, Ctype: CTypeSnapshot})
+		s.Nil(err)
+	}
+
+	s.Equal(4, s.db.ContainerNextSnapshot("c1", "snap%d"))
+	s.Equal(6, s.db.ContainerNextSnapshot("c1", "daily-%d"))
+	s.Equal(0, s.db.ContainerNextSnapshot("c1", "weekly-%d"))
+	s.Equal(0, s.db.ContainerNextSnapshot("c2", "snap%d"))
+}
+
+func (s *dbTestSuite) Test_ContainerBackups() {
+	expiry := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	err := s.db.ContainerBackupCreate(ContainerBackupArgs{
+		ContainerID:   1,
+		Name:          "backup0",
+		CreationDate:  time.Now(),
+		ExpiryDate:    expiry,
+		ContainerOnly: true,
+	})
+	s.Nil(err)
+
+	backup, err := s.db.ContainerGetBackup("thename", "backup0")
+	s.Nil(err)
+	s.Equal(1, backup.ContainerID)
+	s.True(backup.ExpiryDate.Equal(expiry))
+	s.True(backup.ContainerOnly)
+	s.False(backup.OptimizedStorage)
+
+	err = s.db.ContainerBackupRename("thename", "backup0", "backup1")
+	s.Nil(err)
+
+	backups, err := s.db.ContainerGetBackups("thename")
+	s.Nil(err)
+	s.Equal([]string{"backup1"}, backups)
+
+	_, err = s.db.ContainerGetBackup("thename", "backup0")
+	s.Equal(NoSuchObjectError, err)
+
+	err = s.db.ContainerBackupRemove("thename", "backup1")
+	s.Nil(err)
+
+	backups, err = s.db.ContainerGetBackups("thename")
+	s.Nil(err)
+	s.Equal([]string{}, backups)
+}
+
+func (s *dbTestSuite) Test_ContainerGetExpiredBackups() {
+	now := time.Now().UTC()
+
+	backups := map[string]time.Time{
+		"expired": now.Add(-time.Hour),
+		"valid":   now.Add(time.Hour),
+		"forever": {},
+	}
+
+	for name, expiry := range backups {
+		err := s.db.ContainerBackupCreate(ContainerBackupArgs{ContainerID: 1, Name: name, CreationDate: now, ExpiryDate: expiry})
+		s.Nil(err)
+	}
+
+	expired, err := s.db.ContainerGetExpiredBackups(now)
+	s.Nil(err)
+	s.Equal([]string{"thename/expired"}, expired)
+}
+
 func (s *dbTestSuite) Test_dbProfileConfig() {
 	var err error
 	var result map[string]string
@@ -491,3 +621,157 @@ func (s *dbTestSuite) Test_dbDevices_containers() {
 			fmt.Sprintf("Mismatching value for key %s: %s != %s", key, subresult[key], value))
 	}
 }
+
+func (s *dbTestSuite) Test_ProjectGet_default() {
+	_, project, err := s.db.ProjectGet("default")
+	s.Nil(err)
+	s.Equal("true", project.Config["features.images"])
+	s.Equal("true", project.Config["features.profiles"])
+}
+
+func (s *dbTestSuite) Test_ProjectCreate() {
+	_, err := s.db.ProjectCreate("foo", "Foo project", map[string]string{"features.profiles": "false"})
+	s.Nil(err)
+
+	projects, err := s.db.Projects()
+	s.Nil(err)
+	s.Equal([]string{"default", "foo"}, projects)
+
+	_, project, err := s.db.ProjectGet("foo")
+	s.Nil(err)
+	s.Equal("Foo project", project.Description)
+	s.Equal(map[string]string{"features.profiles": "false"}, project.Config)
+}
+
+func (s *dbTestSuite) Test_ProjectDelete_removes_profiles_and_aliases() {
+	_, err := s.db.ProjectCreate("foo", "", map[string]string{})
+	s.Nil(err)
+
+	_, err = s.db.ProfileCreate("foo", "foo_default", "", map[string]string{}, types.Devices{})
+	s.Nil(err)
+
+	err = s.db.ImageAliasAdd("foo", "foo_somealias", 1, "")
+	s.Nil(err)
+
+	profiles, err := s.db.ProfilesForProject("foo")
+	s.Nil(err)
+	s.Equal([]string{"foo_default"}, profiles)
+
+	err = s.db.ProjectDelete("foo")
+	s.Nil(err)
+
+	_, _, err = s.db.ProfileGet("foo_default")
+	s.Equal(sql.ErrNoRows, err)
+
+	_, _, err = s.db.ImageAliasGet("foo_somealias", true)
+	s.Equal(NoSuchObjectError, err)
+
+	aliases, err := s.db.ImageAliasesGetForProject("default")
+	s.Nil(err)
+	s.Equal([]string{"somealias"}, aliases)
+}
+
+func (s *dbTestSuite) Test_ImageProjectAdd() {
+	_, err := s.db.ProjectCreate("foo", "", map[string]string{})
+	s.Nil(err)
+
+	images, err := s.db.ImagesGetForProject("foo", false)
+	s.Nil(err)
+	s.Len(images, 0)
+
+	err = s.db.ImageProjectAdd(1, "foo")
+	s.Nil(err)
+
+	images, err = s.db.ImagesGetForProject("foo", false)
+	s.Nil(err)
+	s.Equal([]string{"fingerprint"}, images)
+
+	projects, err := s.db.ImageProjects(1)
+	s.Nil(err)
+	s.Equal([]string{"foo"}, projects)
+
+	err = s.db.ImageProjectRemove(1, "foo")
+	s.Nil(err)
+
+	images, err = s.db.ImagesGetForProject("foo", false)
+	s.Nil(err)
+	s.Len(images, 0)
+}
+
+func (s *dbTestSuite) Test_CertSave_restricted() {
+	_, err := s.db.ProjectCreate("foo", "", map[string]string{})
+	s.Nil(err)
+
+	cert := &CertInfo{
+		Fingerprint: "abcd",
+		Type:        1,
+		Name:        "ci",
+		Certificate: "PEM",
+		Restricted:  true,
+		Projects:    []string{"foo"},
+	}
+	err = s.db.CertSave(cert)
+	s.Nil(err)
+
+	result, err := s.db.CertificateGet("abcd")
+	s.Nil(err)
+	s.True(result.Restricted)
+	s.Equal([]string{"foo"}, result.Projects)
+
+	err = s.db.CertUpdate("abcd", "ci", 1, true, []string{"default", "foo"})
+	s.Nil(err)
+
+	certs, err := s.db.CertificatesGet()
+	s.Nil(err)
+	s.Len(certs, 1)
+	s.Equal([]string{"default", "foo"}, certs[0].Projects)
+
+	// Unknown projects are rejected
+	err = s.db.CertUpdate("abcd", "ci", 1, true, []string{"bar"})
+	s.NotNil(err)
+}
+
+func (s *dbTestSuite) Test_OperationsHistory() {
+	now := time.Now().UTC()
+	for i, uuid := range []string{"old", "recent"} {
+		createdAt := now.Add(time.Duration(i-2) * time.Hour)
+		err := s.db.OperationHistoryAdd(OperationHistoryArgs{
+			UUID:              uuid,
+			Project:           "default",
+			Class:             "task",
+			StatusCode:        200,
+			Resources:         map[string][]string{"containers": {"c1"}},
+			RequestorProtocol: "unix",
+			RequestorUsername: "1000",
+			CreatedAt:         createdAt,
+			FinishedAt:        createdAt.Add(time.Minute),
+		})
+		s.Nil(err)
+	}
+
+	op, err := s.db.OperationHistoryGet("recent")
+	s.Nil(err)
+	s.Equal(map[string][]string{"containers": {"c1"}}, op.Resources)
+	s.Equal("1000", op.RequestorUsername)
+	s.Equal(time.Minute, op.FinishedAt.Sub(op.CreatedAt))
+
+	_, err = s.db.OperationHistoryGet("unknown")
+	s.Equal(NoSuchObjectError, err)
+
+	ops, err := s.db.OperationsHistoryGet(time.Time{}, time.Time{})
+	s.Nil(err)
+	s.Len(ops, 2)
+	s.Equal("old", ops[0].UUID)
+
+	ops, err = s.db.OperationsHistoryGet(now.Add(-90*time.Minute), time.Time{})
+	s.Nil(err)
+	s.Len(ops, 1)
+	s.Equal("recent", ops[0].UUID)
+
+	err = s.db.OperationsHistoryPrune(now.Add(-time.Hour))
+	s.Nil(err)
+
+	ops, err = s.db.OperationsHistoryGet(time.Time{}, now.Add(-90*time.Minute))
+	s.Nil(err)
+	s.Len(ops, 0)
+}
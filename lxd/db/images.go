@@ -39,6 +39,98 @@ func (n *Node) ImagesGet(public bool) ([]string, error) {
 	return results, nil
 }
 
+// ImagesGetForProject returns the fingerprints of the images available in the
+// given project.
+func (n *Node) ImagesGetForProject(project string, public bool) ([]string, error) {
+	q := `SELECT images.fingerprint FROM images
+		JOIN images_projects ON images.id=images_projects.image_id
+		JOIN projects ON images_projects.project_id=projects.id
+		WHERE projects.name=?`
+	if public == true {
+		q += " AND images.public=1"
+	}
+
+	var fp string
+	inargs := []interface{}{project}
+	outfmt := []interface{}{fp}
+	dbResults, err := queryScan(n.db, q, inargs, outfmt)
+	if err != nil {
+		return []string{}, err
+	}
+
+	results := []string{}
+	for _, r := range dbResults {
+		results = append(results, r[0].(string))
+	}
+
+	return results, nil
+}
+
+// ImageProjects returns the names of the projects the given image is
+// available in.
+func (n *Node) ImageProjects(id int) ([]string, error) {
+	q := `SELECT projects.name FROM projects
+		JOIN images_projects ON projects.id=images_projects.project_id
+		WHERE images_projects.image_id=?`
+
+	var name string
+	inargs := []interface{}{id}
+	outfmt := []interface{}{name}
+	dbResults, err := queryScan(n.db, q, inargs, outfmt)
+	if err != nil {
+		return []string{}, err
+	}
+
+	results := []string{}
+	for _, r := range dbResults {
+		results = append(results, r[0].(string))
+	}
+
+	return results, nil
+}
+
+// ImageProjectAdd makes the given image available in the given project.
+func (n *Node) ImageProjectAdd(id int, project string) error {
+	stmt := `INSERT OR IGNORE INTO images_projects (image_id, project_id)
+		SELECT ?, id FROM projects WHERE name=?`
+	_, err := exec(n.db, stmt, id, project)
+	return err
+}
+
+// ImageProjectRemove removes the given image, and the aliases pointing to it,
+// from the given project.
+func (n *Node) ImageProjectRemove(id int, project string) error {
+	tx, err := begin(n.db)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM images_aliases
+		WHERE image_id=? AND project_id=(SELECT id FROM projects WHERE name=?)`, id, project)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM images_projects
+		WHERE image_id=? AND project_id=(SELECT id FROM projects WHERE name=?)`, id, project)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return TxCommit(tx)
+}
+
+// ImageProjectsCopy makes the image with the destination ID available in
+// all the projects the source image is available in.
+func (n *Node) ImageProjectsCopy(source int, destination int) error {
+	stmt := `INSERT OR IGNORE INTO images_projects (image_id, project_id)
+		SELECT ?, project_id FROM images_projects WHERE image_id=?`
+	_, err := exec(n.db, stmt, destination, source)
+	return err
+}
+
 func (n *Node) ImagesGetExpired(expiry int64) ([]string, error) {
 	q := `SELECT fingerprint, last_use_date, upload_date FROM images WHERE cached=1`
 
@@ -328,6 +420,26 @@ func (n *Node) ImageAliasesGet() ([]string, error) {
 	return names, nil
 }
 
+// ImageAliasesGetForProject returns the names of the image aliases
+// belonging to the given project.
+func (n *Node) ImageAliasesGetForProject(project string) ([]string, error) {
+	q := `SELECT images_aliases.name FROM images_aliases
+		JOIN projects ON images_aliases.project_id=projects.id
+		WHERE projects.name=?`
+	var name string
+	inargs := []interface{}{project}
+	outfmt := []interface{}{name}
+	results, err := queryScan(n.db, q, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, res := range results {
+		names = append(names, res[0].(string))
+	}
+	return names, nil
+}
+
 func (n *Node) ImageAliasGet(name string, isTrustedClient bool) (int, api.ImageAliasesEntry, error) {
 	q := `SELECT images_aliases.id, images.fingerprint, images_aliases.description
 			 FROM images_aliases
@@ -376,9 +488,14 @@ func (n *Node) ImageAliasesMove(source int, destination int) error {
 }
 
 // Insert an alias ento the database.
-func (n *Node) ImageAliasAdd(name string, imageID int, desc string) error {
-	stmt := `INSERT INTO images_aliases (name, image_id, description) values (?, ?, ?)`
-	_, err := exec(n.db, stmt, name, imageID, desc)
+func (n *Node) ImageAliasAdd(project string, name string, imageID int, desc string) error {
+	if project == "" {
+		project = "default"
+	}
+
+	stmt := `INSERT INTO images_aliases (name, image_id, description, project_id)
+		values (?, ?, ?, (SELECT id FROM projects WHERE name=?))`
+	_, err := exec(n.db, stmt, name, imageID, desc, project)
 	return err
 }
 
@@ -461,7 +578,7 @@ func (n *Node) ImageUpdate(id int, fname string, sz int64, public bool, autoUpda
 	return nil
 }
 
-func (n *Node) ImageInsert(fp string, fname string, sz int64, public bool, autoUpdate bool, architecture string, createdAt time.Time, expiresAt time.Time, properties map[string]string) error {
+func (n *Node) ImageInsert(project string, fp string, fname string, sz int64, public bool, autoUpdate bool, architecture string, createdAt time.Time, expiresAt time.Time, properties map[string]string) error {
 	arch, err := osarch.ArchitectureId(architecture)
 	if err != nil {
 		arch = 0
@@ -495,14 +612,25 @@ func (n *Node) ImageInsert(fp string, fname string, sz int64, public bool, autoU
 		return err
 	}
 
-	if len(properties) > 0 {
-		id64, err := result.LastInsertId()
-		if err != nil {
-			tx.Rollback()
-			return err
-		}
-		id := int(id64)
+	id64, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	id := int(id64)
 
+	if project == "" {
+		project = "default"
+	}
+
+	_, err = tx.Exec(`INSERT INTO images_projects (image_id, project_id)
+		SELECT ?, id FROM projects WHERE name=?`, id, project)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(properties) > 0 {
 		pstmt, err := tx.Prepare(`INSERT INTO images_properties (image_id, type, key, value) VALUES (?, 0, ?, ?)`)
 		if err != nil {
 			tx.Rollback()
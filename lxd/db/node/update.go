@@ -84,9 +84,106 @@ var updates = map[int]schema.Update{
 	34: updateFromV33,
 	35: updateFromV34,
 	36: updateFromV35,
+	37: updateFromV36,
+	38: updateFromV37,
+	39: updateFromV38,
+	40: updateFromV39,
+	41: updateFromV40,
 }
 
 // Schema updates begin here
+func updateFromV40(tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE operations_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    uuid TEXT NOT NULL,
+    project TEXT NOT NULL,
+    class TEXT NOT NULL,
+    status_code INTEGER NOT NULL,
+    resources TEXT NOT NULL,
+    error TEXT NOT NULL,
+    requestor_protocol TEXT NOT NULL,
+    requestor_username TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL,
+    UNIQUE (uuid)
+);`
+	_, err := tx.Exec(stmt)
+	return err
+}
+
+func updateFromV39(tx *sql.Tx) error {
+	stmt := `
+ALTER TABLE certificates ADD COLUMN restricted INTEGER NOT NULL DEFAULT 0;
+CREATE TABLE certificates_projects (
+    certificate_id INTEGER NOT NULL,
+    project_id INTEGER NOT NULL,
+    FOREIGN KEY (certificate_id) REFERENCES certificates (id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE,
+    UNIQUE (certificate_id, project_id)
+);`
+	_, err := tx.Exec(stmt)
+	return err
+}
+
+func updateFromV38(tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    UNIQUE (name)
+);
+CREATE TABLE projects_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    value TEXT,
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE,
+    UNIQUE (project_id, key)
+);
+INSERT INTO projects (name, description) VALUES ("default", "Default LXD project");
+INSERT INTO projects_config (project_id, key, value) SELECT id, "features.images", "true" FROM projects WHERE name="default";
+INSERT INTO projects_config (project_id, key, value) SELECT id, "features.profiles", "true" FROM projects WHERE name="default";
+
+ALTER TABLE containers ADD COLUMN project_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE profiles ADD COLUMN project_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE images_aliases ADD COLUMN project_id INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE images_projects (
+    image_id INTEGER NOT NULL,
+    project_id INTEGER NOT NULL,
+    FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE,
+    UNIQUE (image_id, project_id)
+);
+INSERT INTO images_projects (image_id, project_id) SELECT id, 1 FROM images;`
+	_, err := tx.Exec(stmt)
+	return err
+}
+
+func updateFromV37(tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE containers_backups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    container_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    creation_date DATETIME,
+    expiry_date DATETIME,
+    container_only INTEGER NOT NULL DEFAULT 0,
+    optimized_storage INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (container_id) REFERENCES containers (id) ON DELETE CASCADE,
+    UNIQUE (container_id, name)
+);`
+	_, err := tx.Exec(stmt)
+	return err
+}
+
+func updateFromV36(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE containers ADD COLUMN expiry_date DATETIME;")
+	return err
+}
+
 func updateFromV35(tx *sql.Tx) error {
 	stmts := `
 CREATE TABLE tmp (
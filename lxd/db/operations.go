@@ -0,0 +1,118 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// OperationHistoryArgs is a value object holding the details of a finished
+// operation, as kept in the operations history.
+type OperationHistoryArgs struct {
+	UUID              string
+	Project           string
+	Class             string
+	StatusCode        int
+	Resources         map[string][]string
+	Err               string
+	RequestorProtocol string
+	RequestorUsername string
+	CreatedAt         time.Time
+	FinishedAt        time.Time
+}
+
+const operationHistoryColumns = `uuid, project, class, status_code, resources, error,
+    requestor_protocol, requestor_username, created_at, finished_at`
+
+// OperationHistoryAdd records a finished operation in the history.
+func (n *Node) OperationHistoryAdd(args OperationHistoryArgs) error {
+	resources, err := json.Marshal(args.Resources)
+	if err != nil {
+		return err
+	}
+
+	_, err = exec(n.db, `INSERT INTO operations_history (`+operationHistoryColumns+`)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		args.UUID, args.Project, args.Class, args.StatusCode, string(resources), args.Err,
+		args.RequestorProtocol, args.RequestorUsername, args.CreatedAt.UTC(), args.FinishedAt.UTC())
+	return err
+}
+
+// OperationHistoryGet returns the finished operation with the given UUID.
+func (n *Node) OperationHistoryGet(uuid string) (OperationHistoryArgs, error) {
+	q := `SELECT ` + operationHistoryColumns + ` FROM operations_history WHERE uuid=?`
+	rows, err := n.db.Query(q, uuid)
+	if err != nil {
+		return OperationHistoryArgs{}, err
+	}
+	defer rows.Close()
+
+	operations, err := operationHistoryScan(rows)
+	if err != nil {
+		return OperationHistoryArgs{}, err
+	}
+
+	if len(operations) == 0 {
+		return OperationHistoryArgs{}, NoSuchObjectError
+	}
+
+	return operations[0], nil
+}
+
+// OperationsHistoryGet returns the finished operations created in the given
+// time range, oldest first. A zero time leaves the range open on that end.
+func (n *Node) OperationsHistoryGet(since time.Time, until time.Time) ([]OperationHistoryArgs, error) {
+	q := `SELECT ` + operationHistoryColumns + ` FROM operations_history WHERE 1=1`
+	args := []interface{}{}
+
+	if !since.IsZero() {
+		q += " AND created_at >= ?"
+		args = append(args, since.UTC())
+	}
+
+	if !until.IsZero() {
+		q += " AND created_at <= ?"
+		args = append(args, until.UTC())
+	}
+
+	q += " ORDER BY created_at"
+
+	rows, err := n.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return operationHistoryScan(rows)
+}
+
+// OperationsHistoryPrune removes the operations which finished before the
+// given date from the history.
+func (n *Node) OperationsHistoryPrune(date time.Time) error {
+	_, err := exec(n.db, "DELETE FROM operations_history WHERE finished_at < ?", date.UTC())
+	return err
+}
+
+func operationHistoryScan(rows *sql.Rows) ([]OperationHistoryArgs, error) {
+	operations := []OperationHistoryArgs{}
+
+	for rows.Next() {
+		args := OperationHistoryArgs{}
+		var resources string
+
+		err := rows.Scan(&args.UUID, &args.Project, &args.Class, &args.StatusCode, &resources, &args.Err,
+			&args.RequestorProtocol, &args.RequestorUsername, &args.CreatedAt, &args.FinishedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(resources), &args.Resources)
+		if err != nil {
+			return nil, err
+		}
+
+		operations = append(operations, args)
+	}
+
+	return operations, rows.Err()
+}
@@ -29,6 +29,28 @@ func (n *Node) Profiles() ([]string, error) {
 	return response, nil
 }
 
+// ProfilesForProject returns the names of the profiles belonging to the
+// given project.
+func (n *Node) ProfilesForProject(project string) ([]string, error) {
+	q := `SELECT profiles.name FROM profiles
+		JOIN projects ON profiles.project_id=projects.id
+		WHERE projects.name=?`
+	inargs := []interface{}{project}
+	var name string
+	outfmt := []interface{}{name}
+	result, err := queryScan(n.db, q, inargs, outfmt)
+	if err != nil {
+		return []string{}, err
+	}
+
+	response := []string{}
+	for _, r := range result {
+		response = append(response, r[0].(string))
+	}
+
+	return response, nil
+}
+
 func (n *Node) ProfileGet(name string) (int64, *api.Profile, error) {
 	id := int64(-1)
 	description := sql.NullString{}
@@ -62,14 +84,18 @@ func (n *Node) ProfileGet(name string) (int64, *api.Profile, error) {
 	return id, &profile, nil
 }
 
-func (n *Node) ProfileCreate(profile string, description string, config map[string]string,
+func (n *Node) ProfileCreate(project string, profile string, description string, config map[string]string,
 	devices types.Devices) (int64, error) {
 
 	tx, err := begin(n.db)
 	if err != nil {
 		return -1, err
 	}
-	result, err := tx.Exec("INSERT INTO profiles (name, description) VALUES (?, ?)", profile, description)
+	if project == "" {
+		project = "default"
+	}
+
+	result, err := tx.Exec("INSERT INTO profiles (name, description, project_id) VALUES (?, ?, (SELECT id FROM projects WHERE name=?))", profile, description, project)
 	if err != nil {
 		tx.Rollback()
 		return -1, err
@@ -108,7 +134,7 @@ func (n *Node) ProfileCreateDefault() error {
 		return nil
 	}
 
-	_, err := n.ProfileCreate("default", "Default LXD profile", map[string]string{}, types.Devices{})
+	_, err := n.ProfileCreate("default", "default", "Default LXD profile", map[string]string{}, types.Devices{})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,252 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// Projects returns the names of all the projects.
+func (n *Node) Projects() ([]string, error) {
+	q := "SELECT name FROM projects ORDER BY name"
+	inargs := []interface{}{}
+	var name string
+	outfmt := []interface{}{name}
+	result, err := queryScan(n.db, q, inargs, outfmt)
+	if err != nil {
+		return []string{}, err
+	}
+
+	response := []string{}
+	for _, r := range result {
+		response = append(response, r[0].(string))
+	}
+
+	return response, nil
+}
+
+// ProjectGet returns the ID and details of the project with the given name.
+func (n *Node) ProjectGet(name string) (int64, *api.Project, error) {
+	description := sql.NullString{}
+	id := int64(-1)
+
+	q := "SELECT id, description FROM projects WHERE name=?"
+	arg1 := []interface{}{name}
+	arg2 := []interface{}{&id, &description}
+	err := dbQueryRowScan(n.db, q, arg1, arg2)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	config, err := n.ProjectConfigGet(id)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	project := api.Project{
+		Name: name,
+	}
+	project.Description = description.String
+	project.Config = config
+
+	return id, &project, nil
+}
+
+// ProjectConfigGet returns the config of the project with the given ID.
+func (n *Node) ProjectConfigGet(id int64) (map[string]string, error) {
+	var key, value string
+	query := "SELECT key, value FROM projects_config WHERE project_id=?"
+	inargs := []interface{}{id}
+	outfmt := []interface{}{key, value}
+	results, err := queryScan(n.db, query, inargs, outfmt)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get project '%d'", id)
+	}
+
+	config := map[string]string{}
+
+	for _, r := range results {
+		key = r[0].(string)
+		value = r[1].(string)
+
+		config[key] = value
+	}
+
+	return config, nil
+}
+
+// ProjectCreate adds a new project to the database.
+func (n *Node) ProjectCreate(name, description string, config map[string]string) (int64, error) {
+	tx, err := begin(n.db)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := tx.Exec("INSERT INTO projects (name, description) VALUES (?, ?)", name, description)
+	if err != nil {
+		tx.Rollback()
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return -1, err
+	}
+
+	err = ProjectConfigAdd(tx, id, config)
+	if err != nil {
+		tx.Rollback()
+		return -1, err
+	}
+
+	err = TxCommit(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// ProjectCreateDefault creates the default project, if it doesn't exist yet.
+func (n *Node) ProjectCreateDefault() error {
+	_, _, err := n.ProjectGet("default")
+	if err == nil {
+		// Default project already exists.
+		return nil
+	}
+
+	config := map[string]string{
+		"features.images":   "true",
+		"features.profiles": "true",
+	}
+
+	_, err = n.ProjectCreate("default", "Default LXD project", config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ProjectUpdate replaces the description and config of the given project.
+func (n *Node) ProjectUpdate(name, description string, config map[string]string) error {
+	id, _, err := n.ProjectGet(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := begin(n.db)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE projects SET description=? WHERE id=?", description, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = ProjectConfigClear(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = ProjectConfigAdd(tx, id, config)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return TxCommit(tx)
+}
+
+func ProjectConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	str := fmt.Sprintf("INSERT INTO projects_config (project_id, key, value) VALUES(?, ?, ?)")
+	stmt, err := tx.Prepare(str)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ProjectConfigClear(tx *sql.Tx, id int64) error {
+	_, err := tx.Exec("DELETE FROM projects_config WHERE project_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ProjectRename changes the name of the given project.
+func (n *Node) ProjectRename(oldName string, newName string) error {
+	id, _, err := n.ProjectGet(oldName)
+	if err != nil {
+		return err
+	}
+
+	tx, err := begin(n.db)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE projects SET name=? WHERE id=?", newName, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return TxCommit(tx)
+}
+
+// ProjectDelete removes the given project along with any profile or image
+// alias still attached to it.
+func (n *Node) ProjectDelete(name string) error {
+	id, _, err := n.ProjectGet(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := begin(n.db)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM profiles WHERE project_id=?", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM images_aliases WHERE project_id=?", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM projects WHERE id=?", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return TxCommit(tx)
+}
@@ -6,6 +6,7 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 )
 
@@ -317,6 +318,29 @@ func (n *Node) StoragePoolVolumesGetType(volumeType int, poolID int64) ([]string
 	return response, nil
 }
 
+// StoragePoolVolumeSnapshotsGetType returns the names of all snapshots of a
+// given storage volume, in the order they were created.
+func (n *Node) StoragePoolVolumeSnapshotsGetType(volumeName string, volumeType int, poolID int64) ([]string, error) {
+	result := []string{}
+
+	regexp := volumeName + shared.SnapshotDelimiter
+	length := len(regexp)
+	query := "SELECT name FROM storage_volumes WHERE storage_pool_id=? AND type=? AND SUBSTR(name,1,?)=? ORDER BY id"
+	inargs := []interface{}{poolID, volumeType, length, regexp}
+	outfmt := []interface{}{volumeName}
+
+	dbResults, err := queryScan(n.db, query, inargs, outfmt)
+	if err != nil {
+		return result, err
+	}
+
+	for _, r := range dbResults {
+		result = append(result, r[0].(string))
+	}
+
+	return result, nil
+}
+
 // Get a single storage volume attached to a given storage pool of a given type.
 func (n *Node) StoragePoolVolumeGetType(volumeName string, volumeType int, poolID int64) (int64, *api.StorageVolume, error) {
 	volumeID, err := n.StoragePoolVolumeGetTypeID(volumeName, volumeType, poolID)
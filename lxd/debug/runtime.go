@@ -0,0 +1,23 @@
+package debug
+
+import (
+	"runtime"
+)
+
+// RuntimeStats holds a snapshot of the Go runtime statistics of the daemon.
+type RuntimeStats struct {
+	Goroutines int
+	Memory     runtime.MemStats
+}
+
+// Runtime returns the current Go runtime statistics, including the number of
+// goroutines and the memory allocator statistics.
+func Runtime() RuntimeStats {
+	stats := RuntimeStats{
+		Goroutines: runtime.NumGoroutine(),
+	}
+
+	runtime.ReadMemStats(&stats.Memory)
+
+	return stats
+}
@@ -0,0 +1,19 @@
+package debug_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/lxd/lxd/debug"
+)
+
+func TestRuntime(t *testing.T) {
+	stats := debug.Runtime()
+
+	// At least the goroutine running the test exists and some memory has
+	// been allocated from the system.
+	assert.True(t, stats.Goroutines > 0)
+	assert.True(t, stats.Memory.Sys > 0)
+	assert.True(t, stats.Memory.HeapAlloc > 0)
+}
@@ -19,6 +19,7 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/lxc/lxd/lxd/cgroup"
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/util"
@@ -538,10 +539,16 @@ func deviceTaskBalance(s *state.State) {
 	}
 
 	// Get effective cpus list - those are all guaranteed to be online
-	effectiveCpus, err := cGroupGet("cpuset", "/", "cpuset.effective_cpus")
+	layout := s.OS.CGroupLayout
+	effectiveFile := "cpuset.effective_cpus"
+	if layout == cgroup.Unified {
+		effectiveFile = "cpuset.cpus.effective"
+	}
+
+	effectiveCpus, err := cGroupGet(layout, "cpuset", "/", effectiveFile)
 	if err != nil {
 		// Older kernel - use cpuset.cpus
-		effectiveCpus, err = cGroupGet("cpuset", "/", "cpuset.cpus")
+		effectiveCpus, err = cGroupGet(layout, "cpuset", "/", "cpuset.cpus")
 		if err != nil {
 			logger.Errorf("Error reading host's cpuset.cpus")
 			return
@@ -584,8 +591,8 @@ func deviceTaskBalance(s *state.State) {
 
 	effectiveCpus = strings.Join(effectiveCpusSlice, ",")
 
-	err = cGroupSet("cpuset", "/lxc", "cpuset.cpus", effectiveCpus)
-	if err != nil && shared.PathExists("/sys/fs/cgroup/cpuset/lxc") {
+	err = cGroupSet(layout, "cpuset", "/lxc", "cpuset.cpus", effectiveCpus)
+	if err != nil && cGroupExists(layout, "cpuset", "/lxc") {
 		logger.Warn("Error setting lxd's cpuset.cpus", log.Ctx{"err": err})
 	}
 	cpus, err := parseCpuset(effectiveCpus)
@@ -996,53 +1003,53 @@ func deviceMountDisk(srcPath string, dstPath string, readonly bool, recursive bo
 	return nil
 }
 
-func deviceParseCPU(cpuAllowance string, cpuPriority string) (string, string, string, error) {
+func deviceParseCPU(cpuAllowance string, cpuPriority string) (int64, int64, int64, error) {
 	var err error
 
 	// Parse priority
-	cpuShares := 0
+	cpuShares := int64(0)
 	cpuPriorityInt := 10
 	if cpuPriority != "" {
 		cpuPriorityInt, err = strconv.Atoi(cpuPriority)
 		if err != nil {
-			return "", "", "", err
+			return -1, -1, -1, err
 		}
 	}
-	cpuShares -= 10 - cpuPriorityInt
+	cpuShares -= int64(10 - cpuPriorityInt)
 
 	// Parse allowance
-	cpuCfsQuota := "-1"
-	cpuCfsPeriod := "100000"
+	cpuCfsQuota := int64(-1)
+	cpuCfsPeriod := int64(100000)
 
 	if cpuAllowance != "" {
 		if strings.HasSuffix(cpuAllowance, "%") {
 			// Percentage based allocation
 			percent, err := strconv.Atoi(strings.TrimSuffix(cpuAllowance, "%"))
 			if err != nil {
-				return "", "", "", err
+				return -1, -1, -1, err
 			}
 
-			cpuShares += (10 * percent) + 24
+			cpuShares += int64((10 * percent) + 24)
 		} else {
 			// Time based allocation
 			fields := strings.SplitN(cpuAllowance, "/", 2)
 			if len(fields) != 2 {
-				return "", "", "", fmt.Errorf("Invalid allowance: %s", cpuAllowance)
+				return -1, -1, -1, fmt.Errorf("Invalid allowance: %s", cpuAllowance)
 			}
 
 			quota, err := strconv.Atoi(strings.TrimSuffix(fields[0], "ms"))
 			if err != nil {
-				return "", "", "", err
+				return -1, -1, -1, err
 			}
 
 			period, err := strconv.Atoi(strings.TrimSuffix(fields[1], "ms"))
 			if err != nil {
-				return "", "", "", err
+				return -1, -1, -1, err
 			}
 
 			// Set limit in ms
-			cpuCfsQuota = fmt.Sprintf("%d", quota*1000)
-			cpuCfsPeriod = fmt.Sprintf("%d", period*1000)
+			cpuCfsQuota = int64(quota * 1000)
+			cpuCfsPeriod = int64(period * 1000)
 			cpuShares += 1024
 		}
 	} else {
@@ -1055,7 +1062,7 @@ func deviceParseCPU(cpuAllowance string, cpuPriority string) (string, string, st
 		cpuShares = 0
 	}
 
-	return fmt.Sprintf("%d", cpuShares), cpuCfsQuota, cpuCfsPeriod, nil
+	return cpuShares, cpuCfsQuota, cpuCfsPeriod, nil
 }
 
 func deviceTotalMemory() (int64, error) {
@@ -270,12 +270,32 @@ func getCred(conn *net.UnixConn) (*ucred, error) {
  */
 func extractUnderlyingConn(w http.ResponseWriter) *net.UnixConn {
 	v := reflect.Indirect(reflect.ValueOf(w))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
 	connPtr := v.FieldByName("conn")
+	if !connPtr.IsValid() {
+		return nil
+	}
+
 	conn := reflect.Indirect(connPtr)
+	if conn.Kind() != reflect.Struct {
+		return nil
+	}
+
 	rwc := conn.FieldByName("rwc")
+	if !rwc.IsValid() {
+		return nil
+	}
 
 	netConnPtr := (*net.Conn)(unsafe.Pointer(rwc.UnsafeAddr()))
-	unixConnPtr := (*netConnPtr).(*net.UnixConn)
+
+	// Connections over the network aren't unix ones
+	unixConnPtr, ok := (*netConnPtr).(*net.UnixConn)
+	if !ok {
+		return nil
+	}
 
 	return unixConnPtr
 }
@@ -13,7 +13,9 @@ import (
 	"github.com/pborman/uuid"
 
 	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/version"
 )
 
 type eventsHandler struct {
@@ -36,17 +38,21 @@ func logContextMap(ctx []interface{}) map[string]string {
 }
 
 func (h eventsHandler) Log(r *log.Record) error {
-	eventSend("logging", shared.Jmap{
+	eventSend("", "logging", shared.Jmap{
 		"message": r.Msg,
 		"level":   r.Lvl.String(),
 		"context": logContextMap(r.Ctx)})
 	return nil
 }
 
+// The types of events a listener can subscribe to.
+var eventTypes = []string{"logging", "operation", "lifecycle"}
+
 var eventsLock sync.Mutex
 var eventListeners map[string]*eventListener = make(map[string]*eventListener)
 
 type eventListener struct {
+	projects     []string
 	connection   *websocket.Conn
 	messageTypes []string
 	active       chan bool
@@ -56,23 +62,25 @@ type eventListener struct {
 }
 
 type eventsServe struct {
-	req *http.Request
+	req      *http.Request
+	projects []string
 }
 
 func (r *eventsServe) Render(w http.ResponseWriter) error {
-	return eventsSocket(r.req, w)
+	return eventsSocket(r.req, r.projects, w)
 }
 
 func (r *eventsServe) String() string {
 	return "event handler"
 }
 
-func eventsSocket(r *http.Request, w http.ResponseWriter) error {
+func eventsSocket(r *http.Request, projects []string, w http.ResponseWriter) error {
 	listener := eventListener{}
+	listener.projects = projects
 
 	typeStr := r.FormValue("type")
 	if typeStr == "" {
-		typeStr = "logging,operation"
+		typeStr = strings.Join(eventTypes, ",")
 	}
 
 	c, err := shared.WebsocketUpgrader.Upgrade(w, r, nil)
@@ -97,12 +105,24 @@ func eventsSocket(r *http.Request, w http.ResponseWriter) error {
 }
 
 func eventsGet(d *Daemon, r *http.Request) Response {
-	return &eventsServe{r}
+	typeStr := r.FormValue("type")
+	if typeStr != "" {
+		for _, entry := range strings.Split(typeStr, ",") {
+			if !shared.StringInSlice(entry, eventTypes) {
+				return BadRequest(fmt.Errorf("Invalid event type: %s", entry))
+			}
+		}
+	}
+
+	return &eventsServe{r, d.clientRestrictedProjects(r)}
 }
 
 var eventsCmd = Command{name: "events", get: eventsGet}
 
-func eventSend(eventType string, eventMessage interface{}) error {
+// eventSend sends an event to all the listeners which subscribed to its type.
+// Listeners restricted to a set of projects only get the events of those
+// projects.
+func eventSend(project string, eventType string, eventMessage interface{}) error {
 	event := shared.Jmap{}
 	event["type"] = eventType
 	event["timestamp"] = time.Now()
@@ -120,6 +140,10 @@ func eventSend(eventType string, eventMessage interface{}) error {
 			continue
 		}
 
+		if listener.projects != nil && !shared.StringInSlice(project, listener.projects) {
+			continue
+		}
+
 		go func(listener *eventListener, body []byte) {
 			// Check that the listener still exists
 			if listener == nil {
@@ -154,3 +178,29 @@ func eventSend(eventType string, eventMessage interface{}) error {
 
 	return nil
 }
+
+// eventSendLifecycle sends a lifecycle event for an action performed on the
+// resource at the given URL. The requestor is nil for actions which weren't
+// triggered by an API client.
+func eventSendLifecycle(project string, action string, source string, requestor *api.EventLifecycleRequestor, context map[string]interface{}) error {
+	return eventSend(project, "lifecycle", api.EventLifecycle{
+		Action:    action,
+		Source:    source,
+		Context:   context,
+		Requestor: requestor,
+	})
+}
+
+// eventSendContainerLifecycle sends a lifecycle event for the container or
+// snapshot with the given internal name.
+func eventSendContainerLifecycle(name string, action string, requestor *api.EventLifecycleRequestor, context map[string]interface{}) error {
+	project := projectFromContainerName(name)
+
+	cname, sname, isSnap := containerGetParentAndSnapshotName(projectStripPrefix(project, name))
+	source := fmt.Sprintf("/%s/containers/%s", version.APIVersion, cname)
+	if isSnap {
+		source = fmt.Sprintf("%s/snapshots/%s", source, sname)
+	}
+
+	return eventSendLifecycle(project, action, projectURL(project, source), requestor, context)
+}
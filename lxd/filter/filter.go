@@ -0,0 +1,239 @@
+// Package filter implements the filter expressions and field selection
+// accepted by the listing endpoints of the API.
+//
+// A filter is a list of terms which must all match for an entry to be
+// returned. A term is either a bare word, matched against the name of the
+// entry, or a key=value pair. Keys are the dotted path of a field in the JSON
+// representation of the entry (e.g. "status" or "state.pid"), or a
+// configuration key which may be abbreviated (e.g. "u.foo" for "user.foo").
+// Values are regular expressions anchored at both ends, unless they already
+// contain ^ or $.
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Filter is a parsed list of filter terms.
+type Filter struct {
+	terms []term
+}
+
+type term struct {
+	key    string
+	value  string
+	regexp *regexp.Regexp
+}
+
+// Parse parses the given filter terms, skipping empty ones.
+func Parse(expressions []string) (*Filter, error) {
+	filter := &Filter{}
+
+	for _, expression := range expressions {
+		if expression == "" {
+			continue
+		}
+
+		t := term{}
+		if strings.Contains(expression, "=") {
+			fields := strings.SplitN(expression, "=", 2)
+			t.key = fields[0]
+			t.value = fields[1]
+
+			if t.key == "" {
+				return nil, fmt.Errorf("Missing key in filter '%s'", expression)
+			}
+		} else {
+			t.value = expression
+		}
+
+		pattern := t.value
+		if !strings.Contains(pattern, "^") && !strings.Contains(pattern, "$") {
+			pattern = "^" + pattern + "$"
+		}
+
+		// Values which aren't valid regular expressions are compared
+		// as plain strings.
+		r, err := regexp.Compile(pattern)
+		if err == nil {
+			t.regexp = r
+		}
+
+		filter.terms = append(filter.terms, t)
+	}
+
+	return filter, nil
+}
+
+// FromRequest parses the filter terms passed as "filter" query parameters.
+func FromRequest(r *http.Request) (*Filter, error) {
+	return Parse(r.URL.Query()["filter"])
+}
+
+// Fields returns the optional fields requested through the comma separated
+// "fields" query parameter.
+func Fields(r *http.Request) []string {
+	fields := []string{}
+
+	for _, field := range strings.Split(r.FormValue("fields"), ",") {
+		field = strings.TrimSpace(field)
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// Empty returns whether the filter matches everything.
+func (f *Filter) Empty() bool {
+	return f == nil || len(f.terms) == 0
+}
+
+// Uses returns whether any of the terms refers to a key under the given
+// prefix, allowing callers to only compute expensive fields when needed.
+func (f *Filter) Uses(prefix string) bool {
+	if f.Empty() {
+		return false
+	}
+
+	for _, t := range f.terms {
+		if strings.HasPrefix(t.key, prefix+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match returns whether the given entry matches all the terms of the filter.
+// Bare words are matched against the nameKey field of the entry and keys
+// which aren't fields of the entry are looked up in its configKey map.
+func (f *Filter) Match(entry interface{}, nameKey string, configKey string) (bool, error) {
+	if f.Empty() {
+		return true, nil
+	}
+
+	fields, err := Flatten(entry)
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range f.terms {
+		if !t.match(fields, nameKey, configKey) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (t term) match(fields map[string]string, nameKey string, configKey string) bool {
+	if t.key == "" {
+		name := fields[nameKey]
+		return t.matchValue(name) || strings.HasPrefix(name, t.value)
+	}
+
+	value, ok := fields[t.key]
+	if ok {
+		return t.matchValue(value)
+	}
+
+	found := false
+	prefix := configKey + "."
+	for key, value := range fields {
+		if !strings.HasPrefix(key, prefix) || !dotPrefixMatch(t.key, strings.TrimPrefix(key, prefix)) {
+			continue
+		}
+
+		if t.matchValue(value) {
+			return true
+		}
+
+		found = true
+	}
+
+	// Unset keys match an empty value
+	return !found && t.value == ""
+}
+
+func (t term) matchValue(value string) bool {
+	if t.regexp == nil {
+		return value == t.value
+	}
+
+	return t.regexp.MatchString(value)
+}
+
+// dotPrefixMatch returns whether each dot separated member of short is a
+// prefix of the matching member of full.
+func dotPrefixMatch(short string, full string) bool {
+	fullMembs := strings.Split(full, ".")
+	shortMembs := strings.Split(short, ".")
+
+	if len(fullMembs) != len(shortMembs) {
+		return false
+	}
+
+	for i := range fullMembs {
+		if !strings.HasPrefix(fullMembs[i], shortMembs[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Flatten returns the fields of the JSON representation of the given value,
+// indexed by their dotted path. Array elements are indexed by their position.
+func Flatten(entry interface{}) (map[string]string, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{}
+	flatten(fields, "", value)
+
+	return fields, nil
+}
+
+func flatten(fields map[string]string, path string, value interface{}) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+
+		return path + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flatten(fields, join(key), child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(fields, join(strconv.Itoa(i)), child)
+		}
+	case string:
+		fields[path] = v
+	case float64:
+		fields[path] = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		fields[path] = strconv.FormatBool(v)
+	case nil:
+		fields[path] = ""
+	}
+}
@@ -0,0 +1,72 @@
+package filter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mpvl/subtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/filter"
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestFilter_Match(t *testing.T) {
+	container := api.ContainerFull{
+		Container: api.Container{
+			Name:   "web1",
+			Status: "Running",
+			ExpandedConfig: map[string]string{
+				"user.role":        "frontend",
+				"security.nesting": "true",
+			},
+		},
+		State: &api.ContainerState{
+			Status: "Running",
+			Pid:    1234,
+		},
+	}
+
+	cases := map[string]bool{
+		"":                         true,
+		"web":                      true,
+		"db":                       false,
+		"web[0-9]":                 true,
+		"status=Running":           true,
+		"status=Stopped":           false,
+		"user.role=frontend":       true,
+		"u.r=front.*":              true,
+		"user.role=backend":        false,
+		"user.missing=":            true,
+		"user.missing=foo":         false,
+		"state.pid=1234":           true,
+		"state.pid=12":             false,
+		"web user.role=frontend":   true,
+		"web security.nesting=fal": false,
+	}
+
+	for expression, result := range cases {
+		subtest.Run(t, expression, func(t *testing.T) {
+			f, err := filter.Parse(strings.Fields(expression))
+			require.NoError(t, err)
+
+			match, err := f.Match(container, "name", "expanded_config")
+			require.NoError(t, err)
+			assert.Equal(t, result, match)
+		})
+	}
+}
+
+func TestFilter_Uses(t *testing.T) {
+	f, err := filter.Parse([]string{"web", "state.status=Running"})
+	require.NoError(t, err)
+
+	assert.True(t, f.Uses("state"))
+	assert.False(t, f.Uses("snapshots"))
+}
+
+func TestParse_MissingKey(t *testing.T) {
+	_, err := filter.Parse([]string{"=foo"})
+	assert.Error(t, err)
+}
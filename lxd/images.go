@@ -25,6 +25,7 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/filter"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/task"
 	"github.com/lxc/lxd/lxd/util"
@@ -217,6 +218,7 @@ func compressFile(path string, compress string) (string, error) {
 func imgPostContInfo(d *Daemon, r *http.Request, req api.ImagesPost, builddir string) (*api.Image, error) {
 	info := api.Image{}
 	info.Properties = map[string]string{}
+	project := projectParam(r)
 	name := req.Source.Name
 	ctype := req.Source.Type
 	if ctype == "" || name == "" {
@@ -244,7 +246,12 @@ func imgPostContInfo(d *Daemon, r *http.Request, req api.ImagesPost, builddir st
 		info.Public = false
 	}
 
-	c, err := containerLoadByName(d.State(), name)
+	imageProject, err := projectImageScope(d.db, project)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := containerLoadByName(d.State(), projectPrefix(project, name))
 	if err != nil {
 		return nil, err
 	}
@@ -297,7 +304,12 @@ func imgPostContInfo(d *Daemon, r *http.Request, req api.ImagesPost, builddir st
 
 	_, _, err = d.db.ImageGet(info.Fingerprint, false, true)
 	if err == nil {
-		return nil, fmt.Errorf("The image already exists: %s", info.Fingerprint)
+		err = imageAddToProject(d, info.Fingerprint, imageProject)
+		if err != nil {
+			return nil, err
+		}
+
+		return &info, nil
 	}
 
 	/* rename the the file to the expected name so our caller can use it */
@@ -311,7 +323,7 @@ func imgPostContInfo(d *Daemon, r *http.Request, req api.ImagesPost, builddir st
 	info.Properties = req.Properties
 
 	// Create the database entry
-	err = d.db.ImageInsert(info.Fingerprint, info.Filename, info.Size, info.Public, info.AutoUpdate, info.Architecture, info.CreatedAt, info.ExpiresAt, info.Properties)
+	err = d.db.ImageInsert(imageProject, info.Fingerprint, info.Filename, info.Size, info.Public, info.AutoUpdate, info.Architecture, info.CreatedAt, info.ExpiresAt, info.Properties)
 	if err != nil {
 		return nil, err
 	}
@@ -319,7 +331,7 @@ func imgPostContInfo(d *Daemon, r *http.Request, req api.ImagesPost, builddir st
 	return &info, nil
 }
 
-func imgPostRemoteInfo(d *Daemon, req api.ImagesPost, op *operation) (*api.Image, error) {
+func imgPostRemoteInfo(d *Daemon, project string, req api.ImagesPost, op *operation) (*api.Image, error) {
 	var err error
 	var hash string
 
@@ -331,7 +343,7 @@ func imgPostRemoteInfo(d *Daemon, req api.ImagesPost, op *operation) (*api.Image
 		return nil, fmt.Errorf("must specify one of alias or fingerprint for init from image")
 	}
 
-	info, err := d.ImageDownload(op, req.Source.Server, req.Source.Protocol, req.Source.Certificate, req.Source.Secret, hash, false, req.AutoUpdate, "", false)
+	info, err := d.ImageDownload(op, project, req.Source.Server, req.Source.Protocol, req.Source.Certificate, req.Source.Secret, hash, false, req.AutoUpdate, "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -357,7 +369,7 @@ func imgPostRemoteInfo(d *Daemon, req api.ImagesPost, op *operation) (*api.Image
 	return info, nil
 }
 
-func imgPostURLInfo(d *Daemon, req api.ImagesPost, op *operation) (*api.Image, error) {
+func imgPostURLInfo(d *Daemon, project string, req api.ImagesPost, op *operation) (*api.Image, error) {
 	var err error
 
 	if req.Source.URL == "" {
@@ -400,7 +412,7 @@ func imgPostURLInfo(d *Daemon, req api.ImagesPost, op *operation) (*api.Image, e
 	}
 
 	// Import the image
-	info, err := d.ImageDownload(op, url, "direct", "", "", hash, false, req.AutoUpdate, "", false)
+	info, err := d.ImageDownload(op, project, url, "direct", "", "", hash, false, req.AutoUpdate, "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -425,7 +437,7 @@ func imgPostURLInfo(d *Daemon, req api.ImagesPost, op *operation) (*api.Image, e
 	return info, nil
 }
 
-func getImgPostInfo(d *Daemon, r *http.Request, builddir string, post *os.File) (*api.Image, error) {
+func getImgPostInfo(d *Daemon, r *http.Request, project string, builddir string, post *os.File) (*api.Image, error) {
 	info := api.Image{}
 	var imageMeta *api.ImageMetadata
 	logger := logging.AddContext(logger.Log, log.Ctx{"function": "getImgPostInfo"})
@@ -618,10 +630,15 @@ func getImgPostInfo(d *Daemon, r *http.Request, builddir string, post *os.File)
 		return nil, err
 	}
 	if exists {
-		return nil, fmt.Errorf("Image with same fingerprint already exists")
+		err = imageAddToProject(d, info.Fingerprint, project)
+		if err != nil {
+			return nil, err
+		}
+
+		return &info, nil
 	}
 	// Create the database entry
-	err = d.db.ImageInsert(info.Fingerprint, info.Filename, info.Size, info.Public, info.AutoUpdate, info.Architecture, info.CreatedAt, info.ExpiresAt, info.Properties)
+	err = d.db.ImageInsert(project, info.Fingerprint, info.Filename, info.Size, info.Public, info.AutoUpdate, info.Architecture, info.CreatedAt, info.ExpiresAt, info.Properties)
 	if err != nil {
 		return nil, err
 	}
@@ -705,7 +722,14 @@ func imagesPost(d *Daemon, r *http.Request) Response {
 		return InternalError(fmt.Errorf("Invalid images JSON"))
 	}
 
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		cleanup(builddir, post)
+		return SmartError(err)
+	}
+
 	// Begin background operation
+	requestor := d.requestor(r)
 	run := func(op *operation) error {
 		var err error
 		var info *api.Image
@@ -715,14 +739,14 @@ func imagesPost(d *Daemon, r *http.Request) Response {
 
 		if imageUpload {
 			/* Processing image upload */
-			info, err = getImgPostInfo(d, r, builddir, post)
+			info, err = getImgPostInfo(d, r, project, builddir, post)
 		} else {
 			if req.Source.Type == "image" {
 				/* Processing image copy from remote */
-				info, err = imgPostRemoteInfo(d, req, op)
+				info, err = imgPostRemoteInfo(d, project, req, op)
 			} else if req.Source.Type == "url" {
 				/* Processing image copy from URL */
-				info, err = imgPostURLInfo(d, req, op)
+				info, err = imgPostURLInfo(d, project, req, op)
 			} else {
 				/* Processing image creation from container */
 				imagePublishLock.Lock()
@@ -736,7 +760,12 @@ func imagesPost(d *Daemon, r *http.Request) Response {
 
 		// Apply any provided alias
 		for _, alias := range req.Aliases {
-			_, _, err := d.db.ImageAliasGet(alias.Name, true)
+			err := projectCheckReservedName(d.db, project, alias.Name)
+			if err != nil {
+				return err
+			}
+
+			_, _, err = d.db.ImageAliasGet(projectPrefix(project, alias.Name), true)
 			if err == nil {
 				return fmt.Errorf("Alias already exists: %s", alias.Name)
 			}
@@ -746,7 +775,7 @@ func imagesPost(d *Daemon, r *http.Request) Response {
 				return err
 			}
 
-			err = d.db.ImageAliasAdd(alias.Name, id, alias.Description)
+			err = d.db.ImageAliasAdd(project, projectPrefix(project, alias.Name), id, alias.Description)
 			if err != nil {
 				return err
 			}
@@ -757,10 +786,13 @@ func imagesPost(d *Daemon, r *http.Request) Response {
 		metadata["fingerprint"] = info.Fingerprint
 		metadata["size"] = strconv.FormatInt(info.Size, 10)
 		op.UpdateMetadata(metadata)
+
+		eventSendLifecycle(projectParam(r), "image-created",
+			projectURL(projectParam(r), fmt.Sprintf("/%s/images/%s", version.APIVersion, info.Fingerprint)), requestor, nil)
 		return nil
 	}
 
-	op, err := operationCreate(operationClassTask, nil, nil, run, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, nil, nil, run, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -811,28 +843,46 @@ func getImageMetadata(fname string) (*api.ImageMetadata, error) {
 	return &metadata, nil
 }
 
-func doImagesGet(d *Daemon, recursion bool, public bool) (interface{}, error) {
-	results, err := d.db.ImagesGet(public)
+func doImagesGet(d *Daemon, project string, recursion bool, public bool, f *filter.Filter) (interface{}, error) {
+	imageProject, err := projectImageScope(d.db, project)
 	if err != nil {
 		return []string{}, err
 	}
 
-	resultString := make([]string, len(results))
-	resultMap := make([]*api.Image, len(results))
-	i := 0
+	results, err := d.db.ImagesGetForProject(imageProject, public)
+	if err != nil {
+		return []string{}, err
+	}
+
+	resultString := []string{}
+	resultMap := []*api.Image{}
 	for _, name := range results {
+		if !recursion && f.Empty() {
+			url := fmt.Sprintf("/%s/images/%s", version.APIVersion, name)
+			resultString = append(resultString, projectURL(project, url))
+			continue
+		}
+
+		image, response := doImageGet(d.db, name, public)
+		if response != nil {
+			continue
+		}
+
+		match, err := f.Match(image, "fingerprint", "properties")
+		if err != nil {
+			return []string{}, err
+		}
+
+		if !match {
+			continue
+		}
+
 		if !recursion {
 			url := fmt.Sprintf("/%s/images/%s", version.APIVersion, name)
-			resultString[i] = url
+			resultString = append(resultString, projectURL(project, url))
 		} else {
-			image, response := doImageGet(d.db, name, public)
-			if response != nil {
-				continue
-			}
-			resultMap[i] = image
+			resultMap = append(resultMap, image)
 		}
-
-		i++
 	}
 
 	if !recursion {
@@ -845,7 +895,12 @@ func doImagesGet(d *Daemon, recursion bool, public bool) (interface{}, error) {
 func imagesGet(d *Daemon, r *http.Request) Response {
 	public := d.checkTrustedClient(r) != nil
 
-	result, err := doImagesGet(d, util.IsRecursionRequest(r), public)
+	f, err := filter.FromRequest(r)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	result, err := doImagesGet(d, projectParam(r), util.IsRecursionRequest(r), public, f)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -945,10 +1000,22 @@ func autoUpdateImage(d *Daemon, op *operation, id int, info *api.Image) error {
 		op.UpdateMetadata(metadata)
 	}
 
+	// The refreshed image is made available to the same projects
+	projects, err := d.db.ImageProjects(id)
+	if err != nil {
+		logger.Error("Error getting image projects", log.Ctx{"err": err, "fp": fingerprint})
+		return err
+	}
+
+	project := "default"
+	if len(projects) > 0 {
+		project = projects[0]
+	}
+
 	// Update the image on each pool where it currently exists.
 	hash := fingerprint
 	for _, poolName := range poolNames {
-		newInfo, err := d.ImageDownload(op, source.Server, source.Protocol, source.Certificate, "", source.Alias, false, true, poolName, false)
+		newInfo, err := d.ImageDownload(op, project, source.Server, source.Protocol, source.Certificate, "", source.Alias, false, true, poolName, false)
 
 		if err != nil {
 			logger.Error("Failed to update the image", log.Ctx{"err": err, "fp": fingerprint})
@@ -987,6 +1054,12 @@ func autoUpdateImage(d *Daemon, op *operation, id int, info *api.Image) error {
 			continue
 		}
 
+		err = d.db.ImageProjectsCopy(id, newId)
+		if err != nil {
+			logger.Error("Error copying image projects", log.Ctx{"err": err, "fp": hash})
+			continue
+		}
+
 		// If we do have optimized pools, make sure we remove
 		// the volumes associated with the image.
 		if poolName != "" {
@@ -1156,14 +1229,29 @@ func doDeleteImageFromPool(state *state.State, fingerprint string, storagePool s
 func imageDelete(d *Daemon, r *http.Request) Response {
 	fingerprint := mux.Vars(r)["fingerprint"]
 
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
 	deleteFromAllPools := func() error {
 		// Use the fingerprint we received in a LIKE query and use the full
 		// fingerprint we receive from the database in all further queries.
-		imgID, imgInfo, err := d.db.ImageGet(fingerprint, false, false)
+		imgID, imgInfo, err := imageGetForProject(d, project, fingerprint, false)
 		if err != nil {
 			return err
 		}
 
+		// Only drop the image from the project if others still use it
+		projects, err := d.db.ImageProjects(imgID)
+		if err != nil {
+			return err
+		}
+
+		if len(projects) > 1 {
+			return d.db.ImageProjectRemove(imgID, project)
+		}
+
 		poolIDs, err := d.db.ImageGetPools(imgInfo.Fingerprint)
 		if err != nil {
 			return err
@@ -1203,14 +1291,27 @@ func imageDelete(d *Daemon, r *http.Request) Response {
 		return d.db.ImageDelete(imgID)
 	}
 
+	requestor := d.requestor(r)
 	rmimg := func(op *operation) error {
-		return deleteFromAllPools()
+		_, imgInfo, err := imageGetForProject(d, project, fingerprint, false)
+		if err != nil {
+			return err
+		}
+
+		err = deleteFromAllPools()
+		if err != nil {
+			return err
+		}
+
+		eventSendLifecycle(projectParam(r), "image-deleted",
+			projectURL(projectParam(r), fmt.Sprintf("/%s/images/%s", version.APIVersion, imgInfo.Fingerprint)), requestor, nil)
+		return nil
 	}
 
 	resources := map[string][]string{}
 	resources["images"] = []string{fingerprint}
 
-	op, err := operationCreate(operationClassTask, resources, nil, rmimg, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, resources, nil, rmimg, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -1218,6 +1319,53 @@ func imageDelete(d *Daemon, r *http.Request) Response {
 	return OperationResponse(op)
 }
 
+// imageGetForProject loads the image matching the given fingerprint, making
+// sure it's available in the given project.
+func imageGetForProject(d *Daemon, project string, fingerprint string, public bool) (int, *api.Image, error) {
+	id, info, err := d.db.ImageGet(fingerprint, public, false)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	err = imageCheckProject(d, id, project)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	return id, info, nil
+}
+
+// imageCheckProject returns NoSuchObjectError if the given image isn't
+// available in the given project.
+func imageCheckProject(d *Daemon, id int, project string) error {
+	projects, err := d.db.ImageProjects(id)
+	if err != nil {
+		return err
+	}
+
+	if !shared.StringInSlice(project, projects) {
+		return db.NoSuchObjectError
+	}
+
+	return nil
+}
+
+// imageAddToProject makes an already known image available in the given
+// project, failing if it's there already.
+func imageAddToProject(d *Daemon, fingerprint string, project string) error {
+	id, _, err := d.db.ImageGet(fingerprint, false, true)
+	if err != nil {
+		return err
+	}
+
+	err = imageCheckProject(d, id, project)
+	if err == nil {
+		return fmt.Errorf("Image with same fingerprint already exists")
+	}
+
+	return d.db.ImageProjectAdd(id, project)
+}
+
 func doImageGet(db *db.Node, fingerprint string, public bool) (*api.Image, Response) {
 	_, imgInfo, err := db.ImageGet(fingerprint, public, false)
 	if err != nil {
@@ -1262,9 +1410,14 @@ func imageGet(d *Daemon, r *http.Request) Response {
 	public := d.checkTrustedClient(r) != nil
 	secret := r.FormValue("secret")
 
-	info, response := doImageGet(d.db, fingerprint, false)
-	if response != nil {
-		return response
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, info, err := imageGetForProject(d, project, fingerprint, false)
+	if err != nil {
+		return SmartError(err)
 	}
 
 	if !info.Public && public && !imageValidSecret(info.Fingerprint, secret) {
@@ -1278,7 +1431,13 @@ func imageGet(d *Daemon, r *http.Request) Response {
 func imagePut(d *Daemon, r *http.Request) Response {
 	// Get current value
 	fingerprint := mux.Vars(r)["fingerprint"]
-	id, info, err := d.db.ImageGet(fingerprint, false, false)
+
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	id, info, err := imageGetForProject(d, project, fingerprint, false)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -1300,13 +1459,22 @@ func imagePut(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle(projectParam(r), "image-updated",
+		projectURL(projectParam(r), fmt.Sprintf("/%s/images/%s", version.APIVersion, info.Fingerprint)), d.requestor(r), nil)
+
 	return EmptySyncResponse
 }
 
 func imagePatch(d *Daemon, r *http.Request) Response {
 	// Get current value
 	fingerprint := mux.Vars(r)["fingerprint"]
-	id, info, err := d.db.ImageGet(fingerprint, false, false)
+
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	id, info, err := imageGetForProject(d, project, fingerprint, false)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -1366,6 +1534,9 @@ func imagePatch(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle(projectParam(r), "image-updated",
+		projectURL(projectParam(r), fmt.Sprintf("/%s/images/%s", version.APIVersion, info.Fingerprint)), d.requestor(r), nil)
+
 	return EmptySyncResponse
 }
 
@@ -1381,29 +1552,48 @@ func aliasesPost(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("name and target are required"))
 	}
 
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	err = projectCheckReservedName(d.db, project, req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	// This is just to see if the alias name already exists.
-	_, _, err := d.db.ImageAliasGet(req.Name, true)
+	_, _, err = d.db.ImageAliasGet(projectPrefix(project, req.Name), true)
 	if err == nil {
 		return Conflict
 	}
 
-	id, _, err := d.db.ImageGet(req.Target, false, false)
+	id, _, err := imageGetForProject(d, project, req.Target, false)
 	if err != nil {
 		return SmartError(err)
 	}
 
-	err = d.db.ImageAliasAdd(req.Name, id, req.Description)
+	err = d.db.ImageAliasAdd(project, projectPrefix(project, req.Name), id, req.Description)
 	if err != nil {
 		return SmartError(err)
 	}
 
-	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/images/aliases/%s", version.APIVersion, req.Name))
+	url := fmt.Sprintf("/%s/images/aliases/%s", version.APIVersion, req.Name)
+	eventSendLifecycle(projectParam(r), "image-alias-created", projectURL(projectParam(r), url), d.requestor(r),
+		map[string]interface{}{"target": req.Target})
+
+	return SyncResponseLocation(true, nil, projectURL(projectParam(r), url))
 }
 
 func aliasesGet(d *Daemon, r *http.Request) Response {
 	recursion := util.IsRecursionRequest(r)
 
-	names, err := d.db.ImageAliasesGet()
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	names, err := d.db.ImageAliasesGetForProject(project)
 	if err != nil {
 		return BadRequest(err)
 	}
@@ -1411,14 +1601,15 @@ func aliasesGet(d *Daemon, r *http.Request) Response {
 	responseMap := []api.ImageAliasesEntry{}
 	for _, name := range names {
 		if !recursion {
-			url := fmt.Sprintf("/%s/images/aliases/%s", version.APIVersion, name)
-			responseStr = append(responseStr, url)
+			url := fmt.Sprintf("/%s/images/aliases/%s", version.APIVersion, projectStripPrefix(project, name))
+			responseStr = append(responseStr, projectURL(projectParam(r), url))
 
 		} else {
 			_, alias, err := d.db.ImageAliasGet(name, d.checkTrustedClient(r) == nil)
 			if err != nil {
 				continue
 			}
+			alias.Name = projectStripPrefix(project, alias.Name)
 			responseMap = append(responseMap, alias)
 		}
 	}
@@ -1433,36 +1624,58 @@ func aliasesGet(d *Daemon, r *http.Request) Response {
 func aliasGet(d *Daemon, r *http.Request) Response {
 	name := mux.Vars(r)["name"]
 
-	_, alias, err := d.db.ImageAliasGet(name, d.checkTrustedClient(r) == nil)
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, alias, err := d.db.ImageAliasGet(projectPrefix(project, name), d.checkTrustedClient(r) == nil)
 	if err != nil {
 		return SmartError(err)
 	}
+	alias.Name = name
 
 	return SyncResponseETag(true, alias, alias)
 }
 
 func aliasDelete(d *Daemon, r *http.Request) Response {
 	name := mux.Vars(r)["name"]
-	_, _, err := d.db.ImageAliasGet(name, true)
+
+	project, err := projectImageScope(d.db, projectParam(r))
 	if err != nil {
 		return SmartError(err)
 	}
 
-	err = d.db.ImageAliasDelete(name)
+	_, _, err = d.db.ImageAliasGet(projectPrefix(project, name), true)
 	if err != nil {
 		return SmartError(err)
 	}
 
+	err = d.db.ImageAliasDelete(projectPrefix(project, name))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	url := fmt.Sprintf("/%s/images/aliases/%s", version.APIVersion, name)
+	eventSendLifecycle(projectParam(r), "image-alias-deleted", projectURL(projectParam(r), url), d.requestor(r), nil)
+
 	return EmptySyncResponse
 }
 
 func aliasPut(d *Daemon, r *http.Request) Response {
 	// Get current value
 	name := mux.Vars(r)["name"]
-	id, alias, err := d.db.ImageAliasGet(name, true)
+
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	id, alias, err := d.db.ImageAliasGet(projectPrefix(project, name), true)
 	if err != nil {
 		return SmartError(err)
 	}
+	alias.Name = name
 
 	// Validate ETag
 	err = util.EtagCheck(r, alias)
@@ -1479,7 +1692,7 @@ func aliasPut(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("The target field is required"))
 	}
 
-	imageId, _, err := d.db.ImageGet(req.Target, false, false)
+	imageId, _, err := imageGetForProject(d, project, req.Target, false)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -1489,17 +1702,28 @@ func aliasPut(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	url := fmt.Sprintf("/%s/images/aliases/%s", version.APIVersion, name)
+	eventSendLifecycle(projectParam(r), "image-alias-updated", projectURL(projectParam(r), url), d.requestor(r),
+		map[string]interface{}{"target": req.Target})
+
 	return EmptySyncResponse
 }
 
 func aliasPatch(d *Daemon, r *http.Request) Response {
 	// Get current value
 	name := mux.Vars(r)["name"]
-	id, alias, err := d.db.ImageAliasGet(name, true)
+
+	project, err := projectImageScope(d.db, projectParam(r))
 	if err != nil {
 		return SmartError(err)
 	}
 
+	id, alias, err := d.db.ImageAliasGet(projectPrefix(project, name), true)
+	if err != nil {
+		return SmartError(err)
+	}
+	alias.Name = name
+
 	// Validate ETag
 	err = util.EtagCheck(r, alias)
 	if err != nil {
@@ -1531,7 +1755,7 @@ func aliasPatch(d *Daemon, r *http.Request) Response {
 		alias.Description = description
 	}
 
-	imageId, _, err := d.db.ImageGet(alias.Target, false, false)
+	imageId, _, err := imageGetForProject(d, project, alias.Target, false)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -1541,6 +1765,10 @@ func aliasPatch(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	url := fmt.Sprintf("/%s/images/aliases/%s", version.APIVersion, name)
+	eventSendLifecycle(projectParam(r), "image-alias-updated", projectURL(projectParam(r), url), d.requestor(r),
+		map[string]interface{}{"target": alias.Target})
+
 	return EmptySyncResponse
 }
 
@@ -1552,23 +1780,37 @@ func aliasPost(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	err = projectCheckReservedName(d.db, project, req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	// Check that the name isn't already in use
-	id, _, _ := d.db.ImageAliasGet(req.Name, true)
+	id, _, _ := d.db.ImageAliasGet(projectPrefix(project, req.Name), true)
 	if id > 0 {
 		return Conflict
 	}
 
-	id, _, err := d.db.ImageAliasGet(name, true)
+	id, _, err = d.db.ImageAliasGet(projectPrefix(project, name), true)
 	if err != nil {
 		return SmartError(err)
 	}
 
-	err = d.db.ImageAliasRename(id, req.Name)
+	err = d.db.ImageAliasRename(id, projectPrefix(project, req.Name))
 	if err != nil {
 		return SmartError(err)
 	}
 
-	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/images/aliases/%s", version.APIVersion, req.Name))
+	url := fmt.Sprintf("/%s/images/aliases/%s", version.APIVersion, req.Name)
+	eventSendLifecycle(projectParam(r), "image-alias-renamed", projectURL(projectParam(r), url), d.requestor(r),
+		map[string]interface{}{"old_name": name})
+
+	return SyncResponseLocation(true, nil, projectURL(projectParam(r), url))
 }
 
 func imageExport(d *Daemon, r *http.Request) Response {
@@ -1577,7 +1819,12 @@ func imageExport(d *Daemon, r *http.Request) Response {
 	public := d.checkTrustedClient(r) != nil
 	secret := r.FormValue("secret")
 
-	_, imgInfo, err := d.db.ImageGet(fingerprint, false, false)
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, imgInfo, err := imageGetForProject(d, project, fingerprint, false)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -1627,7 +1874,13 @@ func imageExport(d *Daemon, r *http.Request) Response {
 
 func imageSecret(d *Daemon, r *http.Request) Response {
 	fingerprint := mux.Vars(r)["fingerprint"]
-	_, imgInfo, err := d.db.ImageGet(fingerprint, false, false)
+
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, imgInfo, err := imageGetForProject(d, project, fingerprint, false)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -1644,7 +1897,7 @@ func imageSecret(d *Daemon, r *http.Request) Response {
 	resources := map[string][]string{}
 	resources["images"] = []string{imgInfo.Fingerprint}
 
-	op, err := operationCreate(operationClassToken, resources, meta, nil, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassToken, resources, meta, nil, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -1654,7 +1907,13 @@ func imageSecret(d *Daemon, r *http.Request) Response {
 
 func imageRefresh(d *Daemon, r *http.Request) Response {
 	fingerprint := mux.Vars(r)["fingerprint"]
-	imageId, imageInfo, err := d.db.ImageGet(fingerprint, false, false)
+
+	project, err := projectImageScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	imageId, imageInfo, err := imageGetForProject(d, project, fingerprint, false)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -1664,7 +1923,7 @@ func imageRefresh(d *Daemon, r *http.Request) Response {
 		return autoUpdateImage(d, op, imageId, imageInfo)
 	}
 
-	op, err := operationCreate(operationClassTask, nil, nil, run, nil, nil)
+	op, err := operationCreate(projectParam(r), operationClassTask, nil, nil, run, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -79,6 +79,12 @@ func expireLogs(ctx context.Context, state *state.State) error {
 		default:
 		}
 
+		// Only the log directories of containers are expired, leaving
+		// the daemon and audit logs alone.
+		if !entry.IsDir() {
+			continue
+		}
+
 		// Check if the container still exists
 		if shared.StringInSlice(entry.Name(), containers) {
 			// Remove any log file which wasn't modified in the past 48 hours
@@ -44,8 +44,9 @@ func main() {
 
 // Index of SubCommand functions by command line name
 //
-// "forkputfile", "forkgetfile", "forkmount" and "forkumount" are handled specially in main_nsexec.go
+// "forkputfile", "forkgetfile", "forkmount", "forkumount", "forksysctl" and "forksyscall" are handled specially in main_nsexec.go
 // "forkgetnet" is partially handled in nsexec.go (setns)
+// "forkproxy" is partially handled in nsexec.go (daemonize and setns)
 var subcommands = map[string]SubCommand{
 	// Main commands
 	"activateifneeded": cmdActivateIfNeeded,
@@ -61,6 +62,7 @@ var subcommands = map[string]SubCommand{
 	"forkconsole":        cmdForkConsole,
 	"forkgetnet":         cmdForkGetNet,
 	"forkmigrate":        cmdForkMigrate,
+	"forkproxy":          cmdForkProxy,
 	"forkstart":          cmdForkStart,
 	"forkexec":           cmdForkExec,
 	"netcat":             cmdNetcat,
@@ -4,6 +4,7 @@ package main
 type Args struct {
 	Auto                 bool   `flag:"auto"`
 	Preseed              bool   `flag:"preseed"`
+	Dump                 bool   `flag:"dump"`
 	CPUProfile           string `flag:"cpuprofile"`
 	Debug                bool   `flag:"debug"`
 	Group                string `flag:"group"`
@@ -43,7 +44,7 @@ Commands:
         Start the main LXD daemon
     init [--auto] [--network-address=IP] [--network-port=8443] [--storage-backend=dir]
          [--storage-create-device=DEVICE] [--storage-create-loop=SIZE] [--storage-pool=POOL]
-         [--trust-password=] [--preseed]
+         [--trust-password=] [--preseed] [--dump]
         Setup storage and networking
     ready
         Tells LXD that any setup-mode configuration has been done and that it can start containers.
@@ -86,6 +87,8 @@ Init options:
         Automatic (non-interactive) mode
     --preseed
         Pre-seed mode, expects YAML config from stdin
+    --dump
+        Print the current server configuration as pre-seed YAML
 
 Init options for non-interactive mode (--auto):
     --network-address ADDRESS
@@ -3,7 +3,7 @@ package main
 import (
 	"encoding/json"
 	"os"
-	"strings"
+	"strconv"
 	"syscall"
 
 	"gopkg.in/lxc/go-lxc.v2"
@@ -12,10 +12,10 @@ import (
 )
 
 /*
- * This is called by lxd when called as "lxd forkexec <container>"
+ * This is called by lxd when called as "lxd forkexec <container> <lxcpath> <config> <cwd> <uid> <gid>"
  */
 func cmdForkExec(args *Args) error {
-	if len(args.Params) < 3 {
+	if len(args.Params) < 6 {
 		return SubCommandErrorf(-1, "Bad params: %q", args.Params)
 	}
 	if len(args.Extra) < 1 {
@@ -25,6 +25,17 @@ func cmdForkExec(args *Args) error {
 	name := args.Params[0]
 	lxcpath := args.Params[1]
 	configPath := args.Params[2]
+	cwd := args.Params[3]
+
+	uid, err := strconv.ParseUint(args.Params[4], 10, 32)
+	if err != nil {
+		return SubCommandErrorf(-1, "Bad uid: %q", args.Params[4])
+	}
+
+	gid, err := strconv.ParseUint(args.Params[5], 10, 32)
+	if err != nil {
+		return SubCommandErrorf(-1, "Bad gid: %q", args.Params[5])
+	}
 
 	c, err := lxc.NewContainer(name, lxcpath)
 	if err != nil {
@@ -49,6 +60,11 @@ func cmdForkExec(args *Args) error {
 	opts.StdinFd = 200
 	opts.StdoutFd = 201
 	opts.StderrFd = 202
+	opts.Cwd = cwd
+
+	// Run as the requested user, liblxc drops the supplementary groups
+	opts.UID = int(uid)
+	opts.GID = int(gid)
 
 	logPath := shared.LogPath(name, "forkexec.log")
 	if shared.PathExists(logPath) {
@@ -78,10 +94,6 @@ func cmdForkExec(args *Args) error {
 		}
 
 		if section == "env" {
-			fields := strings.SplitN(arg, "=", 2)
-			if len(fields) == 2 && fields[0] == "HOME" {
-				opts.Cwd = fields[1]
-			}
 			env = append(env, arg)
 		} else if section == "cmd" {
 			cmd = append(cmd, arg)
@@ -0,0 +1,292 @@
+package main
+
+/*
+extern int forkproxy_listener;
+extern int forkproxy_fd;
+*/
+import "C"
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+/*
+ * This is called by lxd when called as
+ * "lxd forkproxy <listen pid> <listen addr> <connect pid> <connect addr> <log path> <pid path>"
+ *
+ * By the time we get here, main_nsexec.go has daemonized us and forked
+ * the process in two. The child is attached to the listen namespace, sets
+ * up the listener and hands its file descriptor over to the parent, which
+ * is attached to the connect namespace and does the actual forwarding.
+ */
+func cmdForkProxy(args *Args) error {
+	if len(args.Params) != 6 {
+		return SubCommandErrorf(-1, "Bad params: %q", args.Params)
+	}
+
+	listenAddr := args.Params[1]
+	connectAddr := args.Params[3]
+
+	if C.forkproxy_fd < 0 {
+		return fmt.Errorf("Failed to call forkproxy constructor")
+	}
+
+	conn, err := net.FileConn(os.NewFile(uintptr(C.forkproxy_fd), "forkproxy"))
+	if err != nil {
+		return fmt.Errorf("Failed to open forkproxy socket: %v", err)
+	}
+	defer conn.Close()
+
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return fmt.Errorf("Invalid forkproxy socket")
+	}
+
+	if C.forkproxy_listener == 1 {
+		return forkProxyListen(unixConn, listenAddr)
+	}
+
+	fmt.Printf("Starting proxy from %s to %s\n", listenAddr, connectAddr)
+
+	file, err := forkProxyReceiveFile(unixConn)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Reap the listener process
+	syscall.Wait4(-1, nil, 0, nil)
+
+	proto, _, err := proxyParseAddr(listenAddr)
+	if err != nil {
+		return err
+	}
+
+	if proto == "udp" {
+		packetConn, err := net.FilePacketConn(file)
+		if err != nil {
+			return err
+		}
+
+		return forkProxyPackets(packetConn, connectAddr)
+	}
+
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return err
+	}
+
+	for {
+		srcConn, err := listener.Accept()
+		if err != nil {
+			return fmt.Errorf("Failed to accept connection: %v", err)
+		}
+
+		go forkProxyStream(srcConn, connectAddr)
+	}
+}
+
+// proxyParseAddr splits a proxy address such as "tcp:127.0.0.1:80" into its
+// protocol and address.
+func proxyParseAddr(addr string) (string, string, error) {
+	fields := strings.SplitN(addr, ":", 2)
+	if len(fields) != 2 || fields[1] == "" {
+		return "", "", fmt.Errorf("Invalid proxy address: %s", addr)
+	}
+
+	if fields[0] != "tcp" && fields[0] != "udp" && fields[0] != "unix" {
+		return "", "", fmt.Errorf("Invalid proxy protocol: %s", fields[0])
+	}
+
+	return fields[0], fields[1], nil
+}
+
+func forkProxyListen(conn *net.UnixConn, listenAddr string) error {
+	proto, addr, err := proxyParseAddr(listenAddr)
+	if err != nil {
+		return err
+	}
+
+	var file *os.File
+	switch proto {
+	case "udp":
+		udpAddr, err := net.ResolveUDPAddr(proto, addr)
+		if err != nil {
+			return err
+		}
+
+		listener, err := net.ListenUDP(proto, udpAddr)
+		if err != nil {
+			return err
+		}
+
+		file, err = listener.File()
+		if err != nil {
+			return err
+		}
+	case "unix":
+		// Clear any leftover socket from a previous run
+		os.Remove(addr)
+
+		unixAddr, err := net.ResolveUnixAddr(proto, addr)
+		if err != nil {
+			return err
+		}
+
+		listener, err := net.ListenUnix(proto, unixAddr)
+		if err != nil {
+			return err
+		}
+
+		file, err = listener.File()
+		if err != nil {
+			return err
+		}
+	default:
+		tcpAddr, err := net.ResolveTCPAddr(proto, addr)
+		if err != nil {
+			return err
+		}
+
+		listener, err := net.ListenTCP(proto, tcpAddr)
+		if err != nil {
+			return err
+		}
+
+		file, err = listener.File()
+		if err != nil {
+			return err
+		}
+	}
+	defer file.Close()
+
+	_, _, err = conn.WriteMsgUnix([]byte{0}, syscall.UnixRights(int(file.Fd())), nil)
+	if err != nil {
+		return fmt.Errorf("Failed to send listener to the proxy: %v", err)
+	}
+
+	return nil
+}
+
+func forkProxyReceiveFile(conn *net.UnixConn) (*os.File, error) {
+	buf := make([]byte, 1)
+	oob := make([]byte, syscall.CmsgSpace(4))
+
+	_, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to receive listener: %v", err)
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		return nil, fmt.Errorf("Failed to receive listener: %v", err)
+	}
+
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		return nil, fmt.Errorf("Failed to receive listener: %v", err)
+	}
+
+	return os.NewFile(uintptr(fds[0]), "listener"), nil
+}
+
+func forkProxyStream(srcConn net.Conn, connectAddr string) {
+	defer srcConn.Close()
+
+	proto, addr, err := proxyParseAddr(connectAddr)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+
+	dstConn, err := net.Dial(proto, addr)
+	if err != nil {
+		fmt.Printf("Failed to connect to target: %v\n", err)
+		return
+	}
+	defer dstConn.Close()
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		io.Copy(dstConn, srcConn)
+		wg.Done()
+	}()
+
+	go func() {
+		io.Copy(srcConn, dstConn)
+		wg.Done()
+	}()
+
+	wg.Wait()
+}
+
+// forkProxyUDPTimeout is how long a datagram client may stay idle before its
+// connection to the target is closed.
+const forkProxyUDPTimeout = 60 * time.Second
+
+func forkProxyPackets(srcConn net.PacketConn, connectAddr string) error {
+	proto, addr, err := proxyParseAddr(connectAddr)
+	if err != nil {
+		return err
+	}
+
+	// Every client gets its own connection to the target, so replies can
+	// be routed back to it. Connections of clients which stayed quiet for
+	// longer than forkProxyUDPTimeout are closed.
+	clients := map[string]net.Conn{}
+	clientsLock := sync.Mutex{}
+
+	buf := make([]byte, 65536)
+	for {
+		n, clientAddr, err := srcConn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		clientsLock.Lock()
+		dstConn, ok := clients[clientAddr.String()]
+		if !ok {
+			dstConn, err = net.Dial(proto, addr)
+			if err != nil {
+				clientsLock.Unlock()
+				fmt.Printf("Failed to connect to target: %v\n", err)
+				continue
+			}
+
+			clients[clientAddr.String()] = dstConn
+
+			go func(dstConn net.Conn, clientAddr net.Addr) {
+				reply := make([]byte, 65536)
+				for {
+					n, err := dstConn.Read(reply)
+					if err != nil {
+						break
+					}
+
+					dstConn.SetReadDeadline(time.Now().Add(forkProxyUDPTimeout))
+					srcConn.WriteTo(reply[:n], clientAddr)
+				}
+
+				clientsLock.Lock()
+				if clients[clientAddr.String()] == dstConn {
+					delete(clients, clientAddr.String())
+				}
+				clientsLock.Unlock()
+				dstConn.Close()
+			}(dstConn, clientAddr)
+		}
+
+		dstConn.SetReadDeadline(time.Now().Add(forkProxyUDPTimeout))
+		dstConn.Write(buf[:n])
+		clientsLock.Unlock()
+	}
+}
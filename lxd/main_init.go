@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"reflect"
 	"strconv"
 	"strings"
 	"syscall"
 
 	"golang.org/x/crypto/ssh/terminal"
+	"gopkg.in/yaml.v2"
 
 	"github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/lxd/util"
@@ -43,6 +45,10 @@ func (cmd *CmdInit) Run() error {
 		return fmt.Errorf("Unable to talk to LXD: %s", err)
 	}
 
+	if cmd.Args.Dump {
+		return cmd.dump(client)
+	}
+
 	existingPools, err := client.GetStoragePoolNames()
 	if err != nil {
 		// We should consider this fatal since this means
@@ -177,6 +183,78 @@ func (cmd *CmdInit) fillDataPreseed(data *cmdInitData, client lxd.ContainerServe
 	return nil
 }
 
+// Print the current server configuration, storage pools, managed networks
+// and profiles as a preseed YAML, which can be fed back to --preseed.
+func (cmd *CmdInit) dump(client lxd.ContainerServer) error {
+	data := &cmdInitData{}
+
+	server, _, err := client.GetServer()
+	if err != nil {
+		return err
+	}
+
+	// Hidden values (e.g. the trust password) are rendered as true, and
+	// are left untouched when applying the preseed anyway.
+	data.Config = map[string]interface{}{}
+	for key, value := range server.Config {
+		if value == true {
+			continue
+		}
+		data.Config[key] = value
+	}
+
+	pools, err := client.GetStoragePools()
+	if err != nil {
+		return err
+	}
+
+	for _, pool := range pools {
+		data.Pools = append(data.Pools, api.StoragePoolsPost{
+			StoragePoolPut: pool.Writable(),
+			Name:           pool.Name,
+			Driver:         pool.Driver,
+		})
+	}
+
+	networks, err := client.GetNetworks()
+	if err != nil {
+		return err
+	}
+
+	for _, network := range networks {
+		if !network.Managed {
+			continue
+		}
+
+		data.Networks = append(data.Networks, api.NetworksPost{
+			NetworkPut: network.Writable(),
+			Name:       network.Name,
+			Type:       network.Type,
+		})
+	}
+
+	profiles, err := client.GetProfiles()
+	if err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		data.Profiles = append(data.Profiles, api.ProfilesPost{
+			ProfilePut: profile.Writable(),
+			Name:       profile.Name,
+		})
+	}
+
+	out, err := yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	cmd.Context.Output("%s", out)
+
+	return nil
+}
+
 // Fill the given data with the current server configuration.
 func (cmd *CmdInit) fillDataWithCurrentServerConfig(data *cmdInitData, client lxd.ContainerServer) error {
 	server, _, err := client.GetServer()
@@ -446,16 +524,23 @@ func (cmd *CmdInit) initConfig(client lxd.ContainerServer, config map[string]int
 		return client.UpdateServer(server.Writable(), "")
 	}
 
+	// Keys which aren't mentioned keep their current value, so that
+	// applying the same preseed again is a no-op.
+	newConfig := map[string]interface{}{}
+	for key, value := range server.Config {
+		newConfig[key] = value
+	}
+
 	// The underlying code expects all values to be string, even if when
 	// using preseed the yaml.v2 package unmarshals them as integers.
 	for key, value := range config {
 		if number, ok := value.(int); ok {
 			value = strconv.Itoa(number)
 		}
-		config[key] = value
+		newConfig[key] = value
 	}
 
-	err = client.UpdateServer(api.ServerPut{Config: config}, etag)
+	err = client.UpdateServer(api.ServerPut{Config: newConfig}, etag)
 	if err != nil {
 		return nil, err
 	}
@@ -470,6 +555,9 @@ func (cmd *CmdInit) initPool(client lxd.ContainerServer, pool api.StoragePoolsPo
 	var reverter func() error
 	currentPool, _, err := client.GetStoragePool(pool.Name)
 	if err == nil {
+		if pool.Driver != "" && pool.Driver != currentPool.Driver {
+			return nil, fmt.Errorf("Storage pool '%s' already exists with driver '%s'", pool.Name, currentPool.Driver)
+		}
 		reverter, err = cmd.initPoolUpdate(client, pool, currentPool.Writable())
 	} else {
 		reverter, err = cmd.initPoolCreate(client, pool)
@@ -495,9 +583,17 @@ func (cmd *CmdInit) initPoolUpdate(client lxd.ContainerServer, pool api.StorageP
 	reverter := func() error {
 		return client.UpdateStoragePool(pool.Name, currentPool, "")
 	}
-	err := client.UpdateStoragePool(pool.Name, api.StoragePoolPut{
-		Config: pool.Config,
-	}, "")
+	newPool := api.StoragePoolPut{
+		Config:      cmd.mergeConfig(currentPool.Config, pool.Config),
+		Description: cmd.mergeDescription(currentPool.Description, pool.Description),
+	}
+
+	// Nothing to do if the pool is already set up as requested.
+	if reflect.DeepEqual(newPool, currentPool) {
+		return func() error { return nil }, nil
+	}
+
+	err := client.UpdateStoragePool(pool.Name, newPool, "")
 	return reverter, err
 }
 
@@ -537,12 +633,45 @@ func (cmd *CmdInit) initNetworkUpdate(client lxd.ContainerServer, network api.Ne
 	reverter := func() error {
 		return client.UpdateNetwork(network.Name, currentNetwork, "")
 	}
-	err := client.UpdateNetwork(network.Name, api.NetworkPut{
-		Config: network.Config,
-	}, "")
+	newNetwork := api.NetworkPut{
+		Config:      cmd.mergeConfig(currentNetwork.Config, network.Config),
+		Description: cmd.mergeDescription(currentNetwork.Description, network.Description),
+	}
+
+	// Nothing to do if the network is already set up as requested.
+	if reflect.DeepEqual(newNetwork, currentNetwork) {
+		return func() error { return nil }, nil
+	}
+
+	err := client.UpdateNetwork(network.Name, newNetwork, "")
 	return reverter, err
 }
 
+// Return the current config of an existing object, updated with the keys
+// set in the preseed. Keys generated by LXD (e.g. a pool's source or a
+// bridge's addresses) are kept when not mentioned.
+func (cmd *CmdInit) mergeConfig(current map[string]string, config map[string]string) map[string]string {
+	merged := map[string]string{}
+	for key, value := range current {
+		merged[key] = value
+	}
+
+	for key, value := range config {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// Return the description set in the preseed, or the current one if unset.
+func (cmd *CmdInit) mergeDescription(current string, description string) string {
+	if description == "" {
+		return current
+	}
+
+	return description
+}
+
 // Create or update a single profile, and return a revert function in case of success.
 func (cmd *CmdInit) initProfile(client lxd.ContainerServer, profile api.ProfilesPost) (reverter, error) {
 	var reverter func() error
@@ -587,6 +716,9 @@ func (cmd *CmdInit) validateArgs() error {
 	if cmd.Args.Auto && cmd.Args.Preseed {
 		return fmt.Errorf("Non-interactive mode supported by only one of --auto or --preseed")
 	}
+	if cmd.Args.Dump && (cmd.Args.Auto || cmd.Args.Preseed) {
+		return fmt.Errorf("Can't use --dump with --auto or --preseed")
+	}
 	if !cmd.Args.Auto {
 		if cmd.Args.StorageBackend != "" || cmd.Args.StorageCreateDevice != "" || cmd.Args.StorageCreateLoop != -1 || cmd.Args.StorageDataset != "" || cmd.Args.NetworkAddress != "" || cmd.Args.NetworkPort != -1 || cmd.Args.TrustPassword != "" {
 			return fmt.Errorf("Init configuration is only valid with --auto")
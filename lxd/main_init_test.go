@@ -628,6 +628,105 @@ func (suite *cmdInitTestSuite) TestCmdInit_ProfilesPreseedUpdate() {
 	suite.Req.Equal("disk", profile.Devices["data"]["type"])
 }
 
+// The current configuration can be dumped as a preseed, and applying it
+// again leaves everything untouched.
+func (suite *cmdInitTestSuite) TestCmdInit_DumpPreseed() {
+	pool := api.StoragePoolsPost{
+		Name:   "egg",
+		Driver: "dir",
+	}
+	err := suite.client.CreateStoragePool(pool)
+	suite.Req.Nil(err)
+
+	network := api.NetworksPost{
+		Name: "egg",
+	}
+	network.Config = map[string]string{
+		"ipv4.address": "10.48.159.1/24",
+		"ipv6.address": "none",
+	}
+	err = suite.client.CreateNetwork(network)
+	suite.Req.Nil(err)
+
+	profile := api.ProfilesPost{
+		Name: "egg",
+	}
+	profile.Config = map[string]string{
+		"limits.memory": "2GB",
+	}
+	err = suite.client.CreateProfile(profile)
+	suite.Req.Nil(err)
+
+	key, _ := daemonConfig["images.auto_update_interval"]
+	err = key.Set(suite.d, "10")
+	suite.Req.Nil(err)
+
+	suite.args.Dump = true
+	suite.Req.Nil(suite.command.Run())
+
+	dump := suite.streams.Out()
+	suite.Req.Contains(dump, "images.auto_update_interval: \"10\"")
+	suite.Req.Contains(dump, "name: egg\n  driver: dir")
+	suite.Req.Contains(dump, "ipv4.address: 10.48.159.1/24")
+	suite.Req.Contains(dump, "limits.memory: 2GB")
+
+	suite.args.Dump = false
+	suite.args.Preseed = true
+	suite.streams.InputAppend(dump)
+	suite.Req.Nil(suite.command.Run())
+
+	currentPool, _, err := suite.client.GetStoragePool("egg")
+	suite.Req.Nil(err)
+	suite.Req.Equal("dir", currentPool.Driver)
+	suite.Req.NotEqual("", currentPool.Config["source"])
+
+	currentNetwork, _, err := suite.client.GetNetwork("egg")
+	suite.Req.Nil(err)
+	suite.Req.Equal("10.48.159.1/24", currentNetwork.Config["ipv4.address"])
+
+	currentProfile, _, err := suite.client.GetProfile("egg")
+	suite.Req.Nil(err)
+	suite.Req.Equal("2GB", currentProfile.Config["limits.memory"])
+
+	suite.Req.Equal("10", key.Get())
+}
+
+// Keys of existing objects which aren't mentioned in the preseed are kept.
+func (suite *cmdInitTestSuite) TestCmdInit_PreseedUpdateKeepsConfig() {
+	network := api.NetworksPost{
+		Name: "egg",
+	}
+	network.Config = map[string]string{
+		"ipv4.address": "10.48.159.1/24",
+		"ipv6.address": "none",
+	}
+	err := suite.client.CreateNetwork(network)
+	suite.Req.Nil(err)
+
+	err = daemonConfig["core.trust_password"].Set(suite.d, "sekret")
+	suite.Req.Nil(err)
+
+	suite.args.Preseed = true
+	suite.streams.InputAppend(`config:
+  images.auto_update_interval: 15
+networks:
+- name: egg
+  type: bridge
+  config:
+    ipv4.nat: false
+`)
+
+	suite.Req.Nil(suite.command.Run())
+
+	currentNetwork, _, err := suite.client.GetNetwork("egg")
+	suite.Req.Nil(err)
+	suite.Req.Equal("10.48.159.1/24", currentNetwork.Config["ipv4.address"])
+	suite.Req.Equal("false", currentNetwork.Config["ipv4.nat"])
+
+	suite.Req.Equal("15", daemonConfig["images.auto_update_interval"].Get())
+	suite.Req.NotEqual("", daemonConfig["core.trust_password"].Get())
+}
+
 // Convenience for building the input text a user would enter for a certain
 // sequence of answers.
 type cmdInitAnswers struct {
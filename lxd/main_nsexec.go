@@ -39,11 +39,25 @@ package main
 #include <ifaddrs.h>
 #include <dirent.h>
 #include <grp.h>
+#include <signal.h>
+#include <sys/socket.h>
+#include <sys/fsuid.h>
+#include <sys/ioctl.h>
+#include <stdint.h>
+#include <sys/xattr.h>
 
 // This expects:
+//  ./lxd forkproxy <listen pid> <listen addr> <connect pid> <connect addr> <log path> <pid path>
+// or
 //  ./lxd forkputfile /source/path <pid> /target/path
 // or
 //  ./lxd forkgetfile /target/path <pid> /soruce/path <uid> <gid> <mode>
+// or
+//  ./lxd forksysctl <pid> /proc/sys/path <value>
+// or
+//  ./lxd forksyscall mknod <pid> <notify fd> <notify id> <cwd> <path> <mode> <dev> <uid> <gid>
+// or
+//  ./lxd forksyscall setxattr <pid> <notify fd> <notify id> <cwd> <path> <name> <value> <flags> <uid> <gid>
 // i.e. 8 arguments, each which have a max length of PATH_MAX.
 // Unfortunately, lseek() and fstat() both fail (EINVAL and 0 size) for
 // procfs. Also, we can't mmap, because procfs doesn't support that, either.
@@ -681,6 +695,346 @@ void forkgetnet(char *buf, char *cur, ssize_t size) {
 	// The rest happens in Go
 }
 
+void forksysctl(char *buf, char *cur, ssize_t size) {
+	char *path, *value;
+	ssize_t len;
+	int fd;
+
+	ADVANCE_ARG_REQUIRED();
+	int pid = atoi(cur);
+
+	ADVANCE_ARG_REQUIRED();
+	path = cur;
+
+	ADVANCE_ARG_REQUIRED();
+	value = cur;
+
+	// Namespaced sysctls resolve to the namespaces of the writer, so
+	// writing to the host's /proc/sys from there reaches the container's.
+	if (dosetns(pid, "net") < 0) {
+		fprintf(stderr, "Failed setns to container network namespace: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	if (dosetns(pid, "ipc") < 0) {
+		fprintf(stderr, "Failed setns to container IPC namespace: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	fd = open(path, O_WRONLY);
+	if (fd < 0) {
+		fprintf(stderr, "Failed to open %s: %s\n", path, strerror(errno));
+		_exit(1);
+	}
+
+	len = strlen(value);
+	if (write(fd, value, len) != len) {
+		fprintf(stderr, "Failed to write %s: %s\n", path, strerror(errno));
+		close(fd);
+		_exit(1);
+	}
+	close(fd);
+
+	_exit(0);
+}
+
+// Report the errno resulting from the system call to the caller.
+void forksyscall_result(int err) {
+	printf("%d\n", err);
+	fflush(stdout);
+	_exit(0);
+}
+
+// The SECCOMP_IOCTL_NOTIF_ID_VALID ioctl, which older kernel headers lack
+#define LXD_SECCOMP_IOCTL_NOTIF_ID_VALID _IOR('!', 2, uint64_t)
+
+// Check whether a seccomp notification is still pending, i.e. its calling
+// process is still alive and waiting for the response.
+int seccomp_notify_id_valid(int notify_fd, uint64_t id) {
+	if (ioctl(notify_fd, LXD_SECCOMP_IOCTL_NOTIF_ID_VALID, &id) < 0) {
+		return -errno;
+	}
+
+	return 0;
+}
+
+// Enter the mount namespace, root and working directory of the process
+// whose system call is being handled. Those are looked up by pid, so the
+// notification must still be pending once they're opened, otherwise the
+// process may be gone and its pid reused.
+void forksyscall_setns(int pid, int notify_fd, uint64_t id, char *cwd) {
+	char path[PATH_MAX];
+	int rootfd, cwdfd, mntfd, ret;
+
+	sprintf(path, "/proc/%d/root", pid);
+	rootfd = open(path, O_RDONLY | O_DIRECTORY | O_CLOEXEC);
+	if (rootfd < 0) {
+		forksyscall_result(errno);
+	}
+
+	cwdfd = open(cwd, O_RDONLY | O_DIRECTORY | O_CLOEXEC);
+	if (cwdfd < 0) {
+		forksyscall_result(errno);
+	}
+
+	sprintf(path, "/proc/%d/ns/mnt", pid);
+	mntfd = open(path, O_RDONLY | O_CLOEXEC);
+	if (mntfd < 0) {
+		forksyscall_result(errno);
+	}
+
+	ret = seccomp_notify_id_valid(notify_fd, id);
+	if (ret < 0) {
+		forksyscall_result(-ret);
+	}
+	close(notify_fd);
+
+	if (setns(mntfd, 0) < 0) {
+		fprintf(stderr, "Failed setns to container mount namespace: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	if (fchdir(rootfd) < 0 || chroot(".") < 0 || fchdir(cwdfd) < 0) {
+		fprintf(stderr, "Failed to enter the container root: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	close(mntfd);
+	close(rootfd);
+	close(cwdfd);
+}
+
+// Check that the calling process could access the path by itself.
+int forksyscall_access(char *path, int mode, uid_t uid, gid_t gid) {
+	int ret, saved_errno;
+
+	if (setgroups(0, NULL) < 0) {
+		return errno;
+	}
+
+	setfsgid(gid);
+	setfsuid(uid);
+
+	ret = faccessat(AT_FDCWD, path, mode, AT_EACCESS);
+	saved_errno = errno;
+
+	setfsuid(0);
+	setfsgid(0);
+
+	if (ret < 0) {
+		return saved_errno;
+	}
+
+	return 0;
+}
+
+void forksyscall(char *buf, char *cur, ssize_t size) {
+	char *syscall_name, *cwd, *path, *dir;
+	uid_t uid;
+	gid_t gid;
+	int pid, notify_fd, ret;
+	uint64_t id;
+
+	ADVANCE_ARG_REQUIRED();
+	syscall_name = cur;
+
+	ADVANCE_ARG_REQUIRED();
+	pid = atoi(cur);
+
+	ADVANCE_ARG_REQUIRED();
+	notify_fd = atoi(cur);
+
+	ADVANCE_ARG_REQUIRED();
+	id = strtoull(cur, NULL, 10);
+
+	ADVANCE_ARG_REQUIRED();
+	cwd = cur;
+
+	ADVANCE_ARG_REQUIRED();
+	path = cur;
+
+	// The result is reported as an errno value on stdout
+	if (strcmp(syscall_name, "mknod") == 0) {
+		mode_t mode;
+		dev_t dev;
+
+		ADVANCE_ARG_REQUIRED();
+		mode = strtoul(cur, NULL, 10);
+
+		ADVANCE_ARG_REQUIRED();
+		dev = strtoull(cur, NULL, 10);
+
+		ADVANCE_ARG_REQUIRED();
+		uid = atoi(cur);
+
+		ADVANCE_ARG_REQUIRED();
+		gid = atoi(cur);
+
+		forksyscall_setns(pid, notify_fd, id, cwd);
+
+		dir = strdup(path);
+		if (!dir)
+			_exit(1);
+
+		ret = forksyscall_access(dirname(dir), W_OK | X_OK, uid, gid);
+		free(dir);
+		if (ret != 0) {
+			forksyscall_result(ret);
+		}
+
+		umask(0);
+		if (mknod(path, mode, dev) < 0) {
+			forksyscall_result(errno);
+		}
+
+		if (lchown(path, uid, gid) < 0) {
+			ret = errno;
+			unlink(path);
+			forksyscall_result(ret);
+		}
+	} else if (strcmp(syscall_name, "setxattr") == 0) {
+		char *name, *value;
+		int flags;
+
+		ADVANCE_ARG_REQUIRED();
+		name = cur;
+
+		ADVANCE_ARG_REQUIRED();
+		value = cur;
+
+		ADVANCE_ARG_REQUIRED();
+		flags = atoi(cur);
+
+		ADVANCE_ARG_REQUIRED();
+		uid = atoi(cur);
+
+		ADVANCE_ARG_REQUIRED();
+		gid = atoi(cur);
+
+		forksyscall_setns(pid, notify_fd, id, cwd);
+
+		ret = forksyscall_access(path, W_OK, uid, gid);
+		if (ret != 0) {
+			forksyscall_result(ret);
+		}
+
+		if (setxattr(path, name, value, strlen(value), flags) < 0) {
+			forksyscall_result(errno);
+		}
+	} else {
+		fprintf(stderr, "Unsupported system call: %s\n", syscall_name);
+		_exit(1);
+	}
+
+	forksyscall_result(0);
+}
+
+// Set by forkproxy so the Go side knows which end of the proxy it is and
+// which socket to use to pass the listening file descriptor along.
+int forkproxy_listener = 0;
+int forkproxy_fd = -1;
+
+void forkproxy_setns(int pid, char *addr) {
+	if (dosetns(pid, "net") < 0) {
+		fprintf(stderr, "Failed setns to network namespace: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	// Unix sockets live on the filesystem, so we need the mount namespace too
+	if (strncmp(addr, "unix:", 5) == 0) {
+		if (dosetns(pid, "mnt") < 0) {
+			fprintf(stderr, "Failed setns to mount namespace: %s\n", strerror(errno));
+			_exit(1);
+		}
+	}
+}
+
+void forkproxy(char *buf, char *cur, ssize_t size) {
+	int listen_pid, connect_pid, logfd, nullfd;
+	int sv[2];
+	char *listen_addr, *connect_addr, *log_path, *pid_path;
+	FILE *pid_file;
+	pid_t pid;
+
+	ADVANCE_ARG_REQUIRED();
+	listen_pid = atoi(cur);
+
+	ADVANCE_ARG_REQUIRED();
+	listen_addr = cur;
+
+	ADVANCE_ARG_REQUIRED();
+	connect_pid = atoi(cur);
+
+	ADVANCE_ARG_REQUIRED();
+	connect_addr = cur;
+
+	ADVANCE_ARG_REQUIRED();
+	log_path = cur;
+
+	ADVANCE_ARG_REQUIRED();
+	pid_path = cur;
+
+	// Daemonize, recording the pid of the long running process
+	pid = fork();
+	if (pid < 0) {
+		fprintf(stderr, "Failed to fork: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	if (pid > 0) {
+		pid_file = fopen(pid_path, "w+");
+		if (!pid_file) {
+			fprintf(stderr, "Failed to create pid file %s: %s\n", pid_path, strerror(errno));
+			kill(pid, SIGKILL);
+			_exit(1);
+		}
+
+		fprintf(pid_file, "%d", pid);
+		fclose(pid_file);
+		_exit(0);
+	}
+
+	setsid();
+
+	logfd = open(log_path, O_WRONLY | O_CREAT | O_APPEND, 0600);
+	nullfd = open("/dev/null", O_RDONLY);
+	if (logfd < 0 || nullfd < 0) {
+		_exit(1);
+	}
+
+	dup2(nullfd, 0);
+	dup2(logfd, 1);
+	dup2(logfd, 2);
+	close(nullfd);
+	close(logfd);
+
+	// The listener is set up in the listen namespace and its file
+	// descriptor is handed over to the connecting end.
+	if (socketpair(AF_UNIX, SOCK_STREAM, 0, sv) < 0) {
+		fprintf(stderr, "Failed to create socket pair: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	pid = fork();
+	if (pid < 0) {
+		fprintf(stderr, "Failed to fork: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	if (pid == 0) {
+		close(sv[1]);
+		forkproxy_setns(listen_pid, listen_addr);
+		forkproxy_listener = 1;
+		forkproxy_fd = sv[0];
+	} else {
+		close(sv[0]);
+		forkproxy_setns(connect_pid, connect_addr);
+		forkproxy_fd = sv[1];
+	}
+
+	// The rest happens in Go
+}
+
 __attribute__((constructor)) void init(void) {
 	int cmdline;
 	char buf[CMDLINE_SIZE];
@@ -723,6 +1077,12 @@ __attribute__((constructor)) void init(void) {
 		forkumount(buf, cur, size);
 	} else if (strcmp(cur, "forkgetnet") == 0) {
 		forkgetnet(buf, cur, size);
+	} else if (strcmp(cur, "forksysctl") == 0) {
+		forksysctl(buf, cur, size);
+	} else if (strcmp(cur, "forksyscall") == 0) {
+		forksyscall(buf, cur, size);
+	} else if (strcmp(cur, "forkproxy") == 0) {
+		forkproxy(buf, cur, size);
 	}
 }
 */
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/lxd/lxd/db"
+	dbg "github.com/lxc/lxd/lxd/debug"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+
+	log "github.com/lxc/lxd/shared/log15"
+)
+
+var metricsCmd = Command{name: "metrics", get: metricsGet}
+
+// metricSample is a single value of a metric, along with its labels.
+type metricSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// metricFamily groups all the samples of a metric.
+type metricFamily struct {
+	name    string
+	kind    string
+	help    string
+	samples []metricSample
+}
+
+// metricSet is a list of metric families, rendered in the OpenMetrics text
+// format in the order they were first added.
+type metricSet struct {
+	families []*metricFamily
+	index    map[string]*metricFamily
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{index: map[string]*metricFamily{}}
+}
+
+// add appends a sample to the given metric, creating it as needed. The kind
+// is either "counter" or "gauge".
+func (m *metricSet) add(name string, kind string, help string, labels map[string]string, value float64) {
+	family, ok := m.index[name]
+	if !ok {
+		family = &metricFamily{name: name, kind: kind, help: help}
+		m.families = append(m.families, family)
+		m.index[name] = family
+	}
+
+	family.samples = append(family.samples, metricSample{labels: labels, value: value})
+}
+
+func (m *metricSet) String() string {
+	var buf bytes.Buffer
+
+	for _, family := range m.families {
+		fmt.Fprintf(&buf, "# HELP %s %s\n", family.name, family.help)
+		fmt.Fprintf(&buf, "# TYPE %s %s\n", family.name, family.kind)
+
+		name := family.name
+		if family.kind == "counter" {
+			name += "_total"
+		}
+
+		for _, sample := range family.samples {
+			buf.WriteString(name)
+
+			if len(sample.labels) > 0 {
+				keys := []string{}
+				for key := range sample.labels {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+
+				labels := []string{}
+				for _, key := range keys {
+					labels = append(labels, fmt.Sprintf("%s=\"%s\"", key, metricEscape(sample.labels[key])))
+				}
+
+				fmt.Fprintf(&buf, "{%s}", strings.Join(labels, ","))
+			}
+
+			fmt.Fprintf(&buf, " %s\n", strconv.FormatFloat(sample.value, 'f', -1, 64))
+		}
+	}
+
+	buf.WriteString("# EOF\n")
+
+	return buf.String()
+}
+
+// metricEscape escapes a label value as required by the OpenMetrics format.
+func metricEscape(value string) string {
+	value = strings.Replace(value, "\\", "\\\\", -1)
+	value = strings.Replace(value, "\"", "\\\"", -1)
+	value = strings.Replace(value, "\n", "\\n", -1)
+
+	return value
+}
+
+type metricsResponse struct {
+	metrics *metricSet
+}
+
+func (r *metricsResponse) Render(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	_, err := w.Write([]byte(r.metrics.String()))
+	return err
+}
+
+func (r *metricsResponse) String() string {
+	return "metrics"
+}
+
+func metricsGet(d *Daemon, r *http.Request) Response {
+	metrics := newMetricSet()
+
+	names, err := d.db.ContainersList(db.CTypeRegular)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	for _, name := range names {
+		c, err := containerLoadByName(d.State(), name)
+		if err != nil {
+			logger.Warn("Failed to load container for metrics", log.Ctx{"container": name, "err": err})
+			continue
+		}
+
+		if !c.IsRunning() {
+			continue
+		}
+
+		state, err := c.RenderUsage()
+		if err != nil {
+			logger.Warn("Failed to get container usage for metrics", log.Ctx{"container": name, "err": err})
+			continue
+		}
+
+		metricsAddContainer(d, metrics, c, state)
+	}
+
+	metricsAddDaemon(metrics)
+
+	return &metricsResponse{metrics: metrics}
+}
+
+// metricsAddContainer adds the resource usage of a running container.
+func metricsAddContainer(d *Daemon, metrics *metricSet, c container, state *api.ContainerState) {
+	project := c.Project()
+	labels := func(extra ...string) map[string]string {
+		result := map[string]string{
+			"name":    projectStripPrefix(project, c.Name()),
+			"project": project,
+		}
+
+		for i := 0; i+1 < len(extra); i += 2 {
+			result[extra[i]] = extra[i+1]
+		}
+
+		return result
+	}
+
+	if d.os.CGroupCPUacctController {
+		metrics.add("lxd_container_cpu_seconds", "counter", "Total CPU time consumed by the container in seconds.",
+			labels(), float64(state.CPU.Usage)/1e9)
+	}
+
+	if d.os.CGroupMemoryController {
+		metrics.add("lxd_container_memory_usage_bytes", "gauge", "Current memory usage of the container in bytes.",
+			labels(), float64(state.Memory.Usage))
+		metrics.add("lxd_container_memory_usage_peak_bytes", "gauge", "Peak memory usage of the container in bytes.",
+			labels(), float64(state.Memory.UsagePeak))
+
+		if d.os.CGroupSwapAccounting {
+			metrics.add("lxd_container_swap_usage_bytes", "gauge", "Current swap usage of the container in bytes.",
+				labels(), float64(state.Memory.SwapUsage))
+		}
+	}
+
+	if state.Processes >= 0 {
+		metrics.add("lxd_container_processes", "gauge", "Number of processes running in the container.",
+			labels(), float64(state.Processes))
+	}
+
+	for name, disk := range state.Disk {
+		metrics.add("lxd_container_filesystem_usage_bytes", "gauge", "Space used by the container root filesystem in bytes.",
+			labels("device", name), float64(disk.Usage))
+	}
+
+	if d.os.CGroupBlkioController {
+		stats, err := c.CGroupIOStats()
+		if err == nil {
+			for device, counters := range stats {
+				metrics.add("lxd_container_disk_read_bytes", "counter", "Bytes read from block devices by the container.",
+					labels("device", device), float64(counters[0]))
+				metrics.add("lxd_container_disk_written_bytes", "counter", "Bytes written to block devices by the container.",
+					labels("device", device), float64(counters[1]))
+			}
+		}
+	}
+
+	for name, network := range state.Network {
+		counters := network.Counters
+		metrics.add("lxd_container_network_receive_bytes", "counter", "Bytes received on the container interface.",
+			labels("device", name), float64(counters.BytesReceived))
+		metrics.add("lxd_container_network_transmit_bytes", "counter", "Bytes sent on the container interface.",
+			labels("device", name), float64(counters.BytesSent))
+		metrics.add("lxd_container_network_receive_packets", "counter", "Packets received on the container interface.",
+			labels("device", name), float64(counters.PacketsReceived))
+		metrics.add("lxd_container_network_transmit_packets", "counter", "Packets sent on the container interface.",
+			labels("device", name), float64(counters.PacketsSent))
+	}
+}
+
+// metricsAddDaemon adds the metrics of the daemon itself.
+func metricsAddDaemon(metrics *metricSet) {
+	statuses := map[api.StatusCode]int{}
+
+	operationsLock.Lock()
+	for _, op := range operations {
+		statuses[op.status]++
+	}
+	operationsLock.Unlock()
+
+	for _, status := range []api.StatusCode{api.Pending, api.Running, api.Cancelling} {
+		metrics.add("lxd_operations", "gauge", "Number of operations in flight.",
+			map[string]string{"status": status.String()}, float64(statuses[status]))
+	}
+
+	stats := dbg.Runtime()
+	metrics.add("lxd_go_goroutines", "gauge", "Number of goroutines of the daemon.",
+		nil, float64(stats.Goroutines))
+	metrics.add("lxd_go_alloc_bytes", "gauge", "Bytes of allocated heap objects.",
+		nil, float64(stats.Memory.Alloc))
+	metrics.add("lxd_go_sys_bytes", "gauge", "Bytes of memory obtained from the system.",
+		nil, float64(stats.Memory.Sys))
+	metrics.add("lxd_go_heap_objects", "gauge", "Number of allocated heap objects.",
+		nil, float64(stats.Memory.HeapObjects))
+	metrics.add("lxd_go_gc", "counter", "Number of completed garbage collection cycles.",
+		nil, float64(stats.Memory.NumGC))
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricSet_String(t *testing.T) {
+	metrics := newMetricSet()
+	metrics.add("lxd_test_bytes", "counter", "Test counter.", map[string]string{"name": "c1", "device": "eth0"}, 10)
+	metrics.add("lxd_test_count", "gauge", "Test gauge.", nil, 1.5)
+	metrics.add("lxd_test_bytes", "counter", "Test counter.", map[string]string{"name": "c\"2\""}, 20)
+
+	expected := `# HELP lxd_test_bytes Test counter.
+# TYPE lxd_test_bytes counter
+lxd_test_bytes_total{device="eth0",name="c1"} 10
+lxd_test_bytes_total{name="c\"2\""} 20
+# HELP lxd_test_count Test gauge.
+# TYPE lxd_test_count gauge
+lxd_test_count 1.5
+# EOF
+`
+	assert.Equal(t, expected, metrics.String())
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/gorilla/websocket"
 	"gopkg.in/lxc/go-lxc.v2"
 
+	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
@@ -45,6 +46,10 @@ type migrationFields struct {
 	fsConn   *websocket.Conn
 
 	container container
+
+	// storage specific fields
+	storage    storage
+	volumeOnly bool
 }
 
 func (c *migrationFields) send(m proto.Message) error {
@@ -190,6 +195,26 @@ func NewMigrationSource(c container, stateful bool, containerOnly bool) (*migrat
 	return &ret, nil
 }
 
+// NewStorageMigrationSource returns a migration source for a custom storage
+// volume. Unless volumeOnly is set, its snapshots are migrated too.
+func NewStorageMigrationSource(storage storage, volumeOnly bool) (*migrationSourceWs, error) {
+	ret := migrationSourceWs{migrationFields{storage: storage}, make(chan bool, 1)}
+	ret.volumeOnly = volumeOnly
+
+	var err error
+	ret.controlSecret, err = shared.RandomCryptoString()
+	if err != nil {
+		return nil, err
+	}
+
+	ret.fsSecret, err = shared.RandomCryptoString()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
 func (s *migrationSourceWs) Metadata() interface{} {
 	secrets := shared.Jmap{
 		"control": s.controlSecret,
@@ -512,6 +537,7 @@ func (s *migrationSourceWs) Do(migrateOp *operation) error {
 			}
 
 			actionScriptOp, err := operationCreate(
+				s.container.Project(),
 				operationClassWebsocket,
 				nil,
 				nil,
@@ -629,6 +655,97 @@ func (s *migrationSourceWs) Do(migrateOp *operation) error {
 	return nil
 }
 
+func (s *migrationSourceWs) DoStorage(state *state.State, poolName string, volumeName string, migrateOp *operation) error {
+	<-s.allConnected
+
+	ourMount, err := s.storage.StoragePoolVolumeMount()
+	if err != nil {
+		s.sendControl(err)
+		return err
+	}
+	if ourMount {
+		defer s.storage.StoragePoolVolumeUmount()
+	}
+
+	snapshots := []*VolumeSnapshot{}
+	// Only send snapshots when requested.
+	if !s.volumeOnly {
+		poolID, _, _ := s.storage.GetContainerPoolInfo()
+		snapshotNames, err := state.DB.StoragePoolVolumeSnapshotsGetType(volumeName, storagePoolVolumeTypeCustom, poolID)
+		if err != nil {
+			s.sendControl(err)
+			return err
+		}
+
+		for _, name := range snapshotNames {
+			_, snapshot, err := state.DB.StoragePoolVolumeGetType(name, storagePoolVolumeTypeCustom, poolID)
+			if err != nil {
+				s.sendControl(err)
+				return err
+			}
+
+			snapshots = append(snapshots, volumeSnapshotToProtobuf(snapshot))
+		}
+	}
+
+	myType := s.storage.MigrationType()
+	header := MigrationHeader{
+		Fs:              &myType,
+		VolumeSnapshots: snapshots,
+	}
+
+	err = s.send(&header)
+	if err != nil {
+		s.sendControl(err)
+		return err
+	}
+
+	err = s.recv(&header)
+	if err != nil {
+		s.sendControl(err)
+		return err
+	}
+
+	bwlimit := ""
+	if *header.Fs != myType {
+		myType = MigrationFSType_RSYNC
+
+		// Check if this storage pool has a rate limit set for rsync.
+		poolwritable := s.storage.GetStoragePoolWritable()
+		if poolwritable.Config != nil {
+			bwlimit = poolwritable.Config["rsync.bwlimit"]
+		}
+	}
+
+	driver, err := s.storage.StorageMigrationSource(myType, s.volumeOnly)
+	if err != nil {
+		s.sendControl(err)
+		return err
+	}
+
+	err = driver.SendStorageVolume(s.fsConn, migrateOp, bwlimit)
+	if err != nil {
+		driver.Cleanup()
+		s.sendControl(err)
+		return err
+	}
+
+	driver.Cleanup()
+
+	msg := MigrationControl{}
+	err = s.recv(&msg)
+	if err != nil {
+		s.disconnect()
+		return err
+	}
+
+	if !*msg.Success {
+		return fmt.Errorf(*msg.Message)
+	}
+
+	return nil
+}
+
 type migrationSink struct {
 	// We are pulling the container from src in pull mode.
 	src migrationFields
@@ -651,11 +768,14 @@ type MigrationSinkArgs struct {
 	Push          bool
 	Live          bool
 	ContainerOnly bool
+
+	// Storage specific fields
+	Storage storage
 }
 
 func NewMigrationSink(args *MigrationSinkArgs) (*migrationSink, error) {
 	sink := migrationSink{
-		src:    migrationFields{container: args.Container, containerOnly: args.ContainerOnly},
+		src:    migrationFields{container: args.Container, containerOnly: args.ContainerOnly, storage: args.Storage},
 		dest:   migrationFields{containerOnly: args.ContainerOnly},
 		url:    args.Url,
 		dialer: args.Dialer,
@@ -1008,3 +1128,124 @@ func (c *migrationSink) Do(migrateOp *operation) error {
 		}
 	}
 }
+
+func (c *migrationSink) DoStorage(state *state.State, poolName string, volumeName string, migrateOp *operation) error {
+	var err error
+
+	if c.push {
+		<-c.allConnected
+	}
+
+	disconnector := c.src.disconnect
+	if c.push {
+		disconnector = c.dest.disconnect
+	}
+
+	if c.push {
+		defer disconnector()
+	} else {
+		c.src.controlConn, err = c.connectWithSecret(c.src.controlSecret)
+		if err != nil {
+			return err
+		}
+		defer c.src.disconnect()
+
+		c.src.fsConn, err = c.connectWithSecret(c.src.fsSecret)
+		if err != nil {
+			c.src.sendControl(err)
+			return err
+		}
+	}
+
+	receiver := c.src.recv
+	if c.push {
+		receiver = c.dest.recv
+	}
+
+	sender := c.src.send
+	if c.push {
+		sender = c.dest.send
+	}
+
+	controller := c.src.sendControl
+	if c.push {
+		controller = c.dest.sendControl
+	}
+
+	header := MigrationHeader{}
+	if err := receiver(&header); err != nil {
+		controller(err)
+		return err
+	}
+
+	for _, snap := range header.VolumeSnapshots {
+		if snap.GetName() == "" || strings.Contains(snap.GetName(), shared.SnapshotDelimiter) {
+			err := fmt.Errorf("Invalid storage volume snapshot name \"%s\"", snap.GetName())
+			controller(err)
+			return err
+		}
+	}
+
+	mySink := c.src.storage.StorageMigrationSink
+	myType := c.src.storage.MigrationType()
+	resp := MigrationHeader{
+		Fs: &myType,
+	}
+
+	// If the storage type the source has doesn't match what we have, then
+	// we have to use rsync.
+	if *header.Fs != *resp.Fs {
+		mySink = func(conn *websocket.Conn, op *operation, snapshots []*VolumeSnapshot) error {
+			return rsyncStorageMigrationSink(state, poolName, volumeName, snapshots, conn, op)
+		}
+		myType = MigrationFSType_RSYNC
+		resp.Fs = &myType
+	}
+
+	err = sender(&resp)
+	if err != nil {
+		controller(err)
+		return err
+	}
+
+	restore := make(chan error)
+	go func(c *migrationSink) {
+		var fsConn *websocket.Conn
+		if c.push {
+			fsConn = c.dest.fsConn
+		} else {
+			fsConn = c.src.fsConn
+		}
+
+		restore <- mySink(fsConn, migrateOp, header.VolumeSnapshots)
+	}(c)
+
+	var source <-chan MigrationControl
+	if c.push {
+		source = c.dest.controlChannel()
+	} else {
+		source = c.src.controlChannel()
+	}
+
+	for {
+		select {
+		case err = <-restore:
+			controller(err)
+			return err
+		case msg, ok := <-source:
+			if !ok {
+				disconnector()
+				return fmt.Errorf("Got error reading source")
+			}
+			if !*msg.Success {
+				disconnector()
+				return fmt.Errorf(*msg.Message)
+			} else {
+				// The source can only tell us it failed. We have to
+				// tell the source whether or not the transfer was
+				// successful.
+				logger.Debugf("Unknown message %v from source", msg)
+			}
+		}
+	}
+}
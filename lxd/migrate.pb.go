@@ -14,6 +14,7 @@ It has these top-level messages:
 	Snapshot
 	MigrationHeader
 	MigrationControl
+	VolumeSnapshot
 */
 package main
 
@@ -274,12 +275,13 @@ func (m *Snapshot) GetStateful() bool {
 }
 
 type MigrationHeader struct {
-	Fs               *MigrationFSType `protobuf:"varint,1,req,name=fs,enum=main.MigrationFSType" json:"fs,omitempty"`
-	Criu             *CRIUType        `protobuf:"varint,2,opt,name=criu,enum=main.CRIUType" json:"criu,omitempty"`
-	Idmap            []*IDMapType     `protobuf:"bytes,3,rep,name=idmap" json:"idmap,omitempty"`
-	SnapshotNames    []string         `protobuf:"bytes,4,rep,name=snapshotNames" json:"snapshotNames,omitempty"`
-	Snapshots        []*Snapshot      `protobuf:"bytes,5,rep,name=snapshots" json:"snapshots,omitempty"`
-	XXX_unrecognized []byte           `json:"-"`
+	Fs               *MigrationFSType  `protobuf:"varint,1,req,name=fs,enum=main.MigrationFSType" json:"fs,omitempty"`
+	Criu             *CRIUType         `protobuf:"varint,2,opt,name=criu,enum=main.CRIUType" json:"criu,omitempty"`
+	Idmap            []*IDMapType      `protobuf:"bytes,3,rep,name=idmap" json:"idmap,omitempty"`
+	SnapshotNames    []string          `protobuf:"bytes,4,rep,name=snapshotNames" json:"snapshotNames,omitempty"`
+	Snapshots        []*Snapshot       `protobuf:"bytes,5,rep,name=snapshots" json:"snapshots,omitempty"`
+	VolumeSnapshots  []*VolumeSnapshot `protobuf:"bytes,6,rep,name=volumeSnapshots" json:"volumeSnapshots,omitempty"`
+	XXX_unrecognized []byte            `json:"-"`
 }
 
 func (m *MigrationHeader) Reset()                    { *m = MigrationHeader{} }
@@ -322,6 +324,13 @@ func (m *MigrationHeader) GetSnapshots() []*Snapshot {
 	return nil
 }
 
+func (m *MigrationHeader) GetVolumeSnapshots() []*VolumeSnapshot {
+	if m != nil {
+		return m.VolumeSnapshots
+	}
+	return nil
+}
+
 type MigrationControl struct {
 	Success *bool `protobuf:"varint,1,req,name=success" json:"success,omitempty"`
 	// optional failure message if sending a failure
@@ -348,6 +357,39 @@ func (m *MigrationControl) GetMessage() string {
 	return ""
 }
 
+type VolumeSnapshot struct {
+	Name             *string   `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	Description      *string   `protobuf:"bytes,2,opt,name=description" json:"description,omitempty"`
+	Config           []*Config `protobuf:"bytes,3,rep,name=config" json:"config,omitempty"`
+	XXX_unrecognized []byte    `json:"-"`
+}
+
+func (m *VolumeSnapshot) Reset()                    { *m = VolumeSnapshot{} }
+func (m *VolumeSnapshot) String() string            { return proto.CompactTextString(m) }
+func (*VolumeSnapshot) ProtoMessage()               {}
+func (*VolumeSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *VolumeSnapshot) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *VolumeSnapshot) GetDescription() string {
+	if m != nil && m.Description != nil {
+		return *m.Description
+	}
+	return ""
+}
+
+func (m *VolumeSnapshot) GetConfig() []*Config {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*IDMapType)(nil), "main.IDMapType")
 	proto.RegisterType((*Config)(nil), "main.Config")
@@ -355,6 +397,7 @@ func init() {
 	proto.RegisterType((*Snapshot)(nil), "main.Snapshot")
 	proto.RegisterType((*MigrationHeader)(nil), "main.MigrationHeader")
 	proto.RegisterType((*MigrationControl)(nil), "main.MigrationControl")
+	proto.RegisterType((*VolumeSnapshot)(nil), "main.VolumeSnapshot")
 	proto.RegisterEnum("main.MigrationFSType", MigrationFSType_name, MigrationFSType_value)
 	proto.RegisterEnum("main.CRIUType", CRIUType_name, CRIUType_value)
 }
@@ -362,38 +405,41 @@ func init() {
 func init() { proto.RegisterFile("lxd/migrate.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 523 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x52, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0xc5, 0x5f, 0xa9, 0x3d, 0x09, 0xa9, 0x59, 0x01, 0x5a, 0x21, 0x0e, 0x91, 0xd5, 0x4a, 0x51,
-	0x85, 0xd2, 0x2a, 0x37, 0x8e, 0x24, 0x21, 0x6a, 0x25, 0x1a, 0xd0, 0xa6, 0x3d, 0xc0, 0x05, 0xad,
-	0xec, 0x89, 0xb3, 0xc2, 0x5f, 0xf2, 0xda, 0x15, 0x3d, 0xf1, 0x33, 0xf9, 0x2f, 0x9c, 0xd0, 0xae,
-	0x3f, 0x9a, 0x48, 0x70, 0x9b, 0xf7, 0xe6, 0x79, 0x9e, 0xe7, 0xed, 0xc0, 0x8b, 0xe4, 0x67, 0x74,
-	0x99, 0x8a, 0xb8, 0xe4, 0x15, 0xce, 0x8a, 0x32, 0xaf, 0x72, 0x62, 0xa7, 0x5c, 0x64, 0xc1, 0x2f,
-	0xf0, 0x6e, 0x56, 0xb7, 0xbc, 0xb8, 0x7b, 0x2c, 0x90, 0xbc, 0x04, 0x47, 0xc8, 0x5a, 0x44, 0xd4,
-	0x98, 0x98, 0x53, 0x97, 0x35, 0xa0, 0x61, 0x63, 0x11, 0x51, 0xb3, 0x63, 0x63, 0x11, 0x91, 0xd7,
-	0x30, 0xd8, 0xe7, 0xb2, 0x12, 0x11, 0xb5, 0x26, 0xe6, 0xd4, 0x61, 0x2d, 0x22, 0x04, 0xec, 0x4c,
-	0x8a, 0x88, 0xda, 0x9a, 0xd5, 0x35, 0x79, 0x03, 0x6e, 0xca, 0x8b, 0x92, 0x67, 0x31, 0x52, 0x47,
-	0xf3, 0x3d, 0x0e, 0xae, 0x60, 0xb0, 0xcc, 0xb3, 0x9d, 0x88, 0x89, 0x0f, 0xd6, 0x0f, 0x7c, 0xd4,
-	0xde, 0x1e, 0x53, 0xa5, 0x72, 0x7e, 0xe0, 0x49, 0x8d, 0xda, 0xd9, 0x63, 0x0d, 0x08, 0x16, 0x30,
-	0x58, 0xe1, 0x83, 0x08, 0x51, 0x7b, 0xf1, 0x14, 0xdb, 0x4f, 0x74, 0x4d, 0xce, 0x60, 0x10, 0xea,
-	0x79, 0xd4, 0x9c, 0x58, 0xd3, 0xe1, 0x7c, 0x34, 0x53, 0x7b, 0xce, 0x1a, 0x0f, 0xd6, 0xf6, 0x82,
-	0x3f, 0x06, 0xb8, 0xdb, 0x8c, 0x17, 0x72, 0x9f, 0x57, 0xff, 0x1c, 0x33, 0x83, 0x61, 0x92, 0x87,
-	0x3c, 0x59, 0xfe, 0x7f, 0xd6, 0xa1, 0x40, 0xad, 0x58, 0x94, 0xf9, 0x4e, 0x24, 0x28, 0xa9, 0x35,
-	0xb1, 0xa6, 0x1e, 0xeb, 0x31, 0x79, 0x0b, 0x1e, 0x16, 0x7b, 0x4c, 0xb1, 0xe4, 0x89, 0xce, 0xc5,
-	0x65, 0x4f, 0x04, 0xb9, 0x82, 0x91, 0x1e, 0xd4, 0xec, 0x24, 0xa9, 0x73, 0x68, 0xd5, 0x90, 0xec,
-	0x48, 0x41, 0x02, 0x18, 0xf1, 0x32, 0xdc, 0x8b, 0x0a, 0xc3, 0xaa, 0x2e, 0x91, 0x0e, 0x74, 0xa4,
-	0x47, 0x9c, 0xfa, 0x1f, 0x59, 0xf1, 0x0a, 0x77, 0x75, 0x42, 0x4f, 0xb4, 0x65, 0x8f, 0x83, 0xdf,
-	0x06, 0x9c, 0xde, 0xea, 0x5b, 0x10, 0x79, 0x76, 0x8d, 0x3c, 0xc2, 0x92, 0x9c, 0x83, 0xb9, 0x93,
-	0x3a, 0x81, 0xf1, 0xfc, 0x55, 0xe3, 0xdd, 0x4b, 0xd6, 0x5b, 0x75, 0x1d, 0xcc, 0xdc, 0x29, 0x6b,
-	0x3b, 0x2c, 0x45, 0x4d, 0xcd, 0x89, 0x31, 0x1d, 0xcf, 0xc7, 0x6d, 0x1e, 0xec, 0xe6, 0x5e, 0x2b,
-	0x74, 0x8f, 0x9c, 0x83, 0x23, 0xa2, 0x94, 0x17, 0x3a, 0x87, 0xe1, 0xfc, 0xb4, 0x11, 0xf5, 0x57,
-	0xc6, 0x9a, 0x2e, 0x39, 0x83, 0xe7, 0xb2, 0x7d, 0x81, 0x0d, 0x4f, 0x51, 0x52, 0x5b, 0xc7, 0x76,
-	0x4c, 0x92, 0x77, 0xe0, 0x75, 0x44, 0x17, 0x4d, 0xeb, 0xda, 0x3d, 0x1f, 0x7b, 0x12, 0x04, 0x6b,
-	0xf0, 0xfb, 0xbf, 0x5e, 0xe6, 0x59, 0x55, 0xe6, 0x09, 0xa1, 0x70, 0x22, 0xeb, 0x30, 0x44, 0x29,
-	0xdb, 0xb3, 0xee, 0xa0, 0xea, 0xa4, 0x28, 0x25, 0x8f, 0x51, 0xef, 0xe3, 0xb1, 0x0e, 0x5e, 0xbc,
-	0x3f, 0x08, 0xa8, 0xd9, 0x9e, 0x78, 0xe0, 0xb0, 0xed, 0xd7, 0xcd, 0xd2, 0x7f, 0xa6, 0xca, 0xc5,
-	0x1d, 0x5b, 0x6f, 0x7d, 0x83, 0x9c, 0x80, 0xf5, 0x6d, 0xbd, 0xf5, 0x4d, 0x55, 0xb0, 0xc5, 0xca,
-	0xb7, 0x2e, 0x2e, 0xc1, 0xed, 0xf2, 0x20, 0x63, 0x00, 0x55, 0x7f, 0x3f, 0xf8, 0xf0, 0xcb, 0xf5,
-	0x87, 0xfb, 0x4f, 0xbe, 0x41, 0x5c, 0xb0, 0x37, 0x9f, 0x37, 0x1f, 0x7d, 0xf3, 0x6f, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0xe4, 0xf2, 0xc3, 0xd0, 0x9b, 0x03, 0x00, 0x00,
+	// 570 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x53, 0x4b, 0x6b, 0xdb, 0x4c,
+	0x14, 0xfd, 0xac, 0x57, 0xa4, 0xeb, 0x7c, 0xb6, 0x3a, 0xa4, 0x65, 0x28, 0x5d, 0x08, 0x91, 0x80,
+	0x09, 0xc5, 0x09, 0xde, 0x75, 0x53, 0xa8, 0xed, 0x9a, 0x04, 0x1a, 0xb7, 0x8c, 0x92, 0x42, 0xbb,
+	0x29, 0x83, 0x34, 0x96, 0x87, 0xea, 0x85, 0x46, 0x32, 0xcd, 0xaa, 0x3f, 0xa3, 0xff, 0xb5, 0xab,
+	0x32, 0xa3, 0x47, 0xec, 0x90, 0xec, 0xee, 0x39, 0xf7, 0x71, 0x34, 0xe7, 0x5e, 0xc1, 0x8b, 0xe4,
+	0x57, 0x74, 0x91, 0xf2, 0xb8, 0xa4, 0x15, 0x9b, 0x16, 0x65, 0x5e, 0xe5, 0xc8, 0x48, 0x29, 0xcf,
+	0xfc, 0xdf, 0xe0, 0x5c, 0x2f, 0x6f, 0x68, 0x71, 0x7b, 0x5f, 0x30, 0x74, 0x02, 0x26, 0x17, 0x35,
+	0x8f, 0xf0, 0xc0, 0xd3, 0x26, 0x36, 0x69, 0x40, 0xc3, 0xc6, 0x3c, 0xc2, 0x5a, 0xc7, 0xc6, 0x3c,
+	0x42, 0xaf, 0xc0, 0xda, 0xe6, 0xa2, 0xe2, 0x11, 0xd6, 0x3d, 0x6d, 0x62, 0x92, 0x16, 0x21, 0x04,
+	0x46, 0x26, 0x78, 0x84, 0x0d, 0xc5, 0xaa, 0x18, 0xbd, 0x06, 0x3b, 0xa5, 0x45, 0x49, 0xb3, 0x98,
+	0x61, 0x53, 0xf1, 0x3d, 0xf6, 0x2f, 0xc1, 0x5a, 0xe4, 0xd9, 0x86, 0xc7, 0xc8, 0x05, 0xfd, 0x27,
+	0xbb, 0x57, 0xda, 0x0e, 0x91, 0xa1, 0x54, 0xde, 0xd1, 0xa4, 0x66, 0x4a, 0xd9, 0x21, 0x0d, 0xf0,
+	0xe7, 0x60, 0x2d, 0xd9, 0x8e, 0x87, 0x4c, 0x69, 0xd1, 0x94, 0xb5, 0x2d, 0x2a, 0x46, 0xa7, 0x60,
+	0x85, 0x6a, 0x1e, 0xd6, 0x3c, 0x7d, 0x32, 0x9c, 0x1d, 0x4f, 0xe5, 0x3b, 0xa7, 0x8d, 0x06, 0x69,
+	0x73, 0xfe, 0xdf, 0x01, 0xd8, 0x41, 0x46, 0x0b, 0xb1, 0xcd, 0xab, 0x27, 0xc7, 0x4c, 0x61, 0x98,
+	0xe4, 0x21, 0x4d, 0x16, 0xcf, 0xcf, 0xda, 0x2f, 0x90, 0x4f, 0x2c, 0xca, 0x7c, 0xc3, 0x13, 0x26,
+	0xb0, 0xee, 0xe9, 0x13, 0x87, 0xf4, 0x18, 0xbd, 0x01, 0x87, 0x15, 0x5b, 0x96, 0xb2, 0x92, 0x26,
+	0xca, 0x17, 0x9b, 0x3c, 0x10, 0xe8, 0x12, 0x8e, 0xd5, 0xa0, 0xe6, 0x4d, 0x02, 0x9b, 0xfb, 0x52,
+	0x0d, 0x49, 0x0e, 0x2a, 0x90, 0x0f, 0xc7, 0xb4, 0x0c, 0xb7, 0xbc, 0x62, 0x61, 0x55, 0x97, 0x0c,
+	0x5b, 0xca, 0xd2, 0x03, 0x4e, 0x7e, 0x8f, 0xa8, 0x68, 0xc5, 0x36, 0x75, 0x82, 0x8f, 0x94, 0x64,
+	0x8f, 0xfd, 0x3f, 0x1a, 0x8c, 0x6f, 0xd4, 0x2d, 0xf0, 0x3c, 0xbb, 0x62, 0x34, 0x62, 0x25, 0x3a,
+	0x03, 0x6d, 0x23, 0x94, 0x03, 0xa3, 0xd9, 0xcb, 0x46, 0xbb, 0x2f, 0x59, 0x05, 0xf2, 0x3a, 0x88,
+	0xb6, 0x91, 0xd2, 0x46, 0x58, 0xf2, 0x1a, 0x6b, 0xde, 0x60, 0x32, 0x9a, 0x8d, 0x5a, 0x3f, 0xc8,
+	0xf5, 0x9d, 0xaa, 0x50, 0x39, 0x74, 0x06, 0x26, 0x8f, 0x52, 0x5a, 0x28, 0x1f, 0x86, 0xb3, 0x71,
+	0x53, 0xd4, 0x5f, 0x19, 0x69, 0xb2, 0xe8, 0x14, 0xfe, 0x17, 0xed, 0x06, 0xd6, 0x34, 0x65, 0x02,
+	0x1b, 0xca, 0xb6, 0x43, 0x12, 0xbd, 0x05, 0xa7, 0x23, 0x3a, 0x6b, 0x5a, 0xd5, 0x6e, 0x7d, 0xe4,
+	0xa1, 0x00, 0xbd, 0x87, 0xf1, 0x2e, 0x4f, 0xea, 0x94, 0x05, 0x7d, 0x8f, 0xa5, 0x7a, 0x4e, 0x9a,
+	0x9e, 0xaf, 0x07, 0x49, 0xf2, 0xb8, 0xd8, 0x5f, 0x81, 0xdb, 0xbf, 0x7a, 0x91, 0x67, 0x55, 0x99,
+	0x27, 0x08, 0xc3, 0x91, 0xa8, 0xc3, 0x90, 0x09, 0xd1, 0xfe, 0x16, 0x1d, 0x94, 0x99, 0x94, 0x09,
+	0x41, 0x63, 0xa6, 0xfc, 0x70, 0x48, 0x07, 0xfd, 0x04, 0x46, 0x87, 0x52, 0x4f, 0xde, 0x98, 0x07,
+	0xc3, 0x88, 0x89, 0xb0, 0xe4, 0x85, 0xd4, 0x6b, 0x67, 0xec, 0x53, 0x7b, 0xc7, 0xac, 0x3f, 0x7f,
+	0xcc, 0xe7, 0xef, 0x60, 0xfc, 0x68, 0x57, 0xc8, 0x01, 0x93, 0x04, 0xdf, 0xd6, 0x0b, 0xf7, 0x3f,
+	0x19, 0xce, 0x6f, 0xc9, 0x2a, 0x70, 0x07, 0xe8, 0x08, 0xf4, 0xef, 0xab, 0xc0, 0xd5, 0x64, 0x40,
+	0xe6, 0x4b, 0x57, 0x3f, 0xbf, 0x00, 0xbb, 0xdb, 0x1e, 0x1a, 0x01, 0xc8, 0xf8, 0xc7, 0x5e, 0xe3,
+	0x97, 0xab, 0x0f, 0x77, 0x9f, 0xdc, 0x01, 0xb2, 0xc1, 0x58, 0x7f, 0x5e, 0x7f, 0x74, 0xb5, 0x7f,
+	0x03, 0x00, 0x7a, 0xb3, 0x25, 0x0b, 0x49, 0x04, 0x00, 0x00,
 }
@@ -16,6 +16,7 @@ import (
 	log "github.com/lxc/lxd/shared/log15"
 
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/filter"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
@@ -32,6 +33,11 @@ func networksGet(d *Daemon, r *http.Request) Response {
 		recursion = 0
 	}
 
+	f, err := filter.FromRequest(r)
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	ifs, err := networkGetInterfaces(d.db)
 	if err != nil {
 		return InternalError(err)
@@ -40,13 +46,28 @@ func networksGet(d *Daemon, r *http.Request) Response {
 	resultString := []string{}
 	resultMap := []api.Network{}
 	for _, iface := range ifs {
+		if recursion == 0 && f.Empty() {
+			resultString = append(resultString, fmt.Sprintf("/%s/networks/%s", version.APIVersion, iface))
+			continue
+		}
+
+		net, err := doNetworkGet(d, iface)
+		if err != nil {
+			continue
+		}
+
+		match, err := f.Match(net, "name", "config")
+		if err != nil {
+			return SmartError(err)
+		}
+
+		if !match {
+			continue
+		}
+
 		if recursion == 0 {
 			resultString = append(resultString, fmt.Sprintf("/%s/networks/%s", version.APIVersion, iface))
 		} else {
-			net, err := doNetworkGet(d, iface)
-			if err != nil {
-				continue
-			}
 			resultMap = append(resultMap, net)
 		}
 	}
@@ -148,7 +169,10 @@ func networksPost(d *Daemon, r *http.Request) Response {
 		return InternalError(err)
 	}
 
-	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/networks/%s", version.APIVersion, req.Name))
+	url := fmt.Sprintf("/%s/networks/%s", version.APIVersion, req.Name)
+	eventSendLifecycle("", "network-created", url, d.requestor(r), nil)
+
+	return SyncResponseLocation(true, nil, url)
 }
 
 var networksCmd = Command{name: "networks", get: networksGet, post: networksPost}
@@ -249,6 +273,8 @@ func networkDelete(d *Daemon, r *http.Request) Response {
 		os.RemoveAll(shared.VarPath("networks", n.name))
 	}
 
+	eventSendLifecycle("", "network-deleted", fmt.Sprintf("/%s/networks/%s", version.APIVersion, name), d.requestor(r), nil)
+
 	return EmptySyncResponse
 }
 
@@ -295,7 +321,10 @@ func networkPost(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
-	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/networks/%s", version.APIVersion, req.Name))
+	url := fmt.Sprintf("/%s/networks/%s", version.APIVersion, req.Name)
+	eventSendLifecycle("", "network-renamed", url, d.requestor(r), map[string]interface{}{"old_name": name})
+
+	return SyncResponseLocation(true, nil, url)
 }
 
 func networkPut(d *Daemon, r *http.Request) Response {
@@ -320,7 +349,7 @@ func networkPut(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
-	return doNetworkUpdate(d, name, dbInfo.Config, req)
+	return doNetworkUpdate(d, r, name, dbInfo.Config, req)
 }
 
 func networkPatch(d *Daemon, r *http.Request) Response {
@@ -357,10 +386,10 @@ func networkPatch(d *Daemon, r *http.Request) Response {
 		}
 	}
 
-	return doNetworkUpdate(d, name, dbInfo.Config, req)
+	return doNetworkUpdate(d, r, name, dbInfo.Config, req)
 }
 
-func doNetworkUpdate(d *Daemon, name string, oldConfig map[string]string, req api.NetworkPut) Response {
+func doNetworkUpdate(d *Daemon, r *http.Request, name string, oldConfig map[string]string, req api.NetworkPut) Response {
 	// Validate the configuration
 	err := networkValidateConfig(name, req.Config)
 	if err != nil {
@@ -385,11 +414,47 @@ func doNetworkUpdate(d *Daemon, name string, oldConfig map[string]string, req ap
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("", "network-updated", fmt.Sprintf("/%s/networks/%s", version.APIVersion, name), d.requestor(r), nil)
+
 	return EmptySyncResponse
 }
 
 var networkCmd = Command{name: "networks/{name}", get: networkGet, delete: networkDelete, post: networkPost, put: networkPut, patch: networkPatch}
 
+func networkLeasesGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	// Only managed networks have leases
+	_, _, err := d.db.NetworkGet(name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	leases, err := networkGetLeases(d.State(), name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	return SyncResponse(true, leases)
+}
+
+var networkLeasesCmd = Command{name: "networks/{name}/leases", get: networkLeasesGet}
+
+func networkStateGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	netIf, err := net.InterfaceByName(name)
+	if err != nil {
+		return NotFound
+	}
+
+	state := networkGetState(*netIf, networkGetCounters())
+
+	return SyncResponse(true, state)
+}
+
+var networkStateCmd = Command{name: "networks/{name}/state", get: networkStateGet}
+
 // The network structs and functions
 func networkLoadByName(s *state.State, name string) (*network, error) {
 	id, dbInfo, err := s.DB.NetworkGet(name)
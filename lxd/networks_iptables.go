@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/lxc/lxd/lxd/types"
 	"github.com/lxc/lxd/shared"
 )
 
@@ -94,3 +95,120 @@ func networkIptablesClear(protocol string, netName string, table string) error {
 
 	return nil
 }
+
+// networkFilterEnabled returns whether MAC or IP filtering is enabled on the
+// given nic device.
+func networkFilterEnabled(m types.Device) bool {
+	return shared.IsTrue(m["security.mac_filtering"]) || shared.IsTrue(m["security.ipv4_filtering"]) || shared.IsTrue(m["security.ipv6_filtering"])
+}
+
+// networkEbtablesFilterRules returns the ebtables arguments restricting the
+// traffic coming in from the host side interface of a bridged nic to its MAC
+// address and, with IP filtering, to its IP addresses. The container is also
+// prevented from acting as a router or DHCP server on the bridge.
+//
+// IP filtering implies MAC filtering and every rule matches on the source
+// MAC address so that they can all be found again on removal.
+func networkEbtablesFilterRules(hostName string, m types.Device) [][]string {
+	hwaddr := m["hwaddr"]
+
+	rules := [][]string{
+		{"-A", "FORWARD", "-s", "!", hwaddr, "-i", hostName, "-o", m["parent"], "-j", "DROP"},
+		{"-A", "INPUT", "-s", "!", hwaddr, "-i", hostName, "-j", "DROP"},
+	}
+
+	// Add a rule to both the INPUT and FORWARD chains
+	add := func(rule ...string) {
+		for _, chain := range []string{"INPUT", "FORWARD"} {
+			rules = append(rules, append([]string{"-A", chain}, rule...))
+		}
+	}
+
+	if shared.IsTrue(m["security.ipv4_filtering"]) {
+		// Reject DHCP server replies
+		add("-p", "IPv4", "-s", hwaddr, "-i", hostName, "--ip-proto", "udp", "--ip-sport", "67", "-j", "DROP")
+
+		if m["ipv4.address"] != "" {
+			// Prevent ARP spoofing
+			add("-p", "ARP", "-s", hwaddr, "-i", hostName, "--arp-mac-src", "!", hwaddr, "-j", "DROP")
+			add("-p", "ARP", "-s", hwaddr, "-i", hostName, "--arp-ip-src", "!", m["ipv4.address"], "-j", "DROP")
+
+			// Let DHCP requests reach the host
+			rules = append(rules, []string{"-A", "INPUT", "-p", "IPv4", "-s", hwaddr, "-i", hostName, "--ip-src", "0.0.0.0", "--ip-dst", "255.255.255.255", "--ip-proto", "udp", "--ip-dport", "67", "-j", "ACCEPT"})
+
+			// Prevent IP spoofing
+			add("-p", "IPv4", "-s", hwaddr, "-i", hostName, "--ip-src", "!", m["ipv4.address"], "-j", "DROP")
+		} else {
+			// No address can be used at all
+			add("-p", "ARP", "-s", hwaddr, "-i", hostName, "-j", "DROP")
+			add("-p", "IPv4", "-s", hwaddr, "-i", hostName, "-j", "DROP")
+		}
+	}
+
+	if shared.IsTrue(m["security.ipv6_filtering"]) {
+		// Reject router advertisements and DHCPv6 server replies
+		add("-p", "IPv6", "-s", hwaddr, "-i", hostName, "--ip6-proto", "ipv6-icmp", "--ip6-icmp-type", "router-advertisement", "-j", "DROP")
+		add("-p", "IPv6", "-s", hwaddr, "-i", hostName, "--ip6-proto", "udp", "--ip6-sport", "547", "-j", "DROP")
+
+		if m["ipv6.address"] != "" {
+			// Allow link-local traffic (neighbour discovery, router
+			// solicitations and DHCPv6 requests) and duplicate address
+			// detection
+			add("-p", "IPv6", "-s", hwaddr, "-i", hostName, "--ip6-src", "fe80::/ffc0::", "-j", "ACCEPT")
+			add("-p", "IPv6", "-s", hwaddr, "-i", hostName, "--ip6-src", "::/ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "--ip6-proto", "ipv6-icmp", "-j", "ACCEPT")
+
+			// Prevent IP spoofing
+			add("-p", "IPv6", "-s", hwaddr, "-i", hostName, "--ip6-src", "!", fmt.Sprintf("%s/ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", m["ipv6.address"]), "-j", "DROP")
+		} else {
+			// No address can be used at all
+			add("-p", "IPv6", "-s", hwaddr, "-i", hostName, "-j", "DROP")
+		}
+	}
+
+	return rules
+}
+
+// networkEbtablesFilterClear removes all the filtering rules of the nic with
+// the given MAC address.
+func networkEbtablesFilterClear(hwaddr string) error {
+	out, err := shared.RunCommand("ebtables", "-L", "--Lmac2", "--Lx")
+	if err != nil {
+		return err
+	}
+
+	hwaddr = strings.ToLower(hwaddr)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(strings.TrimSpace(line))
+		if len(fields) < 5 || fields[0] != "ebtables" || fields[3] != "-A" {
+			continue
+		}
+
+		// Look for the source MAC address match
+		match := false
+		for i := 4; i < len(fields)-1; i++ {
+			if fields[i] != "-s" {
+				continue
+			}
+
+			value := fields[i+1]
+			if value == "!" && i+2 < len(fields) {
+				value = fields[i+2]
+			}
+
+			match = strings.ToLower(value) == hwaddr
+			break
+		}
+
+		if !match {
+			continue
+		}
+
+		fields[3] = "-D"
+		_, err = shared.RunCommand(fields[0], fields[1:]...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
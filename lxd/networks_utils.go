@@ -2,6 +2,8 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"database/sql"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
@@ -23,11 +25,13 @@ import (
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/version"
 )
 
 var networkStaticLock sync.Mutex
+var networkFilterLock sync.Mutex
 
 func networkAutoAttach(db *db.Node, devName string) error {
 	_, dbInfo, err := db.NetworkGetInterface(devName)
@@ -992,3 +996,384 @@ func networkClearLease(s *state.State, network string, hwaddr string) error {
 
 	return nil
 }
+
+// networkGetCounters returns the packet counters of all the interfaces in the
+// current network namespace, as found in /proc/net/dev.
+func networkGetCounters() map[string]api.NetworkStateCounters {
+	result := map[string]api.NetworkStateCounters{}
+
+	content, err := ioutil.ReadFile("/proc/net/dev")
+	if err != nil {
+		return result
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) != 17 {
+			continue
+		}
+
+		rxBytes, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		rxPackets, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		txBytes, err := strconv.ParseInt(fields[9], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		txPackets, err := strconv.ParseInt(fields[10], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		intName := strings.TrimSuffix(fields[0], ":")
+		result[intName] = api.NetworkStateCounters{
+			BytesReceived:   rxBytes,
+			PacketsReceived: rxPackets,
+			BytesSent:       txBytes,
+			PacketsSent:     txPackets,
+		}
+	}
+
+	return result
+}
+
+// networkGetState returns the addresses, counters and link state of the given
+// interface.
+func networkGetState(netIf net.Interface, counters map[string]api.NetworkStateCounters) api.NetworkState {
+	netState := "down"
+	netType := "unknown"
+
+	if netIf.Flags&net.FlagBroadcast > 0 {
+		netType = "broadcast"
+	}
+
+	if netIf.Flags&net.FlagPointToPoint > 0 {
+		netType = "point-to-point"
+	}
+
+	if netIf.Flags&net.FlagLoopback > 0 {
+		netType = "loopback"
+	}
+
+	if netIf.Flags&net.FlagUp > 0 {
+		netState = "up"
+	}
+
+	network := api.NetworkState{
+		Addresses: []api.NetworkStateAddress{},
+		Counters:  counters[netIf.Name],
+		Hwaddr:    netIf.HardwareAddr.String(),
+		Mtu:       netIf.MTU,
+		State:     netState,
+		Type:      netType,
+	}
+
+	addrs, err := netIf.Addrs()
+	if err == nil {
+		for _, addr := range addrs {
+			fields := strings.SplitN(addr.String(), "/", 2)
+			if len(fields) != 2 {
+				continue
+			}
+
+			family := "inet"
+			if strings.Contains(fields[0], ":") {
+				family = "inet6"
+			}
+
+			scope := "global"
+			if strings.HasPrefix(fields[0], "127") {
+				scope = "local"
+			}
+
+			if fields[0] == "::1" {
+				scope = "local"
+			}
+
+			if strings.HasPrefix(fields[0], "169.254") {
+				scope = "link"
+			}
+
+			if strings.HasPrefix(fields[0], "fe80:") {
+				scope = "link"
+			}
+
+			address := api.NetworkStateAddress{}
+			address.Family = family
+			address.Address = fields[0]
+			address.Netmask = fields[1]
+			address.Scope = scope
+
+			network.Addresses = append(network.Addresses, address)
+		}
+	}
+
+	return network
+}
+
+// networkGetLeases returns the DHCP leases of a managed network, combining the
+// static reservations of the containers attached to it with the dynamic
+// leases recorded by dnsmasq.
+func networkGetLeases(s *state.State, name string) ([]api.NetworkLease, error) {
+	leases := []api.NetworkLease{}
+
+	// Get all the static reservations
+	containers, err := s.DB.ContainersList(db.CTypeRegular)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cName := range containers {
+		c, err := containerLoadByName(s, cName)
+		if err != nil {
+			continue
+		}
+
+		for k, d := range c.ExpandedDevices() {
+			if d["type"] != "nic" || d["nictype"] != "bridged" || d["parent"] != name {
+				continue
+			}
+
+			// Fill in the hwaddr from volatile
+			d, err = c.(*containerLXC).fillNetworkDevice(k, d)
+			if err != nil {
+				continue
+			}
+
+			if d["ipv4.address"] != "" {
+				leases = append(leases, api.NetworkLease{Hostname: cName, Hwaddr: d["hwaddr"], Address: d["ipv4.address"], Type: "static"})
+			}
+
+			if d["ipv6.address"] != "" {
+				leases = append(leases, api.NetworkLease{Hostname: cName, Hwaddr: d["hwaddr"], Address: d["ipv6.address"], Type: "static"})
+			}
+		}
+	}
+
+	// Get all the dynamic leases
+	dynamicLeases, err := networkGetDynamicLeases(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lease := range dynamicLeases {
+		// Skip the leases matching a static reservation
+		static := false
+		for _, entry := range leases {
+			if entry.Type == "static" && entry.Address == lease.Address {
+				static = true
+				break
+			}
+		}
+
+		if static {
+			continue
+		}
+
+		leases = append(leases, lease)
+	}
+
+	return leases, nil
+}
+
+// networkGetDynamicLeases parses the dnsmasq lease file of a managed network.
+func networkGetDynamicLeases(name string) ([]api.NetworkLease, error) {
+	leases := []api.NetworkLease{}
+
+	leaseFile := shared.VarPath("networks", name, "dnsmasq.leases")
+	if !shared.PathExists(leaseFile) {
+		return leases, nil
+	}
+
+	content, err := ioutil.ReadFile(leaseFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lease := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(lease)
+		if len(fields) < 5 || fields[0] == "duid" {
+			continue
+		}
+
+		// IPv6 leases record the IAID rather than the MAC, which can
+		// only be recovered from the end of the client DUID.
+		hwaddr := strings.Join(networkGetMacSlice(fields[1]), ":")
+		if strings.Contains(fields[2], ":") {
+			hwaddr = ""
+			if len(fields[4]) >= 17 {
+				hwaddr = fields[4][len(fields[4])-17:]
+			}
+		}
+
+		hostname := fields[3]
+		if hostname == "*" {
+			hostname = ""
+		}
+
+		leases = append(leases, api.NetworkLease{Hostname: hostname, Hwaddr: hwaddr, Address: fields[2], Type: "dynamic"})
+	}
+
+	return leases, nil
+}
+
+// networkAllocateFilterAddress returns the address a bridged nic using IP
+// filtering gets restricted to when it doesn't have a static one. The current
+// DHCP lease of the nic is used if there is one, otherwise the first unused
+// address of the DHCP range, which then gets reserved for it. With SLAAC, the
+// address is derived from the MAC address.
+//
+// An empty string is returned if the network isn't managed by LXD or doesn't
+// have a subnet for the given family ("ipv4" or "ipv6").
+func networkAllocateFilterAddress(s *state.State, network string, family string, hwaddr string) (string, error) {
+	networkFilterLock.Lock()
+	defer networkFilterLock.Unlock()
+
+	_, dbInfo, err := s.DB.NetworkGet(network)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	config := dbInfo.Config
+	if shared.StringInSlice(config[fmt.Sprintf("%s.address", family)], []string{"", "none"}) {
+		return "", nil
+	}
+
+	ip, subnet, err := net.ParseCIDR(config[fmt.Sprintf("%s.address", family)])
+	if err != nil {
+		return "", err
+	}
+
+	if family == "ipv6" && !shared.IsTrue(config["ipv6.dhcp.stateful"]) {
+		return networkGetEUI64Address(subnet, hwaddr)
+	}
+
+	// Addresses reserved by other containers
+	used := []string{ip.String()}
+
+	containers, err := s.DB.ContainersList(db.CTypeRegular)
+	if err != nil {
+		return "", err
+	}
+
+	for _, cName := range containers {
+		c, err := containerLoadByName(s, cName)
+		if err != nil {
+			continue
+		}
+
+		for k, d := range c.ExpandedDevices() {
+			if d["type"] != "nic" || d["nictype"] != "bridged" || d["parent"] != network {
+				continue
+			}
+
+			for _, address := range []string{d[fmt.Sprintf("%s.address", family)], c.LocalConfig()[fmt.Sprintf("volatile.%s.%s.address", k, family)]} {
+				if address != "" {
+					used = append(used, address)
+				}
+			}
+		}
+	}
+
+	// Keep the current lease if there's one
+	leases, err := networkGetDynamicLeases(network)
+	if err != nil {
+		return "", err
+	}
+
+	for _, lease := range leases {
+		if (family == "ipv6") != strings.Contains(lease.Address, ":") {
+			continue
+		}
+
+		if strings.ToLower(lease.Hwaddr) == strings.ToLower(hwaddr) && !shared.StringInSlice(lease.Address, used) {
+			return lease.Address, nil
+		}
+
+		used = append(used, lease.Address)
+	}
+
+	// Find a free address in the DHCP ranges
+	ranges := [][2]net.IP{}
+	if config[fmt.Sprintf("%s.dhcp.ranges", family)] != "" {
+		for _, dhcpRange := range strings.Split(config[fmt.Sprintf("%s.dhcp.ranges", family)], ",") {
+			fields := strings.SplitN(strings.TrimSpace(dhcpRange), "-", 2)
+			if len(fields) != 2 {
+				continue
+			}
+
+			ranges = append(ranges, [2]net.IP{net.ParseIP(fields[0]), net.ParseIP(fields[1])})
+		}
+	} else if family == "ipv4" {
+		ranges = append(ranges, [2]net.IP{networkGetIP(subnet, 2), networkGetIP(subnet, -2)})
+	} else {
+		ranges = append(ranges, [2]net.IP{networkGetIP(subnet, 2), networkGetIP(subnet, -1)})
+	}
+
+	for _, r := range ranges {
+		if r[0] == nil || r[1] == nil {
+			continue
+		}
+
+		for ip := r[0]; bytes.Compare(ip.To16(), r[1].To16()) <= 0; ip = networkNextIP(ip) {
+			if !shared.StringInSlice(ip.String(), used) {
+				return ip.String(), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("No free %s address left on network %s", family, network)
+}
+
+// networkNextIP returns the address following the given one.
+func networkNextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+
+	return next
+}
+
+// networkGetEUI64Address returns the SLAAC address of the given MAC address
+// within the subnet.
+func networkGetEUI64Address(subnet *net.IPNet, hwaddr string) (string, error) {
+	mac, err := net.ParseMAC(hwaddr)
+	if err != nil {
+		return "", err
+	}
+
+	if len(mac) != 6 {
+		return "", fmt.Errorf("Invalid MAC address: %s", hwaddr)
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, subnet.IP.To16()[:8])
+	ip[8] = mac[0] ^ 0x02
+	ip[9] = mac[1]
+	ip[10] = mac[2]
+	ip[11] = 0xff
+	ip[12] = 0xfe
+	ip[13] = mac[3]
+	ip[14] = mac[4]
+	ip[15] = mac[5]
+
+	return ip.String(), nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"runtime"
@@ -11,17 +12,24 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pborman/uuid"
 
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/task"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/cancel"
 	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/version"
+
+	log "github.com/lxc/lxd/shared/log15"
 )
 
 var operationsLock sync.Mutex
 var operations map[string]*operation = make(map[string]*operation)
 
+// Database all the operations get recorded in once done, if any
+var operationsHistory *db.Node
+
 type operationClass int
 
 const (
@@ -39,6 +47,7 @@ func (t operationClass) String() string {
 }
 
 type operation struct {
+	project   string
 	id        string
 	class     operationClass
 	createdAt time.Time
@@ -50,6 +59,10 @@ type operation struct {
 	err       string
 	readonly  bool
 	canceler  *cancel.Canceler
+	requestor *api.EventLifecycleRequestor
+
+	// Database the operation gets recorded in once done, if any
+	history *db.Node
 
 	// Those functions are called at various points in the operation lifecycle
 	onRun     func(*operation) error
@@ -63,6 +76,16 @@ type operation struct {
 	lock sync.Mutex
 }
 
+// isVisible returns whether the operation can be seen by a client restricted
+// to the given projects. A nil list means that the client isn't restricted.
+func (op *operation) isVisible(projects []string) bool {
+	if projects == nil {
+		return true
+	}
+
+	return shared.StringInSlice(op.project, projects)
+}
+
 func (op *operation) done() {
 	if op.readonly {
 		return
@@ -76,6 +99,8 @@ func (op *operation) done() {
 	close(op.chanDone)
 	op.lock.Unlock()
 
+	op.recordHistory()
+
 	time.AfterFunc(time.Second*5, func() {
 		operationsLock.Lock()
 		_, ok := operations[op.id]
@@ -101,6 +126,43 @@ func (op *operation) done() {
 	})
 }
 
+// track sets the client which requested the operation.
+func (op *operation) track(requestor *api.EventLifecycleRequestor) {
+	op.lock.Lock()
+	op.requestor = requestor
+	op.lock.Unlock()
+}
+
+func (op *operation) recordHistory() {
+	op.lock.Lock()
+	history := op.history
+	args := db.OperationHistoryArgs{
+		UUID:       op.id,
+		Project:    op.project,
+		Class:      op.class.String(),
+		StatusCode: int(op.status),
+		Resources:  op.resources,
+		Err:        op.err,
+		CreatedAt:  op.createdAt,
+		FinishedAt: time.Now(),
+	}
+
+	if op.requestor != nil {
+		args.RequestorProtocol = op.requestor.Protocol
+		args.RequestorUsername = op.requestor.Username
+	}
+	op.lock.Unlock()
+
+	if history == nil {
+		return
+	}
+
+	err := history.OperationHistoryAdd(args)
+	if err != nil {
+		logger.Warn("Failed to record operation in history", log.Ctx{"operation": op.id, "err": err})
+	}
+}
+
 func (op *operation) Run() (chan error, error) {
 	if op.status != api.Pending {
 		return nil, fmt.Errorf("Only pending operations can be started")
@@ -125,7 +187,7 @@ func (op *operation) Run() (chan error, error) {
 				logger.Debugf("Failure for %s operation: %s: %s", op.class.String(), op.id, err)
 
 				_, md, _ := op.Render()
-				eventSend("operation", md)
+				eventSend(op.project, "operation", md)
 				return
 			}
 
@@ -138,7 +200,7 @@ func (op *operation) Run() (chan error, error) {
 			op.lock.Lock()
 			logger.Debugf("Success for %s operation: %s", op.class.String(), op.id)
 			_, md, _ := op.Render()
-			eventSend("operation", md)
+			eventSend(op.project, "operation", md)
 			op.lock.Unlock()
 		}(op, chanRun)
 	}
@@ -146,7 +208,7 @@ func (op *operation) Run() (chan error, error) {
 
 	logger.Debugf("Started %s operation: %s", op.class.String(), op.id)
 	_, md, _ := op.Render()
-	eventSend("operation", md)
+	eventSend(op.project, "operation", md)
 
 	return chanRun, nil
 }
@@ -178,7 +240,7 @@ func (op *operation) Cancel() (chan error, error) {
 
 				logger.Debugf("Failed to cancel %s operation: %s: %s", op.class.String(), op.id, err)
 				_, md, _ := op.Render()
-				eventSend("operation", md)
+				eventSend(op.project, "operation", md)
 				return
 			}
 
@@ -190,13 +252,13 @@ func (op *operation) Cancel() (chan error, error) {
 
 			logger.Debugf("Cancelled %s operation: %s", op.class.String(), op.id)
 			_, md, _ := op.Render()
-			eventSend("operation", md)
+			eventSend(op.project, "operation", md)
 		}(op, oldStatus, chanCancel)
 	}
 
 	logger.Debugf("Cancelling %s operation: %s", op.class.String(), op.id)
 	_, md, _ := op.Render()
-	eventSend("operation", md)
+	eventSend(op.project, "operation", md)
 
 	if op.canceler != nil {
 		err := op.canceler.Cancel()
@@ -215,7 +277,7 @@ func (op *operation) Cancel() (chan error, error) {
 
 	logger.Debugf("Cancelled %s operation: %s", op.class.String(), op.id)
 	_, md, _ = op.Render()
-	eventSend("operation", md)
+	eventSend(op.project, "operation", md)
 
 	return chanCancel, nil
 }
@@ -270,20 +332,6 @@ func (op *operation) mayCancel() bool {
 }
 
 func (op *operation) Render() (string, *api.Operation, error) {
-	// Setup the resource URLs
-	resources := op.resources
-	if resources != nil {
-		tmpResources := make(map[string][]string)
-		for key, value := range resources {
-			var values []string
-			for _, c := range value {
-				values = append(values, fmt.Sprintf("/%s/%s/%s", version.APIVersion, key, c))
-			}
-			tmpResources[key] = values
-		}
-		resources = tmpResources
-	}
-
 	return op.url, &api.Operation{
 		ID:         op.id,
 		Class:      op.class.String(),
@@ -291,13 +339,68 @@ func (op *operation) Render() (string, *api.Operation, error) {
 		UpdatedAt:  op.updatedAt,
 		Status:     op.status.String(),
 		StatusCode: op.status,
-		Resources:  resources,
+		Resources:  operationRenderResources(op.resources),
 		Metadata:   op.metadata,
 		MayCancel:  op.mayCancel(),
 		Err:        op.err,
+		Requestor:  op.requestor,
 	}, nil
 }
 
+// operationRenderResources turns the names of the resources used by an
+// operation into URLs.
+func operationRenderResources(resources map[string][]string) map[string][]string {
+	if resources == nil {
+		return nil
+	}
+
+	rendered := make(map[string][]string)
+	for key, value := range resources {
+		var values []string
+		for _, c := range value {
+			if key == "containers" {
+				// Containers are reported under their project's name
+				project := projectFromContainerName(c)
+				url := fmt.Sprintf("/%s/%s/%s", version.APIVersion, key, projectStripPrefix(project, c))
+				values = append(values, projectURL(project, url))
+				continue
+			}
+
+			values = append(values, fmt.Sprintf("/%s/%s/%s", version.APIVersion, key, c))
+		}
+		rendered[key] = values
+	}
+
+	return rendered
+}
+
+// operationHistoryRender returns the API representation of an operation
+// from the history. The last update of such operations is their completion.
+func operationHistoryRender(args db.OperationHistoryArgs) *api.Operation {
+	status := api.StatusCode(args.StatusCode)
+
+	op := &api.Operation{
+		ID:         args.UUID,
+		Class:      args.Class,
+		CreatedAt:  args.CreatedAt,
+		UpdatedAt:  args.FinishedAt,
+		Status:     status.String(),
+		StatusCode: status,
+		Resources:  operationRenderResources(args.Resources),
+		Metadata:   map[string]interface{}{},
+		Err:        args.Err,
+	}
+
+	if args.RequestorProtocol != "" {
+		op.Requestor = &api.EventLifecycleRequestor{
+			Protocol: args.RequestorProtocol,
+			Username: args.RequestorUsername,
+		}
+	}
+
+	return op
+}
+
 func (op *operation) WaitFinal(timeout int) (bool, error) {
 	// Check current state
 	if op.status.IsFinal() {
@@ -341,7 +444,7 @@ func (op *operation) UpdateResources(opResources map[string][]string) error {
 
 	logger.Debugf("Updated resources for %s operation: %s", op.class.String(), op.id)
 	_, md, _ := op.Render()
-	eventSend("operation", md)
+	eventSend(op.project, "operation", md)
 
 	return nil
 }
@@ -367,15 +470,16 @@ func (op *operation) UpdateMetadata(opMetadata interface{}) error {
 
 	logger.Debugf("Updated metadata for %s operation: %s", op.class.String(), op.id)
 	_, md, _ := op.Render()
-	eventSend("operation", md)
+	eventSend(op.project, "operation", md)
 
 	return nil
 }
 
-func operationCreate(opClass operationClass, opResources map[string][]string, opMetadata interface{}, onRun func(*operation) error, onCancel func(*operation) error, onConnect func(*operation, *http.Request, http.ResponseWriter) error) (*operation, error) {
+func operationCreate(project string, opClass operationClass, opResources map[string][]string, opMetadata interface{}, onRun func(*operation) error, onCancel func(*operation) error, onConnect func(*operation, *http.Request, http.ResponseWriter) error) (*operation, error) {
 	// Main attributes
 	op := operation{}
 	op.id = uuid.NewRandom().String()
+	op.project = project
 	op.class = opClass
 	op.createdAt = time.Now()
 	op.updatedAt = op.createdAt
@@ -413,12 +517,13 @@ func operationCreate(opClass operationClass, opResources map[string][]string, op
 	}
 
 	operationsLock.Lock()
+	op.history = operationsHistory
 	operations[op.id] = &op
 	operationsLock.Unlock()
 
 	logger.Debugf("New %s operation: %s", op.class.String(), op.id)
 	_, md, _ := op.Render()
-	eventSend("operation", md)
+	eventSend(op.project, "operation", md)
 
 	return &op, nil
 }
@@ -441,6 +546,10 @@ func operationAPIGet(d *Daemon, r *http.Request) Response {
 
 	op, err := operationGet(id)
 	if err != nil {
+		return operationHistoryResponse(d, r, id)
+	}
+
+	if !op.isVisible(d.clientRestrictedProjects(r)) {
 		return NotFound
 	}
 
@@ -460,6 +569,10 @@ func operationAPIDelete(d *Daemon, r *http.Request) Response {
 		return NotFound
 	}
 
+	if !op.isVisible(d.clientRestrictedProjects(r)) {
+		return NotFound
+	}
+
 	_, err = op.Cancel()
 	if err != nil {
 		return BadRequest(err)
@@ -475,14 +588,40 @@ func operationsAPIGet(d *Daemon, r *http.Request) Response {
 
 	recursion := util.IsRecursionRequest(r)
 
-	md = shared.Jmap{}
+	// Time range of the listing, on creation time
+	var since time.Time
+	var until time.Time
+	var err error
 
-	operationsLock.Lock()
-	ops := operations
-	operationsLock.Unlock()
+	if r.FormValue("since") != "" {
+		since, err = time.Parse(time.RFC3339, r.FormValue("since"))
+		if err != nil {
+			return BadRequest(fmt.Errorf("Invalid since time: %v", err))
+		}
+	}
 
-	for _, v := range ops {
-		status := strings.ToLower(v.status.String())
+	if r.FormValue("until") != "" {
+		until, err = time.Parse(time.RFC3339, r.FormValue("until"))
+		if err != nil {
+			return BadRequest(fmt.Errorf("Invalid until time: %v", err))
+		}
+	}
+
+	inRange := func(createdAt time.Time) bool {
+		if !since.IsZero() && createdAt.Before(since) {
+			return false
+		}
+
+		if !until.IsZero() && createdAt.After(until) {
+			return false
+		}
+
+		return true
+	}
+
+	md = shared.Jmap{}
+
+	add := func(status string, url string, body *api.Operation) {
 		_, ok := md[status]
 		if !ok {
 			if recursion {
@@ -493,16 +632,54 @@ func operationsAPIGet(d *Daemon, r *http.Request) Response {
 		}
 
 		if !recursion {
-			md[status] = append(md[status].([]string), v.url)
+			md[status] = append(md[status].([]string), url)
+			return
+		}
+
+		md[status] = append(md[status].([]*api.Operation), body)
+	}
+
+	operationsLock.Lock()
+	ops := operations
+	operationsLock.Unlock()
+
+	projects := d.clientRestrictedProjects(r)
+
+	for _, v := range ops {
+		if !v.isVisible(projects) || !inRange(v.createdAt) {
 			continue
 		}
 
-		_, body, err := v.Render()
+		url, body, err := v.Render()
 		if err != nil {
 			continue
 		}
 
-		md[status] = append(md[status].([]*api.Operation), body)
+		add(strings.ToLower(v.status.String()), url, body)
+	}
+
+	// Include the operations from the history
+	if shared.IsTrue(r.FormValue("all")) {
+		history, err := d.db.OperationsHistoryGet(since, until)
+		if err != nil {
+			return SmartError(err)
+		}
+
+		for _, args := range history {
+			if !operationHistoryVisible(args, projects) {
+				continue
+			}
+
+			// Recently finished operations are still around
+			_, ok := ops[args.UUID]
+			if ok {
+				continue
+			}
+
+			body := operationHistoryRender(args)
+			url := fmt.Sprintf("/%s/operations/%s", version.APIVersion, args.UUID)
+			add(strings.ToLower(body.Status), url, body)
+		}
 	}
 
 	return SyncResponse(true, md)
@@ -510,6 +687,56 @@ func operationsAPIGet(d *Daemon, r *http.Request) Response {
 
 var operationsCmd = Command{name: "operations", get: operationsAPIGet}
 
+func pruneOperationsHistoryTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		pruneOperationsHistory(d)
+	}
+
+	return f, task.Daily()
+}
+
+func pruneOperationsHistory(d *Daemon) {
+	expiry := daemonConfig["core.operations_history_expiry"].GetInt64()
+
+	// Check if we're supposed to prune at all
+	if expiry <= 0 {
+		return
+	}
+
+	logger.Infof("Pruning the operations history")
+	err := d.db.OperationsHistoryPrune(time.Now().Add(-time.Duration(expiry*24) * time.Hour))
+	if err != nil {
+		logger.Error("Failed to prune the operations history", log.Ctx{"err": err})
+		return
+	}
+	logger.Infof("Done pruning the operations history")
+}
+
+// operationHistoryVisible returns whether the given operation from the
+// history can be seen by a client restricted to the given projects.
+func operationHistoryVisible(args db.OperationHistoryArgs, projects []string) bool {
+	if projects == nil {
+		return true
+	}
+
+	return shared.StringInSlice(args.Project, projects)
+}
+
+// operationHistoryResponse returns the operation with the given ID from the
+// history.
+func operationHistoryResponse(d *Daemon, r *http.Request, id string) Response {
+	args, err := d.db.OperationHistoryGet(id)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if !operationHistoryVisible(args, d.clientRestrictedProjects(r)) {
+		return NotFound
+	}
+
+	return SyncResponse(true, operationHistoryRender(args))
+}
+
 func operationAPIWaitGet(d *Daemon, r *http.Request) Response {
 	timeout, err := shared.AtoiEmptyDefault(r.FormValue("timeout"), -1)
 	if err != nil {
@@ -519,6 +746,11 @@ func operationAPIWaitGet(d *Daemon, r *http.Request) Response {
 	id := mux.Vars(r)["id"]
 	op, err := operationGet(id)
 	if err != nil {
+		// Operations from the history are already done
+		return operationHistoryResponse(d, r, id)
+	}
+
+	if !op.isVisible(d.clientRestrictedProjects(r)) {
 		return NotFound
 	}
 
@@ -568,6 +800,10 @@ func operationAPIWebsocketGet(d *Daemon, r *http.Request) Response {
 		return NotFound
 	}
 
+	if !op.isVisible(d.clientRestrictedProjects(r)) {
+		return NotFound
+	}
+
 	return &operationWebSocket{r, op}
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/gorilla/mux"
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/lxc/lxd/lxd/filter"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
@@ -23,29 +24,52 @@ import (
 
 /* This is used for both profiles post and profile put */
 func profilesGet(d *Daemon, r *http.Request) Response {
-	results, err := d.db.Profiles()
+	project, err := projectProfileScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	results, err := d.db.ProfilesForProject(project)
 	if err != nil {
 		return SmartError(err)
 	}
 
 	recursion := util.IsRecursionRequest(r)
 
-	resultString := make([]string, len(results))
-	resultMap := make([]*api.Profile, len(results))
-	i := 0
+	f, err := filter.FromRequest(r)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	resultString := []string{}
+	resultMap := []*api.Profile{}
 	for _, name := range results {
+		url := fmt.Sprintf("/%s/profiles/%s", version.APIVersion, projectStripPrefix(project, name))
+		if !recursion && f.Empty() {
+			resultString = append(resultString, projectURL(projectParam(r), url))
+			continue
+		}
+
+		profile, err := doProfileGet(d.State(), project, name)
+		if err != nil {
+			logger.Error("Failed to get profile", log.Ctx{"profile": name})
+			continue
+		}
+
+		match, err := f.Match(profile, "name", "config")
+		if err != nil {
+			return SmartError(err)
+		}
+
+		if !match {
+			continue
+		}
+
 		if !recursion {
-			url := fmt.Sprintf("/%s/profiles/%s", version.APIVersion, name)
-			resultString[i] = url
+			resultString = append(resultString, projectURL(projectParam(r), url))
 		} else {
-			profile, err := doProfileGet(d.State(), name)
-			if err != nil {
-				logger.Error("Failed to get profile", log.Ctx{"profile": name})
-				continue
-			}
-			resultMap[i] = profile
+			resultMap = append(resultMap, profile)
 		}
-		i++
 	}
 
 	if !recursion {
@@ -66,7 +90,12 @@ func profilesPost(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("No name provided"))
 	}
 
-	_, profile, _ := d.db.ProfileGet(req.Name)
+	project, err := projectProfileScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, profile, _ := d.db.ProfileGet(projectPrefix(project, req.Name))
 	if profile != nil {
 		return BadRequest(fmt.Errorf("The profile already exists"))
 	}
@@ -79,7 +108,12 @@ func profilesPost(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("Invalid profile name '%s'", req.Name))
 	}
 
-	err := containerValidConfig(d.os, req.Config, true, false)
+	err = projectCheckReservedName(d.db, project, req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	err = containerValidConfig(d.os, req.Config, true, false)
 	if err != nil {
 		return BadRequest(err)
 	}
@@ -90,13 +124,16 @@ func profilesPost(d *Daemon, r *http.Request) Response {
 	}
 
 	// Update DB entry
-	_, err = d.db.ProfileCreate(req.Name, req.Description, req.Config, req.Devices)
+	_, err = d.db.ProfileCreate(project, projectPrefix(project, req.Name), req.Description, req.Config, req.Devices)
 	if err != nil {
 		return SmartError(
 			fmt.Errorf("Error inserting %s into database: %s", req.Name, err))
 	}
 
-	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/profiles/%s", version.APIVersion, req.Name))
+	profileSendLifecycle(d, r, req.Name, "profile-created", nil)
+
+	url := fmt.Sprintf("/%s/profiles/%s", version.APIVersion, req.Name)
+	return SyncResponseLocation(true, nil, projectURL(projectParam(r), url))
 }
 
 var profilesCmd = Command{
@@ -104,11 +141,12 @@ var profilesCmd = Command{
 	get:  profilesGet,
 	post: profilesPost}
 
-func doProfileGet(s *state.State, name string) (*api.Profile, error) {
+func doProfileGet(s *state.State, project string, name string) (*api.Profile, error) {
 	_, profile, err := s.DB.ProfileGet(name)
 	if err != nil {
 		return nil, err
 	}
+	profile.Name = projectStripPrefix(project, profile.Name)
 
 	cts, err := s.DB.ProfileContainersGet(name)
 	if err != nil {
@@ -117,7 +155,9 @@ func doProfileGet(s *state.State, name string) (*api.Profile, error) {
 
 	usedBy := []string{}
 	for _, ct := range cts {
-		usedBy = append(usedBy, fmt.Sprintf("/%s/containers/%s", version.APIVersion, ct))
+		ctProject := projectFromContainerName(ct)
+		url := fmt.Sprintf("/%s/containers/%s", version.APIVersion, projectStripPrefix(ctProject, ct))
+		usedBy = append(usedBy, projectURL(ctProject, url))
 	}
 	profile.UsedBy = usedBy
 
@@ -125,9 +165,14 @@ func doProfileGet(s *state.State, name string) (*api.Profile, error) {
 }
 
 func profileGet(d *Daemon, r *http.Request) Response {
-	name := mux.Vars(r)["name"]
+	project, err := projectProfileScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	name := projectPrefix(project, mux.Vars(r)["name"])
 
-	resp, err := doProfileGet(d.State(), name)
+	resp, err := doProfileGet(d.State(), project, name)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -158,10 +203,15 @@ func getContainersWithProfile(s *state.State, profile string) []container {
 
 func profilePut(d *Daemon, r *http.Request) Response {
 	// Get the profile
-	name := mux.Vars(r)["name"]
+	project, err := projectProfileScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	name := projectPrefix(project, mux.Vars(r)["name"])
 	id, profile, err := d.db.ProfileGet(name)
 	if err != nil {
-		return SmartError(fmt.Errorf("Failed to retrieve profile='%s'", name))
+		return SmartError(fmt.Errorf("Failed to retrieve profile='%s'", mux.Vars(r)["name"]))
 	}
 
 	// Validate the ETag
@@ -176,15 +226,20 @@ func profilePut(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
-	return doProfileUpdate(d, name, id, profile, req)
+	return doProfileUpdate(d, r, name, id, profile, req)
 }
 
 func profilePatch(d *Daemon, r *http.Request) Response {
 	// Get the profile
-	name := mux.Vars(r)["name"]
+	project, err := projectProfileScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	name := projectPrefix(project, mux.Vars(r)["name"])
 	id, profile, err := d.db.ProfileGet(name)
 	if err != nil {
-		return SmartError(fmt.Errorf("Failed to retrieve profile='%s'", name))
+		return SmartError(fmt.Errorf("Failed to retrieve profile='%s'", mux.Vars(r)["name"]))
 	}
 
 	// Validate the ETag
@@ -242,12 +297,17 @@ func profilePatch(d *Daemon, r *http.Request) Response {
 		}
 	}
 
-	return doProfileUpdate(d, name, id, profile, req)
+	return doProfileUpdate(d, r, name, id, profile, req)
 }
 
 // The handler for the post operation.
 func profilePost(d *Daemon, r *http.Request) Response {
-	name := mux.Vars(r)["name"]
+	project, err := projectProfileScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	name := projectPrefix(project, mux.Vars(r)["name"])
 
 	req := api.ProfilePost{}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -260,7 +320,7 @@ func profilePost(d *Daemon, r *http.Request) Response {
 	}
 
 	// Check that the name isn't already in use
-	id, _, _ := d.db.ProfileGet(req.Name)
+	id, _, _ := d.db.ProfileGet(projectPrefix(project, req.Name))
 	if id > 0 {
 		return Conflict
 	}
@@ -273,19 +333,33 @@ func profilePost(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("Invalid profile name '%s'", req.Name))
 	}
 
-	err := d.db.ProfileUpdate(name, req.Name)
+	err = projectCheckReservedName(d.db, project, req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	err = d.db.ProfileUpdate(name, projectPrefix(project, req.Name))
 	if err != nil {
 		return SmartError(err)
 	}
 
-	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/profiles/%s", version.APIVersion, req.Name))
+	profileSendLifecycle(d, r, req.Name, "profile-renamed",
+		map[string]interface{}{"old_name": mux.Vars(r)["name"]})
+
+	url := fmt.Sprintf("/%s/profiles/%s", version.APIVersion, req.Name)
+	return SyncResponseLocation(true, nil, projectURL(projectParam(r), url))
 }
 
 // The handler for the delete operation.
 func profileDelete(d *Daemon, r *http.Request) Response {
-	name := mux.Vars(r)["name"]
+	project, err := projectProfileScope(d.db, projectParam(r))
+	if err != nil {
+		return SmartError(err)
+	}
+
+	name := projectPrefix(project, mux.Vars(r)["name"])
 
-	_, err := doProfileGet(d.State(), name)
+	_, err = doProfileGet(d.State(), project, name)
 	if err != nil {
 		return SmartError(err)
 	}
@@ -300,6 +374,8 @@ func profileDelete(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	profileSendLifecycle(d, r, mux.Vars(r)["name"], "profile-deleted", nil)
+
 	return EmptySyncResponse
 }
 
@@ -2,13 +2,24 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 
+	"github.com/gorilla/mux"
+
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
 )
 
-func doProfileUpdate(d *Daemon, name string, id int64, profile *api.Profile, req api.ProfilePut) Response {
+// profileSendLifecycle sends a lifecycle event for the given profile, on
+// behalf of the client which sent the request.
+func profileSendLifecycle(d *Daemon, r *http.Request, name string, action string, context map[string]interface{}) {
+	url := fmt.Sprintf("/%s/profiles/%s", version.APIVersion, name)
+	eventSendLifecycle(projectParam(r), action, projectURL(projectParam(r), url), d.requestor(r), context)
+}
+
+func doProfileUpdate(d *Daemon, r *http.Request, name string, id int64, profile *api.Profile, req api.ProfilePut) Response {
 	// Sanity checks
 	err := containerValidConfig(d.os, req.Config, true, false)
 	if err != nil {
@@ -80,6 +91,8 @@ func doProfileUpdate(d *Daemon, name string, id int64, profile *api.Profile, req
 			return SmartError(err)
 		}
 
+		profileSendLifecycle(d, r, mux.Vars(r)["name"], "profile-updated", nil)
+
 		return EmptySyncResponse
 	}
 
@@ -121,6 +134,8 @@ func doProfileUpdate(d *Daemon, name string, id int64, profile *api.Profile, req
 		}
 	}
 
+	profileSendLifecycle(d, r, mux.Vars(r)["name"], "profile-updated", nil)
+
 	if len(failures) != 0 {
 		msg := "The following containers failed to update (profile change still saved):\n"
 		for cname, err := range failures {
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/types"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+// API endpoints
+func projectsGet(d *Daemon, r *http.Request) Response {
+	recursionStr := r.FormValue("recursion")
+	recursion, err := strconv.Atoi(recursionStr)
+	if err != nil {
+		recursion = 0
+	}
+
+	projects, err := d.db.Projects()
+	if err != nil {
+		return InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.Project{}
+	for _, name := range projects {
+		if recursion == 0 {
+			resultString = append(resultString, fmt.Sprintf("/%s/projects/%s", version.APIVersion, name))
+		} else {
+			project, err := doProjectGet(d, name)
+			if err != nil {
+				continue
+			}
+			resultMap = append(resultMap, *project)
+		}
+	}
+
+	if recursion == 0 {
+		return SyncResponse(true, resultString)
+	}
+
+	return SyncResponse(true, resultMap)
+}
+
+func projectsPost(d *Daemon, r *http.Request) Response {
+	req := api.ProjectsPost{}
+
+	// Parse the request
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// Sanity checks
+	err = projectValidName(req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	err = projectValidateConfig(req.Config)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	projects, err := d.db.Projects()
+	if err != nil {
+		return InternalError(err)
+	}
+
+	if shared.StringInSlice(req.Name, projects) {
+		return BadRequest(fmt.Errorf("The project already exists"))
+	}
+
+	err = projectCheckPrefixUnused(d, req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// Create the database entry
+	_, err = d.db.ProjectCreate(req.Name, req.Description, req.Config)
+	if err != nil {
+		return SmartError(fmt.Errorf("Error inserting %s into database: %s", req.Name, err))
+	}
+
+	// Projects with their own profiles get an empty default profile
+	if shared.IsTrue(req.Config["features.profiles"]) {
+		err = projectCreateDefaultProfile(d, req.Name)
+		if err != nil {
+			d.db.ProjectDelete(req.Name)
+			return SmartError(err)
+		}
+	}
+
+	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/projects/%s", version.APIVersion, req.Name))
+}
+
+var projectsCmd = Command{name: "projects", get: projectsGet, post: projectsPost}
+
+func projectGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	project, err := doProjectGet(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	etag := []interface{}{project.Description, project.Config}
+
+	return SyncResponseETag(true, project, etag)
+}
+
+func doProjectGet(d *Daemon, name string) (*api.Project, error) {
+	_, project, err := d.db.ProjectGet(name)
+	if err != nil {
+		return nil, err
+	}
+
+	project.UsedBy, err = projectUsedBy(d, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func projectPut(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	// Get the existing project
+	_, project, err := d.db.ProjectGet(name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Validate the ETag
+	etag := []interface{}{project.Description, project.Config}
+
+	err = util.EtagCheck(r, etag)
+	if err != nil {
+		return PreconditionFailed(err)
+	}
+
+	req := api.ProjectPut{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return BadRequest(err)
+	}
+
+	return doProjectUpdate(d, name, project.Config, req)
+}
+
+func projectPatch(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	// Get the existing project
+	_, project, err := d.db.ProjectGet(name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Validate the ETag
+	etag := []interface{}{project.Description, project.Config}
+
+	err = util.EtagCheck(r, etag)
+	if err != nil {
+		return PreconditionFailed(err)
+	}
+
+	req := api.ProjectPut{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return BadRequest(err)
+	}
+
+	// Config stacking
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	for k, v := range project.Config {
+		_, ok := req.Config[k]
+		if !ok {
+			req.Config[k] = v
+		}
+	}
+
+	return doProjectUpdate(d, name, project.Config, req)
+}
+
+func doProjectUpdate(d *Daemon, name string, oldConfig map[string]string, req api.ProjectPut) Response {
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	// Validate the configuration
+	err := projectValidateConfig(req.Config)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// Features can only be changed on empty projects
+	for key := range projectConfigKeys {
+		if shared.IsTrue(oldConfig[key]) == shared.IsTrue(req.Config[key]) {
+			continue
+		}
+
+		if name == "default" {
+			return BadRequest(fmt.Errorf("Features can't be changed on the default project"))
+		}
+
+		empty, err := projectIsEmpty(d.db, name)
+		if err != nil {
+			return SmartError(err)
+		}
+
+		if !empty {
+			return BadRequest(fmt.Errorf("Features can only be changed on empty projects"))
+		}
+	}
+
+	err = d.db.ProjectUpdate(name, req.Description, req.Config)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Add or remove the project's own default profile
+	oldProfiles := shared.IsTrue(oldConfig["features.profiles"])
+	newProfiles := shared.IsTrue(req.Config["features.profiles"])
+	if !oldProfiles && newProfiles {
+		err = projectCreateDefaultProfile(d, name)
+		if err != nil {
+			return SmartError(err)
+		}
+	} else if oldProfiles && !newProfiles {
+		err = d.db.ProfileDelete(projectPrefix(name, "default"))
+		if err != nil {
+			return SmartError(err)
+		}
+	}
+
+	return EmptySyncResponse
+}
+
+func projectPost(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	req := api.ProjectPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// Sanity checks
+	if name == "default" {
+		return Forbidden
+	}
+
+	err = projectValidName(req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	_, project, err := d.db.ProjectGet(name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	projects, err := d.db.Projects()
+	if err != nil {
+		return InternalError(err)
+	}
+
+	if shared.StringInSlice(req.Name, projects) {
+		return Conflict
+	}
+
+	err = projectCheckPrefixUnused(d, req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// The names of the project's objects embed the project name
+	empty, err := projectIsEmpty(d.db, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if !empty {
+		return BadRequest(fmt.Errorf("Only empty projects can be renamed"))
+	}
+
+	err = d.db.ProjectRename(name, req.Name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if shared.IsTrue(project.Config["features.profiles"]) {
+		err = d.db.ProfileUpdate(projectPrefix(name, "default"), projectPrefix(req.Name, "default"))
+		if err != nil {
+			return SmartError(err)
+		}
+	}
+
+	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/projects/%s", version.APIVersion, req.Name))
+}
+
+func projectDelete(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	// Sanity checks
+	if name == "default" {
+		return Forbidden
+	}
+
+	_, _, err := d.db.ProjectGet(name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	empty, err := projectIsEmpty(d.db, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if !empty {
+		return BadRequest(fmt.Errorf("Only empty projects can be removed"))
+	}
+
+	err = d.db.ProjectDelete(name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	return EmptySyncResponse
+}
+
+var projectCmd = Command{name: "projects/{name}", get: projectGet, put: projectPut, patch: projectPatch, post: projectPost, delete: projectDelete}
+
+// projectUsedBy returns the URLs of the containers, images and profiles
+// belonging to the given project.
+func projectUsedBy(d *Daemon, project string) ([]string, error) {
+	usedBy := []string{}
+
+	containers, err := d.db.ContainersListForProject(project, db.CTypeRegular)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range containers {
+		url := fmt.Sprintf("/%s/containers/%s", version.APIVersion, projectStripPrefix(project, name))
+		usedBy = append(usedBy, projectURL(project, url))
+	}
+
+	images, err := d.db.ImagesGetForProject(project, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fingerprint := range images {
+		url := fmt.Sprintf("/%s/images/%s", version.APIVersion, fingerprint)
+		usedBy = append(usedBy, projectURL(project, url))
+	}
+
+	profiles, err := d.db.ProfilesForProject(project)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range profiles {
+		url := fmt.Sprintf("/%s/profiles/%s", version.APIVersion, projectStripPrefix(project, name))
+		usedBy = append(usedBy, projectURL(project, url))
+	}
+
+	return usedBy, nil
+}
+
+// projectCreateDefaultProfile creates the default profile of a project which
+// has the "features.profiles" feature enabled.
+func projectCreateDefaultProfile(d *Daemon, project string) error {
+	description := fmt.Sprintf("Default LXD profile for project %s", project)
+	_, err := d.db.ProfileCreate(project, projectPrefix(project, "default"), description, map[string]string{}, types.Devices{})
+	return err
+}
+
+// projectCheckPrefixUnused makes sure that no profile or image alias of the
+// default project already uses the internal prefix of the given project name.
+func projectCheckPrefixUnused(d *Daemon, name string) error {
+	prefix := projectPrefix(name, "")
+
+	profiles, err := d.db.ProfilesForProject("default")
+	if err != nil {
+		return err
+	}
+
+	aliases, err := d.db.ImageAliasesGetForProject("default")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range append(profiles, aliases...) {
+		if strings.HasPrefix(entry, prefix) {
+			return fmt.Errorf("The name '%s' conflicts with existing profile or image alias '%s'", name, entry)
+		}
+	}
+
+	return nil
+}
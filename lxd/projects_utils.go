@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/shared"
+)
+
+// Supported project configuration keys and their validators
+var projectConfigKeys = map[string]func(value string) error{
+	"features.images":   shared.IsBool,
+	"features.profiles": shared.IsBool,
+}
+
+// projectParam returns the name of the project the request targets.
+func projectParam(r *http.Request) string {
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		return "default"
+	}
+
+	return project
+}
+
+// projectPrefix returns the name under which an object belonging to the
+// given project is recorded in the database and on disk. Objects of the
+// default project keep their name as is, so that existing setups are left
+// untouched.
+func projectPrefix(project string, name string) string {
+	if project == "" || project == "default" {
+		return name
+	}
+
+	return fmt.Sprintf("%s_%s", project, name)
+}
+
+// projectStripPrefix reverts projectPrefix, returning the name the user knows
+// the object by.
+func projectStripPrefix(project string, name string) string {
+	if project == "" || project == "default" {
+		return name
+	}
+
+	return strings.TrimPrefix(name, fmt.Sprintf("%s_", project))
+}
+
+// projectFromContainerName returns the project a container or snapshot
+// belongs to, given its internal name. Container names are valid hostnames
+// and so can't contain an underscore.
+func projectFromContainerName(name string) string {
+	fields := strings.SplitN(name, shared.SnapshotDelimiter, 2)
+
+	i := strings.Index(fields[0], "_")
+	if i < 1 {
+		return "default"
+	}
+
+	return fields[0][:i]
+}
+
+// projectURL appends the project query parameter to the given API URL, unless
+// the project is the default one.
+func projectURL(project string, url string) string {
+	if project == "" || project == "default" {
+		return url
+	}
+
+	return fmt.Sprintf("%s?project=%s", url, project)
+}
+
+func projectValidName(name string) error {
+	if name == "" {
+		return fmt.Errorf("No name provided")
+	}
+
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("Project names may not contain slashes")
+	}
+
+	if strings.Contains(name, "_") {
+		return fmt.Errorf("Project names may not contain underscores")
+	}
+
+	if strings.Contains(name, " ") {
+		return fmt.Errorf("Project names may not contain spaces")
+	}
+
+	if strings.Contains(name, ":") {
+		return fmt.Errorf("Project names may not contain colons")
+	}
+
+	if shared.StringInSlice(name, []string{".", ".."}) {
+		return fmt.Errorf("Invalid project name '%s'", name)
+	}
+
+	return nil
+}
+
+func projectValidateConfig(config map[string]string) error {
+	for k, v := range config {
+		validator, ok := projectConfigKeys[k]
+		if !ok {
+			return fmt.Errorf("Invalid project configuration key: %s", k)
+		}
+
+		err := validator(v)
+		if err != nil {
+			return fmt.Errorf("Invalid value for project key '%s': %v", k, err)
+		}
+	}
+
+	return nil
+}
+
+// projectHasFeature returns whether the given feature is enabled for the
+// project.
+func projectHasFeature(dbObj *db.Node, project string, feature string) (bool, error) {
+	_, info, err := dbObj.ProjectGet(project)
+	if err != nil {
+		return false, err
+	}
+
+	return shared.IsTrue(info.Config[fmt.Sprintf("features.%s", feature)]), nil
+}
+
+// projectProfileScope returns the project whose profiles are used by the
+// given project. That's the default project unless "features.profiles" is
+// enabled.
+func projectProfileScope(dbObj *db.Node, project string) (string, error) {
+	enabled, err := projectHasFeature(dbObj, project, "profiles")
+	if err != nil {
+		return "", err
+	}
+
+	if !enabled {
+		return "default", nil
+	}
+
+	return project, nil
+}
+
+// projectImageScope returns the project whose images and image aliases are
+// used by the given project. That's the default project unless
+// "features.images" is enabled.
+func projectImageScope(dbObj *db.Node, project string) (string, error) {
+	enabled, err := projectHasFeature(dbObj, project, "images")
+	if err != nil {
+		return "", err
+	}
+
+	if !enabled {
+		return "default", nil
+	}
+
+	return project, nil
+}
+
+// projectProfileNames translates the names of the profiles used by a
+// container of the given project into their internal names.
+func projectProfileNames(dbObj *db.Node, project string, profiles []string) ([]string, error) {
+	if profiles == nil {
+		return nil, nil
+	}
+
+	scope, err := projectProfileScope(dbObj, project)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, profile := range profiles {
+		names = append(names, projectPrefix(scope, profile))
+	}
+
+	return names, nil
+}
+
+// projectCheckReservedName makes sure that a profile or image alias name in
+// the default project doesn't clash with the internal names of the objects
+// of another project.
+func projectCheckReservedName(dbObj *db.Node, project string, name string) error {
+	if project != "default" {
+		return nil
+	}
+
+	projects, err := dbObj.Projects()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range projects {
+		if p != "default" && strings.HasPrefix(name, fmt.Sprintf("%s_", p)) {
+			return fmt.Errorf("Names starting with '%s_' are reserved for project '%s'", p, p)
+		}
+	}
+
+	return nil
+}
+
+// projectIsEmpty returns whether the project has no container, image,
+// image alias or profile other than its default one.
+func projectIsEmpty(dbObj *db.Node, project string) (bool, error) {
+	containers, err := dbObj.ContainersListForProject(project, db.CTypeRegular)
+	if err != nil {
+		return false, err
+	}
+
+	if len(containers) > 0 {
+		return false, nil
+	}
+
+	images, err := dbObj.ImagesGetForProject(project, false)
+	if err != nil {
+		return false, err
+	}
+
+	if len(images) > 0 {
+		return false, nil
+	}
+
+	aliases, err := dbObj.ImageAliasesGetForProject(project)
+	if err != nil {
+		return false, err
+	}
+
+	if len(aliases) > 0 {
+		return false, nil
+	}
+
+	profiles, err := dbObj.ProfilesForProject(project)
+	if err != nil {
+		return false, err
+	}
+
+	for _, profile := range profiles {
+		if profile != projectPrefix(project, "default") {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -1,13 +1,105 @@
 package main
 
+/*
+#define _GNU_SOURCE
+#include <fcntl.h>
+#include <stdint.h>
+#include <linux/limits.h>
+#include <sys/types.h>
+#include <sys/syscall.h>
+#include <linux/audit.h>
+
+// The seccomp notification structures, as defined by the kernel and
+// forwarded by the LXC monitor. They're redefined here so that building
+// doesn't depend on the kernel headers knowing about them.
+struct lxd_seccomp_data {
+	int nr;
+	uint32_t arch;
+	uint64_t instruction_pointer;
+	uint64_t args[6];
+};
+
+struct lxd_seccomp_notif {
+	uint64_t id;
+	uint32_t pid;
+	uint32_t flags;
+	struct lxd_seccomp_data data;
+};
+
+struct lxd_seccomp_notif_resp {
+	uint64_t id;
+	int64_t val;
+	int32_t error;
+	uint32_t flags;
+};
+
+struct lxd_seccomp_notif_sizes {
+	uint16_t seccomp_notif;
+	uint16_t seccomp_notif_resp;
+	uint16_t seccomp_data;
+};
+
+// Followed by the seccomp_notif, seccomp_notif_resp and cookie
+struct lxd_seccomp_notify_proxy_msg {
+	uint64_t reserved;
+	pid_t monitor_pid;
+	pid_t init_pid;
+	struct lxd_seccomp_notif_sizes sizes;
+	uint64_t cookie_len;
+};
+
+#ifdef __NR_mknod
+#define LXD_NR_MKNOD __NR_mknod
+#else
+#define LXD_NR_MKNOD -1
+#endif
+
+// Whether the given notification is still pending, defined in main_nsexec.go
+extern int seccomp_notify_id_valid(int notify_fd, uint64_t id);
+
+#define LXD_NR_MKNODAT __NR_mknodat
+#define LXD_NR_SETXATTR __NR_setxattr
+
+// Only system calls made through the native architecture are handled
+#if defined(__x86_64__)
+#define LXD_AUDIT_ARCH AUDIT_ARCH_X86_64
+#elif defined(__i386__)
+#define LXD_AUDIT_ARCH AUDIT_ARCH_I386
+#elif defined(__aarch64__)
+#define LXD_AUDIT_ARCH AUDIT_ARCH_AARCH64
+#elif defined(__arm__)
+#define LXD_AUDIT_ARCH AUDIT_ARCH_ARM
+#elif defined(__powerpc64__) && __BYTE_ORDER__ == __ORDER_LITTLE_ENDIAN__
+#define LXD_AUDIT_ARCH AUDIT_ARCH_PPC64LE
+#elif defined(__powerpc64__)
+#define LXD_AUDIT_ARCH AUDIT_ARCH_PPC64
+#elif defined(__s390x__)
+#define LXD_AUDIT_ARCH AUDIT_ARCH_S390X
+#else
+#define LXD_AUDIT_ARCH 0
+#endif
+*/
+import "C"
+
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
+	"os/exec"
 	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
 
 	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/osarch"
+
+	log "github.com/lxc/lxd/shared/log15"
 )
 
 const SECCOMP_HEADER = `2
@@ -58,6 +150,17 @@ compat_sys_io_submit errno 38
 stub_x32_execveat errno 38
 `
 
+// Character and block device creation is forwarded to LXD, the mode
+// argument being masked with S_IFMT.
+const SECCOMP_NOTIFY_MKNOD = `mknod notify [1,8192,SCMP_CMP_MASKED_EQ,61440]
+mknod notify [1,24576,SCMP_CMP_MASKED_EQ,61440]
+mknodat notify [2,8192,SCMP_CMP_MASKED_EQ,61440]
+mknodat notify [2,24576,SCMP_CMP_MASKED_EQ,61440]
+`
+
+const SECCOMP_NOTIFY_SETXATTR = `setxattr notify
+`
+
 var seccompPath = shared.VarPath("security", "seccomp")
 
 func SeccompProfilePath(c container) string {
@@ -85,6 +188,10 @@ func ContainerNeedsSeccomp(c container) bool {
 		return true
 	}
 
+	if SeccompContainerNeedsIntercept(c) {
+		return true
+	}
+
 	/* this are enabled by default, so if the keys aren't present, that
 	 * means "true"
 	 */
@@ -129,6 +236,14 @@ func getSeccompProfileContent(c container) (string, error) {
 		policy += fmt.Sprintf(COMPAT_BLOCKING_POLICY, arch)
 	}
 
+	if shared.IsTrue(config["security.syscalls.intercept.mknod"]) {
+		policy += SECCOMP_NOTIFY_MKNOD
+	}
+
+	if shared.IsTrue(config["security.syscalls.intercept.setxattr"]) {
+		policy += SECCOMP_NOTIFY_SETXATTR
+	}
+
 	blacklist := config["security.syscalls.blacklist"]
 	if blacklist != "" {
 		policy += blacklist
@@ -137,6 +252,25 @@ func getSeccompProfileContent(c container) (string, error) {
 	return policy, nil
 }
 
+// SeccompContainerNeedsIntercept returns whether some of the system calls
+// made by the container should be forwarded to LXD.
+func SeccompContainerNeedsIntercept(c container) bool {
+	config := c.ExpandedConfig()
+
+	keys := []string{
+		"security.syscalls.intercept.mknod",
+		"security.syscalls.intercept.setxattr",
+	}
+
+	for _, k := range keys {
+		if shared.IsTrue(config[k]) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func SeccompCreateProfile(c container) error {
 	/* Unlike apparmor, there is no way to "cache" profiles, and profiles
 	 * are automatically unloaded when a task dies. Thus, we don't need to
@@ -166,3 +300,408 @@ func SeccompDeleteProfile(c container) {
 	 */
 	os.Remove(SeccompProfilePath(c))
 }
+
+// The character devices which containers may create when mknod is
+// intercepted, the whiteout device (0:0) being needed by overlayfs.
+var seccompMknodDevices = []string{
+	"0:0",
+	"1:3", // /dev/null
+	"1:5", // /dev/zero
+	"1:7", // /dev/full
+	"1:8", // /dev/random
+	"1:9", // /dev/urandom
+	"5:0", // /dev/tty
+	"5:1", // /dev/console
+	"5:2", // /dev/ptmx
+}
+
+// The extended attributes which containers may set when setxattr is
+// intercepted, with their allowed value.
+var seccompSetxattrAttributes = map[string]string{
+	"trusted.overlay.opaque": "y",
+}
+
+// SeccompServer handles the system calls forwarded by the LXC monitors of
+// the containers with syscall interception enabled.
+type SeccompServer struct {
+	d    *Daemon
+	path string
+	l    net.Listener
+}
+
+// NewSeccompServer starts listening for forwarded system calls on the
+// given unix socket.
+func NewSeccompServer(d *Daemon, path string) (*SeccompServer, error) {
+	// Cleanup existing sockets
+	if shared.PathExists(path) {
+		err := os.Remove(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Each message carries one system call
+	l, err := net.Listen("unixpacket", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the LXC monitors, running as root, may connect
+	err = os.Chmod(path, 0700)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	s := &SeccompServer{d: d, path: path, l: l}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.handleConn(conn.(*net.UnixConn))
+		}
+	}()
+
+	return s, nil
+}
+
+// Stop stops listening for forwarded system calls.
+func (s *SeccompServer) Stop() error {
+	os.Remove(s.path)
+	return s.l.Close()
+}
+
+// seccompRequest is a system call forwarded by an LXC monitor, its fields
+// pointing into the message which is sent back with the response.
+type seccompRequest struct {
+	msg    *C.struct_lxd_seccomp_notify_proxy_msg
+	notif  *C.struct_lxd_seccomp_notif
+	resp   *C.struct_lxd_seccomp_notif_resp
+	cookie string
+
+	// Memory of the calling process, to read the arguments from
+	memFd int
+
+	// Seccomp listener the notification was received on, to check that
+	// the calling process is still the one behind its pid
+	notify *os.File
+}
+
+// valid checks whether the notification is still pending. Once the calling
+// process is gone, its pid may be reused, so anything looked up by pid is
+// only trustworthy if the notification is still valid afterwards.
+func (req *seccompRequest) valid() bool {
+	return C.seccomp_notify_id_valid(C.int(req.notify.Fd()), C.uint64_t(req.notif.id)) == 0
+}
+
+func seccompParseRequest(buf []byte) (*seccompRequest, error) {
+	msgSize := int(C.sizeof_struct_lxd_seccomp_notify_proxy_msg)
+	if len(buf) < msgSize {
+		return nil, fmt.Errorf("Short seccomp message of %d bytes", len(buf))
+	}
+
+	req := &seccompRequest{memFd: -1}
+	req.msg = (*C.struct_lxd_seccomp_notify_proxy_msg)(unsafe.Pointer(&buf[0]))
+
+	// The kernel structures may grow, but never shrink
+	notifSize := int(req.msg.sizes.seccomp_notif)
+	respSize := int(req.msg.sizes.seccomp_notif_resp)
+	cookieLen := int(req.msg.cookie_len)
+
+	if notifSize < int(C.sizeof_struct_lxd_seccomp_notif) || respSize < int(C.sizeof_struct_lxd_seccomp_notif_resp) {
+		return nil, fmt.Errorf("Unsupported seccomp structure sizes")
+	}
+
+	if len(buf) != msgSize+notifSize+respSize+cookieLen {
+		return nil, fmt.Errorf("Invalid seccomp message size of %d bytes", len(buf))
+	}
+
+	req.notif = (*C.struct_lxd_seccomp_notif)(unsafe.Pointer(&buf[msgSize]))
+	req.resp = (*C.struct_lxd_seccomp_notif_resp)(unsafe.Pointer(&buf[msgSize+notifSize]))
+	req.cookie = string(buf[msgSize+notifSize+respSize:])
+
+	return req, nil
+}
+
+func (s *SeccompServer) handleConn(conn *net.UnixConn) {
+	defer conn.Close()
+
+	for {
+		buf := make([]byte, 4096)
+		oob := make([]byte, syscall.CmsgSpace(2*4))
+
+		n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+		if err != nil {
+			// The monitor went away along with its container
+			return
+		}
+
+		req, err := seccompParseRequest(buf[:n])
+		if err != nil {
+			logger.Warn("Invalid seccomp notification", log.Ctx{"err": err})
+			return
+		}
+
+		// The monitor passes the memory of the calling process and the
+		// seccomp listener along
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		if err == nil && len(msgs) == 1 {
+			fds, err := syscall.ParseUnixRights(&msgs[0])
+			if err == nil && len(fds) == 2 {
+				req.memFd = fds[0]
+				req.notify = os.NewFile(uintptr(fds[1]), "seccomp")
+			} else if err == nil {
+				for _, fd := range fds {
+					syscall.Close(fd)
+				}
+			}
+		}
+
+		errno := s.handleRequest(req)
+		if req.memFd >= 0 {
+			syscall.Close(req.memFd)
+		}
+
+		if req.notify != nil {
+			req.notify.Close()
+		}
+
+		req.resp.id = req.notif.id
+		req.resp.val = 0
+		req.resp.error = C.int32_t(-int(errno))
+		req.resp.flags = 0
+
+		_, err = conn.Write(buf[:n])
+		if err != nil {
+			logger.Warn("Failed to send the seccomp response", log.Ctx{"err": err})
+			return
+		}
+	}
+}
+
+// handleRequest performs the system call on behalf of the container if it's
+// allowed, returning the resulting errno. Anything which isn't allowed gets
+// the EPERM that the kernel itself would have returned.
+func (s *SeccompServer) handleRequest(req *seccompRequest) syscall.Errno {
+	if req.memFd < 0 || req.notify == nil || uint32(req.notif.data.arch) != uint32(C.LXD_AUDIT_ARCH) {
+		return syscall.EPERM
+	}
+
+	// The cookie is the container ID, as set up by LXD
+	id, err := strconv.Atoi(req.cookie)
+	if err != nil {
+		return syscall.EPERM
+	}
+
+	c, err := containerLoadById(s.d.State(), id)
+	if err != nil || c.InitPID() != int(req.msg.init_pid) {
+		return syscall.EPERM
+	}
+
+	config := c.ExpandedConfig()
+	nr := int(req.notif.data.nr)
+	args := req.notif.data.args
+
+	switch {
+	case nr == int(C.LXD_NR_MKNOD) && shared.IsTrue(config["security.syscalls.intercept.mknod"]):
+		return s.handleMknod(c, req, C.AT_FDCWD, uint64(args[0]), uint32(args[1]), uint32(args[2]))
+	case nr == int(C.LXD_NR_MKNODAT) && shared.IsTrue(config["security.syscalls.intercept.mknod"]):
+		return s.handleMknod(c, req, int32(args[0]), uint64(args[1]), uint32(args[2]), uint32(args[3]))
+	case nr == int(C.LXD_NR_SETXATTR) && shared.IsTrue(config["security.syscalls.intercept.setxattr"]):
+		return s.handleSetxattr(c, req, uint64(args[0]), uint64(args[1]), uint64(args[2]), uint64(args[3]), int32(args[4]))
+	}
+
+	return syscall.EPERM
+}
+
+func (s *SeccompServer) handleMknod(c container, req *seccompRequest, dirfd int32, pathAddr uint64, mode uint32, dev uint32) syscall.Errno {
+	// Only whitelisted character devices
+	if mode&syscall.S_IFMT != syscall.S_IFCHR {
+		return syscall.EPERM
+	}
+
+	major := (dev & 0xfff00) >> 8
+	minor := (dev & 0xff) | ((dev >> 12) & 0xfff00)
+	if !shared.StringInSlice(fmt.Sprintf("%d:%d", major, minor), seccompMknodDevices) {
+		return syscall.EPERM
+	}
+
+	pid := int(req.notif.pid)
+	task, err := seccompTaskStatus(pid)
+	if err != nil || !task.hasCapability(27) { // CAP_MKNOD
+		return syscall.EPERM
+	}
+
+	target, errno := seccompReadString(req.memFd, pathAddr)
+	if errno != 0 {
+		return errno
+	}
+
+	cwd := fmt.Sprintf("/proc/%d/cwd", pid)
+	if dirfd != C.AT_FDCWD && !strings.HasPrefix(target, "/") {
+		cwd = fmt.Sprintf("/proc/%d/fd/%d", pid, dirfd)
+	}
+
+	mode = mode&syscall.S_IFMT | mode&^syscall.S_IFMT&^task.umask
+
+	if !req.valid() {
+		return syscall.ENOENT
+	}
+
+	return s.forkSyscall(c, req, "mknod", cwd, target,
+		fmt.Sprintf("%d", mode), fmt.Sprintf("%d", dev),
+		fmt.Sprintf("%d", task.fsuid), fmt.Sprintf("%d", task.fsgid))
+}
+
+func (s *SeccompServer) handleSetxattr(c container, req *seccompRequest, pathAddr uint64, nameAddr uint64, valueAddr uint64, size uint64, flags int32) syscall.Errno {
+	pid := int(req.notif.pid)
+	task, err := seccompTaskStatus(pid)
+	if err != nil || !task.hasCapability(21) { // CAP_SYS_ADMIN
+		return syscall.EPERM
+	}
+
+	name, errno := seccompReadString(req.memFd, nameAddr)
+	if errno != 0 {
+		return errno
+	}
+
+	// Only whitelisted attributes and values
+	allowed, ok := seccompSetxattrAttributes[name]
+	if !ok || size != uint64(len(allowed)) {
+		return syscall.EPERM
+	}
+
+	value := make([]byte, size)
+	_, err = syscall.Pread(req.memFd, value, int64(valueAddr))
+	if err != nil {
+		return syscall.EFAULT
+	}
+
+	if string(value) != allowed {
+		return syscall.EPERM
+	}
+
+	target, errno := seccompReadString(req.memFd, pathAddr)
+	if errno != 0 {
+		return errno
+	}
+
+	if !req.valid() {
+		return syscall.ENOENT
+	}
+
+	return s.forkSyscall(c, req, "setxattr", fmt.Sprintf("/proc/%d/cwd", pid), target,
+		name, string(value), fmt.Sprintf("%d", flags),
+		fmt.Sprintf("%d", task.fsuid), fmt.Sprintf("%d", task.fsgid))
+}
+
+// forkSyscall performs the system call from within the container's mount
+// namespace, returning the resulting errno. The seccomp listener is passed
+// along as fd 3, for the notification to be checked again once the calling
+// process has been looked up.
+func (s *SeccompServer) forkSyscall(c container, req *seccompRequest, name string, args ...string) syscall.Errno {
+	args = append([]string{"forksyscall", name, fmt.Sprintf("%d", req.notif.pid), "3", fmt.Sprintf("%d", uint64(req.notif.id))}, args...)
+
+	cmd := exec.Command(s.d.os.ExecPath, args...)
+	cmd.ExtraFiles = []*os.File{req.notify}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		logger.Warn("Failed to handle intercepted system call", log.Ctx{"container": c.Name(), "syscall": name, "err": err, "output": strings.TrimSpace(string(out))})
+		return syscall.EPERM
+	}
+
+	errno, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return syscall.EPERM
+	}
+
+	if errno == 0 {
+		logger.Debug("Handled intercepted system call", log.Ctx{"container": c.Name(), "syscall": name, "path": args[6]})
+	}
+
+	return syscall.Errno(errno)
+}
+
+// Read a NUL terminated string from the memory of the calling process.
+func seccompReadString(memFd int, addr uint64) (string, syscall.Errno) {
+	buf := make([]byte, C.PATH_MAX)
+
+	n, err := syscall.Pread(memFd, buf, int64(addr))
+	if err != nil || n == 0 {
+		return "", syscall.EFAULT
+	}
+
+	end := bytes.IndexByte(buf[:n], 0)
+	if end < 0 {
+		return "", syscall.ENAMETOOLONG
+	}
+
+	return string(buf[:end]), 0
+}
+
+type seccompTask struct {
+	fsuid  uint32
+	fsgid  uint32
+	umask  uint32
+	capEff uint64
+}
+
+func (t *seccompTask) hasCapability(capability uint) bool {
+	return t.capEff&(1<<capability) != 0
+}
+
+// Get the credentials of the calling process, as seen from the host.
+func seccompTaskStatus(pid int) (*seccompTask, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	task := &seccompTask{umask: 0022}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "Umask:":
+			value, err := strconv.ParseUint(fields[1], 8, 32)
+			if err != nil {
+				return nil, err
+			}
+			task.umask = uint32(value)
+		case "Uid:", "Gid:":
+			// Real, effective, saved and filesystem IDs
+			if len(fields) != 5 {
+				return nil, fmt.Errorf("Invalid %s line", fields[0])
+			}
+
+			value, err := strconv.ParseUint(fields[4], 10, 32)
+			if err != nil {
+				return nil, err
+			}
+
+			if fields[0] == "Uid:" {
+				task.fsuid = uint32(value)
+			} else {
+				task.fsgid = uint32(value)
+			}
+		case "CapEff:":
+			value, err := strconv.ParseUint(fields[1], 16, 64)
+			if err != nil {
+				return nil, err
+			}
+			task.capEff = value
+		}
+	}
+
+	return task, scanner.Err()
+}
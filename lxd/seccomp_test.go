@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeccompTaskStatus(t *testing.T) {
+	umask := syscall.Umask(0027)
+	defer syscall.Umask(umask)
+
+	task, err := seccompTaskStatus(os.Getpid())
+	require.NoError(t, err)
+
+	assert.Equal(t, uint32(os.Getuid()), task.fsuid)
+	assert.Equal(t, uint32(os.Getgid()), task.fsgid)
+	assert.Equal(t, uint32(0027), task.umask)
+	assert.Equal(t, os.Getuid() == 0, task.hasCapability(21))
+}
+
+func TestSeccompReadString(t *testing.T) {
+	f, err := os.Open("/proc/self/mem")
+	require.NoError(t, err)
+	defer f.Close()
+
+	buf := []byte("/dev/null\x00garbage")
+	value, errno := seccompReadString(int(f.Fd()), uint64(uintptr(unsafe.Pointer(&buf[0]))))
+	assert.Equal(t, syscall.Errno(0), errno)
+	assert.Equal(t, "/dev/null", value)
+
+	_, errno = seccompReadString(int(f.Fd()), 0)
+	assert.Equal(t, syscall.EFAULT, errno)
+}
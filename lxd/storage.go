@@ -158,6 +158,20 @@ type storage interface {
 	StoragePoolVolumeRename(newName string) error
 	GetStoragePoolVolumeWritable() api.StorageVolumePut
 	SetStoragePoolVolumeWritable(writable *api.StorageVolumePut)
+	StoragePoolVolumeCopy(source *api.StorageVolumeSource) error
+	StoragePoolVolumeRestore(snapshotName string) error
+
+	// Functions dealing with custom storage volume snapshots.
+	StoragePoolVolumeSnapshotCreate() error
+	StoragePoolVolumeSnapshotDelete() error
+	StoragePoolVolumeSnapshotRename(newName string) error
+
+	// Functions dealing with custom storage volume migration. The source
+	// driver is returned for the negotiated migration type, which is
+	// either MigrationType() or MigrationFSType_RSYNC. The storage volume
+	// must already exist on the receiving side.
+	StorageMigrationSource(migrationType MigrationFSType, volumeOnly bool) (MigrationStorageVolumeSourceDriver, error)
+	StorageMigrationSink(conn *websocket.Conn, op *operation, snapshots []*VolumeSnapshot) error
 
 	// Functions dealing with container storage volumes.
 	// ContainerCreate creates an empty container (no rootfs/metadata.yaml)
@@ -185,6 +199,14 @@ type storage interface {
 	// For use in migrating snapshots.
 	ContainerSnapshotCreateEmpty(c container) error
 
+	// Functions dealing with container backups.
+	// ContainerBackupCreate dumps the container (and its snapshots unless
+	// the backup is container only) into the target directory.
+	ContainerBackupCreate(backup backup, sourceContainer container, target string) error
+	// ContainerBackupLoad restores an optimized backup. The database
+	// entries of the container and its snapshots must already exist.
+	ContainerBackupLoad(info backupInfo, tarball string) error
+
 	// Functions dealing with image storage volumes.
 	ImageCreate(fingerprint string) error
 	ImageDelete(fingerprint string) error
@@ -578,6 +600,11 @@ func getStoragePoolVolumeMountPoint(poolName string, volumeName string) string {
 	return shared.VarPath("storage-pools", poolName, "custom", volumeName)
 }
 
+// ${LXD_DIR}/storage-pools/<pool>/custom-snapshots/<custom volume name>/<snapshot name>
+func getStoragePoolVolumeSnapshotMountPoint(poolName string, snapshotName string) string {
+	return shared.VarPath("storage-pools", poolName, "custom-snapshots", snapshotName)
+}
+
 func createContainerMountpoint(mountPoint string, mountPointSymlink string, privileged bool) error {
 	var mode os.FileMode
 	if privileged {
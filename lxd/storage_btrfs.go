@@ -684,6 +684,179 @@ func (s *storageBtrfs) SetStoragePoolVolumeWritable(writable *api.StorageVolumeP
 	s.volume.StorageVolumePut = *writable
 }
 
+func (s *storageBtrfs) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Infof("Copying BTRFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	if source.Pool != s.pool.Name {
+		bwlimit := s.pool.Config["rsync.bwlimit"]
+		err = storagePoolVolumeCopyRsync(s.s, s, s.pool.Name, s.volume.Name, source, bwlimit)
+		if err != nil {
+			return err
+		}
+
+		logger.Infof("Copied BTRFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+		return nil
+	}
+
+	// Create subvolume path on the storage pool.
+	customSubvolumePath := s.getCustomSubvolumePath(s.pool.Name)
+	if !shared.PathExists(customSubvolumePath) {
+		err := os.MkdirAll(customSubvolumePath, 0700)
+		if err != nil {
+			return err
+		}
+	}
+
+	srcMountPoint := getStoragePoolVolumeMountPoint(source.Pool, source.Name)
+	dstMountPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	err = s.btrfsPoolVolumesSnapshot(srcMountPoint, dstMountPoint, false)
+	if err != nil {
+		return err
+	}
+
+	// apply quota
+	if s.volume.Config["size"] != "" {
+		size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Copied BTRFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) StoragePoolVolumeRestore(snapshotName string) error {
+	logger.Infof("Restoring BTRFS storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, snapshotName, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	sourcePath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, snapshotName)
+	targetPath := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	backupPath := fmt.Sprintf("%s.back", targetPath)
+
+	// Move the current volume out of the way so we can go back to it if
+	// the restore fails.
+	err = os.Rename(targetPath, backupPath)
+	if err != nil {
+		return err
+	}
+
+	err = s.btrfsPoolVolumesSnapshot(sourcePath, targetPath, false)
+	if err != nil {
+		os.Rename(backupPath, targetPath)
+		return err
+	}
+
+	err = btrfsSubVolumesDelete(backupPath)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Restored BTRFS storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, snapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) StoragePoolVolumeSnapshotCreate() error {
+	logger.Infof("Creating BTRFS storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	sourceName, _, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	sourcePath := getStoragePoolVolumeMountPoint(s.pool.Name, sourceName)
+	targetPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, s.volume.Name)
+
+	// Create the snapshot directory of the volume.
+	err = os.MkdirAll(filepath.Dir(targetPath), 0700)
+	if err != nil {
+		return err
+	}
+
+	err = s.btrfsPoolVolumesSnapshot(sourcePath, targetPath, true)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Created BTRFS storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) StoragePoolVolumeSnapshotDelete() error {
+	logger.Infof("Deleting BTRFS storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	snapshotPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, s.volume.Name)
+	if shared.PathExists(snapshotPath) && isBtrfsSubVolume(snapshotPath) {
+		err = btrfsSubVolumesDelete(snapshotPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remove the snapshot directory of the volume once it's empty.
+	snapshotsPath := filepath.Dir(snapshotPath)
+	empty, _ := shared.PathIsEmpty(snapshotsPath)
+	if empty {
+		os.Remove(snapshotsPath)
+	}
+
+	err = s.db.StoragePoolVolumeDelete(
+		s.volume.Name,
+		storagePoolVolumeTypeCustom,
+		s.poolID)
+	if err != nil {
+		logger.Errorf(`Failed to delete database entry for BTRFS `+
+			`storage volume snapshot "%s" on storage pool "%s"`,
+			s.volume.Name, s.pool.Name)
+	}
+
+	logger.Infof("Deleted BTRFS storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) StoragePoolVolumeSnapshotRename(newName string) error {
+	logger.Infof(`Renaming BTRFS storage volume snapshot on storage pool "%s" from "%s" to "%s"`,
+		s.pool.Name, s.volume.Name, newName)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	oldPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, s.volume.Name)
+	newPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, newName)
+	err = os.Rename(oldPath, newPath)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof(`Renamed BTRFS storage volume snapshot on storage pool "%s" from "%s" to "%s"`,
+		s.pool.Name, s.volume.Name, newName)
+
+	return s.db.StoragePoolVolumeRename(s.volume.Name, newName,
+		storagePoolVolumeTypeCustom, s.poolID)
+}
+
 // Functions dealing with container storage.
 func (s *storageBtrfs) ContainerStorageReady(name string) bool {
 	containerMntPoint := getContainerMountPoint(s.pool.Name, name)
@@ -1368,6 +1541,158 @@ func (s *storageBtrfs) ContainerSnapshotCreateEmpty(snapshotContainer container)
 	return nil
 }
 
+func (s *storageBtrfs) ContainerBackupCreate(backup backup, sourceContainer container, target string) error {
+	logger.Debugf("Creating backup \"%s\" of container \"%s\" on BTRFS storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+
+	if !backup.optimizedStorage {
+		bwlimit := s.pool.Config["rsync.bwlimit"]
+		return rsyncContainerBackupCreate(backup, sourceContainer, target, bwlimit)
+	}
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	// Send the snapshots, each one relative to its predecessor.
+	parent := ""
+	if !backup.containerOnly {
+		snapshots, err := sourceContainer.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		for _, snap := range snapshots {
+			_, snapName, _ := containerGetParentAndSnapshotName(snap.Name())
+			snapMntPoint := getSnapshotMountPoint(s.pool.Name, snap.Name())
+
+			args := []string{"send"}
+			if parent != "" {
+				args = append(args, "-p", parent)
+			}
+			args = append(args, snapMntPoint)
+
+			err := backupSend(filepath.Join(target, "snapshots", fmt.Sprintf("%s.bin", snapName)), "btrfs", args...)
+			if err != nil {
+				return err
+			}
+
+			parent = snapMntPoint
+		}
+	}
+
+	// Only read-only subvolumes can be sent, so take a temporary snapshot
+	// of the container.
+	containersPath := getContainerMountPoint(s.pool.Name, "")
+	tmpContainerMntPoint, err := ioutil.TempDir(containersPath, sourceContainer.Name())
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpContainerMntPoint)
+
+	err = os.Chmod(tmpContainerMntPoint, 0700)
+	if err != nil {
+		return err
+	}
+
+	backupSendSnapshot := fmt.Sprintf("%s/.backup", tmpContainerMntPoint)
+	containerMntPoint := getContainerMountPoint(s.pool.Name, sourceContainer.Name())
+	err = s.btrfsPoolVolumeSnapshot(containerMntPoint, backupSendSnapshot, true)
+	if err != nil {
+		return err
+	}
+	defer btrfsSubVolumesDelete(backupSendSnapshot)
+
+	args := []string{"send"}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	args = append(args, backupSendSnapshot)
+
+	err = backupSend(filepath.Join(target, "container.bin"), "btrfs", args...)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Created backup \"%s\" of container \"%s\" on BTRFS storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) ContainerBackupLoad(info backupInfo, tarball string) error {
+	logger.Debugf("Loading BTRFS storage volume for backup of container \"%s\" on storage pool \"%s\".", info.Name, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	if len(info.Snapshots) > 0 {
+		// Snapshots are received straight into place, which keeps
+		// them available as parents for the next ones.
+		snapshotsPath := getSnapshotMountPoint(s.pool.Name, info.Name)
+		snapshotMntPointSymlinkTarget := shared.VarPath("storage-pools", s.pool.Name, "snapshots", info.Name)
+		snapshotMntPointSymlink := shared.VarPath("snapshots", info.Name)
+		err := createSnapshotMountpoint(snapshotsPath, snapshotMntPointSymlinkTarget, snapshotMntPointSymlink)
+		if err != nil {
+			return err
+		}
+
+		for _, snap := range info.Snapshots {
+			_, snapName, _ := containerGetParentAndSnapshotName(snap.Name)
+			err := backupReceive(tarball, fmt.Sprintf("backup/snapshots/%s.bin", snapName), "btrfs", "receive", "-e", snapshotsPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	containersPath := getContainerMountPoint(s.pool.Name, "")
+	if !shared.PathExists(containersPath) {
+		err := os.MkdirAll(containersPath, 0711)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpContainerMntPoint, err := ioutil.TempDir(containersPath, info.Name)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpContainerMntPoint)
+
+	err = os.Chmod(tmpContainerMntPoint, 0700)
+	if err != nil {
+		return err
+	}
+
+	err = backupReceive(tarball, "backup/container.bin", "btrfs", "receive", "-e", tmpContainerMntPoint)
+	if err != nil {
+		return err
+	}
+
+	// The received subvolume is read-only, turn it into a writable one.
+	receivedSnapshot := fmt.Sprintf("%s/.backup", tmpContainerMntPoint)
+	containerMntPoint := getContainerMountPoint(s.pool.Name, info.Name)
+	err = s.btrfsPoolVolumesSnapshot(receivedSnapshot, containerMntPoint, false)
+	if err != nil {
+		return err
+	}
+
+	err = btrfsSubVolumesDelete(receivedSnapshot)
+	if err != nil {
+		return err
+	}
+
+	privileged := shared.IsTrue(info.Container.Config["security.privileged"])
+	err = createContainerMountpoint(containerMntPoint, containerPath(info.Name, false), privileged)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Loaded BTRFS storage volume for backup of container \"%s\" on storage pool \"%s\".", info.Name, s.pool.Name)
+	return nil
+}
+
 func (s *storageBtrfs) ImageCreate(fingerprint string) error {
 	logger.Debugf("Creating BTRFS storage volume for image \"%s\" on storage pool \"%s\".", fingerprint, s.pool.Name)
 
@@ -1809,7 +2134,9 @@ func (s *btrfsMigrationSourceDriver) Snapshots() []container {
 	return s.snapshots
 }
 
-func (s *btrfsMigrationSourceDriver) send(conn *websocket.Conn, btrfsPath string, btrfsParent string, readWrapper func(io.ReadCloser) io.ReadCloser) error {
+// btrfsMigrationSend sends the given subvolume over the websocket using
+// btrfs send, optionally only sending the delta to its parent.
+func btrfsMigrationSend(conn *websocket.Conn, btrfsPath string, btrfsParent string, readWrapper func(io.ReadCloser) io.ReadCloser) error {
 	args := []string{"send"}
 	if btrfsParent != "" {
 		args = append(args, "-p", btrfsParent)
@@ -1884,7 +2211,7 @@ func (s *btrfsMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *
 		defer btrfsSubVolumesDelete(migrationSendSnapshot)
 
 		wrapper := StorageProgressReader(op, "fs_progress", containerName)
-		return s.send(conn, migrationSendSnapshot, "", wrapper)
+		return btrfsMigrationSend(conn, migrationSendSnapshot, "", wrapper)
 	}
 
 	if !containerOnly {
@@ -1896,7 +2223,7 @@ func (s *btrfsMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *
 
 			snapMntPoint := getSnapshotMountPoint(containerPool, snap.Name())
 			wrapper := StorageProgressReader(op, "fs_progress", snap.Name())
-			if err := s.send(conn, snapMntPoint, prev, wrapper); err != nil {
+			if err := btrfsMigrationSend(conn, snapMntPoint, prev, wrapper); err != nil {
 				return err
 			}
 		}
@@ -1927,7 +2254,7 @@ func (s *btrfsMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *
 	}
 
 	wrapper := StorageProgressReader(op, "fs_progress", containerName)
-	return s.send(conn, migrationSendSnapshot, btrfsParent, wrapper)
+	return btrfsMigrationSend(conn, migrationSendSnapshot, btrfsParent, wrapper)
 }
 
 func (s *btrfsMigrationSourceDriver) SendAfterCheckpoint(conn *websocket.Conn, bwlimit string) error {
@@ -1946,7 +2273,7 @@ func (s *btrfsMigrationSourceDriver) SendAfterCheckpoint(conn *websocket.Conn, b
 		return err
 	}
 
-	return s.send(conn, s.stoppedSnapName, s.runningSnapName, nil)
+	return btrfsMigrationSend(conn, s.stoppedSnapName, s.runningSnapName, nil)
 }
 
 func (s *btrfsMigrationSourceDriver) Cleanup() {
@@ -2010,80 +2337,84 @@ func (s *storageBtrfs) MigrationSource(c container, containerOnly bool) (Migrati
 	return driver, nil
 }
 
-func (s *storageBtrfs) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *idmap.IdmapSet, op *operation, containerOnly bool) error {
-	if s.s.OS.RunningInUserNS {
-		return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly)
-	}
-
-	btrfsRecv := func(snapName string, btrfsPath string, targetPath string, isSnapshot bool, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
-		args := []string{"receive", "-e", btrfsPath}
-		cmd := exec.Command("btrfs", args...)
+// btrfsMigrationRecv receives a subvolume sent by btrfsMigrationSend into
+// btrfsPath and moves it to targetPath, replacing any subvolume there.
+func (s *storageBtrfs) btrfsMigrationRecv(conn *websocket.Conn, snapName string, btrfsPath string, targetPath string, isSnapshot bool, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
+	args := []string{"receive", "-e", btrfsPath}
+	cmd := exec.Command("btrfs", args...)
 
-		// Remove the existing pre-created subvolume
+	// Remove the existing pre-created subvolume
+	if shared.PathExists(targetPath) {
 		err := btrfsSubVolumesDelete(targetPath)
 		if err != nil {
 			logger.Errorf("Failed to delete pre-created BTRFS subvolume: %s.", btrfsPath)
 			return err
 		}
+	}
 
-		stdin, err := cmd.StdinPipe()
-		if err != nil {
-			return err
-		}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
 
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return err
-		}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
 
-		err = cmd.Start()
-		if err != nil {
-			return err
-		}
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
 
-		writePipe := io.WriteCloser(stdin)
-		if writeWrapper != nil {
-			writePipe = writeWrapper(stdin)
-		}
+	writePipe := io.WriteCloser(stdin)
+	if writeWrapper != nil {
+		writePipe = writeWrapper(stdin)
+	}
 
-		<-shared.WebsocketRecvStream(writePipe, conn)
+	<-shared.WebsocketRecvStream(writePipe, conn)
 
-		output, err := ioutil.ReadAll(stderr)
-		if err != nil {
-			logger.Debugf("Problem reading btrfs receive stderr %s.", err)
-		}
+	output, err := ioutil.ReadAll(stderr)
+	if err != nil {
+		logger.Debugf("Problem reading btrfs receive stderr %s.", err)
+	}
 
-		err = cmd.Wait()
-		if err != nil {
-			logger.Errorf("Problem with btrfs receive: %s.", string(output))
-			return err
-		}
+	err = cmd.Wait()
+	if err != nil {
+		logger.Errorf("Problem with btrfs receive: %s.", string(output))
+		return err
+	}
 
-		receivedSnapshot := fmt.Sprintf("%s/.migration-send", btrfsPath)
-		// handle older lxd versions
-		if !shared.PathExists(receivedSnapshot) {
-			receivedSnapshot = fmt.Sprintf("%s/.root", btrfsPath)
-		}
-		if isSnapshot {
-			receivedSnapshot = fmt.Sprintf("%s/%s", btrfsPath, snapName)
-			err = s.btrfsPoolVolumesSnapshot(receivedSnapshot, targetPath, true)
-		} else {
-			err = s.btrfsPoolVolumesSnapshot(receivedSnapshot, targetPath, false)
-		}
-		if err != nil {
-			logger.Errorf("Problem with btrfs snapshot: %s.", err)
-			return err
-		}
+	receivedSnapshot := fmt.Sprintf("%s/.migration-send", btrfsPath)
+	// handle older lxd versions
+	if !shared.PathExists(receivedSnapshot) {
+		receivedSnapshot = fmt.Sprintf("%s/.root", btrfsPath)
+	}
+	if isSnapshot {
+		receivedSnapshot = fmt.Sprintf("%s/%s", btrfsPath, snapName)
+		err = s.btrfsPoolVolumesSnapshot(receivedSnapshot, targetPath, true)
+	} else {
+		err = s.btrfsPoolVolumesSnapshot(receivedSnapshot, targetPath, false)
+	}
+	if err != nil {
+		logger.Errorf("Problem with btrfs snapshot: %s.", err)
+		return err
+	}
 
-		defer os.RemoveAll(btrfsPath)
+	defer os.RemoveAll(btrfsPath)
 
-		err = btrfsSubVolumesDelete(receivedSnapshot)
-		if err != nil {
-			logger.Errorf("Failed to delete BTRFS subvolume \"%s\": %s.", btrfsPath, err)
-			return err
-		}
+	err = btrfsSubVolumesDelete(receivedSnapshot)
+	if err != nil {
+		logger.Errorf("Failed to delete BTRFS subvolume \"%s\": %s.", btrfsPath, err)
+		return err
+	}
 
-		return nil
+	return nil
+}
+
+func (s *storageBtrfs) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *idmap.IdmapSet, op *operation, containerOnly bool) error {
+	if s.s.OS.RunningInUserNS {
+		return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly)
 	}
 
 	containerName := container.Name()
@@ -2161,7 +2492,7 @@ func (s *storageBtrfs) MigrationSink(live bool, container container, snapshots [
 			}
 
 			wrapper := StorageProgressWriter(op, "fs_progress", *snap.Name)
-			err = btrfsRecv(*(snap.Name), tmpSnapshotMntPoint, snapshotMntPoint, true, wrapper)
+			err = s.btrfsMigrationRecv(conn, *(snap.Name), tmpSnapshotMntPoint, snapshotMntPoint, true, wrapper)
 			if err != nil {
 				return err
 			}
@@ -2188,7 +2519,7 @@ func (s *storageBtrfs) MigrationSink(live bool, container container, snapshots [
 	}
 
 	containerMntPoint := getContainerMountPoint(s.pool.Name, containerName)
-	err = btrfsRecv("", tmpContainerMntPoint, containerMntPoint, false, wrapper)
+	err = s.btrfsMigrationRecv(conn, "", tmpContainerMntPoint, containerMntPoint, false, wrapper)
 	if err != nil {
 		return err
 	}
@@ -2196,6 +2527,161 @@ func (s *storageBtrfs) MigrationSink(live bool, container container, snapshots [
 	return nil
 }
 
+type btrfsStorageVolumeSourceDriver struct {
+	btrfs     *storageBtrfs
+	snapshots []string
+}
+
+func (s *btrfsStorageVolumeSourceDriver) SendStorageVolume(conn *websocket.Conn, op *operation, bwlimit string) error {
+	poolName := s.btrfs.pool.Name
+	volumeName := s.btrfs.volume.Name
+
+	for i, snap := range s.snapshots {
+		prev := ""
+		if i > 0 {
+			prev = getStoragePoolVolumeSnapshotMountPoint(poolName, s.snapshots[i-1])
+		}
+
+		snapMntPoint := getStoragePoolVolumeSnapshotMountPoint(poolName, snap)
+		wrapper := StorageProgressReader(op, "fs_progress", snap)
+		err := btrfsMigrationSend(conn, snapMntPoint, prev, wrapper)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Only read-only subvolumes can be sent, so send a temporary
+	// snapshot of the storage volume.
+	tmpVolumeMntPoint, err := ioutil.TempDir(s.btrfs.getCustomSubvolumePath(poolName), volumeName)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpVolumeMntPoint)
+
+	err = os.Chmod(tmpVolumeMntPoint, 0700)
+	if err != nil {
+		return err
+	}
+
+	migrationSendSnapshot := fmt.Sprintf("%s/.migration-send", tmpVolumeMntPoint)
+	volumeMntPoint := getStoragePoolVolumeMountPoint(poolName, volumeName)
+	err = s.btrfs.btrfsPoolVolumesSnapshot(volumeMntPoint, migrationSendSnapshot, true)
+	if err != nil {
+		return err
+	}
+	defer btrfsSubVolumesDelete(migrationSendSnapshot)
+
+	btrfsParent := ""
+	if len(s.snapshots) > 0 {
+		btrfsParent = getStoragePoolVolumeSnapshotMountPoint(poolName, s.snapshots[len(s.snapshots)-1])
+	}
+
+	wrapper := StorageProgressReader(op, "fs_progress", volumeName)
+	return btrfsMigrationSend(conn, migrationSendSnapshot, btrfsParent, wrapper)
+}
+
+func (s *btrfsStorageVolumeSourceDriver) Cleanup() {
+	// noop
+}
+
+func (s *storageBtrfs) StorageMigrationSource(migrationType MigrationFSType, volumeOnly bool) (MigrationStorageVolumeSourceDriver, error) {
+	if migrationType != MigrationFSType_BTRFS {
+		snapshotPath := func(snapshotName string) string {
+			return getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, snapshotName)
+		}
+
+		return rsyncStorageMigrationSource(s.s, s.pool.Name, s.volume.Name, volumeOnly, snapshotPath, nil)
+	}
+
+	driver := &btrfsStorageVolumeSourceDriver{
+		btrfs:     s,
+		snapshots: []string{},
+	}
+
+	if volumeOnly {
+		return driver, nil
+	}
+
+	snapshots, err := s.db.StoragePoolVolumeSnapshotsGetType(s.volume.Name, storagePoolVolumeTypeCustom, s.poolID)
+	if err != nil {
+		return nil, err
+	}
+	driver.snapshots = snapshots
+
+	return driver, nil
+}
+
+func (s *storageBtrfs) StorageMigrationSink(conn *websocket.Conn, op *operation, snapshots []*VolumeSnapshot) error {
+	if s.s.OS.RunningInUserNS {
+		return rsyncStorageMigrationSink(s.s, s.pool.Name, s.volume.Name, snapshots, conn, op)
+	}
+
+	for _, snap := range snapshots {
+		fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snap.GetName()
+		snapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+		snapshotsPath := filepath.Dir(snapshotMntPoint)
+		err := os.MkdirAll(snapshotsPath, 0700)
+		if err != nil {
+			return err
+		}
+
+		tmpSnapshotMntPoint, err := ioutil.TempDir(snapshotsPath, s.volume.Name)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmpSnapshotMntPoint)
+
+		err = os.Chmod(tmpSnapshotMntPoint, 0700)
+		if err != nil {
+			return err
+		}
+
+		wrapper := StorageProgressWriter(op, "fs_progress", snap.GetName())
+		err = s.btrfsMigrationRecv(conn, snap.GetName(), tmpSnapshotMntPoint, snapshotMntPoint, true, wrapper)
+		if err != nil {
+			return err
+		}
+
+		err = storagePoolVolumeSnapshotDBCreate(s.s, s.pool.Name, fullSnapshotName, snap.GetDescription(), volumeSnapshotProtobufToConfig(snap))
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpVolumeMntPoint, err := ioutil.TempDir(s.getCustomSubvolumePath(s.pool.Name), s.volume.Name)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpVolumeMntPoint)
+
+	err = os.Chmod(tmpVolumeMntPoint, 0700)
+	if err != nil {
+		return err
+	}
+
+	volumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	wrapper := StorageProgressWriter(op, "fs_progress", s.volume.Name)
+	err = s.btrfsMigrationRecv(conn, "", tmpVolumeMntPoint, volumeMntPoint, false, wrapper)
+	if err != nil {
+		return err
+	}
+
+	// The received subvolume replaced the one the quota was set on.
+	if s.volume.Config["size"] != "" {
+		size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *storageBtrfs) btrfsLookupFsUUID(fs string) (string, error) {
 	output, err := shared.RunCommand(
 		"btrfs",
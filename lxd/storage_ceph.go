@@ -764,6 +764,122 @@ func (s *storageCeph) StoragePoolVolumeRename(newName string) error {
 		storagePoolVolumeTypeCustom, s.poolID)
 }
 
+func (s *storageCeph) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Infof(`Copying RBD storage volume "%s" on storage pool "%s" as "%s" to storage pool "%s"`,
+		source.Name, source.Pool, s.volume.Name, s.pool.Name)
+
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	err := storagePoolVolumeCopyRsync(s.s, s, s.pool.Name, s.volume.Name, source, bwlimit)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof(`Copied RBD storage volume "%s" on storage pool "%s" as "%s" to storage pool "%s"`,
+		source.Name, source.Pool, s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageCeph) StoragePoolVolumeRestore(snapshotName string) error {
+	logger.Infof(`Restoring RBD storage volume "%s" from snapshot "%s" on storage pool "%s"`,
+		s.volume.Name, snapshotName, s.pool.Name)
+
+	// The filesystem must not be mounted while the RBD storage volume is
+	// rolled back.
+	_, err := s.StoragePoolVolumeUmount()
+	if err != nil {
+		return err
+	}
+
+	_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshotName)
+	err = cephRBDVolumeRestore(s.ClusterName, s.OSDPoolName,
+		s.volume.Name, storagePoolVolumeTypeNameCustom,
+		fmt.Sprintf("snapshot_%s", snapOnlyName), s.UserName)
+	if err != nil {
+		logger.Errorf(`Failed to restore RBD storage volume "%s" from "%s": %s`,
+			s.volume.Name, snapshotName, err)
+		return err
+	}
+
+	logger.Infof(`Restored RBD storage volume "%s" from snapshot "%s" on storage pool "%s"`,
+		s.volume.Name, snapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageCeph) StoragePoolVolumeSnapshotCreate() error {
+	logger.Infof(`Creating RBD storage volume snapshot "%s" on storage pool "%s"`,
+		s.volume.Name, s.pool.Name)
+
+	sourceName, snapOnlyName, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	err := cephRBDSnapshotCreate(s.ClusterName, s.OSDPoolName,
+		sourceName, storagePoolVolumeTypeNameCustom,
+		fmt.Sprintf("snapshot_%s", snapOnlyName), s.UserName)
+	if err != nil {
+		logger.Errorf(`Failed to create RBD storage volume snapshot "%s" on storage pool "%s": %s`,
+			s.volume.Name, s.pool.Name, err)
+		return err
+	}
+
+	logger.Infof(`Created RBD storage volume snapshot "%s" on storage pool "%s"`,
+		s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageCeph) StoragePoolVolumeSnapshotDelete() error {
+	logger.Infof(`Deleting RBD storage volume snapshot "%s" on storage pool "%s"`,
+		s.volume.Name, s.pool.Name)
+
+	sourceName, snapOnlyName, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	rbdSnapshotName := fmt.Sprintf("snapshot_%s", snapOnlyName)
+	if cephRBDSnapshotExists(s.ClusterName, s.OSDPoolName, sourceName,
+		storagePoolVolumeTypeNameCustom, rbdSnapshotName, s.UserName) {
+		err := cephRBDSnapshotDelete(s.ClusterName, s.OSDPoolName,
+			sourceName, storagePoolVolumeTypeNameCustom,
+			rbdSnapshotName, s.UserName)
+		if err != nil {
+			logger.Errorf(`Failed to delete RBD storage volume snapshot "%s" on storage pool "%s": %s`,
+				s.volume.Name, s.pool.Name, err)
+			return err
+		}
+	}
+
+	err := s.db.StoragePoolVolumeDelete(
+		s.volume.Name,
+		storagePoolVolumeTypeCustom,
+		s.poolID)
+	if err != nil {
+		logger.Errorf(`Failed to delete database entry for RBD `+
+			`storage volume snapshot "%s" on storage pool "%s"`,
+			s.volume.Name, s.pool.Name)
+	}
+
+	logger.Infof(`Deleted RBD storage volume snapshot "%s" on storage pool "%s"`,
+		s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageCeph) StoragePoolVolumeSnapshotRename(newName string) error {
+	logger.Infof(`Renaming RBD storage volume snapshot on storage pool "%s" from "%s" to "%s"`,
+		s.pool.Name, s.volume.Name, newName)
+
+	sourceName, oldSnapOnlyName, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	_, newSnapOnlyName, _ := containerGetParentAndSnapshotName(newName)
+	err := cephRBDVolumeSnapshotRename(s.ClusterName, s.OSDPoolName,
+		sourceName, storagePoolVolumeTypeNameCustom,
+		fmt.Sprintf("snapshot_%s", oldSnapOnlyName),
+		fmt.Sprintf("snapshot_%s", newSnapOnlyName), s.UserName)
+	if err != nil {
+		logger.Errorf(`Failed to rename RBD storage volume snapshot "%s" to "%s": %s`,
+			s.volume.Name, newName, err)
+		return err
+	}
+
+	logger.Infof(`Renamed RBD storage volume snapshot on storage pool "%s" from "%s" to "%s"`,
+		s.pool.Name, s.volume.Name, newName)
+
+	return s.db.StoragePoolVolumeRename(s.volume.Name, newName,
+		storagePoolVolumeTypeCustom, s.poolID)
+}
+
 func (s *storageCeph) StoragePoolUpdate(writable *api.StoragePoolPut, changedConfig []string) error {
 	logger.Infof(`Updating CEPH storage pool "%s"`, s.pool.Name)
 
@@ -2232,6 +2348,23 @@ func (s *storageCeph) ContainerSnapshotCreateEmpty(c container) error {
 	return nil
 }
 
+func (s *storageCeph) ContainerBackupCreate(backup backup, sourceContainer container, target string) error {
+	logger.Debugf("Creating backup \"%s\" of container \"%s\" on RBD storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	err := rsyncContainerBackupCreate(backup, sourceContainer, target, bwlimit)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Created backup \"%s\" of container \"%s\" on RBD storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+	return nil
+}
+
+func (s *storageCeph) ContainerBackupLoad(info backupInfo, tarball string) error {
+	return fmt.Errorf("Optimized backups aren't supported by the ceph storage driver")
+}
+
 func (s *storageCeph) ImageCreate(fingerprint string) error {
 	logger.Debugf(`Creating RBD storage volume for image "%s" on storage `+
 		`pool "%s"`, fingerprint, s.pool.Name)
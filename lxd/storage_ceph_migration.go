@@ -407,3 +407,124 @@ func (s *storageCeph) MigrationSink(live bool, c container,
 
 	return nil
 }
+
+func (s *storageCeph) StorageMigrationSource(migrationType MigrationFSType, volumeOnly bool) (MigrationStorageVolumeSourceDriver, error) {
+	// Custom RBD storage volumes are sent through rsync. Their snapshots
+	// aren't mapped, so each of them is mounted while it is being sent.
+	snapshotPath := func(snapshotName string) string {
+		return getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, snapshotName)
+	}
+
+	return rsyncStorageMigrationSource(s.s, s.pool.Name, s.volume.Name, volumeOnly, snapshotPath, s.storagePoolVolumeSnapshotMount)
+}
+
+// storagePoolVolumeSnapshotMount mounts a clone of the given snapshot of a
+// custom RBD storage volume on the snapshot's mount point and returns the
+// function unmounting and removing it again.
+func (s *storageCeph) storagePoolVolumeSnapshotMount(snapshotName string) (func(), error) {
+	sourceName, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshotName)
+	prefixedSnapOnlyName := fmt.Sprintf("snapshot_%s", snapOnlyName)
+	cloneName := fmt.Sprintf("%s_%s_%s_start_clone", storagePoolVolumeTypeNameCustom, sourceName, snapOnlyName)
+	snapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, snapshotName)
+
+	revert := true
+
+	err := cephRBDSnapshotProtect(s.ClusterName, s.OSDPoolName,
+		sourceName, storagePoolVolumeTypeNameCustom,
+		prefixedSnapOnlyName, s.UserName)
+	if err != nil {
+		logger.Errorf(`Failed to protect RBD storage volume snapshot "%s" on storage pool "%s": %s`,
+			snapshotName, s.pool.Name, err)
+		return nil, err
+	}
+
+	unprotect := func() {
+		err := cephRBDSnapshotUnprotect(s.ClusterName, s.OSDPoolName,
+			sourceName, storagePoolVolumeTypeNameCustom,
+			prefixedSnapOnlyName, s.UserName)
+		if err != nil {
+			logger.Warnf(`Failed to unprotect RBD storage volume snapshot "%s" on storage pool "%s": %s`,
+				snapshotName, s.pool.Name, err)
+		}
+	}
+	defer func() {
+		if revert {
+			unprotect()
+		}
+	}()
+
+	err = cephRBDCloneCreate(s.ClusterName, s.OSDPoolName,
+		sourceName, storagePoolVolumeTypeNameCustom,
+		prefixedSnapOnlyName, s.OSDPoolName, cloneName, "snapshots",
+		s.UserName)
+	if err != nil {
+		logger.Errorf(`Failed to create clone of RBD storage volume snapshot "%s" on storage pool "%s": %s`,
+			snapshotName, s.pool.Name, err)
+		return nil, err
+	}
+
+	deleteClone := func() {
+		err := cephRBDVolumeDelete(s.ClusterName, s.OSDPoolName,
+			cloneName, "snapshots", s.UserName)
+		if err != nil {
+			logger.Warnf(`Failed to delete clone of RBD storage volume snapshot "%s" on storage pool "%s": %s`,
+				snapshotName, s.pool.Name, err)
+		}
+	}
+	defer func() {
+		if revert {
+			deleteClone()
+		}
+	}()
+
+	RBDDevPath, err := cephRBDVolumeMap(s.ClusterName, s.OSDPoolName,
+		cloneName, "snapshots", s.UserName)
+	if err != nil {
+		logger.Errorf(`Failed to map clone of RBD storage volume snapshot "%s" on storage pool "%s": %s`,
+			snapshotName, s.pool.Name, err)
+		return nil, err
+	}
+
+	unmap := func() {
+		err := cephRBDVolumeUnmap(s.ClusterName, s.OSDPoolName,
+			cloneName, "snapshots", s.UserName, true)
+		if err != nil {
+			logger.Warnf(`Failed to unmap clone of RBD storage volume snapshot "%s" on storage pool "%s": %s`,
+				snapshotName, s.pool.Name, err)
+		}
+	}
+	defer func() {
+		if revert {
+			unmap()
+		}
+	}()
+
+	err = os.MkdirAll(snapshotMntPoint, 0711)
+	if err != nil {
+		return nil, err
+	}
+
+	RBDFilesystem := s.getRBDFilesystem()
+	mountFlags, mountOptions := lxdResolveMountoptions(s.getRBDMountOptions())
+	err = tryMount(RBDDevPath, snapshotMntPoint, RBDFilesystem, mountFlags, mountOptions)
+	if err != nil {
+		logger.Errorf("Failed to mount RBD device %s onto %s: %s",
+			RBDDevPath, snapshotMntPoint, err)
+		os.Remove(snapshotMntPoint)
+		return nil, err
+	}
+
+	revert = false
+
+	return func() {
+		tryUnmount(snapshotMntPoint, 0)
+		os.Remove(snapshotMntPoint)
+		unmap()
+		deleteClone()
+		unprotect()
+	}, nil
+}
+
+func (s *storageCeph) StorageMigrationSink(conn *websocket.Conn, op *operation, snapshots []*VolumeSnapshot) error {
+	return rsyncStorageMigrationSink(s.s, s.pool.Name, s.volume.Name, snapshots, conn, op)
+}
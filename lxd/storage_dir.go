@@ -437,6 +437,128 @@ func (s *storageDir) StoragePoolVolumeRename(newName string) error {
 		storagePoolVolumeTypeCustom, s.poolID)
 }
 
+func (s *storageDir) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Infof("Copying DIR storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	err = storagePoolVolumeCopyRsync(s.s, s, s.pool.Name, s.volume.Name, source, bwlimit)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Copied DIR storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeRestore(snapshotName string) error {
+	logger.Infof("Restoring DIR storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, snapshotName, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	sourcePath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, snapshotName)
+	targetPath := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	output, err := rsyncLocalCopy(sourcePath, targetPath, bwlimit)
+	if err != nil {
+		return fmt.Errorf("failed to rsync storage volume: %s: %s", string(output), err)
+	}
+
+	logger.Infof("Restored DIR storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, snapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeSnapshotCreate() error {
+	logger.Infof("Creating DIR storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	sourceName, _, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	sourcePath := getStoragePoolVolumeMountPoint(s.pool.Name, sourceName)
+	targetPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, s.volume.Name)
+	err = os.MkdirAll(targetPath, 0711)
+	if err != nil {
+		return err
+	}
+
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	output, err := rsyncLocalCopy(sourcePath, targetPath, bwlimit)
+	if err != nil {
+		os.RemoveAll(targetPath)
+		return fmt.Errorf("failed to rsync storage volume: %s: %s", string(output), err)
+	}
+
+	logger.Infof("Created DIR storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeSnapshotDelete() error {
+	logger.Infof("Deleting DIR storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	snapshotPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, s.volume.Name)
+	err = os.RemoveAll(snapshotPath)
+	if err != nil {
+		return err
+	}
+
+	// Remove the snapshot directory of the volume once it's empty.
+	sourceName, _, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	snapshotsPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, sourceName)
+	empty, _ := shared.PathIsEmpty(snapshotsPath)
+	if empty {
+		os.Remove(snapshotsPath)
+	}
+
+	err = s.db.StoragePoolVolumeDelete(
+		s.volume.Name,
+		storagePoolVolumeTypeCustom,
+		s.poolID)
+	if err != nil {
+		logger.Errorf(`Failed to delete database entry for DIR `+
+			`storage volume snapshot "%s" on storage pool "%s"`,
+			s.volume.Name, s.pool.Name)
+	}
+
+	logger.Infof("Deleted DIR storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeSnapshotRename(newName string) error {
+	logger.Infof("Renaming DIR storage volume snapshot on storage pool \"%s\" from \"%s\" to \"%s\"", s.pool.Name, s.volume.Name, newName)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	oldPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, s.volume.Name)
+	newPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, newName)
+	err = os.Rename(oldPath, newPath)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Renamed DIR storage volume snapshot on storage pool \"%s\" from \"%s\" to \"%s\"", s.pool.Name, s.volume.Name, newName)
+
+	return s.db.StoragePoolVolumeRename(s.volume.Name, newName,
+		storagePoolVolumeTypeCustom, s.poolID)
+}
+
 func (s *storageDir) ContainerStorageReady(name string) bool {
 	containerMntPoint := getContainerMountPoint(s.pool.Name, name)
 	ok, _ := shared.PathIsEmpty(containerMntPoint)
@@ -927,6 +1049,23 @@ func (s *storageDir) ContainerSnapshotCreateEmpty(snapshotContainer container) e
 	return nil
 }
 
+func (s *storageDir) ContainerBackupCreate(backup backup, sourceContainer container, target string) error {
+	logger.Debugf("Creating backup \"%s\" of container \"%s\" on DIR storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	err := rsyncContainerBackupCreate(backup, sourceContainer, target, bwlimit)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Created backup \"%s\" of container \"%s\" on DIR storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) ContainerBackupLoad(info backupInfo, tarball string) error {
+	return fmt.Errorf("Optimized backups aren't supported by the dir storage driver")
+}
+
 func dirSnapshotDeleteInternal(poolName string, snapshotName string) error {
 	snapshotContainerMntPoint := getSnapshotMountPoint(poolName, snapshotName)
 	if shared.PathExists(snapshotContainerMntPoint) {
@@ -1046,6 +1185,18 @@ func (s *storageDir) MigrationSink(live bool, container container, snapshots []*
 	return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly)
 }
 
+func (s *storageDir) StorageMigrationSource(migrationType MigrationFSType, volumeOnly bool) (MigrationStorageVolumeSourceDriver, error) {
+	snapshotPath := func(snapshotName string) string {
+		return getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, snapshotName)
+	}
+
+	return rsyncStorageMigrationSource(s.s, s.pool.Name, s.volume.Name, volumeOnly, snapshotPath, nil)
+}
+
+func (s *storageDir) StorageMigrationSink(conn *websocket.Conn, op *operation, snapshots []*VolumeSnapshot) error {
+	return rsyncStorageMigrationSink(s.s, s.pool.Name, s.volume.Name, snapshots, conn, op)
+}
+
 func (s *storageDir) StorageEntitySetQuota(volumeType int, size int64, data interface{}) error {
 	return fmt.Errorf("the directory container backend doesn't support quotas")
 }
@@ -856,6 +856,159 @@ func (s *storageLvm) StoragePoolVolumeRename(newName string) error {
 		storagePoolVolumeTypeCustom, s.poolID)
 }
 
+func (s *storageLvm) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Infof("Copying LVM storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	err := storagePoolVolumeCopyRsync(s.s, s, s.pool.Name, s.volume.Name, source, bwlimit)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Copied LVM storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageLvm) StoragePoolVolumeRestore(snapshotName string) error {
+	logger.Infof("Restoring LVM storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, snapshotName, s.pool.Name)
+
+	snapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, snapshotName)
+	umount, err := s.storagePoolVolumeSnapshotMount(snapshotName)
+	if err != nil {
+		return err
+	}
+	defer umount()
+
+	ourMount, err := s.StoragePoolVolumeMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer s.StoragePoolVolumeUmount()
+	}
+
+	targetMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	msg, err := rsyncLocalCopy(snapshotMntPoint, targetMntPoint, bwlimit)
+	if err != nil {
+		return fmt.Errorf("failed to rsync storage volume: %s: %s", string(msg), err)
+	}
+
+	logger.Infof("Restored LVM storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, snapshotName, s.pool.Name)
+	return nil
+}
+
+// storagePoolVolumeSnapshotMount mounts the given snapshot of a custom
+// storage volume on its snapshot mount point and returns the function
+// unmounting it again.
+func (s *storageLvm) storagePoolVolumeSnapshotMount(snapshotName string) (func(), error) {
+	poolName := s.getOnDiskPoolName()
+	snapshotLvmName := containerNameToLVName(snapshotName)
+	snapshotLvName := getLVName(poolName, storagePoolVolumeAPIEndpointCustom, snapshotLvmName)
+	snapshotLvmPath := getLvmDevPath(poolName, storagePoolVolumeAPIEndpointCustom, snapshotLvmName)
+	snapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, snapshotName)
+
+	// The snapshot needs to be writable for the filesystem to be
+	// mountable.
+	output, err := shared.TryRunCommand("lvchange", "-prw", snapshotLvName)
+	if err != nil {
+		logger.Errorf("Failed to make LVM snapshot \"%s\" read-write: %s.", snapshotName, output)
+		return nil, err
+	}
+
+	err = os.MkdirAll(snapshotMntPoint, 0711)
+	if err != nil {
+		shared.TryRunCommand("lvchange", "-pr", snapshotLvName)
+		return nil, err
+	}
+
+	lvFsType := s.getLvmFilesystem()
+	mountFlags, mountOptions := lxdResolveMountoptions(s.getLvmMountOptions())
+	if lvFsType == "xfs" {
+		idx := strings.Index(mountOptions, "nouuid")
+		if idx < 0 {
+			mountOptions += ",nouuid"
+		}
+	}
+
+	err = tryMount(snapshotLvmPath, snapshotMntPoint, lvFsType, mountFlags, mountOptions)
+	if err != nil {
+		os.Remove(snapshotMntPoint)
+		shared.TryRunCommand("lvchange", "-pr", snapshotLvName)
+		return nil, err
+	}
+
+	return func() {
+		tryUnmount(snapshotMntPoint, 0)
+		os.Remove(snapshotMntPoint)
+		shared.TryRunCommand("lvchange", "-pr", snapshotLvName)
+	}, nil
+}
+
+func (s *storageLvm) StoragePoolVolumeSnapshotCreate() error {
+	logger.Infof("Creating LVM storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+
+	sourceName, _, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	snapshotLvmName := containerNameToLVName(s.volume.Name)
+
+	_, err := s.createSnapshotLV(s.getOnDiskPoolName(), sourceName,
+		storagePoolVolumeAPIEndpointCustom, snapshotLvmName,
+		storagePoolVolumeAPIEndpointCustom, true, s.useThinpool)
+	if err != nil {
+		return fmt.Errorf("Error creating snapshot LV: %s", err)
+	}
+
+	logger.Infof("Created LVM storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageLvm) StoragePoolVolumeSnapshotDelete() error {
+	logger.Infof("Deleting LVM storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+
+	poolName := s.getOnDiskPoolName()
+	snapshotLvmName := containerNameToLVName(s.volume.Name)
+	snapshotLvmPath := getLvmDevPath(poolName, storagePoolVolumeAPIEndpointCustom, snapshotLvmName)
+
+	lvExists, _ := storageLVExists(snapshotLvmPath)
+	if lvExists {
+		err := removeLV(poolName, storagePoolVolumeAPIEndpointCustom, snapshotLvmName)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.db.StoragePoolVolumeDelete(
+		s.volume.Name,
+		storagePoolVolumeTypeCustom,
+		s.poolID)
+	if err != nil {
+		logger.Errorf(`Failed to delete database entry for LVM `+
+			`storage volume snapshot "%s" on storage pool "%s"`,
+			s.volume.Name, s.pool.Name)
+	}
+
+	logger.Infof("Deleted LVM storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageLvm) StoragePoolVolumeSnapshotRename(newName string) error {
+	logger.Infof(`Renaming LVM storage volume snapshot on storage pool "%s" from "%s" to "%s"`,
+		s.pool.Name, s.volume.Name, newName)
+
+	err := s.renameLVByPath(containerNameToLVName(s.volume.Name),
+		containerNameToLVName(newName), storagePoolVolumeAPIEndpointCustom)
+	if err != nil {
+		return fmt.Errorf(`Failed to rename logical volume from "%s" to "%s": %s`,
+			s.volume.Name, newName, err)
+	}
+
+	logger.Infof(`Renamed LVM storage volume snapshot on storage pool "%s" from "%s" to "%s"`,
+		s.pool.Name, s.volume.Name, newName)
+
+	return s.db.StoragePoolVolumeRename(s.volume.Name, newName,
+		storagePoolVolumeTypeCustom, s.poolID)
+}
+
 func (s *storageLvm) ContainerStorageReady(name string) bool {
 	containerLvmName := containerNameToLVName(name)
 	poolName := s.getOnDiskPoolName()
@@ -1564,6 +1717,23 @@ func (s *storageLvm) ContainerSnapshotCreateEmpty(snapshotContainer container) e
 	return nil
 }
 
+func (s *storageLvm) ContainerBackupCreate(backup backup, sourceContainer container, target string) error {
+	logger.Debugf("Creating backup \"%s\" of container \"%s\" on LVM storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	err := rsyncContainerBackupCreate(backup, sourceContainer, target, bwlimit)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Created backup \"%s\" of container \"%s\" on LVM storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+	return nil
+}
+
+func (s *storageLvm) ContainerBackupLoad(info backupInfo, tarball string) error {
+	return fmt.Errorf("Optimized backups aren't supported by the lvm storage driver")
+}
+
 func (s *storageLvm) ImageCreate(fingerprint string) error {
 	logger.Debugf("Creating LVM storage volume for image \"%s\" on storage pool \"%s\".", fingerprint, s.pool.Name)
 
@@ -1740,6 +1910,20 @@ func (s *storageLvm) MigrationSink(live bool, container container, snapshots []*
 	return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly)
 }
 
+func (s *storageLvm) StorageMigrationSource(migrationType MigrationFSType, volumeOnly bool) (MigrationStorageVolumeSourceDriver, error) {
+	// LVM snapshots aren't kept mounted, so each of them is mounted while
+	// it is being sent.
+	snapshotPath := func(snapshotName string) string {
+		return getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, snapshotName)
+	}
+
+	return rsyncStorageMigrationSource(s.s, s.pool.Name, s.volume.Name, volumeOnly, snapshotPath, s.storagePoolVolumeSnapshotMount)
+}
+
+func (s *storageLvm) StorageMigrationSink(conn *websocket.Conn, op *operation, snapshots []*VolumeSnapshot) error {
+	return rsyncStorageMigrationSink(s.s, s.pool.Name, s.volume.Name, snapshots, conn, op)
+}
+
 func (s *storageLvm) StorageEntitySetQuota(volumeType int, size int64, data interface{}) error {
 	logger.Debugf(`Setting LVM quota for "%s"`, s.volume.Name)
 
@@ -6,8 +6,10 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/types"
 	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/idmap"
 )
 
@@ -83,6 +85,171 @@ func (s rsyncStorageSourceDriver) Cleanup() {
 	// noop
 }
 
+// MigrationStorageVolumeSourceDriver defines the functions needed to
+// implement a migration source driver for custom storage volumes.
+type MigrationStorageVolumeSourceDriver interface {
+	/* send the snapshots of the storage volume, oldest first, followed
+	 * by the storage volume itself.
+	 */
+	SendStorageVolume(conn *websocket.Conn, op *operation, bwlimit string) error
+
+	/* Called after either success or failure of a migration, can be used
+	 * to clean up any temporary snapshots, etc.
+	 */
+	Cleanup()
+}
+
+type rsyncStorageVolumeSourceDriver struct {
+	state         *state.State
+	poolName      string
+	volumeName    string
+	snapshots     []string
+	snapshotPath  func(string) string
+	snapshotMount func(string) (func(), error)
+}
+
+func (s rsyncStorageVolumeSourceDriver) sendSnapshot(conn *websocket.Conn, op *operation, bwlimit string, snapshotName string) error {
+	if s.snapshotMount != nil {
+		umount, err := s.snapshotMount(snapshotName)
+		if err != nil {
+			return err
+		}
+		defer umount()
+	}
+
+	wrapper := StorageProgressReader(op, "fs_progress", snapshotName)
+	return RsyncSend(s.volumeName, shared.AddSlash(s.snapshotPath(snapshotName)), conn, wrapper, bwlimit, s.state.OS.ExecPath)
+}
+
+func (s rsyncStorageVolumeSourceDriver) SendStorageVolume(conn *websocket.Conn, op *operation, bwlimit string) error {
+	for _, snap := range s.snapshots {
+		err := s.sendSnapshot(conn, op, bwlimit, snap)
+		if err != nil {
+			return err
+		}
+	}
+
+	path := getStoragePoolVolumeMountPoint(s.poolName, s.volumeName)
+	wrapper := StorageProgressReader(op, "fs_progress", s.volumeName)
+	return RsyncSend(s.volumeName, shared.AddSlash(path), conn, wrapper, bwlimit, s.state.OS.ExecPath)
+}
+
+func (s rsyncStorageVolumeSourceDriver) Cleanup() {
+	// noop
+}
+
+// rsyncStorageMigrationSource returns a migration source driver sending a
+// custom storage volume and its snapshots through rsync. The snapshotPath
+// function returns the path the content of a snapshot can be read from. The
+// optional snapshotMount function makes that content available for the
+// duration of the transfer and returns the function undoing it, for storage
+// drivers which don't keep their snapshots mounted.
+func rsyncStorageMigrationSource(state *state.State, poolName string, volumeName string, volumeOnly bool, snapshotPath func(string) string, snapshotMount func(string) (func(), error)) (MigrationStorageVolumeSourceDriver, error) {
+	driver := rsyncStorageVolumeSourceDriver{
+		state:         state,
+		poolName:      poolName,
+		volumeName:    volumeName,
+		snapshots:     []string{},
+		snapshotPath:  snapshotPath,
+		snapshotMount: snapshotMount,
+	}
+
+	if volumeOnly {
+		return driver, nil
+	}
+
+	poolID, err := state.DB.StoragePoolGetID(poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := state.DB.StoragePoolVolumeSnapshotsGetType(volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return nil, err
+	}
+
+	driver.snapshots = append(driver.snapshots, snapshots...)
+
+	return driver, nil
+}
+
+// rsyncStorageMigrationSink receives a custom storage volume and its
+// snapshots sent by a rsyncStorageVolumeSourceDriver. The storage volume
+// must already exist.
+func rsyncStorageMigrationSink(state *state.State, poolName string, volumeName string, snapshots []*VolumeSnapshot, conn *websocket.Conn, op *operation) error {
+	s, err := storagePoolVolumeInit(state, poolName, volumeName, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	ourMount, err := s.StoragePoolVolumeMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer s.StoragePoolVolumeUmount()
+	}
+
+	path := getStoragePoolVolumeMountPoint(poolName, volumeName)
+
+	// Every snapshot is received into the storage volume first and then
+	// snapshotted, the same way it was created on the source.
+	for _, snap := range snapshots {
+		wrapper := StorageProgressWriter(op, "fs_progress", snap.GetName())
+		err := RsyncRecv(shared.AddSlash(path), conn, wrapper)
+		if err != nil {
+			return err
+		}
+
+		fullSnapshotName := volumeName + shared.SnapshotDelimiter + snap.GetName()
+		err = storagePoolVolumeSnapshotDBCreate(state, poolName, fullSnapshotName, snap.GetDescription(), volumeSnapshotProtobufToConfig(snap))
+		if err != nil {
+			return err
+		}
+
+		snapStorage, err := storagePoolVolumeInit(state, poolName, fullSnapshotName, storagePoolVolumeTypeCustom)
+		if err != nil {
+			return err
+		}
+
+		err = snapStorage.StoragePoolVolumeSnapshotCreate()
+		if err != nil {
+			return err
+		}
+	}
+
+	wrapper := StorageProgressWriter(op, "fs_progress", volumeName)
+	return RsyncRecv(shared.AddSlash(path), conn, wrapper)
+}
+
+func volumeSnapshotToProtobuf(snapshot *api.StorageVolume) *VolumeSnapshot {
+	config := []*Config{}
+	for k, v := range snapshot.Config {
+		kCopy := string(k)
+		vCopy := string(v)
+		config = append(config, &Config{Key: &kCopy, Value: &vCopy})
+	}
+
+	_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshot.Name)
+	description := snapshot.Description
+
+	return &VolumeSnapshot{
+		Name:        &snapOnlyName,
+		Description: &description,
+		Config:      config,
+	}
+}
+
+func volumeSnapshotProtobufToConfig(snap *VolumeSnapshot) map[string]string {
+	config := map[string]string{}
+
+	for _, ent := range snap.Config {
+		config[ent.GetKey()] = ent.GetValue()
+	}
+
+	return config
+}
+
 func rsyncMigrationSource(c container, containerOnly bool) (MigrationStorageSourceDriver, error) {
 	var err error
 	var snapshots = []container{}
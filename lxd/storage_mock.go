@@ -104,6 +104,26 @@ func (s *storageMock) StoragePoolVolumeRename(newName string) error {
 	return nil
 }
 
+func (s *storageMock) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	return nil
+}
+
+func (s *storageMock) StoragePoolVolumeRestore(snapshotName string) error {
+	return nil
+}
+
+func (s *storageMock) StoragePoolVolumeSnapshotCreate() error {
+	return nil
+}
+
+func (s *storageMock) StoragePoolVolumeSnapshotDelete() error {
+	return nil
+}
+
+func (s *storageMock) StoragePoolVolumeSnapshotRename(newName string) error {
+	return nil
+}
+
 func (s *storageMock) StoragePoolUpdate(writable *api.StoragePoolPut, changedConfig []string) error {
 	return nil
 }
@@ -188,6 +208,14 @@ func (s *storageMock) ContainerSnapshotCreateEmpty(snapshotContainer container)
 	return nil
 }
 
+func (s *storageMock) ContainerBackupCreate(backup backup, sourceContainer container, target string) error {
+	return nil
+}
+
+func (s *storageMock) ContainerBackupLoad(info backupInfo, tarball string) error {
+	return nil
+}
+
 func (s *storageMock) ImageCreate(fingerprint string) error {
 	return nil
 }
@@ -219,6 +247,14 @@ func (s *storageMock) MigrationSink(live bool, container container, snapshots []
 	return nil
 }
 
+func (s *storageMock) StorageMigrationSource(migrationType MigrationFSType, volumeOnly bool) (MigrationStorageVolumeSourceDriver, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (s *storageMock) StorageMigrationSink(conn *websocket.Conn, op *operation, snapshots []*VolumeSnapshot) error {
+	return nil
+}
+
 func (s *storageMock) StorageEntitySetQuota(volumeType int, size int64, data interface{}) error {
 	return nil
 }
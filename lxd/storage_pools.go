@@ -82,7 +82,10 @@ func storagePoolsPost(d *Daemon, r *http.Request) Response {
 		return InternalError(err)
 	}
 
-	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, req.Name))
+	url := fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, req.Name)
+	eventSendLifecycle("", "storage-pool-created", url, d.requestor(r), map[string]interface{}{"driver": req.Driver})
+
+	return SyncResponseLocation(true, nil, url)
 }
 
 var storagePoolsCmd = Command{name: "storage-pools", get: storagePoolsGet, post: storagePoolsPost}
@@ -145,6 +148,8 @@ func storagePoolPut(d *Daemon, r *http.Request) Response {
 		return InternalError(err)
 	}
 
+	eventSendLifecycle("", "storage-pool-updated", fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, poolName), d.requestor(r), nil)
+
 	return EmptySyncResponse
 }
 
@@ -195,6 +200,8 @@ func storagePoolPatch(d *Daemon, r *http.Request) Response {
 		return InternalError(err)
 	}
 
+	eventSendLifecycle("", "storage-pool-updated", fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, poolName), d.requestor(r), nil)
+
 	return EmptySyncResponse
 }
 
@@ -244,6 +251,8 @@ func storagePoolDelete(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("", "storage-pool-deleted", fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, poolName), d.requestor(r), nil)
+
 	return EmptySyncResponse
 }
 
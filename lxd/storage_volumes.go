@@ -1,17 +1,26 @@
 package main
 
 import (
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/filter"
+	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/version"
+
+	log "github.com/lxc/lxd/shared/log15"
 )
 
 // /1.0/storage-pools/{name}/volumes
@@ -25,6 +34,11 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) Response {
 		recursion = 0
 	}
 
+	f, err := filter.FromRequest(r)
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	// Retrieve ID of the storage pool (and check if the storage pool
 	// exists).
 	poolID, err := d.db.StoragePoolGetID(poolName)
@@ -40,28 +54,48 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) Response {
 	}
 
 	resultString := []string{}
+	resultMap := []*api.StorageVolume{}
 	for _, volume := range volumes {
+		// Snapshots of custom storage volumes are listed through the
+		// snapshots endpoint of their parent volume.
+		if volume.Type == storagePoolVolumeTypeNameCustom && strings.Contains(volume.Name, shared.SnapshotDelimiter) {
+			continue
+		}
+
 		apiEndpoint, err := storagePoolVolumeTypeNameToAPIEndpoint(volume.Type)
 		if err != nil {
 			return InternalError(err)
 		}
 
-		if recursion == 0 {
-			resultString = append(resultString, fmt.Sprintf("/%s/storage-pools/%s/volumes/%s/%s", version.APIVersion, poolName, apiEndpoint, volume.Name))
-		} else {
+		if recursion != 0 || f.Uses("used_by") {
 			volumeUsedBy, err := storagePoolVolumeUsedByGet(d.State(), volume.Name, volume.Type)
 			if err != nil {
 				return InternalError(err)
 			}
 			volume.UsedBy = volumeUsedBy
 		}
+
+		match, err := f.Match(volume, "name", "config")
+		if err != nil {
+			return SmartError(err)
+		}
+
+		if !match {
+			continue
+		}
+
+		if recursion == 0 {
+			resultString = append(resultString, fmt.Sprintf("/%s/storage-pools/%s/volumes/%s/%s", version.APIVersion, poolName, apiEndpoint, volume.Name))
+		} else {
+			resultMap = append(resultMap, volume)
+		}
 	}
 
 	if recursion == 0 {
 		return SyncResponse(true, resultString)
 	}
 
-	return SyncResponse(true, volumes)
+	return SyncResponse(true, resultMap)
 }
 
 var storagePoolVolumesCmd = Command{name: "storage-pools/{name}/volumes", get: storagePoolVolumesGet}
@@ -79,6 +113,11 @@ func storagePoolVolumesTypeGet(d *Daemon, r *http.Request) Response {
 		recursion = 0
 	}
 
+	f, err := filter.FromRequest(r)
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	// Get the name of the volume type.
 	volumeTypeName := mux.Vars(r)["type"]
 
@@ -109,24 +148,48 @@ func storagePoolVolumesTypeGet(d *Daemon, r *http.Request) Response {
 	resultString := []string{}
 	resultMap := []*api.StorageVolume{}
 	for _, volume := range volumes {
-		if recursion == 0 {
-			apiEndpoint, err := storagePoolVolumeTypeToAPIEndpoint(volumeType)
-			if err != nil {
-				return InternalError(err)
-			}
-			resultString = append(resultString, fmt.Sprintf("/%s/storage-pools/%s/volumes/%s/%s", version.APIVersion, poolName, apiEndpoint, volume))
-		} else {
-			_, vol, err := d.db.StoragePoolVolumeGetType(volume, volumeType, poolID)
-			if err != nil {
-				continue
-			}
+		// Snapshots of custom storage volumes are listed through the
+		// snapshots endpoint of their parent volume.
+		if volumeType == storagePoolVolumeTypeCustom && strings.Contains(volume, shared.SnapshotDelimiter) {
+			continue
+		}
+
+		apiEndpoint, err := storagePoolVolumeTypeToAPIEndpoint(volumeType)
+		if err != nil {
+			return InternalError(err)
+		}
+
+		url := fmt.Sprintf("/%s/storage-pools/%s/volumes/%s/%s", version.APIVersion, poolName, apiEndpoint, volume)
+		if recursion == 0 && f.Empty() {
+			resultString = append(resultString, url)
+			continue
+		}
+
+		_, vol, err := d.db.StoragePoolVolumeGetType(volume, volumeType, poolID)
+		if err != nil {
+			continue
+		}
 
+		if recursion != 0 || f.Uses("used_by") {
 			volumeUsedBy, err := storagePoolVolumeUsedByGet(d.State(), vol.Name, vol.Type)
 			if err != nil {
 				return SmartError(err)
 			}
 			vol.UsedBy = volumeUsedBy
+		}
+
+		match, err := f.Match(vol, "name", "config")
+		if err != nil {
+			return SmartError(err)
+		}
+
+		if !match {
+			continue
+		}
 
+		if recursion == 0 {
+			resultString = append(resultString, url)
+		} else {
 			resultMap = append(resultMap, vol)
 		}
 	}
@@ -154,6 +217,10 @@ func storagePoolVolumesTypePost(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("No name provided"))
 	}
 
+	if strings.Contains(req.Name, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("Storage volume names may not contain slashes"))
+	}
+
 	// Check that the user gave use a storage volume type for the storage
 	// volume we are about to create.
 	if req.Type == "" {
@@ -172,6 +239,17 @@ func storagePoolVolumesTypePost(d *Daemon, r *http.Request) Response {
 			`storage volumes of type %s`, req.Type))
 	}
 
+	switch req.Source.Type {
+	case "":
+		// Create an empty storage volume.
+	case "copy":
+		return storagePoolVolumesTypeCopy(d, poolName, &req)
+	case "migration":
+		return storagePoolVolumesTypeMigration(d, poolName, &req)
+	default:
+		return BadRequest(fmt.Errorf("Unknown source type %s", req.Source.Type))
+	}
+
 	err = storagePoolVolumeCreateInternal(d.State(), poolName, req.Name, req.Description, req.Type, req.Config)
 	if err != nil {
 		return InternalError(err)
@@ -185,6 +263,158 @@ func storagePoolVolumesTypePost(d *Daemon, r *http.Request) Response {
 	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/storage-pools/%s/volumes/%s", version.APIVersion, poolName, apiEndpoint))
 }
 
+func storagePoolVolumesTypeCopy(d *Daemon, poolName string, req *api.StorageVolumesPost) Response {
+	if req.Source.Name == "" {
+		return BadRequest(fmt.Errorf("No name of the source storage volume provided"))
+	}
+
+	if req.Source.Pool == "" {
+		req.Source.Pool = poolName
+	}
+
+	if strings.Contains(req.Source.Name, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("Copying storage volume snapshots is not supported"))
+	}
+
+	// Check that the name isn't already in use.
+	poolID, err := d.db.StoragePoolGetID(poolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, err = d.db.StoragePoolVolumeGetTypeID(req.Name, storagePoolVolumeTypeCustom, poolID)
+	if err != db.NoSuchObjectError {
+		if err != nil {
+			return SmartError(err)
+		}
+
+		return Conflict
+	}
+
+	run := func(op *operation) error {
+		return storagePoolVolumeCopyInternal(d.State(), poolName, req)
+	}
+
+	resources := map[string][]string{}
+	resources["storage-pools"] = []string{fmt.Sprintf("%s/volumes/%s/%s", poolName, storagePoolVolumeTypeNameCustom, req.Name)}
+
+	op, err := operationCreate("", operationClassTask, resources, nil, run, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func storagePoolVolumesTypeMigration(d *Daemon, poolName string, req *api.StorageVolumesPost) Response {
+	// Validate migration mode
+	if req.Source.Mode != "pull" && req.Source.Mode != "push" {
+		return NotImplemented
+	}
+
+	var cert *x509.Certificate
+	if req.Source.Certificate != "" {
+		certBlock, _ := pem.Decode([]byte(req.Source.Certificate))
+		if certBlock == nil {
+			return InternalError(fmt.Errorf("Invalid certificate"))
+		}
+
+		var err error
+		cert, err = x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return InternalError(err)
+		}
+	}
+
+	config, err := shared.GetTLSConfig("", "", "", cert)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	push := false
+	if req.Source.Mode == "push" {
+		push = true
+	}
+
+	// Check that the name isn't already in use.
+	poolID, err := d.db.StoragePoolGetID(poolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, err = d.db.StoragePoolVolumeGetTypeID(req.Name, storagePoolVolumeTypeCustom, poolID)
+	if err != db.NoSuchObjectError {
+		if err != nil {
+			return SmartError(err)
+		}
+
+		return Conflict
+	}
+
+	// Create the (empty) storage volume the data is received into.
+	err = storagePoolVolumeCreateInternal(d.State(), poolName, req.Name, req.Description, req.Type, req.Config)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	s, err := storagePoolVolumeInit(d.State(), poolName, req.Name, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	revert := func() {
+		storagePoolVolumeSnapshotsDelete(d.State(), poolName, req.Name)
+		s.StoragePoolVolumeDelete()
+		d.db.StoragePoolVolumeDelete(req.Name, storagePoolVolumeTypeCustom, poolID)
+	}
+
+	migrationArgs := MigrationSinkArgs{
+		Url: req.Source.Operation,
+		Dialer: websocket.Dialer{
+			TLSClientConfig: config,
+			NetDial:         shared.RFC3493Dialer},
+		Secrets: req.Source.Websockets,
+		Push:    push,
+		Storage: s,
+	}
+
+	sink, err := NewMigrationSink(&migrationArgs)
+	if err != nil {
+		revert()
+		return InternalError(err)
+	}
+
+	run := func(op *operation) error {
+		// And finally run the migration.
+		err := sink.DoStorage(d.State(), poolName, req.Name, op)
+		if err != nil {
+			logger.Error("Error during migration sink", log.Ctx{"err": err})
+			revert()
+			return fmt.Errorf("Error transferring storage volume: %s", err)
+		}
+
+		return nil
+	}
+
+	resources := map[string][]string{}
+	resources["storage-pools"] = []string{fmt.Sprintf("%s/volumes/%s/%s", poolName, storagePoolVolumeTypeNameCustom, req.Name)}
+
+	var op *operation
+	if push {
+		op, err = operationCreate("", operationClassWebsocket, resources, sink.Metadata(), run, nil, sink.Connect)
+		if err != nil {
+			return InternalError(err)
+		}
+	} else {
+		op, err = operationCreate("", operationClassTask, resources, nil, run, nil, nil)
+		if err != nil {
+			return InternalError(err)
+		}
+	}
+
+	return OperationResponse(op)
+}
+
 var storagePoolVolumesTypeCmd = Command{name: "storage-pools/{name}/volumes/{type}", get: storagePoolVolumesTypeGet, post: storagePoolVolumesTypePost}
 
 // /1.0/storage-pools/{name}/volumes/{type}/{name}
@@ -213,6 +443,10 @@ func storagePoolVolumeTypePost(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("No name provided"))
 	}
 
+	if strings.Contains(req.Name, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("Storage volume names may not contain slashes"))
+	}
+
 	// We currently only allow to create storage volumes of type
 	// storagePoolVolumeTypeCustom. So check, that nothing else was
 	// requested.
@@ -220,6 +454,10 @@ func storagePoolVolumeTypePost(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("Renaming storage volumes of type %s is not allowed", volumeTypeName))
 	}
 
+	if strings.Contains(volumeName, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("Storage volume snapshots must be renamed through the snapshots endpoint"))
+	}
+
 	// Retrieve ID of the storage pool (and check if the storage pool
 	// exists).
 	poolID, err := d.db.StoragePoolGetID(poolName)
@@ -227,6 +465,20 @@ func storagePoolVolumeTypePost(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	if req.Migration {
+		return storagePoolVolumeTypePostMigration(d.State(), poolName, volumeName, req)
+	}
+
+	// Storage volumes with snapshots can't be renamed.
+	snapshots, err := d.db.StoragePoolVolumeSnapshotsGetType(volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if len(snapshots) > 0 {
+		return BadRequest(fmt.Errorf("Renaming storage volumes with snapshots is not supported"))
+	}
+
 	// Check that the name isn't already in use.
 	_, err = d.db.StoragePoolVolumeGetTypeID(req.Name,
 		storagePoolVolumeTypeCustom, poolID)
@@ -247,6 +499,48 @@ func storagePoolVolumeTypePost(d *Daemon, r *http.Request) Response {
 	return EmptySyncResponse
 }
 
+func storagePoolVolumeTypePostMigration(state *state.State, poolName string, volumeName string, req api.StorageVolumePost) Response {
+	s, err := storagePoolVolumeInit(state, poolName, volumeName, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	ws, err := NewStorageMigrationSource(s, req.VolumeOnly)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	resources := map[string][]string{}
+	resources["storage-pools"] = []string{fmt.Sprintf("%s/volumes/%s/%s", poolName, storagePoolVolumeTypeNameCustom, volumeName)}
+
+	run := func(op *operation) error {
+		return ws.DoStorage(state, poolName, volumeName, op)
+	}
+
+	if req.Target != nil {
+		// Push mode
+		err := ws.ConnectTarget(api.ContainerPostTarget(*req.Target))
+		if err != nil {
+			return InternalError(err)
+		}
+
+		op, err := operationCreate("", operationClassTask, resources, nil, run, nil, nil)
+		if err != nil {
+			return InternalError(err)
+		}
+
+		return OperationResponse(op)
+	}
+
+	// Pull mode
+	op, err := operationCreate("", operationClassWebsocket, resources, ws.Metadata(), run, nil, ws.Connect)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
 // /1.0/storage-pools/{pool}/volumes/{type}/{name}
 // Get storage volume of a given volume type on a given storage pool.
 func storagePoolVolumeTypeGet(d *Daemon, r *http.Request) Response {
@@ -340,6 +634,10 @@ func storagePoolVolumeTypePut(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
+	if req.Restore != "" {
+		return storagePoolVolumeRestore(d, poolName, volumeName, volumeType, req.Restore)
+	}
+
 	// Validate the configuration
 	err = storageVolumeValidateConfig(volumeName, req.Config, pool)
 	if err != nil {
@@ -354,6 +652,52 @@ func storagePoolVolumeTypePut(d *Daemon, r *http.Request) Response {
 	return EmptySyncResponse
 }
 
+func storagePoolVolumeRestore(d *Daemon, poolName string, volumeName string, volumeType int, snapshotName string) Response {
+	if volumeType != storagePoolVolumeTypeCustom {
+		return BadRequest(fmt.Errorf("Only custom storage volumes can be restored"))
+	}
+
+	// Check that the volume isn't in use by a running container.
+	usedBy, err := storagePoolVolumeUsedByContainersGet(d.State(), volumeName, storagePoolVolumeTypeNameCustom)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	for _, ctName := range usedBy {
+		c, err := containerLoadByName(d.State(), ctName)
+		if err != nil {
+			return SmartError(err)
+		}
+
+		if c.IsRunning() {
+			return BadRequest(fmt.Errorf("The storage volume is still in use by running containers"))
+		}
+	}
+
+	poolID, err := d.db.StoragePoolGetID(poolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	fullSnapshotName := fmt.Sprintf("%s%s%s", volumeName, shared.SnapshotDelimiter, snapshotName)
+	_, err = d.db.StoragePoolVolumeGetTypeID(fullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	s, err := storagePoolVolumeInit(d.State(), poolName, volumeName, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	err = s.StoragePoolVolumeRestore(fullSnapshotName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	return EmptySyncResponse
+}
+
 // /1.0/storage-pools/{pool}/volumes/{type}/{name}
 func storagePoolVolumeTypePatch(d *Daemon, r *http.Request) Response {
 	// Get the name of the storage volume.
@@ -451,7 +795,9 @@ func storagePoolVolumeTypeDelete(d *Daemon, r *http.Request) Response {
 
 	switch volumeType {
 	case storagePoolVolumeTypeCustom:
-		// allowed
+		if strings.Contains(volumeName, shared.SnapshotDelimiter) {
+			return BadRequest(fmt.Errorf("Storage volume snapshots must be deleted through the snapshots endpoint"))
+		}
 	case storagePoolVolumeTypeImage:
 		// allowed
 	default:
@@ -482,6 +828,11 @@ func storagePoolVolumeTypeDelete(d *Daemon, r *http.Request) Response {
 
 	switch volumeType {
 	case storagePoolVolumeTypeCustom:
+		err = storagePoolVolumeSnapshotsDelete(d.State(), poolName, volumeName)
+		if err != nil {
+			return SmartError(err)
+		}
+
 		err = s.StoragePoolVolumeDelete()
 	case storagePoolVolumeTypeImage:
 		err = s.ImageDelete(volumeName)
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var storagePoolVolumeSnapshotsTypeCmd = Command{
+	name: "storage-pools/{pool}/volumes/{type}/{name}/snapshots",
+	get:  storagePoolVolumeSnapshotsTypeGet,
+	post: storagePoolVolumeSnapshotsTypePost,
+}
+
+var storagePoolVolumeSnapshotTypeCmd = Command{
+	name:   "storage-pools/{pool}/volumes/{type}/{name}/snapshots/{snapshotName}",
+	get:    storagePoolVolumeSnapshotTypeGet,
+	post:   storagePoolVolumeSnapshotTypePost,
+	delete: storagePoolVolumeSnapshotTypeDelete,
+}
+
+// storagePoolVolumeSnapshotTypeCheck validates the pool and volume type of a
+// snapshot request and returns the ID of the storage pool.
+func storagePoolVolumeSnapshotTypeCheck(d *Daemon, poolName string, volumeTypeName string) (int64, Response) {
+	// Convert the volume type name to our internal integer representation.
+	volumeType, err := storagePoolVolumeTypeNameToType(volumeTypeName)
+	if err != nil {
+		return -1, BadRequest(err)
+	}
+
+	// Only custom storage volumes can be snapshotted through the storage
+	// api.
+	if volumeType != storagePoolVolumeTypeCustom {
+		return -1, BadRequest(fmt.Errorf("Invalid storage volume type %s", volumeTypeName))
+	}
+
+	// Retrieve ID of the storage pool (and check if the storage pool
+	// exists).
+	poolID, err := d.db.StoragePoolGetID(poolName)
+	if err != nil {
+		return -1, SmartError(err)
+	}
+
+	return poolID, nil
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots
+// List all snapshots of a given storage volume.
+func storagePoolVolumeSnapshotsTypeGet(d *Daemon, r *http.Request) Response {
+	poolName := mux.Vars(r)["pool"]
+	volumeTypeName := mux.Vars(r)["type"]
+	volumeName := mux.Vars(r)["name"]
+
+	recursion, err := strconv.Atoi(r.FormValue("recursion"))
+	if err != nil {
+		recursion = 0
+	}
+
+	poolID, resp := storagePoolVolumeSnapshotTypeCheck(d, poolName, volumeTypeName)
+	if resp != nil {
+		return resp
+	}
+
+	// Check that the storage volume exists.
+	_, err = d.db.StoragePoolVolumeGetTypeID(volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	snapshots, err := d.db.StoragePoolVolumeSnapshotsGetType(volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []*api.StorageVolumeSnapshot{}
+	for _, snapshot := range snapshots {
+		if recursion == 0 {
+			_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshot)
+			url := fmt.Sprintf("/%s/storage-pools/%s/volumes/%s/%s/snapshots/%s", version.APIVersion, poolName, volumeTypeName, volumeName, snapOnlyName)
+			resultString = append(resultString, url)
+		} else {
+			_, vol, err := d.db.StoragePoolVolumeGetType(snapshot, storagePoolVolumeTypeCustom, poolID)
+			if err != nil {
+				continue
+			}
+
+			resultMap = append(resultMap, &api.StorageVolumeSnapshot{
+				Name:        vol.Name,
+				Config:      vol.Config,
+				Description: vol.Description,
+			})
+		}
+	}
+
+	if recursion == 0 {
+		return SyncResponse(true, resultString)
+	}
+
+	return SyncResponse(true, resultMap)
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots
+// Create a new snapshot of a given storage volume.
+func storagePoolVolumeSnapshotsTypePost(d *Daemon, r *http.Request) Response {
+	poolName := mux.Vars(r)["pool"]
+	volumeTypeName := mux.Vars(r)["type"]
+	volumeName := mux.Vars(r)["name"]
+
+	poolID, resp := storagePoolVolumeSnapshotTypeCheck(d, poolName, volumeTypeName)
+	if resp != nil {
+		return resp
+	}
+
+	// Check that the storage volume exists.
+	_, err := d.db.StoragePoolVolumeGetTypeID(volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	req := api.StorageVolumeSnapshotsPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	snapshots, err := d.db.StoragePoolVolumeSnapshotsGetType(volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if req.Name == "" {
+		// come up with a name
+		for i := 0; ; i++ {
+			req.Name = fmt.Sprintf("snap%d", i)
+			if !shared.StringInSlice(volumeName+shared.SnapshotDelimiter+req.Name, snapshots) {
+				break
+			}
+		}
+	}
+
+	if strings.Contains(req.Name, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("Snapshot names may not contain slashes"))
+	}
+
+	// Check that the name isn't already in use.
+	if shared.StringInSlice(volumeName+shared.SnapshotDelimiter+req.Name, snapshots) {
+		return Conflict
+	}
+
+	snapshot := func(op *operation) error {
+		return storagePoolVolumeSnapshotCreateInternal(d.State(), poolName, volumeName, req.Name)
+	}
+
+	resources := map[string][]string{}
+	resources["storage-pools"] = []string{fmt.Sprintf("%s/volumes/%s/%s", poolName, volumeTypeName, volumeName)}
+
+	op, err := operationCreate("", operationClassTask, resources, nil, snapshot, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots/{snapshotName}
+// Get a snapshot of a given storage volume.
+func storagePoolVolumeSnapshotTypeGet(d *Daemon, r *http.Request) Response {
+	poolName := mux.Vars(r)["pool"]
+	volumeTypeName := mux.Vars(r)["type"]
+	volumeName := mux.Vars(r)["name"]
+	snapshotName := mux.Vars(r)["snapshotName"]
+
+	poolID, resp := storagePoolVolumeSnapshotTypeCheck(d, poolName, volumeTypeName)
+	if resp != nil {
+		return resp
+	}
+
+	fullSnapshotName := volumeName + shared.SnapshotDelimiter + snapshotName
+	_, volume, err := d.db.StoragePoolVolumeGetType(fullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	snapshot := api.StorageVolumeSnapshot{
+		Name:        volume.Name,
+		Config:      volume.Config,
+		Description: volume.Description,
+	}
+
+	return SyncResponse(true, &snapshot)
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots/{snapshotName}
+// Rename a snapshot of a given storage volume.
+func storagePoolVolumeSnapshotTypePost(d *Daemon, r *http.Request) Response {
+	poolName := mux.Vars(r)["pool"]
+	volumeTypeName := mux.Vars(r)["type"]
+	volumeName := mux.Vars(r)["name"]
+	snapshotName := mux.Vars(r)["snapshotName"]
+
+	poolID, resp := storagePoolVolumeSnapshotTypeCheck(d, poolName, volumeTypeName)
+	if resp != nil {
+		return resp
+	}
+
+	req := api.StorageVolumeSnapshotPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// Sanity checks.
+	if req.Name == "" {
+		return BadRequest(fmt.Errorf("No name provided"))
+	}
+
+	if strings.Contains(req.Name, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("Snapshot names may not contain slashes"))
+	}
+
+	fullSnapshotName := volumeName + shared.SnapshotDelimiter + snapshotName
+	newFullSnapshotName := volumeName + shared.SnapshotDelimiter + req.Name
+
+	// Check that the name isn't already in use.
+	_, err = d.db.StoragePoolVolumeGetTypeID(newFullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+	if err == nil {
+		return Conflict
+	}
+
+	s, err := storagePoolVolumeInit(d.State(), poolName, fullSnapshotName, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	rename := func(op *operation) error {
+		return s.StoragePoolVolumeSnapshotRename(newFullSnapshotName)
+	}
+
+	resources := map[string][]string{}
+	resources["storage-pools"] = []string{fmt.Sprintf("%s/volumes/%s/%s", poolName, volumeTypeName, volumeName)}
+
+	op, err := operationCreate("", operationClassTask, resources, nil, rename, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots/{snapshotName}
+// Delete a snapshot of a given storage volume.
+func storagePoolVolumeSnapshotTypeDelete(d *Daemon, r *http.Request) Response {
+	poolName := mux.Vars(r)["pool"]
+	volumeTypeName := mux.Vars(r)["type"]
+	volumeName := mux.Vars(r)["name"]
+	snapshotName := mux.Vars(r)["snapshotName"]
+
+	_, resp := storagePoolVolumeSnapshotTypeCheck(d, poolName, volumeTypeName)
+	if resp != nil {
+		return resp
+	}
+
+	fullSnapshotName := volumeName + shared.SnapshotDelimiter + snapshotName
+	s, err := storagePoolVolumeInit(d.State(), poolName, fullSnapshotName, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	remove := func(op *operation) error {
+		return s.StoragePoolVolumeSnapshotDelete()
+	}
+
+	resources := map[string][]string{}
+	resources["storage-pools"] = []string{fmt.Sprintf("%s/volumes/%s/%s", poolName, volumeTypeName, volumeName)}
+
+	op, err := operationCreate("", operationClassTask, resources, nil, remove, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
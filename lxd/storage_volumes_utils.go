@@ -8,6 +8,8 @@ import (
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/version"
 )
 
@@ -367,3 +369,172 @@ func storagePoolVolumeCreateInternal(state *state.State, poolName string, volume
 
 	return nil
 }
+
+func storagePoolVolumeCopyInternal(state *state.State, poolName string, req *api.StorageVolumesPost) error {
+	// Check that the source volume exists.
+	sourcePoolID, err := state.DB.StoragePoolGetID(req.Source.Pool)
+	if err != nil {
+		return err
+	}
+
+	_, sourceVolume, err := state.DB.StoragePoolVolumeGetType(req.Source.Name, storagePoolVolumeTypeCustom, sourcePoolID)
+	if err != nil {
+		return err
+	}
+
+	// The new volume inherits the configuration of its source unless
+	// specified otherwise. Driver specific keys don't make sense on a
+	// different pool though, so only the size is kept then.
+	volumeConfig := req.Config
+	if volumeConfig == nil {
+		volumeConfig = map[string]string{}
+		for k, v := range sourceVolume.Config {
+			if req.Source.Pool != poolName && k != "size" {
+				continue
+			}
+
+			volumeConfig[k] = v
+		}
+	}
+
+	err = storagePoolVolumeDBCreate(state, poolName, req.Name, req.Description, req.Type, volumeConfig)
+	if err != nil {
+		return err
+	}
+
+	s, err := storagePoolVolumeInit(state, poolName, req.Name, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	poolID, _, _ := s.GetContainerPoolInfo()
+
+	// Copy storage volume.
+	err = s.StoragePoolVolumeCopy(&req.Source)
+	if err != nil {
+		s.StoragePoolVolumeDelete()
+		state.DB.StoragePoolVolumeDelete(req.Name, storagePoolVolumeTypeCustom, poolID)
+		return err
+	}
+
+	return nil
+}
+
+// storagePoolVolumeCopyRsync creates the (empty) storage volume backing s and
+// then copies the content of the source custom volume into it using rsync.
+// This works across storage pools and storage drivers.
+func storagePoolVolumeCopyRsync(state *state.State, s storage, poolName string, volumeName string, source *api.StorageVolumeSource, bwlimit string) error {
+	srcStorage, err := storagePoolVolumeInit(state, source.Pool, source.Name, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	err = s.StoragePoolVolumeCreate()
+	if err != nil {
+		return err
+	}
+
+	ourMount, err := srcStorage.StoragePoolVolumeMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer srcStorage.StoragePoolVolumeUmount()
+	}
+
+	ourMount, err = s.StoragePoolVolumeMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer s.StoragePoolVolumeUmount()
+	}
+
+	srcMountPoint := getStoragePoolVolumeMountPoint(source.Pool, source.Name)
+	dstMountPoint := getStoragePoolVolumeMountPoint(poolName, volumeName)
+	output, err := rsyncLocalCopy(srcMountPoint, dstMountPoint, bwlimit)
+	if err != nil {
+		return fmt.Errorf("Failed to rsync storage volume: %s: %s", output, err)
+	}
+
+	return nil
+}
+
+func storagePoolVolumeSnapshotCreateInternal(state *state.State, poolName string, volumeName string, snapshotName string) error {
+	poolID, err := state.DB.StoragePoolGetID(poolName)
+	if err != nil {
+		return err
+	}
+
+	_, volume, err := state.DB.StoragePoolVolumeGetType(volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return err
+	}
+
+	// The snapshot records the configuration of the volume at the time
+	// it was taken.
+	fullSnapshotName := fmt.Sprintf("%s%s%s", volumeName, shared.SnapshotDelimiter, snapshotName)
+	err = storagePoolVolumeSnapshotDBCreate(state, poolName, fullSnapshotName, volume.Description, volume.Config)
+	if err != nil {
+		return err
+	}
+
+	s, err := storagePoolVolumeInit(state, poolName, fullSnapshotName, storagePoolVolumeTypeCustom)
+	if err != nil {
+		state.DB.StoragePoolVolumeDelete(fullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+		return err
+	}
+
+	err = s.StoragePoolVolumeSnapshotCreate()
+	if err != nil {
+		state.DB.StoragePoolVolumeDelete(fullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+		return err
+	}
+
+	return nil
+}
+
+// storagePoolVolumeSnapshotDBCreate creates the database entry of a custom
+// storage volume snapshot.
+func storagePoolVolumeSnapshotDBCreate(state *state.State, poolName string, fullSnapshotName string, description string, config map[string]string) error {
+	poolID, err := state.DB.StoragePoolGetID(poolName)
+	if err != nil {
+		return err
+	}
+
+	_, err = state.DB.StoragePoolVolumeCreate(fullSnapshotName, description, storagePoolVolumeTypeCustom, poolID, config)
+	if err != nil {
+		return fmt.Errorf("Error inserting snapshot %s into database: %s", fullSnapshotName, err)
+	}
+
+	return nil
+}
+
+// storagePoolVolumeSnapshotsDelete deletes all snapshots of a custom storage
+// volume.
+func storagePoolVolumeSnapshotsDelete(state *state.State, poolName string, volumeName string) error {
+	poolID, err := state.DB.StoragePoolGetID(poolName)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := state.DB.StoragePoolVolumeSnapshotsGetType(volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		s, err := storagePoolVolumeInit(state, poolName, snapshot, storagePoolVolumeTypeCustom)
+		if err != nil {
+			return err
+		}
+
+		err = s.StoragePoolVolumeSnapshotDelete()
+		if err != nil {
+			logger.Errorf("Failed to delete storage volume snapshot \"%s\": %s", snapshot, err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -632,6 +632,175 @@ func (s *storageZfs) StoragePoolVolumeRename(newName string) error {
 		storagePoolVolumeTypeCustom, s.poolID)
 }
 
+func (s *storageZfs) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Infof("Copying ZFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+
+	if source.Pool != s.pool.Name {
+		bwlimit := s.pool.Config["rsync.bwlimit"]
+		err := storagePoolVolumeCopyRsync(s.s, s, s.pool.Name, s.volume.Name, source, bwlimit)
+		if err != nil {
+			return err
+		}
+
+		logger.Infof("Copied ZFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+		return nil
+	}
+
+	poolName := s.getOnDiskPoolName()
+	sourcefs := fmt.Sprintf("custom/%s", source.Name)
+	targetfs := fmt.Sprintf("custom/%s", s.volume.Name)
+	snapshotSuffix := uuid.NewRandom().String()
+
+	err := zfsPoolVolumeSnapshotCreate(poolName, sourcefs, snapshotSuffix)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := zfsPoolVolumeSnapshotDestroy(poolName, sourcefs, snapshotSuffix)
+		if err != nil {
+			logger.Warnf("Failed to delete temporary ZFS snapshot \"%s/%s@%s\". Manual cleanup needed.", poolName, sourcefs, snapshotSuffix)
+		}
+	}()
+
+	zfsSendCmd := exec.Command("zfs", "send", fmt.Sprintf("%s/%s@%s", poolName, sourcefs, snapshotSuffix))
+	zfsRecvCmd := exec.Command("zfs", "receive", fmt.Sprintf("%s/%s", poolName, targetfs))
+
+	zfsRecvCmd.Stdin, _ = zfsSendCmd.StdoutPipe()
+	zfsRecvCmd.Stdout = os.Stdout
+	zfsRecvCmd.Stderr = os.Stderr
+
+	err = zfsRecvCmd.Start()
+	if err != nil {
+		return err
+	}
+
+	err = zfsSendCmd.Run()
+	if err != nil {
+		return err
+	}
+
+	err = zfsRecvCmd.Wait()
+	if err != nil {
+		return err
+	}
+
+	err = zfsPoolVolumeSnapshotDestroy(poolName, targetfs, snapshotSuffix)
+	if err != nil {
+		return err
+	}
+
+	err = zfsPoolVolumeSet(poolName, targetfs, "canmount", "noauto")
+	if err != nil {
+		return err
+	}
+
+	customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	err = zfsPoolVolumeSet(poolName, targetfs, "mountpoint", customPoolVolumeMntPoint)
+	if err != nil {
+		return err
+	}
+
+	if !shared.IsMountPoint(customPoolVolumeMntPoint) {
+		zfsMount(poolName, targetfs)
+	}
+
+	logger.Infof("Copied ZFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\"", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) StoragePoolVolumeRestore(snapshotName string) error {
+	logger.Infof("Restoring ZFS storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, snapshotName, s.pool.Name)
+
+	_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshotName)
+	fs := fmt.Sprintf("custom/%s", s.volume.Name)
+	poolName := s.getOnDiskPoolName()
+
+	// ZFS can only rollback to the most recent snapshot.
+	snaps, err := zfsPoolListSnapshots(poolName, fs)
+	if err != nil {
+		return err
+	}
+
+	snapshotSuffix := fmt.Sprintf("snapshot-%s", snapOnlyName)
+	if len(snaps) == 0 || snaps[len(snaps)-1] != snapshotSuffix {
+		return fmt.Errorf("ZFS can only restore from the latest snapshot. Delete newer snapshots or copy the snapshot into a new volume instead")
+	}
+
+	err = zfsPoolVolumeSnapshotRestore(poolName, fs, snapshotSuffix)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Restored ZFS storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, snapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) StoragePoolVolumeSnapshotCreate() error {
+	logger.Infof("Creating ZFS storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+
+	sourceName, snapOnlyName, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	fs := fmt.Sprintf("custom/%s", sourceName)
+
+	err := zfsPoolVolumeSnapshotCreate(s.getOnDiskPoolName(), fs, fmt.Sprintf("snapshot-%s", snapOnlyName))
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Created ZFS storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) StoragePoolVolumeSnapshotDelete() error {
+	logger.Infof("Deleting ZFS storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+
+	sourceName, snapOnlyName, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	fs := fmt.Sprintf("custom/%s", sourceName)
+	snapshotSuffix := fmt.Sprintf("snapshot-%s", snapOnlyName)
+
+	poolName := s.getOnDiskPoolName()
+	if zfsFilesystemEntityExists(poolName, fmt.Sprintf("%s@%s", fs, snapshotSuffix)) {
+		err := zfsPoolVolumeSnapshotDestroy(poolName, fs, snapshotSuffix)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.db.StoragePoolVolumeDelete(
+		s.volume.Name,
+		storagePoolVolumeTypeCustom,
+		s.poolID)
+	if err != nil {
+		logger.Errorf(`Failed to delete database entry for ZFS `+
+			`storage volume snapshot "%s" on storage pool "%s"`,
+			s.volume.Name, s.pool.Name)
+	}
+
+	logger.Infof("Deleted ZFS storage volume snapshot \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) StoragePoolVolumeSnapshotRename(newName string) error {
+	logger.Infof(`Renaming ZFS storage volume snapshot on storage pool "%s" from "%s" to "%s"`,
+		s.pool.Name, s.volume.Name, newName)
+
+	sourceName, oldSnapOnlyName, _ := containerGetParentAndSnapshotName(s.volume.Name)
+	_, newSnapOnlyName, _ := containerGetParentAndSnapshotName(newName)
+	fs := fmt.Sprintf("custom/%s", sourceName)
+
+	err := zfsPoolVolumeSnapshotRename(s.getOnDiskPoolName(), fs,
+		fmt.Sprintf("snapshot-%s", oldSnapOnlyName),
+		fmt.Sprintf("snapshot-%s", newSnapOnlyName))
+	if err != nil {
+		return err
+	}
+
+	logger.Infof(`Renamed ZFS storage volume snapshot on storage pool "%s" from "%s" to "%s"`,
+		s.pool.Name, s.volume.Name, newName)
+
+	return s.db.StoragePoolVolumeRename(s.volume.Name, newName,
+		storagePoolVolumeTypeCustom, s.poolID)
+}
+
 // Things we don't need to care about
 func (s *storageZfs) ContainerMount(c container) (bool, error) {
 	name := c.Name()
@@ -1793,6 +1962,152 @@ func (s *storageZfs) ContainerSnapshotCreateEmpty(snapshotContainer container) e
 	return nil
 }
 
+func (s *storageZfs) ContainerBackupCreate(backup backup, sourceContainer container, target string) error {
+	logger.Debugf("Creating backup \"%s\" of container \"%s\" on ZFS storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+
+	if !backup.optimizedStorage {
+		bwlimit := s.pool.Config["rsync.bwlimit"]
+		return rsyncContainerBackupCreate(backup, sourceContainer, target, bwlimit)
+	}
+
+	poolName := s.getOnDiskPoolName()
+	fs := fmt.Sprintf("containers/%s", sourceContainer.Name())
+
+	// Send the snapshots, each one relative to its predecessor.
+	parent := ""
+	if !backup.containerOnly {
+		snapshots, err := sourceContainer.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		for _, snap := range snapshots {
+			_, snapName, _ := containerGetParentAndSnapshotName(snap.Name())
+			zfsSnapshot := fmt.Sprintf("%s/%s@snapshot-%s", poolName, fs, snapName)
+
+			args := []string{"send"}
+			if parent != "" {
+				args = append(args, "-i", parent)
+			}
+			args = append(args, zfsSnapshot)
+
+			err := backupSend(filepath.Join(target, "snapshots", fmt.Sprintf("%s.bin", snapName)), "zfs", args...)
+			if err != nil {
+				return err
+			}
+
+			parent = zfsSnapshot
+		}
+	}
+
+	// Send a temporary snapshot of the container.
+	backupSnapName := fmt.Sprintf("backup-%s", uuid.NewRandom().String())
+	err := zfsPoolVolumeSnapshotCreate(poolName, fs, backupSnapName)
+	if err != nil {
+		return err
+	}
+	defer zfsPoolVolumeSnapshotDestroy(poolName, fs, backupSnapName)
+
+	args := []string{"send"}
+	if parent != "" {
+		args = append(args, "-i", parent)
+	}
+	args = append(args, fmt.Sprintf("%s/%s@%s", poolName, fs, backupSnapName))
+
+	err = backupSend(filepath.Join(target, "container.bin"), "zfs", args...)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Created backup \"%s\" of container \"%s\" on ZFS storage pool \"%s\".", backup.Name(), sourceContainer.Name(), s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) ContainerBackupLoad(info backupInfo, tarball string) error {
+	logger.Debugf("Loading ZFS storage volume for backup of container \"%s\" on storage pool \"%s\".", info.Name, s.pool.Name)
+
+	poolName := s.getOnDiskPoolName()
+	fs := fmt.Sprintf("containers/%s", info.Name)
+
+	if len(info.Snapshots) > 0 {
+		snapshotMntPointSymlinkTarget := shared.VarPath("storage-pools", s.pool.Name, "snapshots", info.Name)
+		snapshotMntPointSymlink := shared.VarPath("snapshots", info.Name)
+		if !shared.PathExists(snapshotMntPointSymlink) {
+			err := os.Symlink(snapshotMntPointSymlinkTarget, snapshotMntPointSymlink)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, snap := range info.Snapshots {
+		_, snapName, _ := containerGetParentAndSnapshotName(snap.Name)
+		zfsSnapshot := fmt.Sprintf("%s/%s@snapshot-%s", poolName, fs, snapName)
+		err := backupReceive(tarball, fmt.Sprintf("backup/snapshots/%s.bin", snapName), "zfs", "receive", "-F", "-u", zfsSnapshot)
+		if err != nil {
+			return err
+		}
+
+		snapshotMntPoint := getSnapshotMountPoint(s.pool.Name, snap.Name)
+		if !shared.PathExists(snapshotMntPoint) {
+			err := os.MkdirAll(snapshotMntPoint, 0700)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := backupReceive(tarball, "backup/container.bin", "zfs", "receive", "-F", "-u", fmt.Sprintf("%s/%s", poolName, fs))
+	if err != nil {
+		return err
+	}
+
+	// Remove the temporary snapshot the container was sent from.
+	zfsSnapshots, err := zfsPoolListSnapshots(poolName, fs)
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range zfsSnapshots {
+		if !strings.HasPrefix(snap, "backup-") {
+			continue
+		}
+
+		err := zfsPoolVolumeSnapshotDestroy(poolName, fs, snap)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Set up the container's mountpoint the same way ContainerCreate()
+	// does.
+	containerPoolVolumeMntPoint := getContainerMountPoint(s.pool.Name, info.Name)
+	err = zfsPoolVolumeSet(poolName, fs, "canmount", "noauto")
+	if err != nil {
+		return err
+	}
+
+	err = zfsPoolVolumeSet(poolName, fs, "mountpoint", containerPoolVolumeMntPoint)
+	if err != nil {
+		return err
+	}
+
+	err = zfsMount(poolName, fs)
+	if err != nil {
+		return err
+	}
+	defer zfsUmount(poolName, fs, containerPoolVolumeMntPoint)
+
+	privileged := shared.IsTrue(info.Container.Config["security.privileged"])
+	err = createContainerMountpoint(containerPoolVolumeMntPoint, containerPath(info.Name, false), privileged)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Loaded ZFS storage volume for backup of container \"%s\" on storage pool \"%s\".", info.Name, s.pool.Name)
+	return nil
+}
+
 // - create temporary directory ${LXD_DIR}/images/lxd_images_
 // - create new zfs volume images/<fingerprint>
 // - mount the zfs volume on ${LXD_DIR}/images/lxd_images_
@@ -2004,9 +2319,20 @@ func (s *zfsMigrationSourceDriver) Snapshots() []container {
 func (s *zfsMigrationSourceDriver) send(conn *websocket.Conn, zfsName string, zfsParent string, readWrapper func(io.ReadCloser) io.ReadCloser) error {
 	sourceParentName, _, _ := containerGetParentAndSnapshotName(s.container.Name())
 	poolName := s.zfs.getOnDiskPoolName()
-	args := []string{"send", fmt.Sprintf("%s/containers/%s@%s", poolName, sourceParentName, zfsName)}
+	zfsSnapshot := fmt.Sprintf("%s/containers/%s@%s", poolName, sourceParentName, zfsName)
+	if zfsParent != "" {
+		zfsParent = fmt.Sprintf("%s/containers/%s@%s", poolName, s.container.Name(), zfsParent)
+	}
+
+	return zfsMigrationSend(conn, zfsSnapshot, zfsParent, readWrapper)
+}
+
+// zfsMigrationSend sends the given ZFS snapshot over the websocket using zfs
+// send, optionally only sending the delta to the parent snapshot.
+func zfsMigrationSend(conn *websocket.Conn, zfsSnapshot string, zfsParent string, readWrapper func(io.ReadCloser) io.ReadCloser) error {
+	args := []string{"send", zfsSnapshot}
 	if zfsParent != "" {
-		args = append(args, "-i", fmt.Sprintf("%s/containers/%s@%s", poolName, s.container.Name(), zfsParent))
+		args = append(args, "-i", zfsParent)
 	}
 
 	cmd := exec.Command("zfs", args...)
@@ -2165,45 +2491,48 @@ func (s *storageZfs) MigrationSource(ct container, containerOnly bool) (Migratio
 	return &driver, nil
 }
 
-func (s *storageZfs) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *idmap.IdmapSet, op *operation, containerOnly bool) error {
-	poolName := s.getOnDiskPoolName()
-	zfsRecv := func(zfsName string, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
-		zfsFsName := fmt.Sprintf("%s/%s", poolName, zfsName)
-		args := []string{"receive", "-F", "-u", zfsFsName}
-		cmd := exec.Command("zfs", args...)
+// zfsMigrationRecv receives a ZFS snapshot sent by zfsMigrationSend into the
+// given dataset of the storage pool.
+func (s *storageZfs) zfsMigrationRecv(conn *websocket.Conn, zfsName string, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
+	zfsFsName := fmt.Sprintf("%s/%s", s.getOnDiskPoolName(), zfsName)
+	args := []string{"receive", "-F", "-u", zfsFsName}
+	cmd := exec.Command("zfs", args...)
 
-		stdin, err := cmd.StdinPipe()
-		if err != nil {
-			return err
-		}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
 
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return err
-		}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
 
-		if err := cmd.Start(); err != nil {
-			return err
-		}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
 
-		writePipe := io.WriteCloser(stdin)
-		if writeWrapper != nil {
-			writePipe = writeWrapper(stdin)
-		}
+	writePipe := io.WriteCloser(stdin)
+	if writeWrapper != nil {
+		writePipe = writeWrapper(stdin)
+	}
 
-		<-shared.WebsocketRecvStream(writePipe, conn)
+	<-shared.WebsocketRecvStream(writePipe, conn)
 
-		output, err := ioutil.ReadAll(stderr)
-		if err != nil {
-			logger.Debugf("problem reading zfs recv stderr %s.", err)
-		}
+	output, err := ioutil.ReadAll(stderr)
+	if err != nil {
+		logger.Debugf("problem reading zfs recv stderr %s.", err)
+	}
 
-		err = cmd.Wait()
-		if err != nil {
-			logger.Errorf("problem with zfs recv: %s.", string(output))
-		}
-		return err
+	err = cmd.Wait()
+	if err != nil {
+		logger.Errorf("problem with zfs recv: %s.", string(output))
 	}
+	return err
+}
+
+func (s *storageZfs) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *idmap.IdmapSet, op *operation, containerOnly bool) error {
+	poolName := s.getOnDiskPoolName()
 
 	/* In some versions of zfs we can write `zfs recv -F` to mounted
 	 * filesystems, and in some versions we can't. So, let's always unmount
@@ -2267,7 +2596,7 @@ func (s *storageZfs) MigrationSink(live bool, container container, snapshots []*
 
 		wrapper := StorageProgressWriter(op, "fs_progress", snap.GetName())
 		name := fmt.Sprintf("containers/%s@snapshot-%s", container.Name(), snap.GetName())
-		if err := zfsRecv(name, wrapper); err != nil {
+		if err := s.zfsMigrationRecv(conn, name, wrapper); err != nil {
 			return err
 		}
 
@@ -2300,14 +2629,14 @@ func (s *storageZfs) MigrationSink(live bool, container container, snapshots []*
 
 	/* finally, do the real container */
 	wrapper := StorageProgressWriter(op, "fs_progress", container.Name())
-	if err := zfsRecv(zfsName, wrapper); err != nil {
+	if err := s.zfsMigrationRecv(conn, zfsName, wrapper); err != nil {
 		return err
 	}
 
 	if live {
 		/* and again for the post-running snapshot if this was a live migration */
 		wrapper := StorageProgressWriter(op, "fs_progress", container.Name())
-		if err := zfsRecv(zfsName, wrapper); err != nil {
+		if err := s.zfsMigrationRecv(conn, zfsName, wrapper); err != nil {
 			return err
 		}
 	}
@@ -2321,6 +2650,164 @@ func (s *storageZfs) MigrationSink(live bool, container container, snapshots []*
 	return nil
 }
 
+type zfsStorageVolumeSourceDriver struct {
+	zfs              *storageZfs
+	snapshots        []string
+	zfsSnapshotNames []string
+	runningSnapName  string
+}
+
+func (s *zfsStorageVolumeSourceDriver) SendStorageVolume(conn *websocket.Conn, op *operation, bwlimit string) error {
+	poolName := s.zfs.getOnDiskPoolName()
+	fs := fmt.Sprintf("custom/%s", s.zfs.volume.Name)
+	dataset := fmt.Sprintf("%s/%s", poolName, fs)
+
+	lastSnap := ""
+	for i, snap := range s.zfsSnapshotNames {
+		prev := ""
+		if i > 0 {
+			prev = fmt.Sprintf("%s@%s", dataset, s.zfsSnapshotNames[i-1])
+		}
+
+		lastSnap = fmt.Sprintf("%s@%s", dataset, snap)
+
+		wrapper := StorageProgressReader(op, "fs_progress", s.snapshots[i])
+		err := zfsMigrationSend(conn, lastSnap, prev, wrapper)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.runningSnapName = fmt.Sprintf("migration-send-%s", uuid.NewRandom().String())
+	err := zfsPoolVolumeSnapshotCreate(poolName, fs, s.runningSnapName)
+	if err != nil {
+		return err
+	}
+
+	wrapper := StorageProgressReader(op, "fs_progress", s.zfs.volume.Name)
+	return zfsMigrationSend(conn, fmt.Sprintf("%s@%s", dataset, s.runningSnapName), lastSnap, wrapper)
+}
+
+func (s *zfsStorageVolumeSourceDriver) Cleanup() {
+	if s.runningSnapName != "" {
+		zfsPoolVolumeSnapshotDestroy(s.zfs.getOnDiskPoolName(), fmt.Sprintf("custom/%s", s.zfs.volume.Name), s.runningSnapName)
+	}
+}
+
+func (s *storageZfs) StorageMigrationSource(migrationType MigrationFSType, volumeOnly bool) (MigrationStorageVolumeSourceDriver, error) {
+	if migrationType != MigrationFSType_ZFS {
+		// The content of ZFS snapshots is exposed in the hidden .zfs
+		// directory of the mounted storage volume.
+		snapshotPath := func(snapshotName string) string {
+			_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshotName)
+			customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+			return filepath.Join(customPoolVolumeMntPoint, ".zfs", "snapshot", fmt.Sprintf("snapshot-%s", snapOnlyName))
+		}
+
+		return rsyncStorageMigrationSource(s.s, s.pool.Name, s.volume.Name, volumeOnly, snapshotPath, nil)
+	}
+
+	driver := zfsStorageVolumeSourceDriver{
+		zfs:              s,
+		snapshots:        []string{},
+		zfsSnapshotNames: []string{},
+	}
+
+	if volumeOnly {
+		return &driver, nil
+	}
+
+	snapshots, err := s.db.StoragePoolVolumeSnapshotsGetType(s.volume.Name, storagePoolVolumeTypeCustom, s.poolID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snap := range snapshots {
+		_, snapOnlyName, _ := containerGetParentAndSnapshotName(snap)
+		driver.snapshots = append(driver.snapshots, snap)
+		driver.zfsSnapshotNames = append(driver.zfsSnapshotNames, fmt.Sprintf("snapshot-%s", snapOnlyName))
+	}
+
+	return &driver, nil
+}
+
+func (s *storageZfs) StorageMigrationSink(conn *websocket.Conn, op *operation, snapshots []*VolumeSnapshot) error {
+	poolName := s.getOnDiskPoolName()
+	fs := fmt.Sprintf("custom/%s", s.volume.Name)
+	customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+
+	// Just like for containers, the (empty) dataset needs to be unmounted
+	// before we can zfs recv into it.
+	if shared.IsMountPoint(customPoolVolumeMntPoint) {
+		err := zfsUmount(poolName, fs, customPoolVolumeMntPoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, snap := range snapshots {
+		wrapper := StorageProgressWriter(op, "fs_progress", snap.GetName())
+		name := fmt.Sprintf("%s@snapshot-%s", fs, snap.GetName())
+		err := s.zfsMigrationRecv(conn, name, wrapper)
+		if err != nil {
+			return err
+		}
+
+		fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snap.GetName()
+		err = storagePoolVolumeSnapshotDBCreate(s.s, s.pool.Name, fullSnapshotName, snap.GetDescription(), volumeSnapshotProtobufToConfig(snap))
+		if err != nil {
+			return err
+		}
+	}
+
+	defer func() {
+		/* clean up the migration-send snapshot we got from recv. */
+		zfsSnapshots, err := zfsPoolListSnapshots(poolName, fs)
+		if err != nil {
+			logger.Errorf("failed listing snapshots post migration: %s.", err)
+			return
+		}
+
+		for _, snap := range zfsSnapshots {
+			if !strings.HasPrefix(snap, "migration-send") {
+				continue
+			}
+
+			zfsPoolVolumeSnapshotDestroy(poolName, fs, snap)
+		}
+	}()
+
+	wrapper := StorageProgressWriter(op, "fs_progress", s.volume.Name)
+	err := s.zfsMigrationRecv(conn, fs, wrapper)
+	if err != nil {
+		return err
+	}
+
+	// The received dataset doesn't carry our mountpoint and quota.
+	err = zfsPoolVolumeSet(poolName, fs, "mountpoint", customPoolVolumeMntPoint)
+	if err != nil {
+		return err
+	}
+
+	if s.volume.Config["size"] != "" {
+		size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !shared.IsMountPoint(customPoolVolumeMntPoint) {
+		zfsMount(poolName, fs)
+	}
+
+	return nil
+}
+
 func (s *storageZfs) StorageEntitySetQuota(volumeType int, size int64, data interface{}) error {
 	logger.Debugf(`Setting ZFS quota for "%s"`, s.volume.Name)
 
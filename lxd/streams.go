@@ -0,0 +1,303 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/simplestreams"
+)
+
+// The simplestreams feed publishes the public images of the default project
+// under /streams/v1, so that they can be consumed by any simplestreams
+// client, including LXD itself through the "simplestreams" protocol.
+var apiStreams = []Command{
+	streamsIndexCmd,
+	streamsImagesCmd,
+	streamsImageFileCmd,
+}
+
+var streamsIndexCmd = Command{name: "index.json", untrustedGet: true, get: streamsIndexGet}
+var streamsImagesCmd = Command{name: "images.json", untrustedGet: true, get: streamsImagesGet}
+var streamsImageFileCmd = Command{name: "images/{fingerprint}/{file}", untrustedGet: true, get: streamsImageFileGet}
+
+// Names of the files of an image in the feed.
+const (
+	streamsFileMeta     = "lxd.tar.xz"
+	streamsFileSquashfs = "rootfs.squashfs"
+	streamsFileTarball  = "rootfs.tar.xz"
+	streamsFileCombined = "lxd_combined.tar.gz"
+)
+
+// Image files never change, so their hashes are only computed once.
+var streamsHashesLock sync.Mutex
+var streamsHashes = map[string]string{}
+
+// streamsResponse renders a simplestreams document as plain JSON.
+type streamsResponse struct {
+	content interface{}
+}
+
+func (r *streamsResponse) Render(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	return json.NewEncoder(w).Encode(r.content)
+}
+
+func (r *streamsResponse) String() string {
+	return "simplestreams"
+}
+
+func streamsIndexGet(d *Daemon, r *http.Request) Response {
+	manifest, err := streamsManifest(d)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	products := []string{}
+	for name := range manifest.Products {
+		products = append(products, name)
+	}
+	sort.Strings(products)
+
+	index := simplestreams.SimpleStreamsIndex{
+		Format:  "index:1.0",
+		Updated: manifest.Updated,
+		Index: map[string]simplestreams.SimpleStreamsIndexStream{
+			"images": {
+				DataType: "image-downloads",
+				Path:     "streams/v1/images.json",
+				Updated:  manifest.Updated,
+				Products: products,
+			},
+		},
+	}
+
+	return &streamsResponse{content: index}
+}
+
+func streamsImagesGet(d *Daemon, r *http.Request) Response {
+	manifest, err := streamsManifest(d)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	return &streamsResponse{content: manifest}
+}
+
+func streamsImageFileGet(d *Daemon, r *http.Request) Response {
+	fingerprint := mux.Vars(r)["fingerprint"]
+	file := mux.Vars(r)["file"]
+
+	_, info, err := d.db.ImageGet(fingerprint, true, true)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	path, ok := streamsImageFiles(info.Fingerprint)[file]
+	if !ok {
+		return NotFound
+	}
+
+	files := []fileResponseEntry{{path: path, filename: file}}
+	return FileResponse(r, files, nil, false)
+}
+
+// streamsImageFiles returns the files of the image with the given
+// fingerprint, indexed by their name in the feed.
+func streamsImageFiles(fingerprint string) map[string]string {
+	files := map[string]string{}
+
+	metaPath := shared.VarPath("images", fingerprint)
+	rootfsPath := shared.VarPath("images", fingerprint+".rootfs")
+
+	if !shared.PathExists(rootfsPath) {
+		files[streamsFileCombined] = metaPath
+		return files
+	}
+
+	files[streamsFileMeta] = metaPath
+	if streamsIsSquashfs(rootfsPath) {
+		files[streamsFileSquashfs] = rootfsPath
+	} else {
+		files[streamsFileTarball] = rootfsPath
+	}
+
+	return files
+}
+
+// streamsIsSquashfs returns whether the given file is a squashfs image.
+func streamsIsSquashfs(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	_, err = io.ReadFull(f, magic)
+	if err != nil {
+		return false
+	}
+
+	return string(magic) == "hsqs"
+}
+
+// streamsHash returns the SHA-256 hash of the given image file.
+func streamsHash(path string) (string, error) {
+	streamsHashesLock.Lock()
+	hash, ok := streamsHashes[path]
+	streamsHashesLock.Unlock()
+	if ok {
+		return hash, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, f)
+	if err != nil {
+		return "", err
+	}
+
+	hash = fmt.Sprintf("%x", hasher.Sum(nil))
+
+	streamsHashesLock.Lock()
+	streamsHashes[path] = hash
+	streamsHashesLock.Unlock()
+
+	return hash, nil
+}
+
+// streamsManifest builds the simplestreams products of the public images.
+// Each image is its own product, named after its first alias (or its
+// fingerprint if it has none) and its architecture.
+func streamsManifest(d *Daemon) (*simplestreams.SimpleStreamsManifest, error) {
+	fingerprints, err := d.db.ImagesGetForProject("default", true)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := time.Time{}
+	products := map[string]simplestreams.SimpleStreamsManifestProduct{}
+	for _, fingerprint := range fingerprints {
+		_, info, err := d.db.ImageGet(fingerprint, true, true)
+		if err != nil {
+			return nil, err
+		}
+
+		product, err := streamsProduct(info)
+		if err != nil {
+			return nil, err
+		}
+
+		name := info.Fingerprint
+		if len(info.Aliases) > 0 {
+			name = strings.Split(product.Aliases, ",")[0]
+		}
+		products[fmt.Sprintf("%s:%s", name, product.Architecture)] = *product
+
+		if info.UploadedAt.After(updated) {
+			updated = info.UploadedAt
+		}
+	}
+
+	manifest := simplestreams.SimpleStreamsManifest{
+		Updated:  updated.UTC().Format(time.RFC1123Z),
+		DataType: "image-downloads",
+		Format:   "products:1.0",
+		Products: products,
+	}
+
+	return &manifest, nil
+}
+
+// streamsProduct returns the simplestreams product of an image.
+func streamsProduct(info *api.Image) (*simplestreams.SimpleStreamsManifestProduct, error) {
+	aliases := []string{}
+	for _, alias := range info.Aliases {
+		aliases = append(aliases, alias.Name)
+	}
+	sort.Strings(aliases)
+
+	items := map[string]simplestreams.SimpleStreamsManifestProductVersionItem{}
+	for file, path := range streamsImageFiles(info.Fingerprint) {
+		hash, err := streamsHash(path)
+		if err != nil {
+			return nil, err
+		}
+
+		st, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		items[file] = simplestreams.SimpleStreamsManifestProductVersionItem{
+			Path:       fmt.Sprintf("streams/v1/images/%s/%s", info.Fingerprint, file),
+			HashSha256: hash,
+			Size:       st.Size(),
+		}
+	}
+
+	for file, item := range items {
+		switch file {
+		case streamsFileMeta:
+			item.FileType = "lxd.tar.xz"
+			item.LXDHashSha256 = info.Fingerprint
+			if _, ok := items[streamsFileSquashfs]; ok {
+				item.LXDHashSha256SquashFs = info.Fingerprint
+			} else {
+				item.LXDHashSha256RootXz = info.Fingerprint
+			}
+		case streamsFileSquashfs:
+			item.FileType = "squashfs"
+		case streamsFileTarball:
+			item.FileType = "root.tar.xz"
+		case streamsFileCombined:
+			item.FileType = "lxd_combined.tar.gz"
+		}
+
+		items[file] = item
+	}
+
+	// Versions are named after the creation date of the image as
+	// simplestreams clients derive it from their name.
+	created := info.CreatedAt
+	if !shared.TimeIsSet(created) {
+		created = info.UploadedAt
+	}
+	serial := created.UTC().Format("20060102_1504")
+
+	product := simplestreams.SimpleStreamsManifestProduct{
+		Aliases:         strings.Join(aliases, ","),
+		Architecture:    info.Architecture,
+		OperatingSystem: info.Properties["os"],
+		Release:         info.Properties["release"],
+		ReleaseTitle:    info.Properties["release"],
+		Version:         info.Properties["version"],
+		Versions: map[string]simplestreams.SimpleStreamsManifestProductVersion{
+			serial: {
+				Label: info.Properties["label"],
+				Items: items,
+			},
+		},
+	}
+
+	return &product, nil
+}
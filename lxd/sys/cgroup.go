@@ -2,13 +2,21 @@ package sys
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/lxc/lxd/lxd/cgroup"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/logger"
 )
 
 // Detect CGroup support.
 func (s *OS) initCGroup() {
+	s.CGroupLayout = cgroup.DetectLayout()
+	if s.CGroupLayout == cgroup.Unified {
+		s.initCGroupUnified()
+		return
+	}
+
 	flags := []*bool{
 		&s.CGroupBlkioController,
 		&s.CGroupCPUController,
@@ -28,6 +36,50 @@ func (s *OS) initCGroup() {
 	}
 }
 
+// Detect CGroup support on hosts using the unified hierarchy, where all the
+// controllers are listed in the root cgroup. There the CPU usage is always
+// accounted and there are no devices and network priority controllers.
+func (s *OS) initCGroupUnified() {
+	controllers, err := cgroup.UnifiedControllers()
+	if err != nil {
+		logger.Warnf("Couldn't list the unified CGroup controllers: %v", err)
+	}
+
+	flags := []*bool{
+		&s.CGroupBlkioController,
+		&s.CGroupCPUController,
+		&s.CGroupCPUsetController,
+		&s.CGroupMemoryController,
+		&s.CGroupPidsController,
+	}
+	for i, flag := range flags {
+		*flag = shared.StringInSlice(cGroupsUnified[i].name, controllers)
+		if !*flag {
+			logger.Warnf(cGroupsUnified[i].warn)
+		}
+	}
+
+	s.CGroupCPUacctController = true
+
+	s.CGroupDevicesController = false
+	logger.Warnf(cGroupMissing("devices controller", "device access control won't work"))
+
+	s.CGroupNetPrioController = false
+	logger.Warnf(cGroupMissing("network class controller", "network limits will be ignored"))
+
+	// The swap files only exist outside of the root cgroup
+	if s.CGroupMemoryController {
+		path, err := cgroup.ProcessPath(cgroup.Unified, os.Getpid(), "")
+		if err == nil {
+			s.CGroupSwapAccounting = shared.PathExists(cgroup.HostPath(cgroup.Unified, "", path, "memory.swap.max"))
+		}
+	}
+
+	if !s.CGroupSwapAccounting {
+		logger.Warnf(cGroupDisabled("memory swap accounting", "swap limits will be ignored"))
+	}
+}
+
 func cGroupMissing(name, message string) string {
 	return fmt.Sprintf("Couldn't find the CGroup %s, %s.", name, message)
 }
@@ -50,3 +102,14 @@ var cGroups = []struct {
 	{"pids", cGroupMissing("pids controller", "process limits will be ignored")},
 	{"memory/memory.memsw.limit_in_bytes", cGroupDisabled("memory swap accounting", "swap limits will be ignored")},
 }
+
+var cGroupsUnified = []struct {
+	name string
+	warn string
+}{
+	{"io", cGroupMissing("io controller", "I/O limits will be ignored")},
+	{"cpu", cGroupMissing("CPU controller", "CPU time limits will be ignored")},
+	{"cpuset", cGroupMissing("CPUset controller", "CPU pinning will be ignored")},
+	{"memory", cGroupMissing("memory controller", "memory limits will be ignored")},
+	{"pids", cGroupMissing("pids controller", "process limits will be ignored")},
+}
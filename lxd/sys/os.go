@@ -5,6 +5,7 @@ import (
 
 	log "github.com/lxc/lxd/shared/log15"
 
+	"github.com/lxc/lxd/lxd/cgroup"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/idmap"
@@ -30,6 +31,7 @@ type OS struct {
 	AppArmorStacked         bool
 	AppArmorAdmin           bool
 	AppArmorConfined        bool
+	CGroupLayout            cgroup.Layout
 	CGroupBlkioController   bool
 	CGroupCPUController     bool
 	CGroupCPUacctController bool
@@ -39,6 +41,7 @@ type OS struct {
 	CGroupNetPrioController bool
 	CGroupPidsController    bool
 	CGroupSwapAccounting    bool
+	SeccompListener         bool
 
 	MockMode bool // If true some APIs will be mocked (for testing)
 }
@@ -77,6 +80,7 @@ func (s *OS) Init() error {
 
 	s.initAppArmor()
 	s.initCGroup()
+	s.initSeccomp()
 
 	return nil
 }
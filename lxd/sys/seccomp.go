@@ -0,0 +1,27 @@
+package sys
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// Detect support for intercepting system calls through seccomp user
+// notifications, which requires kernel and liblxc support.
+func (s *OS) initSeccomp() {
+	content, err := ioutil.ReadFile("/proc/sys/kernel/seccomp/actions_avail")
+	if err != nil || !shared.StringInSlice("user_notif", strings.Fields(string(content))) {
+		logger.Warnf("Seccomp syscall interception has been disabled because of lack of kernel support")
+		return
+	}
+
+	if !util.RuntimeLiblxcVersionAtLeast(3, 2, 0) {
+		logger.Warnf("Seccomp syscall interception has been disabled because liblxc is older than 3.2")
+		return
+	}
+
+	s.SeccompListener = true
+}
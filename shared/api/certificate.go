@@ -1,11 +1,20 @@
 package api
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
 // CertificatesPost represents the fields of a new LXD certificate
 type CertificatesPost struct {
 	CertificatePut `yaml:",inline"`
 
 	Certificate string `json:"certificate" yaml:"certificate"`
 	Password    string `json:"password" yaml:"password"`
+
+	// API extension: certificate_token
+	TrustToken string `json:"trust_token" yaml:"trust_token"`
 }
 
 // CertificatePut represents the modifiable fields of a LXD certificate
@@ -14,6 +23,10 @@ type CertificatesPost struct {
 type CertificatePut struct {
 	Name string `json:"name" yaml:"name"`
 	Type string `json:"type" yaml:"type"`
+
+	// API extension: certificate_restrictions
+	Restricted bool     `json:"restricted" yaml:"restricted"`
+	Projects   []string `json:"projects" yaml:"projects"`
 }
 
 // Certificate represents a LXD certificate
@@ -28,3 +41,25 @@ type Certificate struct {
 func (cert *Certificate) Writable() CertificatePut {
 	return cert.CertificatePut
 }
+
+// CertificateAddToken represents the fields contained within an encoded join
+// token, used by a client to add itself to the trust store
+//
+// API extension: certificate_token
+type CertificateAddToken struct {
+	ClientName  string    `json:"client_name" yaml:"client_name"`
+	Fingerprint string    `json:"fingerprint" yaml:"fingerprint"`
+	Addresses   []string  `json:"addresses" yaml:"addresses"`
+	Secret      string    `json:"secret" yaml:"secret"`
+	ExpiresAt   time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
+// String encodes the token in the form handed out to the client
+func (t *CertificateAddToken) String() string {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return ""
+	}
+
+	return base64.StdEncoding.EncodeToString(data)
+}
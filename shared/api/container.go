@@ -41,6 +41,13 @@ type ContainerPostTarget struct {
 	Websockets  map[string]string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
 }
 
+// ContainerRebuildPost represents the fields required to rebuild a LXD container
+//
+// API extension: container_rebuild
+type ContainerRebuildPost struct {
+	Source ContainerSource `json:"source" yaml:"source"`
+}
+
 // ContainerPut represents the modifiable fields of a LXD container
 type ContainerPut struct {
 	Architecture string                       `json:"architecture" yaml:"architecture"`
@@ -72,6 +79,17 @@ type Container struct {
 	LastUsedAt time.Time `json:"last_used_at" yaml:"last_used_at"`
 }
 
+// ContainerFull is a container along with the optional fields selected when
+// listing containers
+//
+// API extension: api_filtering
+type ContainerFull struct {
+	Container `yaml:",inline"`
+
+	State     *ContainerState     `json:"state,omitempty" yaml:"state,omitempty"`
+	Snapshots []ContainerSnapshot `json:"snapshots,omitempty" yaml:"snapshots,omitempty"`
+}
+
 // Writable converts a full Container struct into a ContainerPut struct (filters read-only fields)
 func (c *Container) Writable() ContainerPut {
 	return c.ContainerPut
@@ -18,4 +18,9 @@ type ContainerExecPost struct {
 
 	// API extension: container_exec_recording
 	RecordOutput bool `json:"record-output" yaml:"record-output"`
+
+	// API extension: container_exec_user_group_cwd
+	User  string `json:"user" yaml:"user"`
+	Group string `json:"group" yaml:"group"`
+	Cwd   string `json:"cwd" yaml:"cwd"`
 }
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // ContainerStatePut represents the modifiable fields of a LXD container's state
 type ContainerStatePut struct {
 	Action   string `json:"action" yaml:"action"`
@@ -20,6 +24,20 @@ type ContainerState struct {
 
 	// API extension: container_cpu_time
 	CPU ContainerStateCPU `json:"cpu" yaml:"cpu"`
+
+	// API extension: container_health
+	Health   *ContainerStateHealth `json:"health" yaml:"health"`
+	Restarts int                   `json:"restarts" yaml:"restarts"`
+}
+
+// ContainerStateHealth represents the result of a LXD container's health checks
+//
+// API extension: container_health
+type ContainerStateHealth struct {
+	Status        string    `json:"status" yaml:"status"`
+	FailingStreak int       `json:"failing_streak" yaml:"failing_streak"`
+	LastCheck     time.Time `json:"last_check" yaml:"last_check"`
+	LastExitCode  int       `json:"last_exit_code" yaml:"last_exit_code"`
 }
 
 // ContainerStateDisk represents the disk information section of a LXD container's state
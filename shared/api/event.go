@@ -0,0 +1,20 @@
+package api
+
+// EventLifecycle represents the metadata of a lifecycle event
+//
+// API extension: event_lifecycle
+type EventLifecycle struct {
+	Action    string                   `json:"action" yaml:"action"`
+	Source    string                   `json:"source" yaml:"source"`
+	Context   map[string]interface{}   `json:"context,omitempty" yaml:"context,omitempty"`
+	Requestor *EventLifecycleRequestor `json:"requestor,omitempty" yaml:"requestor,omitempty"`
+}
+
+// EventLifecycleRequestor represents the client which triggered a lifecycle
+// event
+//
+// API extension: event_lifecycle
+type EventLifecycleRequestor struct {
+	Username string `json:"username" yaml:"username"`
+	Protocol string `json:"protocol" yaml:"protocol"`
+}
@@ -0,0 +1,21 @@
+package api
+
+// MetadataConfiguration represents the configuration keys supported by a LXD server
+//
+// API extension: metadata_configuration
+type MetadataConfiguration struct {
+	// Keys indexed by scope (container, profile, pool, volume, network or
+	// server) and then by name
+	Configs map[string]map[string]MetadataConfigurationKey `json:"configs" yaml:"configs"`
+}
+
+// MetadataConfigurationKey describes a configuration key
+//
+// API extension: metadata_configuration
+type MetadataConfigurationKey struct {
+	Type          string   `json:"type" yaml:"type"`
+	Default       string   `json:"default" yaml:"default"`
+	AllowedValues []string `json:"allowed_values" yaml:"allowed_values"`
+	Drivers       []string `json:"drivers" yaml:"drivers"`
+	Description   string   `json:"description" yaml:"description"`
+}
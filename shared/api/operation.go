@@ -16,4 +16,7 @@ type Operation struct {
 	Metadata   map[string]interface{} `json:"metadata" yaml:"metadata"`
 	MayCancel  bool                   `json:"may_cancel" yaml:"may_cancel"`
 	Err        string                 `json:"err" yaml:"err"`
+
+	// API extension: operation_history
+	Requestor *EventLifecycleRequestor `json:"requestor" yaml:"requestor"`
 }
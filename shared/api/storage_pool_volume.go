@@ -8,6 +8,24 @@ type StorageVolumesPost struct {
 
 	Name string `json:"name" yaml:"name"`
 	Type string `json:"type" yaml:"type"`
+
+	// API extension: storage_api_local_volume_handling
+	Source StorageVolumeSource `json:"source" yaml:"source"`
+}
+
+// StorageVolumeSource represents the creation source for a new storage volume
+//
+// API extension: storage_api_local_volume_handling
+type StorageVolumeSource struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+	Pool string `json:"pool" yaml:"pool"`
+
+	// API extension: storage_api_remote_volume_handling
+	Certificate string            `json:"certificate" yaml:"certificate"`
+	Mode        string            `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Operation   string            `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Websockets  map[string]string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
 }
 
 // StorageVolumePost represents the fields required to rename a LXD storage pool volume
@@ -15,6 +33,20 @@ type StorageVolumesPost struct {
 // API extension: storage_api_volume_rename
 type StorageVolumePost struct {
 	Name string `json:"name" yaml:"name"`
+
+	// API extension: storage_api_remote_volume_handling
+	Migration  bool                     `json:"migration" yaml:"migration"`
+	Target     *StorageVolumePostTarget `json:"target" yaml:"target"`
+	VolumeOnly bool                     `json:"volume_only" yaml:"volume_only"`
+}
+
+// StorageVolumePostTarget represents the migration target host and operation
+//
+// API extension: storage_api_remote_volume_handling
+type StorageVolumePostTarget struct {
+	Certificate string            `json:"certificate" yaml:"certificate"`
+	Operation   string            `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Websockets  map[string]string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
 }
 
 // StorageVolume represents the fields of a LXD storage volume.
@@ -35,6 +67,9 @@ type StorageVolumePut struct {
 
 	// API extension: entity_description
 	Description string `json:"description" yaml:"description"`
+
+	// API extension: storage_api_volume_snapshots
+	Restore string `json:"restore,omitempty" yaml:"restore,omitempty"`
 }
 
 // Writable converts a full StorageVolume struct into a StorageVolumePut struct
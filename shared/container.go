@@ -2,8 +2,11 @@ package shared
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ContainerAction string
@@ -96,6 +99,11 @@ var KnownContainerConfigKeys = map[string]func(value string) error{
 	"boot.autostart.priority":    IsInt64,
 	"boot.host_shutdown_timeout": IsInt64,
 
+	"health.command":  IsAny,
+	"health.interval": IsUint32,
+	"health.retries":  IsUint32,
+	"health.timeout":  IsUint32,
+
 	"limits.cpu": IsAny,
 	"limits.cpu.allowance": func(value string) error {
 		if value == "" {
@@ -167,6 +175,12 @@ var KnownContainerConfigKeys = map[string]func(value string) error{
 
 	"linux.kernel_modules": IsAny,
 
+	"restart.backoff":     IsUint32,
+	"restart.max_retries": IsUint32,
+	"restart.policy": func(value string) error {
+		return IsOneOf(value, []string{"never", "on-failure", "always"})
+	},
+
 	"security.nesting":    IsBool,
 	"security.privileged": IsBool,
 
@@ -179,6 +193,18 @@ var KnownContainerConfigKeys = map[string]func(value string) error{
 	"security.syscalls.blacklist":         IsAny,
 	"security.syscalls.whitelist":         IsAny,
 
+	"security.syscalls.intercept.mknod":    IsBool,
+	"security.syscalls.intercept.setxattr": IsBool,
+
+	// Caller is responsible for full validation of the schedule
+	"snapshots.schedule":         IsAny,
+	"snapshots.schedule.stopped": IsBool,
+	"snapshots.pattern":          IsAny,
+	"snapshots.expiry": func(value string) error {
+		_, err := GetSnapshotExpiry(time.Time{}, value)
+		return err
+	},
+
 	// Caller is responsible for full validation of any raw.* value
 	"raw.apparmor": IsAny,
 	"raw.lxc":      IsAny,
@@ -194,6 +220,77 @@ var KnownContainerConfigKeys = map[string]func(value string) error{
 	"volatile.apply_quota":      IsAny,
 }
 
+// namespacedSysctls lists the sysctls which are namespaced and as such safe
+// to set on a per-container basis. Entries ending with a dot are prefixes.
+var namespacedSysctls = []string{
+	"fs.mqueue.",
+	"kernel.msgmax",
+	"kernel.msgmnb",
+	"kernel.msgmni",
+	"kernel.sem",
+	"kernel.shm_rmid_forced",
+	"kernel.shmall",
+	"kernel.shmmax",
+	"kernel.shmmni",
+	"net.",
+}
+
+// sysctlName matches dot separated sysctl names, with no empty components
+var sysctlName = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// isNamespacedSysctl returns whether the given sysctl (e.g. net.core.somaxconn)
+// is namespaced, and so only affects the container it's set in.
+func isNamespacedSysctl(sysctl string) bool {
+	// The name becomes a path under /proc/sys
+	if !sysctlName.MatchString(sysctl) {
+		return false
+	}
+
+	for _, entry := range namespacedSysctls {
+		if strings.HasSuffix(entry, ".") {
+			if strings.HasPrefix(sysctl, entry) && len(sysctl) > len(entry) {
+				return true
+			}
+
+			continue
+		}
+
+		if sysctl == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SysctlPath returns the path under /proc/sys of the given namespaced sysctl.
+func SysctlPath(sysctl string) (string, error) {
+	if !isNamespacedSysctl(sysctl) {
+		return "", fmt.Errorf("Sysctl %s isn't namespaced and can't be set per container", sysctl)
+	}
+
+	path := filepath.Join("/proc/sys", strings.Replace(sysctl, ".", "/", -1))
+
+	// Make sure the path didn't escape the namespaced entries
+	for _, entry := range namespacedSysctls {
+		entryPath := filepath.Join("/proc/sys", strings.Replace(entry, ".", "/", -1))
+
+		if strings.HasSuffix(entry, ".") {
+			if strings.HasPrefix(path, entryPath+"/") {
+				return path, nil
+			}
+
+			continue
+		}
+
+		if path == entryPath {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("Invalid sysctl path %s", path)
+}
+
 // ConfigKeyChecker returns a function that will check whether or not
 // a provide value is valid for the associate config key.  Returns an
 // error if the key is not known.  The checker function only performs
@@ -217,6 +314,14 @@ func ConfigKeyChecker(key string) (func(value string) error, error) {
 		if strings.HasSuffix(key, ".host_name") {
 			return IsAny, nil
 		}
+
+		if strings.HasSuffix(key, ".ipv4.address") {
+			return IsAny, nil
+		}
+
+		if strings.HasSuffix(key, ".ipv6.address") {
+			return IsAny, nil
+		}
 	}
 
 	if strings.HasPrefix(key, "environment.") {
@@ -236,5 +341,14 @@ func ConfigKeyChecker(key string) (func(value string) error, error) {
 		return IsAny, nil
 	}
 
+	if strings.HasPrefix(key, "linux.sysctl.") {
+		sysctl := strings.TrimPrefix(key, "linux.sysctl.")
+		if !isNamespacedSysctl(sysctl) {
+			return nil, fmt.Errorf("Sysctl %s isn't namespaced and can't be set per container", sysctl)
+		}
+
+		return IsAny, nil
+	}
+
 	return nil, fmt.Errorf("Unknown configuration key: %s", key)
 }
@@ -0,0 +1,61 @@
+package shared
+
+import (
+	"testing"
+)
+
+func TestConfigKeyCheckerSysctl(t *testing.T) {
+	valid := []string{
+		"linux.sysctl.net.core.somaxconn",
+		"linux.sysctl.net.ipv4.ip_unprivileged_port_start",
+		"linux.sysctl.kernel.shmmax",
+		"linux.sysctl.fs.mqueue.msg_max",
+	}
+
+	for _, key := range valid {
+		_, err := ConfigKeyChecker(key)
+		if err != nil {
+			t.Errorf("%s should be valid: %v", key, err)
+		}
+	}
+
+	invalid := []string{
+		"linux.sysctl.",
+		"linux.sysctl.net.",
+		"linux.sysctl.kernel.panic",
+		"linux.sysctl.kernel.shmmax.foo",
+		"linux.sysctl.vm.swappiness",
+		"linux.sysctl.net.x/../../kernel/core_pattern",
+		"linux.sysctl.net../kernel/core_pattern",
+		"linux.sysctl.net..core.somaxconn",
+		"linux.sysctl.net.core.",
+		"linux.sysctl.net.core somaxconn",
+		"linux.sysctl.fs.mqueue/../../kernel/panic",
+	}
+
+	for _, key := range invalid {
+		_, err := ConfigKeyChecker(key)
+		if err == nil {
+			t.Errorf("%s should be invalid", key)
+		}
+	}
+}
+
+func TestSysctlPath(t *testing.T) {
+	path, err := SysctlPath("net.ipv4.ip_forward")
+	if err != nil || path != "/proc/sys/net/ipv4/ip_forward" {
+		t.Errorf("Unexpected path %q: %v", path, err)
+	}
+
+	path, err = SysctlPath("kernel.shmmni")
+	if err != nil || path != "/proc/sys/kernel/shmmni" {
+		t.Errorf("Unexpected path %q: %v", path, err)
+	}
+
+	for _, sysctl := range []string{"net.x/../../kernel/core_pattern", "net/../kernel/panic", "kernel.panic"} {
+		_, err := SysctlPath(sysctl)
+		if err == nil {
+			t.Errorf("%s should be rejected", sysctl)
+		}
+	}
+}
@@ -113,6 +113,7 @@ func (s *SimpleStreamsManifest) ToLXD() ([]api.Image, map[string][][]string) {
 			}
 
 			var meta SimpleStreamsManifestProductVersionItem
+			var combined SimpleStreamsManifestProductVersionItem
 			var rootTar SimpleStreamsManifestProductVersionItem
 			var rootSquash SimpleStreamsManifestProductVersionItem
 			deltas := []SimpleStreamsManifestProductVersionItem{}
@@ -124,11 +125,13 @@ func (s *SimpleStreamsManifest) ToLXD() ([]api.Image, map[string][][]string) {
 				}
 
 				// Skip the files we don't care about
-				if !shared.StringInSlice(item.FileType, []string{"root.tar.xz", "lxd.tar.xz", "squashfs"}) {
+				if !shared.StringInSlice(item.FileType, []string{"root.tar.xz", "lxd.tar.xz", "lxd_combined.tar.gz", "squashfs"}) {
 					continue
 				}
 
-				if item.FileType == "lxd.tar.xz" {
+				if item.FileType == "lxd_combined.tar.gz" {
+					combined = item
+				} else if item.FileType == "lxd.tar.xz" {
 					meta = item
 				} else if item.FileType == "squashfs" {
 					rootSquash = item
@@ -137,7 +140,13 @@ func (s *SimpleStreamsManifest) ToLXD() ([]api.Image, map[string][][]string) {
 				}
 			}
 
-			if meta.FileType == "" || (rootTar.FileType == "" && rootSquash.FileType == "") {
+			// Unified images are made of a single tarball
+			if meta.FileType == "" && combined.FileType != "" {
+				meta = combined
+				meta.LXDHashSha256 = combined.HashSha256
+			}
+
+			if meta.FileType == "" || (combined.FileType == "" && rootTar.FileType == "" && rootSquash.FileType == "") {
 				// Invalid image
 				continue
 			}
@@ -153,7 +162,9 @@ func (s *SimpleStreamsManifest) ToLXD() ([]api.Image, map[string][][]string) {
 			size := meta.Size
 			fingerprint := ""
 
-			if rootSquash.FileType != "" {
+			if meta.FileType == "lxd_combined.tar.gz" {
+				fingerprint = meta.LXDHashSha256
+			} else if rootSquash.FileType != "" {
 				if meta.LXDHashSha256SquashFs != "" {
 					fingerprint = meta.LXDHashSha256SquashFs
 				} else {
@@ -230,8 +241,11 @@ func (s *SimpleStreamsManifest) ToLXD() ([]api.Image, map[string][][]string) {
 			}
 
 			imgDownloads := [][]string{
-				{metaPath, metaHash, "meta", fmt.Sprintf("%d", metaSize)},
-				{rootfsPath, rootfsHash, "root", fmt.Sprintf("%d", rootfsSize)}}
+				{metaPath, metaHash, "meta", fmt.Sprintf("%d", metaSize)}}
+
+			if rootfsPath != "" {
+				imgDownloads = append(imgDownloads, []string{rootfsPath, rootfsHash, "root", fmt.Sprintf("%d", rootfsSize)})
+			}
 
 			// Add the deltas
 			for _, delta := range deltas {
@@ -650,6 +664,23 @@ func (s *SimpleStreams) downloadFile(path string, hash string, target string, pr
 	return nil
 }
 
+// GetIndex returns the index of the streams.
+func (s *SimpleStreams) GetIndex() (*SimpleStreamsIndex, error) {
+	return s.parseIndex()
+}
+
+// GetManifest returns the products manifest at the given path, relative to
+// the root of the streams.
+func (s *SimpleStreams) GetManifest(path string) (*SimpleStreamsManifest, error) {
+	return s.parseManifest(path)
+}
+
+// DownloadFile downloads the file at the given path, relative to the root of
+// the streams, checking its SHA-256 hash.
+func (s *SimpleStreams) DownloadFile(path string, hash string, target string, progress func(int64, int64)) error {
+	return s.downloadFile(path, hash, target, progress)
+}
+
 func (s *SimpleStreams) ListAliases() ([]api.ImageAliasesEntry, error) {
 	_, aliasesMap, err := s.getImages()
 	if err != nil {
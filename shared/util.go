@@ -890,3 +890,52 @@ func WriteTempFile(dir string, prefix string, content string) (string, error) {
 	_, err = f.WriteString(content)
 	return f.Name(), err
 }
+
+// GetSnapshotExpiry returns the expiry date of a snapshot created at refDate,
+// given an expiry expression such as "1M 2H 3d 4w 5m 6y" (minutes, hours,
+// days, weeks, months and years). An empty expression never expires and
+// yields the zero time.
+func GetSnapshotExpiry(refDate time.Time, s string) (time.Time, error) {
+	expr := strings.TrimSpace(s)
+
+	if expr == "" {
+		return time.Time{}, nil
+	}
+
+	re := regexp.MustCompile(`^(\d+)(M|H|d|w|m|y)$`)
+	expiry := map[string]int{
+		"M": 0,
+		"H": 0,
+		"d": 0,
+		"w": 0,
+		"m": 0,
+		"y": 0,
+	}
+
+	seen := map[string]bool{}
+
+	for _, value := range strings.Fields(expr) {
+		fields := re.FindStringSubmatch(value)
+		if fields == nil {
+			return time.Time{}, fmt.Errorf("Invalid expiry expression: %s", value)
+		}
+
+		// We don't allow units to be set multiple times
+		if seen[fields[2]] {
+			return time.Time{}, fmt.Errorf("Duplicate expiry unit: %s", fields[2])
+		}
+		seen[fields[2]] = true
+
+		val, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		expiry[fields[2]] = val
+	}
+
+	t := refDate.AddDate(expiry["y"], expiry["m"], expiry["d"]+expiry["w"]*7).Add(
+		time.Hour*time.Duration(expiry["H"]) + time.Minute*time.Duration(expiry["M"]))
+
+	return t, nil
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestURLEncode(t *testing.T) {
@@ -105,3 +106,36 @@ func TestReaderToChannel(t *testing.T) {
 		}
 	}
 }
+
+func TestGetSnapshotExpiry(t *testing.T) {
+	refDate := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"":            {},
+		"30M":         refDate.Add(30 * time.Minute),
+		"1H 30M":      refDate.Add(90 * time.Minute),
+		"2d":          refDate.AddDate(0, 0, 2),
+		"1w 1d":       refDate.AddDate(0, 0, 8),
+		"1m":          refDate.AddDate(0, 1, 0),
+		"1y 2m 3d 4H": refDate.AddDate(1, 2, 3).Add(4 * time.Hour),
+	}
+
+	for expr, expected := range tests {
+		expiry, err := GetSnapshotExpiry(refDate, expr)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", expr, err)
+			continue
+		}
+
+		if !expiry.Equal(expected) {
+			t.Errorf("Wrong expiry for %q: %v != %v", expr, expiry, expected)
+		}
+	}
+
+	for _, expr := range []string{"1", "1x", "d", "1d 2d", "-1d"} {
+		_, err := GetSnapshotExpiry(refDate, expr)
+		if err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
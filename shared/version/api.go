@@ -80,4 +80,28 @@ var APIExtensions = []string{
 	"macaroon_authentication",
 	"network_sriov",
 	"console",
+	"snapshot_scheduling",
+	"snapshot_expiry",
+	"proxy",
+	"storage_api_local_volume_handling",
+	"storage_api_volume_snapshots",
+	"storage_api_remote_volume_handling",
+	"container_backup",
+	"projects",
+	"certificate_restrictions",
+	"metrics",
+	"network_leases",
+	"network_state",
+	"network_ip_filtering",
+	"container_exec_user_group_cwd",
+	"event_lifecycle",
+	"container_health",
+	"api_filtering",
+	"image_simplestreams_feed",
+	"container_rebuild",
+	"operation_history",
+	"certificate_token",
+	"metadata_configuration",
+	"container_sysctl",
+	"container_syscall_intercept",
 }
@@ -150,18 +150,22 @@ run_test test_static_analysis "static analysis"
 run_test test_database_update "database schema updates"
 run_test test_remote_url "remote url handling"
 run_test test_remote_admin "remote administration"
+run_test test_remote_token "remote join tokens"
 run_test test_remote_usage "remote usage"
 run_test test_basic_usage "basic usage"
 run_test test_security "security features"
+run_test test_container_syscall_intercept "container syscall interception"
 run_test test_image_expiry "image expiry"
 run_test test_image_list_all_aliases "image list all aliases"
 run_test test_image_auto_update "image auto-update"
 run_test test_image_prefer_cached "image prefer cached"
 run_test test_image_import_dir "import image from directory"
 run_test test_concurrent_exec "concurrent exec"
+run_test test_exec_user "exec as a user"
 run_test test_concurrent "concurrent startup"
 run_test test_snapshots "container snapshots"
 run_test test_snap_restore "snapshot restores"
+run_test test_snap_schedule "snapshot scheduling"
 run_test test_config_profiles "profiles and configuration"
 run_test test_config_edit "container configuration edit"
 run_test test_config_edit_container_snapshot_pool_config "container and snapshot volume configuration edit"
@@ -184,12 +188,28 @@ run_test test_init_interactive "lxd init interactive"
 run_test test_init_preseed "lxd init preseed"
 run_test test_storage_profiles "storage profiles"
 run_test test_container_import "container import"
+run_test test_backup_import "backup import"
 run_test test_storage_volume_attach "attaching storage volumes"
+run_test test_storage_volume_snapshots "storage volume snapshots"
 run_test test_storage_driver_ceph "ceph storage driver"
 run_test test_resources "resources"
 run_test test_kernel_limits "kernel limits"
+run_test test_container_sysctl "container sysctls"
 run_test test_macaroon_auth "macaroon authentication"
 run_test test_console "console"
+run_test test_proxy_device "proxy device"
+run_test test_projects_crud "projects CRUD"
+run_test test_projects_containers "containers inside projects"
+run_test test_projects_profiles "profiles inside projects"
+run_test test_projects_images "images inside projects"
+run_test test_projects_restricted_certificate "restricted certificates"
+run_test test_metrics "metrics"
+run_test test_lifecycle_events "lifecycle events"
+run_test test_container_health "container health checks and restart policy"
+run_test test_api_filtering "API filtering"
+run_test test_image_simplestreams_feed "image simplestreams feed"
+run_test test_container_rebuild "container rebuild"
+run_test test_operation_history "operation history and audit log"
 
 # shellcheck disable=SC2034
 TEST_RESULT=success
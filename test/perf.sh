@@ -79,7 +79,11 @@ TEST_RESULT=failure
 
 run_benchmark "create-one" "create 1 container" launch --count 1 --start=false --image=testimage
 run_benchmark "start-one" "start 1 container" start
+run_benchmark "exec-one" "run 100 commands in 1 container" exec --iterations 100
+run_benchmark "file-one" "transfer 10 files with 1 container" file --iterations 10 --file-size 1MB
 run_benchmark "stop-one" "stop 1 container" stop
+run_benchmark "snapshot-one" "snapshot and restore 1 container" snapshot
+run_benchmark "copy-one" "copy 1 container" copy
 run_benchmark "delete-one" "delete 1 container" delete
 run_benchmark "create-128" "create 128 containers" launch --count 128 --start=false --image=testimage
 run_benchmark "start-128" "start 128 containers" start
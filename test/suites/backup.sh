@@ -178,3 +178,77 @@ test_container_import() {
   LXD_DIR=${LXD_DIR}
   kill_lxd "${LXD_IMPORT_DIR}"
 }
+
+test_backup_import() {
+  ensure_import_testimage
+
+  lxd_backend=$(storage_backend "$LXD_DIR")
+
+  lxc launch testimage b1
+  lxc exec b1 -- touch /root/foo
+  lxc snapshot b1
+
+  # Create, list, rename and delete backups
+  lxc query -X POST --wait /1.0/containers/b1/backups -d '{"name": "manual"}'
+  lxc query /1.0/containers/b1/backups | grep -q "/1.0/containers/b1/backups/manual"
+  lxc query -X POST --wait /1.0/containers/b1/backups/manual -d '{"name": "renamed"}'
+  lxc query /1.0/containers/b1/backups/renamed | grep -q '"name": "renamed"'
+  [ -f "${LXD_DIR}/backups/b1/renamed" ]
+  lxc query -X DELETE --wait /1.0/containers/b1/backups/renamed
+  ! [ -e "${LXD_DIR}/backups/b1" ] || false
+
+  # Export the container and restore it
+  lxc export b1 "${LXD_DIR}/b1.tar.gz"
+  tar -tzf "${LXD_DIR}/b1.tar.gz" | grep -q "^backup/index.yaml$"
+  tar -tzf "${LXD_DIR}/b1.tar.gz" | grep -q "^backup/snapshots/snap0/"
+  [ "$(lxc query /1.0/containers/b1/backups)" = "[]" ]
+
+  ! lxc import "${LXD_DIR}/b1.tar.gz" || false
+  lxc delete --force b1
+
+  # Snapshots not belonging to the backed up container are rejected
+  tmpdir=$(mktemp -d -p "${TEST_DIR}" XXX)
+  tar -xzf "${LXD_DIR}/b1.tar.gz" -C "${tmpdir}"
+  sed -i "s|name: b1/snap0|name: other/snap0|" "${tmpdir}/backup/index.yaml"
+  tar -czf "${LXD_DIR}/bad.tar.gz" -C "${tmpdir}" backup
+  ! lxc import "${LXD_DIR}/bad.tar.gz" || false
+  ! lxc info b1 || false
+  rm -rf "${tmpdir}" "${LXD_DIR}/bad.tar.gz"
+
+  lxc import "${LXD_DIR}/b1.tar.gz"
+  lxc info b1 | grep snap0
+  lxc start b1
+  lxc exec b1 -- stat /root/foo
+  lxc delete --force b1
+
+  # Export without snapshots
+  lxc init testimage b1
+  lxc snapshot b1
+  lxc export b1 "${LXD_DIR}/b1.tar.gz" --container-only
+  ! tar -tzf "${LXD_DIR}/b1.tar.gz" | grep -q "^backup/snapshots/" || false
+  lxc delete --force b1
+
+  lxc import "${LXD_DIR}/b1.tar.gz"
+  ! lxc info b1 | grep snap0 || false
+  lxc delete --force b1
+
+  # Optimized backups
+  if [ "$lxd_backend" = "btrfs" ] || [ "$lxd_backend" = "zfs" ]; then
+    lxc init testimage b1
+    lxc snapshot b1
+    lxc export b1 "${LXD_DIR}/b1.tar.gz" --optimized-storage
+    tar -tzf "${LXD_DIR}/b1.tar.gz" | grep -q "^backup/container.bin$"
+    lxc delete --force b1
+
+    lxc import "${LXD_DIR}/b1.tar.gz"
+    lxc info b1 | grep snap0
+    lxc start b1
+    lxc delete --force b1
+  else
+    lxc init testimage b1
+    ! lxc export b1 "${LXD_DIR}/b1.tar.gz" --optimized-storage || false
+    lxc delete --force b1
+  fi
+
+  rm -f "${LXD_DIR}/b1.tar.gz"
+}
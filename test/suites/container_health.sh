@@ -0,0 +1,82 @@
+test_container_health() {
+  ensure_import_testimage
+
+  wait_for_health() {
+    for _ in $(seq 30); do
+      [ "$(lxc query /1.0/containers/c1/state | jq -r .health.status)" = "${1}" ] && return 0
+      sleep 2
+    done
+
+    return 1
+  }
+
+  # Invalid values are rejected
+  ! lxc init testimage c1 -c restart.policy=sometimes || false
+  ! lxc init testimage c1 -c health.interval=-1 || false
+
+  lxc launch testimage c1 -c health.command="test ! -e /tmp/broken" -c health.interval=1 -c health.retries=1
+
+  # Containers report their health once checked
+  wait_for_health healthy
+  lxc info c1 | grep -q "Health: healthy"
+
+  # Failing checks mark the container unhealthy
+  lxc exec c1 -- touch /tmp/broken
+  wait_for_health unhealthy
+
+  # It's left alone without a restart policy
+  [ "$(lxc query /1.0/containers/c1/state | jq -r .restarts)" = "0" ]
+  lxc exec c1 -- rm /tmp/broken
+  wait_for_health healthy
+
+  # Containers stopping on their own are restarted
+  lxc config set c1 restart.policy always
+  pid=$(lxc query /1.0/containers/c1/state | jq -r .pid)
+  kill -9 "${pid}"
+  for _ in $(seq 30); do
+    [ "$(lxc query /1.0/containers/c1/state | jq -r .restarts)" = "1" ] && break
+    sleep 1
+  done
+  [ "$(lxc query /1.0/containers/c1/state | jq -r .restarts)" = "1" ]
+  for _ in $(seq 10); do
+    lxc info c1 | grep -q RUNNING && break
+    sleep 1
+  done
+  lxc info c1 | grep -q "Restarts: 1"
+  lxc info c1 | grep -q RUNNING
+
+  # Stops requested through LXD aren't
+  lxc stop c1 --force
+  sleep 3
+  lxc info c1 | grep -q STOPPED
+
+  # With the on-failure policy, shutting down from the inside isn't a failure
+  lxc config set c1 restart.policy on-failure
+  lxc start c1
+  lxc exec c1 -- poweroff
+  for _ in $(seq 30); do
+    lxc info c1 | grep -q STOPPED && break
+    sleep 1
+  done
+  sleep 3
+  lxc info c1 | grep -q STOPPED
+  [ "$(lxc query /1.0/containers/c1/state | jq -r .restarts)" = "1" ]
+
+  # But init getting killed is
+  lxc start c1
+  pid=$(lxc query /1.0/containers/c1/state | jq -r .pid)
+  kill -9 "${pid}"
+  for _ in $(seq 30); do
+    [ "$(lxc query /1.0/containers/c1/state | jq -r .restarts)" = "2" ] && break
+    sleep 1
+  done
+  [ "$(lxc query /1.0/containers/c1/state | jq -r .restarts)" = "2" ]
+  for _ in $(seq 10); do
+    lxc info c1 | grep -q RUNNING && break
+    sleep 1
+  done
+  lxc info c1 | grep -q RUNNING
+  lxc stop c1 --force
+
+  lxc delete c1
+}
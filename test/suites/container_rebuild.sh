@@ -0,0 +1,39 @@
+test_container_rebuild() {
+  ensure_import_testimage
+  (deps/import-busybox --alias rebuild-image --template create)
+  fp=$(lxc image info rebuild-image | awk -F: '/^Fingerprint/ { print $2 }' | awk '{ print $1 }')
+
+  # Rebuilding a container with snapshots keeps them along with its config
+  lxc init testimage c1 -c user.foo=bar
+  echo "hello" > "${TEST_DIR}/rebuild-file"
+  lxc file push "${TEST_DIR}/rebuild-file" c1/root/hello
+  lxc snapshot c1 snap0
+
+  lxc start c1
+  ! lxc rebuild rebuild-image c1 || false
+  lxc stop c1 --force
+
+  lxc rebuild rebuild-image c1
+  [ "$(lxc config get c1 user.foo)" = "bar" ]
+  [ "$(lxc config get c1 volatile.base_image)" = "${fp}" ]
+  [ "$(lxc config get c1 volatile.apply_template)" = "create" ]
+  lxc info c1 | grep -q snap0
+  ! lxc file pull c1/root/hello - || false
+  lxc start c1
+  lxc exec c1 -- test -e /template
+  lxc stop c1 --force
+
+  # Restoring the snapshot brings the old root filesystem back
+  lxc restore c1 snap0
+  [ "$(lxc file pull c1/root/hello -)" = "hello" ]
+
+  # Containers without snapshots get a new volume, here an empty one
+  lxc init testimage c2
+  lxc rebuild --empty c2
+  ! lxc file pull c2/bin/sh - || false
+  [ "$(lxc config get c2 volatile.base_image)" = "" ]
+
+  lxc delete -f c1 c2
+  lxc image delete rebuild-image
+  rm -f "${TEST_DIR}/rebuild-file"
+}
@@ -0,0 +1,49 @@
+test_lifecycle_events() {
+  ensure_import_testimage
+
+  # Record the lifecycle events in the background
+  lxc monitor --type=lifecycle > "${TEST_DIR}/lifecycle.log" &
+  monitor_pid=$!
+  sleep 1
+
+  lxc init testimage c1
+  lxc start c1
+  lxc snapshot c1 snap0
+
+  # Init getting killed is a crash
+  pid=$(lxc query /1.0/containers/c1/state | jq -r .pid)
+  kill -9 "${pid}"
+  for _ in $(seq 30); do
+    lxc info c1 | grep -q STOPPED && break
+    sleep 1
+  done
+
+  lxc start c1
+  lxc stop c1 --force
+  lxc move c1 c2
+  lxc delete c2
+
+  lxc profile create p1
+  lxc profile delete p1
+
+  sleep 1
+  kill -9 "${monitor_pid}" || true
+
+  for action in container-created container-started container-snapshot-created container-crashed \
+                container-stopped container-renamed container-deleted profile-created profile-deleted; do
+    grep -q "action: ${action}$" "${TEST_DIR}/lifecycle.log"
+  done
+
+  # Events point to the resource and the client which requested them
+  grep -q "source: /1.0/containers/c2$" "${TEST_DIR}/lifecycle.log"
+  grep -q "source: /1.0/profiles/p1$" "${TEST_DIR}/lifecycle.log"
+  grep -q "protocol: unix$" "${TEST_DIR}/lifecycle.log"
+
+  # Other event types were filtered out
+  ! grep -q "type: logging" "${TEST_DIR}/lifecycle.log" || false
+
+  # Unknown event types are rejected
+  ! lxc query "/1.0/events?type=foo" || false
+
+  rm -f "${TEST_DIR}/lifecycle.log"
+}
@@ -27,3 +27,39 @@ test_concurrent_exec() {
   lxc stop "${name}" --force
   lxc delete "${name}"
 }
+
+test_exec_user() {
+  ensure_import_testimage
+
+  lxc launch testimage x1
+  lxc exec x1 -- mkdir -p /home/foo
+  lxc exec x1 -- sh -c 'echo "foo:x:1000:1001:foo:/home/foo:/bin/sh" >> /etc/passwd'
+  lxc exec x1 -- sh -c 'echo "bar:x:1002:" >> /etc/group'
+
+  # Named users get their primary group, home directory and name
+  [ "$(lxc exec x1 --user foo -- id -u)" = "1000" ]
+  [ "$(lxc exec x1 --user foo -- id -g)" = "1001" ]
+  [ "$(lxc exec x1 --user foo -- pwd)" = "/home/foo" ]
+  lxc exec x1 --user foo -- env | grep -q "^USER=foo$"
+  lxc exec x1 --user foo -- env | grep -q "^HOME=/home/foo$"
+  lxc exec x1 --user foo --env HOME=/tmp -- env | grep -q "^HOME=/tmp$"
+
+  # Groups can be overridden by name or ID
+  [ "$(lxc exec x1 --user foo --group bar -- id -g)" = "1002" ]
+  [ "$(lxc exec x1 --group 1003 -- id -g)" = "1003" ]
+
+  # Numeric IDs don't need to exist in the container
+  [ "$(lxc exec x1 --user 2000 -- id -u)" = "2000" ]
+  [ "$(lxc exec x1 --user 2000 -- id -g)" = "2000" ]
+
+  # Working directory
+  [ "$(lxc exec x1 --cwd /tmp -- pwd)" = "/tmp" ]
+  [ "$(lxc exec x1 -- pwd)" = "/root" ]
+
+  # Unknown names and relative paths are rejected
+  ! lxc exec x1 --user nosuchuser -- true || false
+  ! lxc exec x1 --group nosuchgroup -- true || false
+  ! lxc exec x1 --cwd tmp -- true || false
+
+  lxc delete x1 --force
+}
@@ -0,0 +1,29 @@
+test_api_filtering() {
+  ensure_import_testimage
+
+  lxc launch testimage web1 -c user.role=frontend
+  lxc init testimage web2 -c user.role=frontend
+  lxc init testimage db1 -c user.role=backend
+
+  # Names, configuration keys and properties
+  [ "$(lxc query "/1.0/containers?filter=web" | jq -r 'length')" = "2" ]
+  [ "$(lxc query "/1.0/containers?recursion=1&filter=user.role=backend" | jq -r '.[].name')" = "db1" ]
+  [ "$(lxc query "/1.0/containers?recursion=1&filter=u.r=front.*&filter=status=Running" | jq -r '.[].name')" = "web1" ]
+  [ "$(lxc query "/1.0/containers?filter=user.role=nothing" | jq -r 'length')" = "0" ]
+
+  # State is only returned when requested, but can always be filtered on
+  [ "$(lxc query "/1.0/containers?recursion=1&filter=web1" | jq -r '.[0].state')" = "null" ]
+  [ "$(lxc query "/1.0/containers?recursion=1&filter=web1&fields=state" | jq -r '.[0].state.status')" = "Running" ]
+  [ "$(lxc query "/1.0/containers?recursion=1&filter=state.status=Running" | jq -r '.[].name')" = "web1" ]
+  ! lxc query "/1.0/containers?fields=foo" || false
+
+  # lxc list uses the server-side filters
+  [ "$(lxc list -c n --format csv user.role=frontend | wc -l)" = "2" ]
+  [ "$(lxc list -c n --format csv state.status=Running)" = "web1" ]
+
+  # Other collections
+  [ "$(lxc query "/1.0/profiles?recursion=1&filter=name=default" | jq -r '.[].name')" = "default" ]
+  [ "$(lxc query "/1.0/images?recursion=1&filter=properties.description=nothing" | jq -r 'length')" = "0" ]
+
+  lxc delete -f web1 web2 db1
+}
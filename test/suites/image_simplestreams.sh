@@ -0,0 +1,36 @@
+test_image_simplestreams_feed() {
+  # shellcheck disable=2039
+  local LXD2_DIR LXD2_ADDR
+  LXD2_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD2_DIR}"
+  spawn_lxd "${LXD2_DIR}" true
+  LXD2_ADDR=$(cat "${LXD2_DIR}/lxd.addr")
+
+  (LXD_DIR=${LXD2_DIR} deps/import-busybox --alias feed-unified --public)
+  (LXD_DIR=${LXD2_DIR} deps/import-busybox --alias feed-split --public --split --template create)
+  (LXD_DIR=${LXD2_DIR} deps/import-busybox --alias feed-private --template start)
+  fp1=$(LXD_DIR=${LXD2_DIR} lxc image info feed-unified | awk -F: '/^Fingerprint/ { print $2 }' | awk '{ print $1 }')
+  fp2=$(LXD_DIR=${LXD2_DIR} lxc image info feed-split | awk -F: '/^Fingerprint/ { print $2 }' | awk '{ print $1 }')
+
+  # The feed lists the public images only and doesn't need authentication
+  curl -k -s "https://${LXD2_ADDR}/streams/v1/index.json" | jq -r '.index.images.products[]' | grep -q "^feed-unified:"
+  curl -k -s "https://${LXD2_ADDR}/streams/v1/images.json" | jq -r '.products[].aliases' | grep -qx "feed-split"
+  ! curl -k -s "https://${LXD2_ADDR}/streams/v1/images.json" | grep -q "feed-private" || false
+  curl -k -s "https://${LXD2_ADDR}/streams/v1/images.json" | jq -r '.products[].versions[].items[].ftype' | grep -qx "lxd_combined.tar.gz"
+  curl -k -s "https://${LXD2_ADDR}/streams/v1/images.json" | jq -r '.products[].versions[].items[].ftype' | grep -qx "lxd.tar.xz"
+
+  # And can be consumed as a simplestreams remote
+  lxc remote add feed "https://${LXD2_ADDR}" --protocol=simplestreams --public
+  mkdir -p "${LXD_CONF}/servercerts"
+  cp "${LXD2_DIR}/server.crt" "${LXD_CONF}/servercerts/feed.crt"
+
+  lxc image info "feed:${fp1}"
+  lxc image copy feed:feed-unified local:
+  lxc image copy feed:feed-split local:
+  lxc image info "${fp1}"
+  lxc image info "${fp2}"
+
+  lxc image delete "${fp1}" "${fp2}"
+  lxc remote remove feed
+  kill_lxd "${LXD2_DIR}"
+}
@@ -70,6 +70,16 @@ EOF
     lxc profile show test-profile | grep -q "limits.memory: 2GB"
     lxc profile show test-profile | grep -q "nictype: bridged"
     lxc profile show test-profile | grep -q "parent: lxdt$$"
+
+    # The dumped configuration can be applied again as is
+    lxd init --dump > "${LXD_INIT_DIR}/preseed.yaml"
+    grep -q "core.https_address: 127.0.0.1:9999" "${LXD_INIT_DIR}/preseed.yaml"
+    grep -q "name: lxdt$$" "${LXD_INIT_DIR}/preseed.yaml"
+    grep -q "name: test-profile" "${LXD_INIT_DIR}/preseed.yaml"
+    lxd init --preseed < "${LXD_INIT_DIR}/preseed.yaml"
+    lxc profile show test-profile | grep -q "limits.memory: 2GB"
+    lxc profile show default | grep -q "pool: data"
+
     lxc profile delete default
     lxc profile delete test-profile
     lxc network delete lxdt$$
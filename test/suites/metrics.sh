@@ -0,0 +1,27 @@
+test_metrics() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  lxc launch testimage c1
+  lxc init testimage c2
+
+  metrics_curl() {
+    curl -k -s --cert "${LXD_CONF}/client.crt" --key "${LXD_CONF}/client.key" "https://${LXD_ADDR}/1.0/metrics"
+  }
+
+  # Running containers are reported, stopped ones aren't
+  metrics_curl | grep -q '^lxd_container_memory_usage_bytes{name="c1",project="default"}'
+  metrics_curl | grep -q '^lxd_container_processes{name="c1",project="default"}'
+  ! metrics_curl | grep -q 'name="c2"' || false
+
+  # Daemon metrics are always there
+  metrics_curl | grep -q '^lxd_operations{status="Running"}'
+  metrics_curl | grep -q '^lxd_go_goroutines '
+  metrics_curl | tail -n1 | grep -qx "# EOF"
+
+  # Untrusted clients are rejected
+  curl -k -s "https://${LXD_ADDR}/1.0/metrics" | grep -q 403
+
+  lxc delete c1 --force
+  lxc delete c2
+}
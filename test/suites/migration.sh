@@ -222,6 +222,40 @@ migration() {
     lxc storage unset "lxdtest-$(basename "${LXD_DIR}")" zfs.clone_copy
   fi
 
+  # Test custom storage volume migration
+  # shellcheck disable=2039
+  local remote_pool1 remote_pool2
+  remote_pool1="lxdtest-$(basename "${LXD_DIR}")"
+  remote_pool2="lxdtest-$(basename "${lxd2_dir}")"
+
+  lxc_remote storage volume create l1:"$remote_pool1" vol1
+  lxc_remote storage volume create l1:"$remote_pool1" vol2
+
+  # Remote storage volume copy in pull and push mode
+  lxc_remote storage volume copy l1:"$remote_pool1/vol1" l2:"$remote_pool2/vol1"
+  lxc_remote storage volume copy l1:"$remote_pool1/vol1" l2:"$remote_pool2/vol2" --mode=push
+  ! lxc_remote storage volume copy l1:"$remote_pool1/vol1" l2:"$remote_pool2/vol2" || false
+  lxc_remote storage volume delete l2:"$remote_pool2" vol2
+
+  # Remote storage volume move
+  lxc_remote storage volume move l1:"$remote_pool1/vol2" l2:"$remote_pool2/vol2"
+  ! lxc_remote storage volume show l1:"$remote_pool1" vol2 || false
+  lxc_remote storage volume show l2:"$remote_pool2" vol2
+  lxc_remote storage volume delete l2:"$remote_pool2" vol2
+
+  # Snapshots are transferred unless --volume-only is passed.
+  lxc_remote storage volume snapshot l1:"$remote_pool1" vol1 snap0
+  lxc_remote storage volume copy l1:"$remote_pool1/vol1" l2:"$remote_pool2/vol2"
+  lxc_remote storage volume show l2:"$remote_pool2" vol2/snap0
+  lxc_remote storage volume delete l2:"$remote_pool2" vol2
+
+  lxc_remote storage volume copy l1:"$remote_pool1/vol1" l2:"$remote_pool2/vol2" --volume-only
+  ! lxc_remote storage volume show l2:"$remote_pool2" vol2/snap0 || false
+  lxc_remote storage volume delete l2:"$remote_pool2" vol2
+
+  lxc_remote storage volume delete l1:"$remote_pool1" vol1
+  lxc_remote storage volume delete l2:"$remote_pool2" vol1
+
   if ! which criu >/dev/null 2>&1; then
     echo "==> SKIP: live migration with CRIU (missing binary)"
     return
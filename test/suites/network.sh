@@ -38,8 +38,12 @@ test_network() {
   lxc config device set nettest eth0 ipv6.address "${v6_addr}"
   grep -q "${v4_addr}.*nettest" "${LXD_DIR}/networks/lxdt$$/dnsmasq.hosts/nettest"
   grep -q "${v6_addr}.*nettest" "${LXD_DIR}/networks/lxdt$$/dnsmasq.hosts/nettest"
+  lxc config device set nettest eth0 security.ipv4_filtering true
   lxc start nettest
 
+  # The container is restricted to its static address
+  ebtables -L --Lmac2 --Lx | grep -q -- "--ip-src ! ${v4_addr} -j DROP"
+
   SUCCESS=0
   # shellcheck disable=SC2034
   for i in $(seq 10); do
@@ -49,6 +53,16 @@ test_network() {
 
   [ "${SUCCESS}" = "0" ] && (echo "Container static IP wasn't applied" && false)
 
+  # Static reservations show up as leases
+  lxc network list-leases lxdt$$ | grep -q "${v4_addr}.*STATIC"
+  lxc network list-leases lxdt$$ | grep -q "${v6_addr}.*STATIC"
+
+  # Runtime state of the bridge
+  lxc network info lxdt$$ | grep -q "State: up"
+  lxc network info lxdt$$ | grep -q "$(lxc network get lxdt$$ ipv4.address | cut -d/ -f1)"
+  ! lxc network list-leases lo || false
+
   lxc delete nettest -f
+  ! ebtables -L --Lmac2 --Lx | grep -q -- "--ip-src ! ${v4_addr} -j DROP" || false
   lxc network delete lxdt$$
 }
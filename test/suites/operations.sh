@@ -0,0 +1,23 @@
+test_operation_history() {
+  ensure_import_testimage
+
+  lxc init testimage c1
+  uuid=$(lxc query -X POST -d '{"name": "snap0"}' /1.0/containers/c1/snapshots | jq -r .id)
+  lxc query "/1.0/operations/${uuid}/wait" >/dev/null
+
+  # Finished operations are kept in memory for a few seconds
+  sleep 6
+
+  # The finished operation is then served from the history
+  [ "$(lxc query "/1.0/operations/${uuid}" | jq -r .status)" = "Success" ]
+  [ "$(lxc query "/1.0/operations/${uuid}" | jq -r .requestor.protocol)" = "unix" ]
+  lxc query "/1.0/operations?all=1" | jq -r '.[]' | grep -q "${uuid}"
+  ! lxc query "/1.0/operations" | jq -r '.[]' | grep -q "${uuid}" || false
+  ! lxc query "/1.0/operations?all=1&since=2999-01-01T00:00:00Z" | jq -r '.[]' | grep -q "${uuid}" || false
+  lxc operation list --all | grep -q "${uuid}"
+
+  # Mutating requests are audited
+  grep "/1.0/containers/c1/snapshots" "${LXD_DIR}/logs/audit.log" | grep -q "${uuid}"
+
+  lxc delete c1
+}
@@ -0,0 +1,164 @@
+test_projects_crud() {
+  # Create a project
+  lxc project create foo
+  lxc project show foo
+
+  # Validate the project name and features
+  ! lxc project create foo_bar || false
+  ! lxc project create bar features.unknown=true || false
+  ! lxc project create foo || false
+
+  # Set and unset a configuration key
+  lxc project set foo features.images true
+  [ "$(lxc project get foo features.images)" = "true" ]
+  lxc project unset foo features.images
+  [ "$(lxc project get foo features.images)" = "" ]
+
+  # The default project can't be touched
+  ! lxc project rename default bar || false
+  ! lxc project delete default || false
+  ! lxc project set default features.profiles false || false
+
+  # Rename and delete the project
+  lxc project rename foo bar
+  lxc project list | grep -q bar
+  ! lxc project list | grep -q foo || false
+  lxc project delete bar
+  ! lxc project list | grep -q bar || false
+
+  # Unknown projects are rejected
+  ! lxc list --project foo || false
+}
+
+test_projects_containers() {
+  ensure_import_testimage
+
+  pool=$(lxc profile device get default root pool)
+
+  # Create a project with its own profiles, sharing the default images
+  lxc project create foo features.profiles=true
+  lxc profile device add --project foo default root disk path="/" pool="${pool}"
+
+  # Containers in different projects can share the same name
+  lxc init testimage c1
+  lxc init testimage c1 --project foo
+  lxc list --project foo --format csv -c n | grep -qx c1
+  [ "$(lxc list --format csv -c n | grep -c c1)" = "1" ]
+
+  # Each copy is managed independently
+  lxc start c1 --project foo
+  lxc info c1 --project foo | grep -q RUNNING
+  lxc info c1 | grep -q STOPPED
+  lxc stop c1 --project foo --force
+
+  # Snapshots stay within the project
+  lxc snapshot c1 snap0 --project foo
+  lxc info c1 --project foo | grep -q snap0
+  ! lxc info c1 | grep -q snap0 || false
+
+  # A project can't be deleted or have its features changed while in use
+  ! lxc project delete foo || false
+  ! lxc project set foo features.profiles false || false
+
+  lxc delete c1 --project foo
+  lxc delete c1
+  lxc project delete foo
+}
+
+test_projects_profiles() {
+  # Projects without the profiles feature use the default project profiles
+  lxc project create foo
+  lxc profile list --project foo | grep -q default
+  lxc profile create p1 --project foo
+  lxc profile show p1 | grep -q "name: p1"
+  lxc profile delete p1
+
+  # Projects with the profiles feature have their own profiles
+  lxc project set foo features.profiles true
+  lxc profile create p1 --project foo
+  ! lxc profile show p1 || false
+  lxc profile create p1
+  lxc profile set p1 user.test foo --project foo
+  [ "$(lxc profile get p1 user.test --project foo)" = "foo" ]
+  [ "$(lxc profile get p1 user.test)" = "" ]
+
+  # Names reserved for the project can't be used in the default project
+  ! lxc profile create foo_p2 || false
+
+  lxc profile delete p1 --project foo
+  lxc profile delete p1
+  lxc project delete foo
+}
+
+test_projects_images() {
+  ensure_import_testimage
+
+  fingerprint=$(lxc image info testimage | grep ^Fingerprint | cut -d' ' -f2)
+
+  # Projects without the images feature see the default project images
+  lxc project create foo
+  lxc image list --project foo | grep -q testimage
+  lxc image show "${fingerprint}" --project foo
+
+  # Projects with the images feature have their own images and aliases
+  lxc project set foo features.images true
+  ! lxc image show "${fingerprint}" --project foo || false
+  ! lxc image list --project foo | grep -q testimage || false
+
+  # Adding the same image to the project shares its files
+  lxc image export testimage "${LXD_DIR}/"
+  lxc image import "${LXD_DIR}/${fingerprint}.tar.xz" --alias testimage --project foo
+  lxc image show "${fingerprint}" --project foo
+  lxc image alias list --project foo | grep -q testimage
+
+  # Deleting the image from the project leaves the default project untouched
+  lxc image delete testimage --project foo
+  ! lxc image show "${fingerprint}" --project foo || false
+  lxc image show "${fingerprint}"
+  rm "${LXD_DIR}/${fingerprint}.tar.xz"
+
+  lxc project delete foo
+}
+
+test_projects_restricted_certificate() {
+  ensure_import_testimage
+
+  gen_cert restricted
+  restricted_curl() {
+    curl -k -s --cert "${LXD_CONF}/restricted.crt" --key "${LXD_CONF}/restricted.key" "$@"
+  }
+
+  lxc project create foo
+  lxc init testimage c1
+  lxc init testimage c1 --project foo
+
+  # Add a certificate only allowed to access the foo project
+  lxc config trust add "${LXD_CONF}/restricted.crt" --restricted --projects=foo
+  fingerprint=$(lxc query /1.0/certificates?recursion=1 | jq -r '.[] | select(.restricted == true) | .fingerprint')
+  [ "$(lxc query "/1.0/certificates/${fingerprint}" | jq -r '.projects[0]')" = "foo" ]
+
+  # Access to the foo project is allowed
+  restricted_curl "https://${LXD_ADDR}/1.0/containers?project=foo" | grep -q "/1.0/containers/c1?project=foo"
+  restricted_curl "https://${LXD_ADDR}/1.0/projects/foo" | grep -q '"name":"foo"'
+
+  # Access to other projects and to the server configuration is denied
+  restricted_curl "https://${LXD_ADDR}/1.0/containers" | grep -q 403
+  restricted_curl "https://${LXD_ADDR}/1.0/projects/default" | grep -q 403
+  restricted_curl "https://${LXD_ADDR}/1.0/certificates" | grep -q 403
+  restricted_curl "https://${LXD_ADDR}/1.0/networks" | grep -q 403
+  restricted_curl -X PUT -d '{"config": {}}' "https://${LXD_ADDR}/1.0" | grep -q 403
+  ! restricted_curl "https://${LXD_ADDR}/1.0" | grep -q core.https_address || false
+
+  # Images shared with the default project can't be modified
+  restricted_curl "https://${LXD_ADDR}/1.0/images?project=foo" | grep -q "/1.0/images/"
+  restricted_curl -X DELETE "https://${LXD_ADDR}/1.0/images/aliases/testimage?project=foo" | grep -q 403
+
+  # Lifting the restriction gives full access
+  lxc query -X PATCH -d '{"restricted": false, "projects": []}' "/1.0/certificates/${fingerprint}"
+  restricted_curl "https://${LXD_ADDR}/1.0/containers" | grep -q "/1.0/containers/c1"
+
+  lxc config trust remove "${fingerprint}"
+  lxc delete c1 --project foo
+  lxc delete c1
+  lxc project delete foo
+}
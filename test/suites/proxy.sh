@@ -0,0 +1,48 @@
+test_proxy_device() {
+  ensure_import_testimage
+
+  MESSAGE="Proxy device test string"
+  HOST_TCP_PORT=$(local_tcp_port)
+
+  lxc launch testimage proxyTester
+  lxc config device add proxyTester proxyDev proxy "listen=tcp:127.0.0.1:${HOST_TCP_PORT}" connect=tcp:127.0.0.1:4321 bind=host
+
+  # The proxy process should be running
+  [ -e "${LXD_DIR}/devices/proxyTester/proxy.proxyDev" ]
+  PROXY_PID=$(cat "${LXD_DIR}/devices/proxyTester/proxy.proxyDev")
+  grep -q forkproxy "/proc/${PROXY_PID}/cmdline"
+
+  # Forward a message into the container
+  nsenter -n -t "$(lxc info proxyTester | grep ^Pid | awk '{print $2}')" -- nc -l 127.0.0.1 4321 > proxyTest.out &
+  NSENTER_PID=$!
+  sleep 1
+
+  echo "${MESSAGE}" | nc -w1 127.0.0.1 "${HOST_TCP_PORT}"
+  wait "${NSENTER_PID}" || true
+
+  if [ "$(cat proxyTest.out)" != "${MESSAGE}" ]; then
+    cat proxyTest.out
+    rm -f proxyTest.out
+    false
+  fi
+  rm -f proxyTest.out
+
+  # Invalid configurations are rejected
+  ! lxc config device set proxyTester proxyDev connect udp:127.0.0.1:4321 || false
+  ! lxc config device set proxyTester proxyDev bind foo || false
+
+  # Stopping the container stops the proxy
+  lxc stop proxyTester --force
+  ! [ -e "/proc/${PROXY_PID}" ] || false
+  [ ! -e "${LXD_DIR}/devices/proxyTester/proxy.proxyDev" ]
+
+  # Starting it brings the proxy back
+  lxc start proxyTester
+  [ -e "${LXD_DIR}/devices/proxyTester/proxy.proxyDev" ]
+
+  # Hot-unplug
+  lxc config device remove proxyTester proxyDev
+  [ ! -e "${LXD_DIR}/devices/proxyTester/proxy.proxyDev" ]
+
+  lxc delete proxyTester --force
+}
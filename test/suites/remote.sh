@@ -70,6 +70,39 @@ test_remote_admin() {
   fi
 }
 
+test_remote_token() {
+  token_conf=$(mktemp -d -p "${TEST_DIR}" XXX)
+  other_conf=$(mktemp -d -p "${TEST_DIR}" XXX)
+
+  # Pending tokens are listed and can be revoked
+  lxc config trust add --name revoked-client
+  lxc config trust list-tokens | grep -q revoked-client
+  lxc config trust revoke-token revoked-client
+  ! lxc config trust list-tokens | grep -q revoked-client || false
+
+  # A token lets a new client add itself to the trust store, once
+  token=$(lxc config trust add --name token-client | tail -n1)
+  LXD_CONF="${token_conf}" lxc_remote remote add token-remote "${token}"
+  LXD_CONF="${token_conf}" lxc_remote list token-remote:
+  ! lxc config trust list-tokens | grep -q token-client || false
+  ! LXD_CONF="${other_conf}" lxc_remote remote add token-remote "${token}" || false
+
+  fingerprint=$(lxc query "/1.0/certificates?recursion=1" | jq -r '.[] | select(.name == "token-client") | .fingerprint')
+  [ -n "${fingerprint}" ]
+  lxc config trust remove "${fingerprint}"
+
+  # Without a name, the remote is named after the server address
+  token=$(lxc config trust add --name token-client | tail -n1)
+  LXD_CONF="${other_conf}" lxc_remote remote add "${token}"
+  LXD_CONF="${other_conf}" lxc_remote remote list | grep -q "${LXD_ADDR%:*}"
+  LXD_CONF="${other_conf}" lxc_remote list "${LXD_ADDR%:*}:"
+
+  fingerprint=$(lxc query "/1.0/certificates?recursion=1" | jq -r '.[] | select(.name == "token-client") | .fingerprint')
+  lxc config trust remove "${fingerprint}"
+
+  rm -rf "${token_conf}" "${other_conf}"
+}
+
 test_remote_usage() {
   # shellcheck disable=2039
   local LXD2_DIR LXD2_ADDR
@@ -23,4 +23,11 @@ test_server_config() {
   # macaroons are also enabled
   curl --unix-socket "$LXD_DIR/unix.socket" "lxd/1.0" | jq .metadata.auth_methods | grep macaroons
   lxc config unset core.macaroon.endpoint
+
+  # configuration metadata
+  curl --unix-socket "$LXD_DIR/unix.socket" "lxd/1.0/metadata/configuration" | jq -r '.metadata.configs.server["images.remote_cache_expiry"].type' | grep -q integer
+  lxc config keys container | grep -q limits.memory.enforce
+  lxc config keys server | grep -q core.trust_password
+  ! lxc config set core.https_allowed_credentials maybe || false
+  ! lxc config set core.no_such_key foo || false
 }
@@ -218,3 +218,32 @@ restore_and_compare_fs() {
     diff -r "${LXD_DIR}/containers/bar/rootfs" "${LXD_DIR}/snapshots/bar/${snap}/rootfs"
   fi
 }
+
+test_snap_schedule() {
+  ensure_import_testimage
+
+  lxc init testimage c1
+
+  # Check config validation
+  ! lxc config set c1 snapshots.schedule "invalid" || false
+  ! lxc config set c1 snapshots.schedule "* * * *" || false
+  ! lxc config set c1 snapshots.expiry "1x" || false
+  lxc config set c1 snapshots.schedule "*/5 * * * *"
+  lxc config set c1 snapshots.expiry "1d 2H"
+  lxc info c1 | grep -q "Snapshot schedule: \*/5 \* \* \* \*"
+
+  # Unnamed snapshots follow snapshots.pattern and snapshots.expiry
+  lxc config set c1 snapshots.pattern "daily-%d"
+  lxc snapshot c1
+  lxc snapshot c1
+  lxc info c1 | grep -q "daily-0 .*expires at"
+  lxc info c1 | grep -q "daily-1"
+
+  # Snapshots with an expiry in the past get deleted
+  wait_for "${LXD_ADDR}" my_curl -X POST "https://${LXD_ADDR}/1.0/containers/c1/snapshots" -d "{\"name\":\"expired\",\"expires_at\":\"2000-01-01T00:00:00Z\"}"
+  lxc info c1 | grep -q expired
+  sleep 65
+  ! lxc info c1 | grep -q expired || false
+
+  lxc delete c1
+}
@@ -0,0 +1,70 @@
+test_storage_volume_snapshots() {
+  ensure_import_testimage
+
+  pool="lxdtest-$(basename "${LXD_DIR}")"
+
+  lxc storage volume create "${pool}" vol1
+  lxc launch testimage c1
+  lxc storage volume attach "${pool}" vol1 c1 /mnt
+  lxc exec c1 -- touch /mnt/foo
+
+  # Create snapshots
+  lxc storage volume snapshot "${pool}" vol1
+  lxc storage volume show "${pool}" vol1/snap0
+  ! lxc storage volume snapshot "${pool}" vol1 snap0 || false
+
+  # Snapshots aren't listed as volumes
+  ! lxc storage volume list "${pool}" | grep -q snap0 || false
+
+  lxc exec c1 -- rm /mnt/foo
+  lxc exec c1 -- touch /mnt/bar
+
+  # Restoring a volume used by a running container isn't allowed
+  ! lxc storage volume restore "${pool}" vol1 snap0 || false
+  lxc stop c1 --force
+  lxc storage volume restore "${pool}" vol1 snap0
+  lxc start c1
+  lxc exec c1 -- test -f /mnt/foo
+  ! lxc exec c1 -- test -f /mnt/bar || false
+
+  # Rename snapshots
+  lxc storage volume rename "${pool}" vol1/snap0 vol1/snap1
+  ! lxc storage volume show "${pool}" vol1/snap0 || false
+  lxc storage volume show "${pool}" vol1/snap1
+
+  # Volumes with snapshots can't be renamed
+  ! lxc storage volume rename "${pool}" vol1 vol3 || false
+
+  # Copy volumes
+  lxc storage volume copy "${pool}/vol1" "${pool}/vol2"
+  ! lxc storage volume copy "${pool}/vol1" "${pool}/vol2" || false
+  lxc storage volume attach "${pool}" vol2 c1 /mnt2
+  lxc exec c1 -- test -f /mnt2/foo
+  lxc storage volume detach "${pool}" vol2 c1
+
+  # Copy volumes to a different pool, keeping their user configuration
+  pool2="lxdtest-$(basename "${LXD_DIR}")-copy"
+  lxc storage create "${pool2}" dir
+  lxc storage volume set "${pool}" vol1 user.foo bar
+  lxc storage volume copy "${pool}/vol1" "${pool2}/vol1"
+  [ "$(lxc storage volume get "${pool2}" vol1 user.foo)" = "bar" ]
+  lxc storage volume attach "${pool2}" vol1 c1 /mnt3
+  lxc exec c1 -- test -f /mnt3/foo
+  lxc storage volume detach "${pool2}" vol1 c1
+  lxc storage volume delete "${pool2}" vol1
+  lxc storage delete "${pool2}"
+
+  # Delete snapshots
+  lxc storage volume delete "${pool}" vol1/snap1
+  ! lxc storage volume show "${pool}" vol1/snap1 || false
+
+  # Deleting a volume deletes its snapshots
+  lxc delete -f c1
+  lxc storage volume snapshot "${pool}" vol1
+  lxc storage volume delete "${pool}" vol1
+  lxc storage volume create "${pool}" vol1
+  ! lxc storage volume show "${pool}" vol1/snap0 || false
+
+  lxc storage volume delete "${pool}" vol1
+  lxc storage volume delete "${pool}" vol2
+}
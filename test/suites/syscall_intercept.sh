@@ -0,0 +1,42 @@
+test_container_syscall_intercept() {
+  ensure_import_testimage
+
+  # Interception is mutually exclusive with whitelisting and raw policies
+  ! lxc init testimage c1 -c security.syscalls.whitelist=read -c security.syscalls.intercept.mknod=true || false
+  ! lxc init testimage c1 -c raw.seccomp=foo -c security.syscalls.intercept.setxattr=true || false
+
+  if ! grep -qw user_notif /proc/sys/kernel/seccomp/actions_avail 2>/dev/null; then
+    echo "==> SKIP: syscall interception requires kernel support for seccomp user notifications"
+    return
+  fi
+
+  lxc_version=$(lxc info | grep "driver_version: " | cut -d' ' -f4)
+  lxc_major=$(echo "${lxc_version}" | cut -d. -f1)
+  lxc_minor=$(echo "${lxc_version}" | cut -d. -f2)
+
+  if [ "${lxc_major}" -lt 3 ] || ([ "${lxc_major}" = "3" ] && [ "${lxc_minor}" -lt "2" ]); then
+    echo "==> SKIP: syscall interception requires liblxc 3.2 or higher"
+    return
+  fi
+
+  lxc launch testimage c1
+
+  # Without interception, unprivileged containers can't create devices
+  ! lxc exec c1 -- mknod /root/null c 1 3 || false
+  lxc stop c1 --force
+
+  lxc config set c1 security.syscalls.intercept.mknod true
+  lxc start c1
+  grep -q "mknod notify" "${LXD_DIR}/security/seccomp/c1"
+
+  # Whitelisted devices are created and owned by the caller
+  lxc exec c1 -- mknod /root/null c 1 3
+  lxc exec c1 -- stat -c "%F %t:%T %u" /root/null | grep -q "character special file 1:3 0"
+  lxc exec c1 -- sh -c "echo foo > /root/null"
+
+  # Others are still refused
+  ! lxc exec c1 -- mknod /root/mem c 1 1 || false
+  ! lxc exec c1 -- mknod /root/sda b 8 0 || false
+
+  lxc delete --force c1
+}
@@ -0,0 +1,38 @@
+test_container_sysctl() {
+  lxc_version=$(lxc info | grep "driver_version: " | cut -d' ' -f4)
+  lxc_major=$(echo "${lxc_version}" | cut -d. -f1)
+
+  if [ "${lxc_major}" -lt 3 ]; then
+    echo "==> SKIP: container sysctls require liblxc 3.0 or higher"
+    return
+  fi
+
+  host_shmmni=$(cat /proc/sys/kernel/shmmni)
+
+  ensure_import_testimage
+  lxc init testimage sysctl
+
+  # Only namespaced sysctls are allowed
+  ! lxc config set sysctl linux.sysctl.kernel.panic 1 || false
+  ! lxc config set sysctl linux.sysctl.vm.swappiness 10 || false
+  ! lxc config set sysctl linux.sysctl. 1 || false
+  ! lxc config set sysctl linux.sysctl.net.x/../../kernel/core_pattern foo || false
+  ! lxc config set sysctl linux.sysctl.net..core.somaxconn 1024 || false
+  ! lxc config set sysctl "linux.sysctl.net.core somaxconn" 1024 || false
+
+  # Applied on start
+  lxc config set sysctl linux.sysctl.net.ipv4.ip_forward 1
+  lxc start sysctl
+  [ "$(lxc exec sysctl -- cat /proc/sys/net/ipv4/ip_forward)" = "1" ]
+
+  # Applied live
+  lxc config set sysctl linux.sysctl.net.ipv4.ip_forward 0
+  [ "$(lxc exec sysctl -- cat /proc/sys/net/ipv4/ip_forward)" = "0" ]
+  lxc config set sysctl linux.sysctl.kernel.shmmni 1024
+  [ "$(lxc exec sysctl -- cat /proc/sys/kernel/shmmni)" = "1024" ]
+
+  # The host is left alone
+  [ "$(cat /proc/sys/kernel/shmmni)" = "${host_shmmni}" ]
+
+  lxc delete --force sysctl
+}